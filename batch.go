@@ -0,0 +1,253 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Batch queues statements that are executed together by [ExecBatch]
+// in as few round trips to the database as the connection supports.
+// The zero value is an empty batch ready to use.
+//
+// Example:
+//
+//	var batch sqldb.Batch
+//	batch.Exec("UPDATE account SET balance = balance - $1 WHERE id = $2", amount, fromID)
+//	batch.Exec("UPDATE account SET balance = balance + $1 WHERE id = $2", amount, toID)
+//	batch.Query(
+//		func(rows sqldb.Rows) error {
+//			for rows.Next() {
+//				...
+//			}
+//			return rows.Err()
+//		},
+//		"SELECT id, balance FROM account WHERE id IN ($1, $2)", fromID, toID,
+//	)
+//	results, err := sqldb.ExecBatch(ctx, conn, &batch)
+type Batch struct {
+	statements []BatchStatement
+}
+
+// BatchStatement is a statement queued in a [Batch].
+type BatchStatement struct {
+	Query string
+	Args  []any
+
+	// ScanRows is called with the result rows of a statement
+	// queued with [Batch.Query] and is nil for statements
+	// queued with [Batch.Exec].
+	// ScanRows must not close the rows.
+	ScanRows func(rows Rows) error
+}
+
+// Exec queues a statement that is executed like [Executor.Exec].
+func (b *Batch) Exec(query string, args ...any) {
+	b.statements = append(b.statements, BatchStatement{Query: query, Args: args})
+}
+
+// Query queues a query whose result rows are passed to scanRows
+// when the batch is executed. The rows are discarded if scanRows is nil.
+// A scanRows error is returned as error of the statement
+// but does not abort the following statements of the batch.
+func (b *Batch) Query(scanRows func(rows Rows) error, query string, args ...any) {
+	if scanRows == nil {
+		scanRows = func(Rows) error { return nil }
+	}
+	b.statements = append(b.statements, BatchStatement{Query: query, Args: args, ScanRows: scanRows})
+}
+
+// Len returns the number of queued statements.
+func (b *Batch) Len() int {
+	return len(b.statements)
+}
+
+// Statements returns the queued statements.
+func (b *Batch) Statements() []BatchStatement {
+	return b.statements
+}
+
+// BatchResult is the result of a [BatchStatement].
+type BatchResult struct {
+	// RowsAffected is the number of rows affected by an Exec statement,
+	// or -1 for Query statements and if the database driver
+	// does not report it for statements executed in one round trip.
+	RowsAffected int64
+
+	// Err is the error of the statement.
+	// Statements that were not executed because of the database error
+	// of a previous statement have [ErrBatchAborted] as error.
+	Err error
+}
+
+// BatchExecer is implemented by connections that can execute
+// multiple statements in a single round trip to the database.
+// Use [ExecBatch] to fall back to executing the statements one after another
+// for connections without BatchExecer support.
+type BatchExecer interface {
+	// ExecBatch executes the statements in order and returns one result per statement.
+	// A database error of a statement aborts the batch,
+	// so the following statements have [ErrBatchAborted] as error.
+	ExecBatch(ctx context.Context, statements []BatchStatement) []BatchResult
+}
+
+// ExecBatch executes the statements of the batch in order
+// using the [BatchExecer] implementation of conn if available,
+// also if conn is wrapped with interceptors whose ExecBatch hooks are called,
+// else with [ExecBatchSequentially] one statement per round trip,
+// so code using batches stays portable across connections.
+//
+// Returns one result per statement and the first statement error
+// wrapped with the statement index and query.
+// A database error of a statement aborts the batch,
+// so the following statements have [ErrBatchAborted] as error.
+// Use a transaction to roll back the already executed statements.
+func ExecBatch(ctx context.Context, conn Connection, batch *Batch) ([]BatchResult, error) {
+	statements := batch.Statements()
+	if len(statements) == 0 {
+		return nil, nil
+	}
+	var results []BatchResult
+	if execBatch, ok := batchExecerOf(conn); ok {
+		results = execBatch(ctx, statements)
+	} else {
+		results = ExecBatchSequentially(ctx, conn, statements)
+	}
+	for i := range results {
+		if err := results[i].Err; err != nil {
+			stmt := statements[i]
+			return results, fmt.Errorf("batch statement %d: %w", i, WrapErrorWithQuery(err, stmt.Query, stmt.Args, conn))
+		}
+	}
+	return results, nil
+}
+
+// batchExecerOf returns the ExecBatch method of the [BatchExecer]
+// implementation of conn or of a connection wrapped by conn
+// chained with the ExecBatch hooks of the interceptors in between.
+func batchExecerOf(conn Connection) (ExecBatchFunc, bool) {
+	return unwrapConnWithHooks(conn,
+		func(conn Connection) (ExecBatchFunc, bool) {
+			execer, ok := conn.(BatchExecer)
+			if !ok {
+				return nil, false
+			}
+			return execer.ExecBatch, true
+		},
+		(*interceptedConn).chainExecBatch,
+	)
+}
+
+// ExecBatchSequentially executes the statements one after another
+// with one round trip per statement and returns one result per statement.
+// A database error of a statement aborts the batch,
+// so the following statements have [ErrBatchAborted] as error.
+//
+// It is the fallback of [ExecBatch] and can be used by [BatchExecer]
+// implementations for statements they can't execute in a single round trip.
+func ExecBatchSequentially(ctx context.Context, conn interface {
+	Executor
+	Querier
+}, statements []BatchStatement) []BatchResult {
+	results := make([]BatchResult, len(statements))
+	for i, stmt := range statements {
+		if stmt.ScanRows == nil {
+			numRows, err := conn.ExecRowsAffected(ctx, stmt.Query, stmt.Args...)
+			if err != nil {
+				AbortBatchResults(results, i, err)
+				return results
+			}
+			results[i].RowsAffected = numRows
+			continue
+		}
+		results[i].RowsAffected = -1
+		rows := conn.Query(ctx, stmt.Query, stmt.Args...)
+		err := rows.Err()
+		if err == nil {
+			results[i].Err = stmt.ScanRows(rows)
+			err = rows.Err()
+		}
+		if e := rows.Close(); err == nil {
+			err = e
+		}
+		if err != nil {
+			AbortBatchResults(results, i, err)
+			return results
+		}
+	}
+	return results
+}
+
+// MultiResultSetRows are [Rows] with multiple result sets
+// like the *sql.Rows of a query with multiple statements.
+type MultiResultSetRows interface {
+	Rows
+
+	// NextResultSet prepares the next result set for reading.
+	// It returns false if there is no further result set
+	// or an error happened, see the Err method.
+	NextResultSet() bool
+}
+
+// ReadBatchResultSets returns the results of statements that were executed
+// as one multi-statement query by a [BatchExecer] implementation.
+// Every statement must return one result set in rows:
+// the result rows of query statements passed to their ScanRows function,
+// and a single row with the number of affected rows for exec statements.
+// A missing result set is reported as error of its statement with the error of rows
+// and the following statements get [ErrBatchAborted].
+// The returned aborted is true if a database error aborted the statements.
+// The rows are closed.
+func ReadBatchResultSets(rows MultiResultSetRows, statements []BatchStatement) (results []BatchResult, aborted bool) {
+	results = make([]BatchResult, len(statements))
+	for i, stmt := range statements {
+		if i > 0 && !rows.NextResultSet() {
+			err := rows.Err()
+			if err == nil {
+				err = errors.New("no result set for batch statement")
+			}
+			AbortBatchResults(results, i, errors.Join(err, rows.Close()))
+			return results, true
+		}
+		var err error
+		if stmt.ScanRows == nil {
+			results[i].RowsAffected, err = scanRowsAffected(rows)
+		} else {
+			results[i].RowsAffected = -1
+			err = stmt.ScanRows(rows)
+		}
+		if rowsErr := rows.Err(); rowsErr != nil {
+			AbortBatchResults(results, i, errors.Join(rowsErr, rows.Close()))
+			return results, true
+		}
+		results[i].Err = err
+	}
+	if err := rows.Close(); err != nil {
+		AbortBatchResults(results, len(results)-1, err)
+		return results, true
+	}
+	return results, false
+}
+
+func scanRowsAffected(rows Rows) (numRows int64, err error) {
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return -1, err
+		}
+		return -1, errors.New("no affected rows count in batch result set")
+	}
+	if err = rows.Scan(&numRows); err != nil {
+		return -1, err
+	}
+	return numRows, nil
+}
+
+// AbortBatchResults sets err as error of the result at index i
+// and [ErrBatchAborted] as error of all following results.
+// It is used by [BatchExecer] implementations.
+func AbortBatchResults(results []BatchResult, i int, err error) {
+	results[i] = BatchResult{RowsAffected: -1, Err: err}
+	for j := i + 1; j < len(results); j++ {
+		results[j] = BatchResult{RowsAffected: -1, Err: ErrBatchAborted}
+	}
+}
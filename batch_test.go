@@ -0,0 +1,253 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchExecerConn is a Connection implementing BatchExecer
+// that records the executed statements.
+type batchExecerConn struct {
+	Connection
+
+	statements []BatchStatement
+}
+
+func (c *batchExecerConn) ExecBatch(ctx context.Context, statements []BatchStatement) []BatchResult {
+	c.statements = statements
+	results := make([]BatchResult, len(statements))
+	for i := range results {
+		results[i].RowsAffected = int64(i)
+	}
+	return results
+}
+
+// multiResultSetRows implements MultiResultSetRows with MockRows.
+type multiResultSetRows struct {
+	*MockRows
+
+	next   []*MockRows
+	err    error // returned by Err after NextResultSet failed
+	failed bool
+	closed bool
+}
+
+func (r *multiResultSetRows) NextResultSet() bool {
+	if len(r.next) == 0 {
+		r.failed = r.err != nil
+		return false
+	}
+	r.MockRows, r.next = r.next[0], r.next[1:]
+	return true
+}
+
+func (r *multiResultSetRows) Err() error {
+	if r.failed {
+		return r.err
+	}
+	return r.MockRows.Err()
+}
+
+func (r *multiResultSetRows) Close() error {
+	r.closed = true
+	return r.MockRows.Close()
+}
+
+func scanIDs(ids *[]int64) func(rows Rows) error {
+	return func(rows Rows) error {
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			*ids = append(*ids, id)
+		}
+		return rows.Err()
+	}
+}
+
+func TestExecBatch(t *testing.T) {
+	t.Run("sequential fallback", func(t *testing.T) {
+		// given
+		conn, _, _, _ := newTestInterfaces()
+		conn.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+			return 2, nil
+		}
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("id").WithRow(int64(1)).WithRow(int64(2))
+		}
+		var ids []int64
+		var batch Batch
+		batch.Exec("UPDATE t SET x = $1", 1)
+		batch.Query(scanIDs(&ids), "SELECT id FROM t WHERE x = $1", 1)
+
+		// when
+		results, err := ExecBatch(t.Context(), conn, &batch)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []BatchResult{{RowsAffected: 2}, {RowsAffected: -1}}, results)
+		assert.Equal(t, []int64{1, 2}, ids)
+		assert.Len(t, conn.Recordings.Execs, 1)
+		assert.Len(t, conn.Recordings.Queries, 1)
+	})
+
+	t.Run("database error aborts batch", func(t *testing.T) {
+		// given
+		errExec := errors.New("exec error")
+		conn, _, _, _ := newTestInterfaces()
+		conn.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+			if strings.HasPrefix(query, "DELETE") {
+				return 0, errExec
+			}
+			return 1, nil
+		}
+		var batch Batch
+		batch.Exec("UPDATE t SET x = $1", 1)
+		batch.Exec("DELETE FROM t WHERE x = $1", 2)
+		batch.Exec("UPDATE t SET x = $1", 3)
+
+		// when
+		results, err := ExecBatch(t.Context(), conn, &batch)
+
+		// then
+		require.ErrorIs(t, err, errExec)
+		assert.Contains(t, err.Error(), "batch statement 1")
+		assert.Contains(t, err.Error(), "DELETE FROM t WHERE x = 2")
+		require.Len(t, results, 3)
+		assert.Equal(t, BatchResult{RowsAffected: 1}, results[0])
+		assert.ErrorIs(t, results[1].Err, errExec)
+		assert.ErrorIs(t, results[2].Err, ErrBatchAborted)
+		assert.Len(t, conn.Recordings.Execs, 2)
+	})
+
+	t.Run("scan error does not abort batch", func(t *testing.T) {
+		// given
+		errScan := errors.New("scan error")
+		conn, _, _, _ := newTestInterfaces()
+		conn.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+			return 1, nil
+		}
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("id").WithRow(int64(1))
+		}
+		var batch Batch
+		batch.Query(func(Rows) error { return errScan }, "SELECT id FROM t")
+		batch.Exec("UPDATE t SET x = 1")
+
+		// when
+		results, err := ExecBatch(t.Context(), conn, &batch)
+
+		// then
+		require.ErrorIs(t, err, errScan)
+		require.Len(t, results, 2)
+		assert.ErrorIs(t, results[0].Err, errScan)
+		assert.Equal(t, BatchResult{RowsAffected: 1}, results[1])
+	})
+
+	t.Run("BatchExecer", func(t *testing.T) {
+		// given
+		mock, _, _, _ := newTestInterfaces()
+		conn := &batchExecerConn{Connection: mock}
+		var batch Batch
+		batch.Exec("UPDATE t SET x = $1", 1)
+		batch.Query(nil, "SELECT 1")
+
+		// when
+		results, err := ExecBatch(t.Context(), conn, &batch)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []BatchResult{{RowsAffected: 0}, {RowsAffected: 1}}, results)
+		assert.Equal(t, batch.Statements(), conn.statements)
+		assert.Empty(t, mock.Recordings.Execs)
+	})
+
+	t.Run("BatchExecer with ExecBatch hook", func(t *testing.T) {
+		// given
+		var hooked [][]BatchStatement
+		mock, _, _, _ := newTestInterfaces()
+		base := &batchExecerConn{Connection: mock}
+		conn := WrapConnection(base, Interceptor{
+			ExecBatch: func(ctx context.Context, conn Connection, statements []BatchStatement, next ExecBatchFunc) []BatchResult {
+				hooked = append(hooked, statements)
+				return next(ctx, statements)
+			},
+		})
+		var batch Batch
+		batch.Exec("UPDATE t SET x = $1", 1)
+
+		// when
+		results, err := ExecBatch(t.Context(), conn, &batch)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []BatchResult{{RowsAffected: 0}}, results)
+		assert.Equal(t, [][]BatchStatement{batch.Statements()}, hooked)
+		assert.Equal(t, batch.Statements(), base.statements)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		conn, _, _, _ := newTestInterfaces()
+		results, err := ExecBatch(t.Context(), conn, new(Batch))
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestReadBatchResultSets(t *testing.T) {
+	t.Run("all result sets", func(t *testing.T) {
+		// given
+		var ids []int64
+		var batch Batch
+		batch.Exec("UPDATE t SET x = 1")
+		batch.Query(scanIDs(&ids), "SELECT id FROM t")
+		batch.Exec("DELETE FROM t")
+		rows := &multiResultSetRows{
+			MockRows: NewMockRows("row_count").WithRow(int64(3)),
+			next: []*MockRows{
+				NewMockRows("id").WithRow(int64(7)).WithRow(int64(8)),
+				NewMockRows("row_count").WithRow(int64(2)),
+			},
+		}
+
+		// when
+		results, aborted := ReadBatchResultSets(rows, batch.Statements())
+
+		// then
+		assert.False(t, aborted)
+		assert.Equal(t, []BatchResult{{RowsAffected: 3}, {RowsAffected: -1}, {RowsAffected: 2}}, results)
+		assert.Equal(t, []int64{7, 8}, ids)
+		assert.True(t, rows.closed)
+	})
+
+	t.Run("missing result set of failed statement", func(t *testing.T) {
+		// given
+		errStatement := errors.New("statement error")
+		var batch Batch
+		batch.Exec("UPDATE t SET x = 1")
+		batch.Exec("DELETE FROM t")
+		batch.Query(nil, "SELECT id FROM t")
+		rows := &multiResultSetRows{
+			MockRows: NewMockRows("row_count").WithRow(int64(3)),
+			err:      errStatement,
+		}
+
+		// when
+		results, aborted := ReadBatchResultSets(rows, batch.Statements())
+
+		// then
+		assert.True(t, aborted)
+		require.Len(t, results, 3)
+		assert.Equal(t, BatchResult{RowsAffected: 3}, results[0])
+		assert.ErrorIs(t, results[1].Err, errStatement)
+		assert.Equal(t, int64(-1), results[1].RowsAffected)
+		assert.ErrorIs(t, results[2].Err, ErrBatchAborted)
+		assert.True(t, rows.closed)
+	})
+}
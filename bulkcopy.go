@@ -0,0 +1,238 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"time"
+)
+
+// CopyRowSource is the source of the rows copied by [BulkCopier.CopyFrom].
+type CopyRowSource interface {
+	// Next advances to the next row and returns false
+	// if there are no more rows or an error happened.
+	Next() bool
+
+	// Values returns the column values of the current row.
+	Values() ([]any, error)
+
+	// Err returns the error, if any, that was encountered during iteration.
+	Err() error
+}
+
+// BulkCopier is implemented by connections that support copying
+// rows into a table with a native bulk loading protocol
+// that is much faster than INSERT statements,
+// like PostgreSQL COPY FROM STDIN.
+// Use [CopyFrom] to fall back to batched multi-row INSERT
+// statements for connections without BulkCopier support.
+type BulkCopier interface {
+	// CopyFrom copies all rows from the source into the columns of the table
+	// and returns the number of copied rows.
+	// If the connection is not a transaction, then implementations
+	// may use a transaction to copy all rows atomically.
+	CopyFrom(ctx context.Context, table string, columns []string, rows CopyRowSource) (numRows int64, err error)
+}
+
+// CopyRowsFromSlice returns a [CopyRowSource] for the passed rows.
+func CopyRowsFromSlice(rows [][]any) CopyRowSource {
+	return &sliceCopyRowSource{rows: rows, index: -1}
+}
+
+type sliceCopyRowSource struct {
+	rows  [][]any
+	index int
+}
+
+func (s *sliceCopyRowSource) Next() bool {
+	if s.index+1 >= len(s.rows) {
+		return false
+	}
+	s.index++
+	return true
+}
+
+func (s *sliceCopyRowSource) Values() ([]any, error) { return s.rows[s.index], nil }
+
+func (s *sliceCopyRowSource) Err() error { return nil }
+
+// CopyFrom copies all rows from the source into the columns of the table
+// using the [BulkCopier] implementation of conn if available,
+// also if conn is wrapped with interceptors whose CopyFrom hooks are called,
+// else with multi-row INSERT statements of at most MaxArgs() arguments
+// executed in a transaction.
+// Rows are read from the source while copying, so they don't have to
+// be loaded into memory at once except for one INSERT batch in the fallback.
+// Returns the number of copied rows.
+func CopyFrom(ctx context.Context, conn Connection, builder QueryBuilder, fmtr QueryFormatter, table string, columns []string, rows CopyRowSource) (numRows int64, err error) {
+	if len(columns) == 0 {
+		return 0, errors.New("CopyFrom: no columns")
+	}
+	if copyFrom, ok := bulkCopierOf(conn); ok {
+		numRows, err = copyFrom(ctx, table, columns, rows)
+		if err != nil {
+			return numRows, fmt.Errorf("CopyFrom table %s: %w", table, err)
+		}
+		return numRows, nil
+	}
+
+	numCols := len(columns)
+	rowsPerBatch := fmtr.MaxArgs() / numCols
+	if rowsPerBatch < 1 {
+		return 0, fmt.Errorf("CopyFrom: MaxArgs() %d is less than number of columns %d", fmtr.MaxArgs(), numCols)
+	}
+	columnInfos := make([]ColumnInfo, numCols)
+	for i, name := range columns {
+		columnInfos[i].Name = name
+	}
+	err = Transaction(ctx, conn, nil, func(tx Connection) error {
+		vals := make([]any, 0, rowsPerBatch*numCols)
+		insertBatch := func() error {
+			batchRows := len(vals) / numCols
+			query, err := builder.InsertRows(fmtr, table, columnInfos, batchRows)
+			if err != nil {
+				return fmt.Errorf("failed to create INSERT query: %w", err)
+			}
+			err = tx.Exec(ctx, query, vals...)
+			if err != nil {
+				return WrapErrorWithQuery(err, query, vals, fmtr)
+			}
+			numRows += int64(batchRows)
+			vals = vals[:0]
+			return nil
+		}
+		for rows.Next() {
+			rowVals, err := rows.Values()
+			if err != nil {
+				return err
+			}
+			if len(rowVals) != numCols {
+				return fmt.Errorf("CopyFrom: got %d values for %d columns", len(rowVals), numCols)
+			}
+			vals = append(vals, rowVals...)
+			if len(vals) == rowsPerBatch*numCols {
+				if err = insertBatch(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(vals) > 0 {
+			return insertBatch()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return numRows, nil
+}
+
+// bulkCopierOf returns the CopyFrom method of the [BulkCopier]
+// implementation of conn or of a connection wrapped by conn
+// chained with the CopyFrom hooks of the interceptors in between.
+func bulkCopierOf(conn Connection) (CopyFromFunc, bool) {
+	return unwrapConnWithHooks(conn,
+		func(conn Connection) (CopyFromFunc, bool) {
+			copier, ok := conn.(BulkCopier)
+			if !ok {
+				return nil, false
+			}
+			return copier.CopyFrom, true
+		},
+		(*interceptedConn).chainCopyFrom,
+	)
+}
+
+// CopyRowStructs copies the structs yielded by rowStructs as new rows
+// into the table for the given struct type using [CopyFrom],
+// so a [BulkCopier] implementation of conn is used if available.
+// The structs are reflected one at a time while copying,
+// so rowStructs can stream any number of rows in constant memory.
+// Use [slices.Values] to copy the structs of a slice.
+// Returns the number of copied rows.
+//
+// The table name is derived from the `db` struct tag of an embedded sqldb.TableName field
+// (e.g., sqldb.TableName `db:"my_table"`).
+// Column names are derived from the `db` struct tags of the struct's fields.
+// Optional QueryOption can be passed to ignore mapped columns.
+//
+// Fields with the created or updated tag option are copied with the time
+// of the [Clock] from ctx like with [InsertRowStruct] and are set
+// to that time when the struct is copied if rowStructs yields pointers.
+func CopyRowStructs[S StructWithTableName](ctx context.Context, conn Connection, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rowStructs iter.Seq[S], options ...QueryOption) (numRows int64, err error) {
+	if refl == nil {
+		return 0, errors.New("CopyRowStructs: nil StructReflector")
+	}
+	options = append(options, IgnoreReadOnly)
+	structType := reflect.TypeFor[S]()
+	for structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	columnInfos, err := refl.ReflectStructColumns(structType, options...)
+	if err != nil {
+		return 0, err
+	}
+	if len(columnInfos) == 0 {
+		return 0, fmt.Errorf("CopyRowStructs: no columns mapped for struct %s", structType)
+	}
+	table, err := refl.TableNameForStruct(structType)
+	if err != nil {
+		return 0, err
+	}
+	columns := make([]string, len(columnInfos))
+	for i := range columnInfos {
+		columns[i] = columnInfos[i].Name
+	}
+	timestamps, err := timestampFieldsOf(refl, structType, columnInfos, true)
+	if err != nil {
+		return 0, err
+	}
+
+	next, stop := iter.Pull(rowStructs)
+	defer stop()
+	source := &structCopyRowSource[S]{
+		next:       next,
+		refl:       refl,
+		options:    options,
+		timestamps: timestamps,
+		now:        timestamps.now(ctx),
+	}
+	return CopyFrom(ctx, conn, builder, fmtr, table, columns, source)
+}
+
+// structCopyRowSource implements CopyRowSource
+// by reflecting the values of pulled structs.
+type structCopyRowSource[S any] struct {
+	next       func() (S, bool)
+	refl       StructReflector
+	options    []QueryOption
+	timestamps timestampFields
+	now        time.Time
+	current    S
+}
+
+func (s *structCopyRowSource[S]) Next() bool {
+	var ok bool
+	s.current, ok = s.next()
+	return ok
+}
+
+func (s *structCopyRowSource[S]) Values() ([]any, error) {
+	structVal, err := derefStruct(reflect.ValueOf(s.current))
+	if err != nil {
+		return nil, err
+	}
+	vals, err := s.refl.ReflectStructValues(structVal, s.options...)
+	if err != nil {
+		return nil, err
+	}
+	s.timestamps.setValues(structVal, vals, s.now)
+	return vals, s.timestamps.writeBack(structVal, s.now)
+}
+
+func (s *structCopyRowSource[S]) Err() error { return nil }
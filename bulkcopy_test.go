@@ -0,0 +1,220 @@
+package sqldb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bulkCopierConn is a Connection implementing BulkCopier
+// that records the copied rows.
+type bulkCopierConn struct {
+	Connection
+
+	table   string
+	columns []string
+	rows    [][]any
+}
+
+func (c *bulkCopierConn) CopyFrom(ctx context.Context, table string, columns []string, rows CopyRowSource) (int64, error) {
+	c.table = table
+	c.columns = columns
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return 0, err
+		}
+		c.rows = append(c.rows, vals)
+	}
+	return int64(len(c.rows)), rows.Err()
+}
+
+func TestCopyFrom(t *testing.T) {
+	t.Run("fallback to batched inserts in transaction", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn, _, builder, _ := newTestInterfaces()
+		conn = conn.WithQueryLog(log)
+		conn.MockMaxArgs = 4
+		rows := CopyRowsFromSlice([][]any{{1, "a"}, {2, "b"}, {3, "c"}})
+
+		// when
+		numRows, err := CopyFrom(t.Context(), conn, builder, conn, "users", []string{"id", "name"}, rows)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), numRows)
+		assert.Equal(t,
+			"BEGIN;\n"+
+				"INSERT INTO users(id,name) VALUES(1,'a'),(2,'b');\n"+
+				"INSERT INTO users(id,name) VALUES(3,'c');\n"+
+				"COMMIT;\n",
+			log.String(),
+		)
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn, _, builder, fmtr := newTestInterfaces()
+		conn = conn.WithQueryLog(log)
+
+		// when
+		numRows, err := CopyFrom(t.Context(), conn, builder, fmtr, "users", []string{"id"}, CopyRowsFromSlice(nil))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), numRows)
+		assert.Equal(t, "BEGIN;\nCOMMIT;\n", log.String())
+	})
+
+	t.Run("wrong number of values rolls back", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn, _, builder, fmtr := newTestInterfaces()
+		conn = conn.WithQueryLog(log)
+		rows := CopyRowsFromSlice([][]any{{1, "a"}, {2}})
+
+		// when
+		numRows, err := CopyFrom(t.Context(), conn, builder, fmtr, "users", []string{"id", "name"}, rows)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, int64(0), numRows)
+		assert.Equal(t, "BEGIN;\nROLLBACK;\n", log.String())
+	})
+
+	t.Run("insert error", func(t *testing.T) {
+		// given
+		errInsert := errors.New("insert error")
+		conn, _, builder, fmtr := newTestInterfaces()
+		conn.MockExec = func(ctx context.Context, query string, args ...any) error { return errInsert }
+
+		// when
+		_, err := CopyFrom(t.Context(), conn, builder, fmtr, "users", []string{"id"}, CopyRowsFromSlice([][]any{{1}}))
+
+		// then
+		assert.ErrorIs(t, err, errInsert)
+	})
+
+	t.Run("no columns", func(t *testing.T) {
+		conn, _, builder, fmtr := newTestInterfaces()
+		_, err := CopyFrom(t.Context(), conn, builder, fmtr, "users", nil, CopyRowsFromSlice(nil))
+		assert.Error(t, err)
+	})
+
+	t.Run("BulkCopier", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		mock, _, builder, fmtr := newTestInterfaces()
+		conn := &bulkCopierConn{Connection: mock.WithQueryLog(log)}
+		rows := CopyRowsFromSlice([][]any{{1, "a"}, {2, "b"}})
+
+		// when
+		numRows, err := CopyFrom(t.Context(), conn, builder, fmtr, "users", []string{"id", "name"}, rows)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), numRows)
+		assert.Equal(t, "users", conn.table)
+		assert.Equal(t, []string{"id", "name"}, conn.columns)
+		assert.Equal(t, [][]any{{1, "a"}, {2, "b"}}, conn.rows)
+		assert.Empty(t, log.String(), "no INSERT statements")
+	})
+}
+
+func TestCopyFromInterceptor(t *testing.T) {
+	t.Run("CopyFrom hook", func(t *testing.T) {
+		// given
+		var hooked []string
+		mock, _, builder, fmtr := newTestInterfaces()
+		base := &bulkCopierConn{Connection: mock}
+		conn := WrapConnection(base, Interceptor{
+			CopyFrom: func(ctx context.Context, conn Connection, table string, columns []string, rows CopyRowSource, next CopyFromFunc) (int64, error) {
+				hooked = append(hooked, table)
+				return next(ctx, table, columns, rows)
+			},
+		})
+
+		// when
+		numRows, err := CopyFrom(t.Context(), conn, builder, fmtr, "users", []string{"id"}, CopyRowsFromSlice([][]any{{1}}))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), numRows)
+		assert.Equal(t, []string{"users"}, hooked)
+		assert.Equal(t, [][]any{{1}}, base.rows)
+	})
+
+	t.Run("hooks of nested interceptors in order", func(t *testing.T) {
+		// given
+		var hooked []string
+		hook := func(name string) Interceptor {
+			return Interceptor{
+				CopyFrom: func(ctx context.Context, conn Connection, table string, columns []string, rows CopyRowSource, next CopyFromFunc) (int64, error) {
+					hooked = append(hooked, name)
+					return next(ctx, table, columns, rows)
+				},
+			}
+		}
+		mock, _, builder, fmtr := newTestInterfaces()
+		base := &bulkCopierConn{Connection: mock}
+		conn := WrapConnection(WrapConnection(base, hook("inner")), hook("outer1"), hook("outer2"))
+
+		// when
+		_, err := CopyFrom(t.Context(), conn, builder, fmtr, "users", []string{"id"}, CopyRowsFromSlice([][]any{{1}}))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"outer1", "outer2", "inner"}, hooked)
+	})
+}
+
+func TestCopyRowStructs(t *testing.T) {
+	t.Run("BulkCopier", func(t *testing.T) {
+		// given
+		mock, refl, builder, fmtr := newTestInterfaces()
+		conn := &bulkCopierConn{Connection: mock}
+		items := []reflectTestStruct{
+			{ID: 1, Name: "Alice", Active: true},
+			{ID: 2, Name: "Bob"},
+		}
+
+		// when
+		numRows, err := CopyRowStructs(t.Context(), conn, refl, builder, fmtr, slices.Values(items))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), numRows)
+		assert.Equal(t, "test_table", conn.table)
+		assert.Equal(t, []string{"id", "name", "active"}, conn.columns)
+		assert.Equal(t, [][]any{{int64(1), "Alice", true}, {int64(2), "Bob", false}}, conn.rows)
+	})
+
+	t.Run("pointers with fallback", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn = conn.WithQueryLog(log)
+		items := []*reflectTestStruct{{ID: 1, Name: "Alice", Active: true}}
+
+		// when
+		numRows, err := CopyRowStructs(t.Context(), conn, refl, builder, fmtr, slices.Values(items), IgnoreColumns("active"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), numRows)
+		assert.Equal(t, "BEGIN;\nINSERT INTO test_table(id,name) VALUES(1,'Alice');\nCOMMIT;\n", log.String())
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		_, err := CopyRowStructs(t.Context(), conn, refl, builder, fmtr, slices.Values([]*reflectTestStruct{nil}))
+		assert.Error(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package sqldb
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"slices"
@@ -13,6 +14,10 @@ type reflectedStructField struct {
 	Column      ColumnInfo
 	StructField reflect.StructField
 	FieldIndex  []int // multi-level index for reflect.Value.FieldByIndex
+	// InlinePtrIndex is the index of the outermost inline
+	// pointer-to-struct field containing the field or nil.
+	// FieldIndex can't be accessed if that pointer is nil.
+	InlinePtrIndex []int
 }
 
 // reflectedStruct holds the cached reflection data
@@ -25,11 +30,34 @@ type reflectedStruct struct {
 type queryCache struct {
 	query              string
 	structFieldIndices [][]int
+	// columns are the columns of the fields at structFieldIndices
+	// for wrapping the field values with structFieldValue
+	columns []ColumnInfo
+	// versionIndex is the index in structFieldIndices of the version field
+	// that has to be incremented for optimistic locking, or -1 if there is none
+	versionIndex int
+	// softDelete is true if the query is a soft delete UPDATE
+	// that needs the deletion time as first value
+	softDelete bool
+	// timestamps are the fields with the created or updated tag option
+	timestamps timestampFields
 }
 
 type queryRowStructCacheEntry struct {
 	query        string
 	numPKColumns int
+	// notDeletedQuery excludes soft deleted rows
+	// if the struct has a soft delete column
+	notDeletedQuery string
+}
+
+// queryFor returns notDeletedQuery if set
+// and ctx was not returned by ContextWithDeleted.
+func (e queryRowStructCacheEntry) queryFor(ctx context.Context) string {
+	if e.notDeletedQuery != "" && !IsContextWithDeleted(ctx) {
+		return e.notDeletedQuery
+	}
+	return e.query
 }
 
 var (
@@ -66,7 +94,10 @@ func reflectStruct(reflector StructReflector, structType reflect.Type) (*reflect
 	reflectedStructCacheMtx.RUnlock()
 
 	// Cache miss — build and store
-	fields := flattenStructFields(reflector, structType, nil)
+	fields, err := flattenStructFields(reflector, structType, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
 	columnIndex := make(map[string]int, len(fields))
 	for i, f := range fields {
 		if _, exists := columnIndex[f.Column.Name]; exists {
@@ -94,7 +125,9 @@ func reflectStruct(reflector StructReflector, structType reflect.Type) (*reflect
 // flattenStructFields recursively traverses the struct type
 // and returns a flat list of all mapped fields with their
 // full field indices for FieldByIndex access.
-func flattenStructFields(reflector StructReflector, structType reflect.Type, parentIndex []int) []reflectedStructField {
+// Column names of inline struct fields are prefixed with
+// prefix and the ColumnInfo.InlinePrefix of the inline field.
+func flattenStructFields(reflector StructReflector, structType reflect.Type, parentIndex []int, prefix string, inlinePtrIndex []int) ([]reflectedStructField, error) {
 	var fields []reflectedStructField
 	for i := range structType.NumField() {
 		field := structType.Field(i)
@@ -102,23 +135,81 @@ func flattenStructFields(reflector StructReflector, structType reflect.Type, par
 		if !use {
 			continue
 		}
+		fieldIndex := slices.Concat(parentIndex, field.Index)
 		if column.Name == "" {
-			// Empty Name signals an embedded struct field — recurse into it.
-			embeddedFields := flattenStructFields(
+			// Empty Name signals an embedded or inline struct field — recurse into it.
+			fieldType := field.Type
+			embeddedPtrIndex := inlinePtrIndex
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+				if embeddedPtrIndex == nil {
+					embeddedPtrIndex = fieldIndex
+				}
+			}
+			if fieldType.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("embedded or inline field %s of %s is not a struct or pointer to a struct", field.Name, structType)
+			}
+			embeddedFields, err := flattenStructFields(
 				reflector,
-				field.Type,
-				slices.Concat(parentIndex, field.Index),
+				fieldType,
+				fieldIndex,
+				prefix+column.InlinePrefix,
+				embeddedPtrIndex,
 			)
+			if err != nil {
+				return nil, err
+			}
 			fields = append(fields, embeddedFields...)
 			continue
 		}
+		column.Name = prefix + column.Name
 		fields = append(fields, reflectedStructField{
-			Column:      column,
-			StructField: field,
-			FieldIndex:  slices.Concat(parentIndex, field.Index),
+			Column:         column,
+			StructField:    field,
+			FieldIndex:     fieldIndex,
+			InlinePtrIndex: inlinePtrIndex,
 		})
 	}
-	return fields
+	return fields, nil
+}
+
+// fieldByIndexAlloc returns the nested field of v by index
+// like reflect.Value.FieldByIndex but allocates nil struct
+// pointers on the way instead of panicking.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("can't allocate nil pointer %s of unexported field", v.Type())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// structFieldValuer is implemented by StructReflectors
+// that wrap field values as query arguments.
+type structFieldValuer interface {
+	fieldValue(field reflect.Value, column *ColumnInfo) any
+}
+
+// structFieldValue returns the value of the struct field with fieldIndex
+// for column as query argument for the cached queries, wrapped the same way
+// as by the reflector's ReflectStructColumnsFieldIndicesAndValues.
+func structFieldValue(refl StructReflector, structVal reflect.Value, fieldIndex []int, column *ColumnInfo) any {
+	field, err := structVal.FieldByIndexErr(fieldIndex)
+	if err != nil {
+		return nil // Field of nil inline struct pointer
+	}
+	if v, ok := refl.(structFieldValuer); ok {
+		return v.fieldValue(field, column)
+	}
+	return field.Interface()
 }
 
 // ClearQueryCaches clears all internal query caches.
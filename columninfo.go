@@ -13,7 +13,8 @@ package sqldb
 //     type of the mapped struct field as returned by
 //     [reflect.StructField.Type.String] (e.g. "string", "int",
 //     "*time.Time", "uu.ID"), and the boolean flags reflect tag
-//     options (`primarykey`, `default`, `readonly`). Generated is
+//     options (`primarykey`, `default`, `readonly`, `version`,
+//     `softdelete`, `created`, `updated`, `json`). Generated is
 //     always false on this path — the struct-tag vocabulary has no
 //     equivalent.
 //
@@ -45,8 +46,9 @@ type ColumnInfo struct {
 	//
 	// Empty Name has a special meaning when the ColumnInfo was
 	// produced by [StructReflector.MapStructField]: the entry
-	// represents an embedded struct field whose own fields should be
-	// flattened into the parent's column set. It is NOT a real
+	// represents an embedded or inline struct field whose own fields
+	// should be flattened into the parent's column set with
+	// [ColumnInfo.InlinePrefix] prepended to their column names. It is NOT a real
 	// column, and the other fields (Type, PrimaryKey, HasDefault,
 	// ReadOnly, Generated) are not meaningful in that case. Callers
 	// iterating reflection results must detect Name == "" and recurse
@@ -157,4 +159,91 @@ type ColumnInfo struct {
 	// false because the struct-tag layer has no notion of
 	// catalog-derived generation.
 	Generated bool
+
+	// Version is true when the column holds the row version
+	// for optimistic locking.
+	//
+	// From struct reflection: the field has the `version` tag option
+	// (e.g. `db:"version,version"`). [UpdateRowStruct], [UpsertRowStruct],
+	// [DeleteRowStruct], and their batch variants only change the row
+	// if its version column still has the value of the struct field,
+	// increment the column, and return [ErrStaleRow] otherwise.
+	//
+	// From database introspection: always false because
+	// optimistic locking is a convention of the application.
+	Version bool
+
+	// SoftDelete is true when the column holds the nullable
+	// deletion timestamp of soft deleted rows.
+	//
+	// From struct reflection: the field has the `softdelete` tag option
+	// (e.g. `db:"deleted_at,softdelete"`). [DeleteRowStruct] and its
+	// variants set the column to the current time instead of deleting
+	// the row, and [QueryRowStruct] excludes rows where it is not NULL
+	// unless the context was returned by [ContextWithDeleted].
+	//
+	// From database introspection: always false because
+	// soft deletion is a convention of the application.
+	SoftDelete bool
+
+	// Created is true when the column holds the creation time of the row.
+	//
+	// From struct reflection: the field has the `created` tag option
+	// (e.g. `db:"created_at,created"`). [InsertRowStruct], [UpsertRowStruct],
+	// and their variants set the column to the time of the [Clock]
+	// from the context if the field has the zero value.
+	// The column is never updated by [UpdateRowStruct]
+	// or on conflict by [UpsertRowStruct].
+	//
+	// From database introspection: always false.
+	Created bool
+
+	// Updated is true when the column holds the time of the last
+	// change of the row.
+	//
+	// From struct reflection: the field has the `updated` tag option
+	// (e.g. `db:"updated_at,updated"`). [InsertRowStruct], [UpdateRowStruct],
+	// [UpsertRowStruct], and their variants set the column to the time
+	// of the [Clock] from the context.
+	//
+	// From database introspection: always false.
+	Updated bool
+
+	// Patch is true when the column is mapped to a struct field
+	// whose type implements [PatchField] like [Patch].
+	//
+	// From struct reflection: set by [TaggedStructReflector]
+	// depending on the field type. [UpdateRowStruct] and [UpsertRowStruct]
+	// don't write the column if the field is not set.
+	//
+	// From database introspection: always false.
+	Patch bool
+
+	// JSON is true when the value of the mapped struct field
+	// is stored as JSON marshalled with encoding/json.
+	//
+	// From struct reflection: the field has the `json` tag option
+	// (e.g. `db:"settings,json"`). [TaggedStructReflector] wraps the field
+	// as driver.Valuer returning the JSON as string or NULL for nil
+	// pointers, maps, and slices, and as sql.Scanner unmarshalling
+	// the column value into the field. Suitable column types are
+	// jsonb on PostgreSQL, JSON on MySQL, NVARCHAR(MAX) on SQL Server,
+	// TEXT on SQLite, and JSON or CLOB on Oracle.
+	//
+	// From database introspection: always false.
+	JSON bool
+
+	// InlinePrefix is only meaningful with an empty Name when the
+	// ColumnInfo was produced by [StructReflector.MapStructField]
+	// for an embedded or inline struct field. It is prepended to the
+	// column names of the flattened fields of that struct.
+	//
+	// From struct reflection: the tag name of a struct or
+	// pointer-to-struct field with the `inline` tag option
+	// (e.g. `db:"billing_,inline"` maps the field Street of
+	// the inline struct to the column billing_street).
+	// Prefixes of nested inline fields are concatenated.
+	//
+	// From database introspection: always empty.
+	InlinePrefix string
 }
@@ -64,6 +64,22 @@ type Config struct {
 	// If ConnMaxLifetime <= 0, connections are not closed due to a connection's age.
 	ConnMaxLifetime time.Duration `json:"connMaxLifetime,omitempty"`
 
+	// DefaultQueryTimeout limits the duration of every statement
+	// if the context has no other timeout from [ContextWithQueryTimeout].
+	// It is enforced with a context deadline by [TimeoutInterceptor]
+	// and server-side where supported by the database.
+	//
+	// If DefaultQueryTimeout <= 0, statements have no default timeout.
+	DefaultQueryTimeout time.Duration `json:"defaultQueryTimeout,omitempty"`
+
+	// DefaultTransactionTimeout limits the duration of every transaction
+	// from Begin until Commit or Rollback.
+	// It is enforced with a context deadline by [TimeoutInterceptor]
+	// that rolls back the transaction when it expires.
+	//
+	// If DefaultTransactionTimeout <= 0, transactions have no default timeout.
+	DefaultTransactionTimeout time.Duration `json:"defaultTransactionTimeout,omitempty"`
+
 	// ListenerMinReconnectInterval is the minimum interval between
 	// reconnection attempts for a LISTEN/NOTIFY listener.
 	// Zero value means a default defined by the vendor package is used.
@@ -175,6 +191,8 @@ func (c *Config) URL() *url.URL {
 //   - MaxOpenConns
 //   - MaxIdleConns
 //   - ConnMaxLifetime
+//   - DefaultQueryTimeout
+//   - DefaultTransactionTimeout
 //
 // See also [ParseConfig]
 func (c *Config) String() string {
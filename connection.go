@@ -165,10 +165,10 @@ func (c connectionWithoutPlaceholderSubstitution) Begin(ctx context.Context, id
 // to the pool. Required for session-scoped state like pg_advisory_lock that must
 // live and die on one session.
 //
-// Not every driver implements ConnPinner, so type-assert at the call site
-// (the same pattern as [UpsertQueryBuilder] and [ReturningQueryBuilder]):
+// Not every driver implements ConnPinner, so look it up at the call site
+// with [ConnectionAs], which also finds it through wrapped connections:
 //
-//	if pinner, ok := conn.(sqldb.ConnPinner); ok {
+//	if pinner, ok := sqldb.ConnectionAs[sqldb.ConnPinner](conn); ok {
 //		pinned, err := pinner.Conn(ctx)
 //		// ...
 //		defer pinned.Close() // returns the session to the pool
@@ -188,8 +188,8 @@ type ConnPinner interface {
 }
 
 // PinConn checks out one dedicated session from conn and returns a
-// [PinnedConnection] pinned to it for the lifetime of the returned value, by
-// type-asserting conn to [ConnPinner]. The caller must Close the returned
+// [PinnedConnection] pinned to it for the lifetime of the returned value, using
+// the [ConnPinner] of conn found with [ConnectionAs]. The caller must Close the returned
 // Connection to return the session to the pool.
 //
 // PinConn returns [ErrWithinTransaction] if conn is already within a
@@ -204,7 +204,7 @@ func PinConn(ctx context.Context, conn Connection) (PinnedConnection, error) {
 	if conn.Transaction().Active() {
 		return nil, fmt.Errorf("PinConn: %w", ErrWithinTransaction)
 	}
-	pinner, ok := conn.(ConnPinner)
+	pinner, ok := ConnectionAs[ConnPinner](conn)
 	if !ok {
 		return nil, fmt.Errorf("PinConn: connection type %T does not implement sqldb.ConnPinner: %w", conn, errors.ErrUnsupported)
 	}
@@ -58,6 +58,13 @@ type Config struct {
 	// drivers with custom connection management (e.g., SQLite) may not.
 	ExecAfterClosedTxErrors bool
 
+	// SessionVarQuery is the SQL to select the value of the session
+	// variable "conntest.tenant_id" set with sqldb.ContextWithSessionVars
+	// as nullable string, e.g. current_setting('conntest.tenant_id', true)
+	// for PostgreSQL. The connection must implement sqldb.ConnPinner.
+	// If empty, the SessionVars tests are skipped.
+	SessionVarQuery string
+
 	// Information records which Information interface features are
 	// supported by this driver. Used by the Information test group.
 	Information InformationFeatures
@@ -84,7 +91,9 @@ type DDL struct {
 	// CreateReturningTable creates a table with columns:
 	//   id (auto-increment int PK), name (text NOT NULL), score (int NOT NULL DEFAULT 0).
 	// The table name MUST be "conntest_returning".
-	// May be empty if the vendor does not support ReturningQueryBuilder.
+	// Used by the Returning tests if the QueryBuilder implements
+	// ReturningQueryBuilder and by the InsertReturning tests.
+	// May be empty to skip those tests.
 	CreateReturningTable string
 
 	// CreateMailAddressTable creates a table with columns:
@@ -92,6 +101,14 @@ type DDL struct {
 	// The table name MUST be "conntest_mail_address".
 	CreateMailAddressTable string
 
+	// CreateJSONTable creates a table with columns:
+	//   id (int PK), data (JSON), tags (JSON).
+	// The JSON columns use the vendor type for JSON documents,
+	// e.g. jsonb on PostgreSQL or NVARCHAR(MAX) on SQL Server.
+	// The table name MUST be "conntest_json".
+	// May be empty to skip the JSON tests.
+	CreateJSONTable string
+
 	// CreateInfoParent creates a parent table for the Information test
 	// group with a composite primary key declared in NON-declaration
 	// order:
@@ -187,12 +204,15 @@ func RunAll(t *testing.T, config Config) {
 	t.Run("Query", func(t *testing.T) { runQueryTests(t, config) })
 	t.Run("Prepare", func(t *testing.T) { runPrepareTests(t, config) })
 	t.Run("Transaction", func(t *testing.T) { runTransactionTests(t, config) })
+	t.Run("SessionVars", func(t *testing.T) { runSessionVarsTests(t, config) })
 	t.Run("QueryBuilder", func(t *testing.T) { runQueryBuilderTests(t, config) })
 	t.Run("Upsert", func(t *testing.T) { runUpsertTests(t, config) })
 	t.Run("Returning", func(t *testing.T) { runReturningTests(t, config) })
+	t.Run("InsertReturning", func(t *testing.T) { runInsertReturningTests(t, config) })
 	t.Run("QueryCallback", func(t *testing.T) { runQueryCallbackTests(t, config) })
 	t.Run("Batch", func(t *testing.T) { runBatchTests(t, config) })
 	t.Run("MailAddress", func(t *testing.T) { runMailAddressTests(t, config) })
+	t.Run("JSON", func(t *testing.T) { runJSONTests(t, config) })
 	t.Run("Information", func(t *testing.T) { runInformationTests(t, config) })
 }
 
@@ -0,0 +1,64 @@
+package conntest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+type returningRow struct {
+	sqldb.TableName `db:"conntest_returning"`
+
+	ID    int    `db:"id,primarykey,default"`
+	Name  string `db:"name"`
+	Score int    `db:"score,default"`
+}
+
+func runInsertReturningTests(t *testing.T, config Config) {
+	if config.DDL.CreateReturningTable == "" {
+		t.Skip("no DDL provided for returning table")
+	}
+
+	t.Run("InsertRowStructReturning", func(t *testing.T) {
+		// given
+		conn := config.NewConn(t)
+		ctx := t.Context()
+		setupTable(t, conn, config.DDL.CreateReturningTable, "conntest_returning")
+		row := returningRow{ID: -1, Name: "alice", Score: -1}
+
+		// when
+		err := sqldb.InsertRowStructReturning(ctx, conn, refl, config.QueryBuilder, conn, &row)
+
+		// then
+		require.NoError(t, err)
+		assert.Greater(t, row.ID, 0)
+		assert.Equal(t, "alice", row.Name)
+		assert.Equal(t, 0, row.Score, "score should be DB default 0")
+	})
+
+	t.Run("InsertRowStructsReturning", func(t *testing.T) {
+		// given
+		conn := config.NewConn(t)
+		ctx := t.Context()
+		setupTable(t, conn, config.DDL.CreateReturningTable, "conntest_returning")
+		rows := []returningRow{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+		// when
+		err := sqldb.InsertRowStructsReturning(ctx, conn, refl, config.QueryBuilder, conn, rows)
+
+		// then
+		require.NoError(t, err)
+		for i, row := range rows {
+			assert.Greater(t, row.ID, 0)
+			if i > 0 {
+				assert.Greater(t, row.ID, rows[i-1].ID, "IDs in input order")
+			}
+			got, err := sqldb.QueryRowStruct[returningRow](ctx, conn, refl, config.QueryBuilder, conn, row.ID)
+			require.NoError(t, err)
+			assert.Equal(t, row, got, "generated values of row %d", i)
+		}
+	})
+}
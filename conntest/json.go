@@ -0,0 +1,109 @@
+package conntest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+type jsonData struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type jsonRow struct {
+	sqldb.TableName `db:"conntest_json"`
+
+	ID   int       `db:"id,primarykey"`
+	Data *jsonData `db:"data,json"`
+	Tags []string  `db:"tags,json"`
+}
+
+func runJSONTests(t *testing.T, config Config) {
+	if config.DDL.CreateJSONTable == "" {
+		t.Skip("CreateJSONTable DDL not provided")
+	}
+
+	t.Run("InsertAndQuery", func(t *testing.T) {
+		// given
+		conn := config.NewConn(t)
+		qb := config.QueryBuilder
+		setupTable(t, conn, config.DDL.CreateJSONTable, "conntest_json")
+		row := jsonRow{
+			ID:   1,
+			Data: &jsonData{Name: "Alice", Count: 3},
+			Tags: []string{"a", "b"},
+		}
+
+		// when
+		err := sqldb.InsertRowStruct(t.Context(), conn, refl, qb, conn, &row)
+		require.NoError(t, err)
+		got, err := sqldb.QueryRowStruct[jsonRow](t.Context(), conn, refl, qb, conn, 1)
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, row, got)
+	})
+
+	t.Run("InsertAndQueryNil", func(t *testing.T) {
+		// given
+		conn := config.NewConn(t)
+		qb := config.QueryBuilder
+		setupTable(t, conn, config.DDL.CreateJSONTable, "conntest_json")
+		row := jsonRow{ID: 2}
+
+		// when
+		err := sqldb.InsertRowStruct(t.Context(), conn, refl, qb, conn, &row)
+		require.NoError(t, err)
+		got, err := sqldb.QueryRowStruct[jsonRow](t.Context(), conn, refl, qb, conn, 2)
+		require.NoError(t, err)
+
+		// then
+		assert.Nil(t, got.Data)
+		assert.Nil(t, got.Tags)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		// given
+		conn := config.NewConn(t)
+		qb := config.QueryBuilder
+		setupTable(t, conn, config.DDL.CreateJSONTable, "conntest_json")
+		row := jsonRow{ID: 3, Data: &jsonData{Name: "Bob"}}
+		err := sqldb.InsertRowStruct(t.Context(), conn, refl, qb, conn, &row)
+		require.NoError(t, err)
+
+		// when
+		row.Data = nil
+		row.Tags = []string{"c"}
+		err = sqldb.UpdateRowStruct(t.Context(), conn, refl, qb, conn, &row)
+		require.NoError(t, err)
+		got, err := sqldb.QueryRowStruct[jsonRow](t.Context(), conn, refl, qb, conn, 3)
+		require.NoError(t, err)
+
+		// then
+		assert.Nil(t, got.Data)
+		assert.Equal(t, []string{"c"}, got.Tags)
+	})
+
+	t.Run("UnmarshalErrorNamesColumn", func(t *testing.T) {
+		// given
+		conn := config.NewConn(t)
+		qb := config.QueryBuilder
+		setupTable(t, conn, config.DDL.CreateJSONTable, "conntest_json")
+		err := sqldb.Insert(t.Context(), conn, qb, conn, "conntest_json", sqldb.Values{
+			"id":   4,
+			"data": `[1, 2]`,
+		})
+		require.NoError(t, err)
+
+		// when
+		_, err = sqldb.QueryRowStruct[jsonRow](t.Context(), conn, refl, qb, conn, 4)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "conntest_json.data")
+	})
+}
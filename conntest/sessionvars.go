@@ -0,0 +1,87 @@
+package conntest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func runSessionVarsTests(t *testing.T, config Config) {
+	if config.SessionVarQuery == "" {
+		t.Skip("session variables not supported")
+	}
+
+	// pinConn returns a pinned connection so that
+	// leaked session variables would be visible
+	// to later statements of the test.
+	pinConn := func(t *testing.T) sqldb.Connection {
+		t.Helper()
+		pinner, ok := sqldb.ConnectionAs[sqldb.ConnPinner](config.NewConn(t))
+		require.True(t, ok, "connection must implement sqldb.ConnPinner")
+		pinned, err := pinner.Conn(t.Context())
+		require.NoError(t, err)
+		t.Cleanup(func() { pinned.Close() })
+		return pinned
+	}
+	queryVar := func(ctx context.Context, t *testing.T, conn sqldb.Connection) sql.NullString {
+		t.Helper()
+		val, err := sqldb.QueryRowAs[sql.NullString](ctx, conn, refl, conn, config.SessionVarQuery)
+		require.NoError(t, err)
+		return val
+	}
+	vars := map[string]string{"conntest.tenant_id": "42"}
+
+	t.Run("Transaction", func(t *testing.T) {
+		// given
+		conn := pinConn(t)
+		ctx := sqldb.ContextWithSessionVars(t.Context(), vars)
+
+		// when
+		var inTx sql.NullString
+		err := sqldb.Transaction(ctx, conn, nil, func(tx sqldb.Connection) error {
+			inTx = queryVar(ctx, t, tx)
+			return nil
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "42", inTx.String)
+		assert.NotEqual(t, "42", queryVar(t.Context(), t, conn).String, "not leaked after COMMIT")
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		// given
+		conn := pinConn(t)
+		ctx := sqldb.ContextWithSessionVars(t.Context(), vars)
+
+		// when
+		err := sqldb.Transaction(ctx, conn, nil, func(tx sqldb.Connection) error {
+			return sql.ErrNoRows
+		})
+
+		// then
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NotEqual(t, "42", queryVar(t.Context(), t, conn).String, "not leaked after ROLLBACK")
+	})
+
+	t.Run("ImplicitTransaction", func(t *testing.T) {
+		// given
+		pinned := pinConn(t)
+		conn := sqldb.WrapConnection(pinned, sqldb.SessionVarsInterceptor())
+		ctx := sqldb.ContextWithSessionVars(t.Context(), vars)
+
+		// when
+		withVars := queryVar(ctx, t, conn)
+		withoutVars := queryVar(t.Context(), t, conn)
+
+		// then
+		assert.Equal(t, "42", withVars.String)
+		assert.NotEqual(t, "42", withoutVars.String)
+		assert.False(t, pinned.Transaction().Active())
+	})
+}
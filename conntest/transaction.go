@@ -1,15 +1,18 @@
 package conntest
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/domonda/go-sqldb"
+	"github.com/domonda/go-sqldb/db"
 )
 
 func runTransactionTests(t *testing.T, config Config) {
@@ -397,4 +400,57 @@ func runTransactionTests(t *testing.T, config Config) {
 		conn := config.NewConn(t)
 		assert.Equal(t, config.DefaultIsolationLevel, conn.DefaultIsolationLevel())
 	})
+
+	t.Run("TransactionWithRetry", func(t *testing.T) {
+		// given
+		conn := config.NewConn(t)
+		qb := config.QueryBuilder
+		setupTable(t, conn, config.DDL.CreateSimpleTable, "conntest_simple")
+		ctx := db.ContextWithConn(t.Context(), conn)
+		policy := &db.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+		attempts := 0
+
+		// when — the first attempt fails with a retryable error after inserting
+		err := db.TransactionWithRetry(ctx, policy, func(ctx context.Context) error {
+			attempts++
+			tx := db.Conn(ctx)
+			err := sqldb.InsertRowStruct(ctx, tx, refl, qb, tx, &simpleRow{ID: 1, Val: fmt.Sprint("attempt ", attempts)})
+			if err != nil {
+				return err
+			}
+			if attempts == 1 {
+				return fmt.Errorf("simulated: %w", sqldb.ErrDeadlock)
+			}
+			return nil
+		})
+
+		// then — the first attempt was rolled back and the second committed
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		got := querySimpleRow(t, conn, qb, 1)
+		assert.Equal(t, "attempt 2", got.Val)
+	})
+
+	t.Run("TransactionWithRetryNotRetryable", func(t *testing.T) {
+		// given
+		conn := config.NewConn(t)
+		qb := config.QueryBuilder
+		setupTable(t, conn, config.DDL.CreateSimpleTable, "conntest_simple")
+		insertSimpleRow(t, conn, qb, simpleRow{ID: 1, Val: "existing"})
+		ctx := db.ContextWithConn(t.Context(), conn)
+		policy := &db.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+		attempts := 0
+
+		// when — a unique violation from the database is not retryable
+		err := db.TransactionWithRetry(ctx, policy, func(ctx context.Context) error {
+			attempts++
+			tx := db.Conn(ctx)
+			return sqldb.InsertRowStruct(ctx, tx, refl, qb, tx, &simpleRow{ID: 1, Val: "duplicate"})
+		})
+
+		// then
+		require.Error(t, err)
+		assert.False(t, sqldb.IsRetryable(err), "unexpected retryable error: %v", err)
+		assert.Equal(t, 1, attempts)
+	})
 }
@@ -51,6 +51,27 @@ func runUpsertTests(t *testing.T, config Config) {
 			assert.Equal(t, "alice_updated", got.Name)
 			assert.Equal(t, 200, got.Score)
 		})
+
+		t.Run("UpdateColumns", func(t *testing.T) {
+			// given
+			conn := config.NewConn(t)
+			ctx := t.Context()
+			qb := config.QueryBuilder
+			setupTable(t, conn, config.DDL.CreateUpsertTable, "conntest_upsert")
+			require.NoError(t, sqldb.UpsertRowStruct(ctx, conn, refl, uqb, conn, &upsertRow{ID: 1, Name: "alice", Score: 100}))
+
+			// when
+			err := sqldb.UpsertRowStruct(ctx, conn, refl, uqb, conn,
+				&upsertRow{ID: 1, Name: "alice_updated", Score: 200},
+				sqldb.UpsertOptions{UpdateColumns: []string{"score"}},
+			)
+
+			// then
+			require.NoError(t, err)
+			got := queryUpsertRow(t, conn, qb, 1)
+			assert.Equal(t, "alice", got.Name, "name not updated")
+			assert.Equal(t, 200, got.Score)
+		})
 	})
 
 	t.Run("UpsertRowStructs", func(t *testing.T) {
@@ -12,4 +12,6 @@ var (
 	typeOfContext    = reflect.TypeFor[context.Context]()
 	typeOfSQLScanner = reflect.TypeFor[sql.Scanner]()
 	typeOfTime       = reflect.TypeFor[time.Time]()
+	typeOfTimePtr    = reflect.TypeFor[*time.Time]()
+	typeOfPatchField = reflect.TypeFor[PatchField]()
 )
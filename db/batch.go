@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// Batch queues statements that are executed together by [ExecBatch].
+// The zero value is an empty batch ready to use.
+type Batch = sqldb.Batch
+
+// BatchResult is the result of a statement executed by [ExecBatch].
+type BatchResult = sqldb.BatchResult
+
+// ExecBatch executes the statements of the batch in order
+// in as few round trips to the database as the connection supports,
+// see [sqldb.BatchExecer], else with one round trip per statement.
+//
+// Returns one result per statement and the first statement error.
+// A database error of a statement aborts the batch,
+// so the following statements have [sqldb.ErrBatchAborted] as error.
+// Use a transaction to roll back the already executed statements.
+//
+// Example:
+//
+//	var batch db.Batch
+//	batch.Exec("INSERT INTO audit_log (user_id, action) VALUES ($1, $2)", userID, "login")
+//	batch.Exec("UPDATE public.user SET last_login = now() WHERE id = $1", userID)
+//	results, err := db.ExecBatch(ctx, &batch)
+func ExecBatch(ctx context.Context, batch *Batch) ([]BatchResult, error) {
+	return sqldb.ExecBatch(ctx, Conn(ctx), batch)
+}
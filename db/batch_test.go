@@ -0,0 +1,34 @@
+package db_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+	"github.com/domonda/go-sqldb/db"
+)
+
+func TestExecBatch(t *testing.T) {
+	// given
+	log := new(bytes.Buffer)
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).WithQueryLog(log)
+	ctx := testContext(t, mock)
+	var batch db.Batch
+	batch.Exec("INSERT INTO audit_log (user_id) VALUES ($1)", 1)
+	batch.Exec("UPDATE public.user SET active = $1 WHERE id = $2", true, 1)
+
+	// when
+	results, err := db.ExecBatch(ctx, &batch)
+
+	// then
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t,
+		"INSERT INTO audit_log (user_id) VALUES (1);\n"+
+			"UPDATE public.user SET active = TRUE WHERE id = 1;\n",
+		log.String(),
+	)
+}
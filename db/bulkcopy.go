@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"iter"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// CopyFrom copies all rows from the source into the columns of the table
+// using the native bulk loading protocol of the connection
+// if it implements [sqldb.BulkCopier], like PostgreSQL COPY FROM STDIN,
+// else with batched multi-row INSERT statements within a transaction.
+// Returns the number of copied rows.
+func CopyFrom(ctx context.Context, table string, columns []string, rows sqldb.CopyRowSource) (numRows int64, err error) {
+	conn := Conn(ctx)
+	return sqldb.CopyFrom(
+		ctx,
+		conn,
+		QueryBuilder(ctx),
+		conn,
+		table,
+		columns,
+		rows,
+	)
+}
+
+// CopyRowStructs copies the structs yielded by rowStructs as new rows
+// into the table for the given struct type using [CopyFrom].
+// The structs are reflected one at a time while copying,
+// so rowStructs can stream any number of rows in constant memory.
+// Use [slices.Values] to copy the structs of a slice.
+// Returns the number of copied rows.
+//
+// Table name and column names are determined by the [StructReflector] from the context.
+// The default reflector uses `db` struct tags
+// (e.g., db.TableName `db:"my_table"`, field `db:"column"`).
+// Optional QueryOption can be passed to ignore mapped columns.
+func CopyRowStructs[S sqldb.StructWithTableName](ctx context.Context, rowStructs iter.Seq[S], options ...QueryOption) (numRows int64, err error) {
+	conn := Conn(ctx)
+	return sqldb.CopyRowStructs(
+		ctx,
+		conn,
+		StructReflector(ctx),
+		QueryBuilder(ctx),
+		conn,
+		rowStructs,
+		options...,
+	)
+}
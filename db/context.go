@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/domonda/go-sqldb"
 )
@@ -62,26 +63,43 @@ func SetStructReflector(structReflector sqldb.StructReflector) {
 
 // Conn returns the connection from the context
 // or the global connection that was configured with [SetConn].
+//
+// If the context has session variables added with [ContextWithSessionVars]
+// and the connection is not a transaction, then the connection is returned
+// wrapped with [sqldb.SessionVarsInterceptor] so that every statement
+// is executed within an implicit transaction that sets the variables.
+//
+// If the context has a query timeout added with [ContextWithQueryTimeout],
+// then the connection is returned wrapped with [sqldb.TimeoutInterceptor]
+// to enforce it.
 func Conn(ctx context.Context) sqldb.Connection {
-	if c, _ := ctx.Value(connCtxKey{}).(sqldb.Connection); c != nil {
-		return c
+	c, _ := ctx.Value(connCtxKey{}).(sqldb.Connection)
+	if c == nil {
+		globalConnMtx.RLock()
+		c = globalConn
+		globalConnMtx.RUnlock()
 	}
-	globalConnMtx.RLock()
-	c := globalConn
-	globalConnMtx.RUnlock()
-	return c
+	var interceptors []sqldb.Interceptor
+	if len(sqldb.SessionVarsFromContext(ctx)) > 0 && !c.Transaction().Active() {
+		interceptors = append(interceptors, sqldb.SessionVarsInterceptor())
+	}
+	if _, ok := sqldb.QueryTimeoutFromContext(ctx); ok {
+		interceptors = append(interceptors, sqldb.TimeoutInterceptor())
+	}
+	return sqldb.WrapConnection(c, interceptors...)
 }
 
 // QueryBuilder returns the [sqldb.QueryBuilder] for the given context.
 // It checks the following sources in order:
 //  1. A query builder stored in the context via [ContextWithQueryBuilder].
-//  2. The connection from [Conn] if it implements [sqldb.QueryBuilder].
+//  2. The connection from [Conn] if it or a connection wrapped by it
+//     implements [sqldb.QueryBuilder], see [sqldb.ConnectionAs].
 //  3. The global query builder configured with [SetQueryBuilder].
 func QueryBuilder(ctx context.Context) sqldb.QueryBuilder {
 	if qb, _ := ctx.Value(queryBuilderCtxKey{}).(sqldb.QueryBuilder); qb != nil {
 		return qb
 	}
-	if qb, ok := Conn(ctx).(sqldb.QueryBuilder); ok {
+	if qb, ok := sqldb.ConnectionAs[sqldb.QueryBuilder](Conn(ctx)); ok {
 		return qb
 	}
 	globalQueryBuilderMtx.RLock()
@@ -129,6 +147,139 @@ func ContextWithGlobalConn(ctx context.Context) context.Context {
 	return ContextWithConn(ctx, c)
 }
 
+// ContextWithPrimary returns a new context that makes a connection
+// created by [sqldb.NewReadWriteSplitConn] send all queries to the
+// primary connection instead of a replica, to read your own writes.
+func ContextWithPrimary(ctx context.Context) context.Context {
+	return sqldb.ContextWithPrimary(ctx)
+}
+
+// IsContextWithPrimary returns true if the context
+// was returned by [ContextWithPrimary].
+func IsContextWithPrimary(ctx context.Context) bool {
+	return sqldb.IsContextWithPrimary(ctx)
+}
+
+// ContextWithDeleted returns a new context that makes
+// [QueryRowStruct] and [QueryRowStructOr] also return rows
+// that were soft deleted by [DeleteRowStruct].
+func ContextWithDeleted(ctx context.Context) context.Context {
+	return sqldb.ContextWithDeleted(ctx)
+}
+
+// IsContextWithDeleted returns true if the context
+// was returned by [ContextWithDeleted].
+func IsContextWithDeleted(ctx context.Context) bool {
+	return sqldb.IsContextWithDeleted(ctx)
+}
+
+// ContextWithClock returns a new context that makes the struct insert,
+// update, and upsert functions use clock for the columns of struct fields
+// with the created or updated tag option instead of time.Now.
+//
+// Use [CurrentTimestamp] as clock to use the CURRENT_TIMESTAMP
+// of the database, which is the start time of the current transaction
+// for PostgreSQL:
+//
+//	ctx = db.ContextWithClock(ctx, db.CurrentTimestamp)
+//
+// See [sqldb.ContextWithClock] for details.
+func ContextWithClock(ctx context.Context, clock sqldb.Clock) context.Context {
+	return sqldb.ContextWithClock(ctx, clock)
+}
+
+// ClockFromContext returns the clock added to the context
+// with [ContextWithClock] or a clock returning time.Now().
+func ClockFromContext(ctx context.Context) sqldb.Clock {
+	return sqldb.ClockFromContext(ctx)
+}
+
+// ContextWithSessionVars returns a new context with the passed session
+// variables merged with the variables of the parent context.
+// The variables are set automatically for every transaction begun
+// with the context, and statements outside of transactions
+// are executed within an implicit transaction that sets them,
+// so the values never leak to other users of the connection pool.
+//
+// The variables are set as transaction-local settings
+// with set_config for PostgreSQL, as user variables that are
+// restored before the transaction ends for MySQL,
+// and with sp_set_session_context for SQL Server.
+//
+// Example:
+//
+//	ctx = db.ContextWithSessionVars(ctx, map[string]string{"app.tenant_id": tenantID})
+//
+//	err := db.Transaction(ctx, func(ctx context.Context) error {
+//	    // current_setting('app.tenant_id') returns tenantID
+//	})
+//
+// See [sqldb.ContextWithSessionVars] for details.
+func ContextWithSessionVars(ctx context.Context, vars map[string]string) context.Context {
+	return sqldb.ContextWithSessionVars(ctx, vars)
+}
+
+// SessionVarsFromContext returns the session variables
+// added to the context with [ContextWithSessionVars] or nil.
+func SessionVarsFromContext(ctx context.Context) map[string]string {
+	return sqldb.SessionVarsFromContext(ctx)
+}
+
+// ContextWithQueryTimeout returns a new context that limits the duration
+// of every statement executed with the connection returned by [Conn]
+// to timeout, overriding [sqldb.Config.DefaultQueryTimeout].
+// A timeout <= 0 disables the default query timeout for the context.
+//
+// The timeout is enforced client-side with a context deadline
+// and server-side where supported with statement_timeout for PostgreSQL,
+// a MAX_EXECUTION_TIME hint for MySQL SELECT queries,
+// and SET LOCK_TIMEOUT for SQL Server transactions.
+// Errors caused by the timeout wrap a [sqldb.ErrQueryTimeout].
+//
+// Example:
+//
+//	ctx = db.ContextWithQueryTimeout(ctx, 5*time.Second)
+//
+//	users, err := db.QueryRowsAsSlice[User](ctx, `SELECT * FROM public.user`)
+//	if errors.Is(err, context.DeadlineExceeded) {
+//	    // query took longer than 5 seconds
+//	}
+//
+// See [sqldb.ContextWithQueryTimeout] for details.
+func ContextWithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return sqldb.ContextWithQueryTimeout(ctx, timeout)
+}
+
+// QueryTimeoutFromContext returns the timeout added to the context
+// with [ContextWithQueryTimeout] and true,
+// or false if the context has no query timeout.
+func QueryTimeoutFromContext(ctx context.Context) (timeout time.Duration, ok bool) {
+	return sqldb.QueryTimeoutFromContext(ctx)
+}
+
+// ContextWithQueryTags returns a new context with the tag key and value
+// added to the query tags of the parent context.
+// The tags are appended as sqlcommenter comment like
+// /*controller='users',route='%2Fapi%2Fusers'*/ to every statement
+// executed with the context if the connection is wrapped with
+// [sqldb.QueryCommentInterceptor], so that queries in pg_stat_activity
+// or slow query logs can be traced back to the service endpoint:
+//
+//	db.SetConn(sqldb.WrapConnection(conn, sqldb.QueryCommentInterceptor()))
+//
+//	ctx = db.ContextWithQueryTags(ctx, "route", "/api/users")
+//
+// See [sqldb.ContextWithQueryTags] for details.
+func ContextWithQueryTags(ctx context.Context, key, value string) context.Context {
+	return sqldb.ContextWithQueryTags(ctx, key, value)
+}
+
+// QueryTagsFromContext returns the query tags
+// added to the context with [ContextWithQueryTags] or nil.
+func QueryTagsFromContext(ctx context.Context) map[string]string {
+	return sqldb.QueryTagsFromContext(ctx)
+}
+
 // Close the global connection that was configured with [SetConn].
 func Close() error {
 	globalConnMtx.RLock()
@@ -1,7 +1,12 @@
 package db_test
 
 import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -33,3 +38,123 @@ func TestClose(t *testing.T) {
 		require.Equal(t, 1, closeCount, "MockClose call count")
 	})
 }
+
+func TestContextWithPrimary(t *testing.T) {
+	// given
+	primary := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).
+		WithQueryResult([]string{"v"}, [][]driver.Value{{"primary"}}, "SELECT v")
+	replica := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).
+		WithQueryResult([]string{"v"}, [][]driver.Value{{"replica"}}, "SELECT v")
+	ctx := db.ContextWithConn(t.Context(), sqldb.NewReadWriteSplitConn(primary, sqldb.NewReplicaRouter(sqldb.RoundRobin, replica)))
+
+	// when
+	fromReplica, err1 := db.QueryRowAs[string](ctx, "SELECT v")
+	fromPrimary, err2 := db.QueryRowAs[string](db.ContextWithPrimary(ctx), "SELECT v")
+
+	// then
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	require.Equal(t, "replica", fromReplica)
+	require.Equal(t, "primary", fromPrimary)
+	require.True(t, db.IsContextWithPrimary(db.ContextWithPrimary(ctx)))
+	require.False(t, db.IsContextWithPrimary(ctx))
+}
+
+func TestContextWithDeleted(t *testing.T) {
+	require.True(t, db.IsContextWithDeleted(db.ContextWithDeleted(t.Context())))
+	require.False(t, db.IsContextWithDeleted(t.Context()))
+}
+
+func TestContextWithClock(t *testing.T) {
+	type row struct {
+		sqldb.TableName `db:"t"`
+
+		ID      int64     `db:"id,primarykey"`
+		Updated time.Time `db:"updated_at,updated"`
+	}
+	// given
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	conn := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	ctx := db.ContextWithClock(testContext(t, conn), func(context.Context) time.Time { return now })
+	r := &row{ID: 1}
+
+	// when
+	err := db.InsertRowStruct(ctx, r)
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, now, r.Updated)
+	require.Equal(t, now, db.ClockFromContext(ctx)(ctx))
+	require.Len(t, conn.Recordings.Execs, 1)
+	assertArgs(t, conn.Recordings.Execs[0].Args, []any{1, now})
+}
+
+func TestContextWithSessionVars(t *testing.T) {
+	// given
+	var log strings.Builder
+	conn := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).
+		WithQueryLog(&log).
+		WithQueryResult([]string{"v"}, [][]driver.Value{{"1"}}, "SELECT current_setting('app.tenant_id')")
+	conn.MockSetSessionVars = func(ctx context.Context, vars map[string]string) (func(context.Context) error, error) {
+		fmt.Fprintf(&log, "SET app.tenant_id=%s;\n", vars["app.tenant_id"])
+		return func(context.Context) error {
+			log.WriteString("RESET;\n")
+			return nil
+		}, nil
+	}
+	ctx := db.ContextWithSessionVars(testContext(t, conn), map[string]string{"app.tenant_id": "1"})
+
+	// when
+	tenantID, err := db.QueryRowAs[string](ctx, "SELECT current_setting('app.tenant_id')")
+	require.NoError(t, err)
+	err = db.Transaction(ctx, func(ctx context.Context) error {
+		return db.Exec(ctx, "DELETE FROM t")
+	})
+	require.NoError(t, err)
+
+	// then
+	require.Equal(t, "1", tenantID)
+	require.Equal(t, map[string]string{"app.tenant_id": "1"}, db.SessionVarsFromContext(ctx))
+	require.Equal(t,
+		"BEGIN;\nSET app.tenant_id=1;\nSELECT current_setting('app.tenant_id');\nRESET;\nCOMMIT;\n"+
+			"BEGIN;\nSET app.tenant_id=1;\nDELETE FROM t;\nRESET;\nCOMMIT;\n",
+		log.String(),
+	)
+}
+
+func TestContextWithQueryTimeout(t *testing.T) {
+	// given
+	var hasDeadline bool
+	conn := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	conn.MockExec = func(ctx context.Context, query string, args ...any) error {
+		_, hasDeadline = ctx.Deadline()
+		return nil
+	}
+	ctx := db.ContextWithQueryTimeout(testContext(t, conn), time.Minute)
+
+	// when
+	err := db.Exec(ctx, "DELETE FROM t")
+
+	// then
+	require.NoError(t, err)
+	require.True(t, hasDeadline)
+	timeout, ok := db.QueryTimeoutFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, timeout)
+}
+
+func TestContextWithQueryTags(t *testing.T) {
+	// given
+	var log strings.Builder
+	conn := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).WithQueryLog(&log)
+	ctx := testContext(t, sqldb.WrapConnection(conn, sqldb.QueryCommentInterceptor()))
+	ctx = db.ContextWithQueryTags(ctx, "controller", "users")
+
+	// when
+	err := db.Exec(ctx, "DELETE FROM t")
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"controller": "users"}, db.QueryTagsFromContext(ctx))
+	require.Equal(t, "DELETE FROM t /*controller='users'*/;\n", log.String())
+}
@@ -12,6 +12,9 @@ import (
 // (e.g., db.TableName `db:"my_table"`, field `db:"id,primarykey"`).
 // The struct must have at least one primary key field.
 // Returns a wrapped [sql.ErrNoRows] error if no row was affected by the delete.
+// Structs with a field with the softdelete tag option (e.g. `db:"deleted_at,softdelete"`)
+// are soft deleted by setting the column to the time of the [sqldb.Clock]
+// from ctx, see [HardDeleteRowStruct].
 func DeleteRowStruct(ctx context.Context, rowStruct sqldb.StructWithTableName) error {
 	conn := Conn(ctx)
 	return sqldb.DeleteRowStruct(
@@ -24,6 +27,22 @@ func DeleteRowStruct(ctx context.Context, rowStruct sqldb.StructWithTableName) e
 	)
 }
 
+// HardDeleteRowStruct deletes a row from the table identified by the primary key columns
+// of the given struct like [DeleteRowStruct], but always with a DELETE query,
+// also if the struct has a field with the softdelete tag option.
+// Returns a wrapped [sql.ErrNoRows] error if no row was affected by the delete.
+func HardDeleteRowStruct(ctx context.Context, rowStruct sqldb.StructWithTableName) error {
+	conn := Conn(ctx)
+	return sqldb.HardDeleteRowStruct(
+		ctx,
+		conn,
+		StructReflector(ctx),
+		QueryBuilder(ctx),
+		conn,
+		rowStruct,
+	)
+}
+
 // DeleteRowStructStmt prepares a statement for deleting rows of type S.
 // Table name, column names, and primary key columns are determined by
 // the [StructReflector] from the context. The default reflector uses `db` struct tags
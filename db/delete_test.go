@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -99,3 +100,55 @@ func TestDbDeleteRowStruct(t *testing.T) {
 		require.ErrorIs(t, err, sql.ErrNoRows)
 	})
 }
+
+func TestDbHardDeleteRowStruct(t *testing.T) {
+	type SoftDeleteRow struct {
+		db.TableName `db:"soft_delete_users"`
+		ID           int        `db:"id,primarykey"`
+		DeletedAt    *time.Time `db:"deleted_at,softdelete"`
+	}
+
+	t.Run("soft delete", func(t *testing.T) {
+		// given
+		mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+		var gotQuery string
+		var gotArgs []any
+		mock.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+			gotQuery = query
+			gotArgs = args
+			return 1, nil
+		}
+		ctx := testContext(t, mock)
+
+		// when
+		err := db.DeleteRowStruct(ctx, SoftDeleteRow{ID: 1})
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, "UPDATE soft_delete_users SET deleted_at=$1 WHERE id = $2 AND deleted_at IS NULL", gotQuery)
+		require.Len(t, gotArgs, 2)
+		require.IsType(t, time.Time{}, gotArgs[0])
+		require.Equal(t, 1, gotArgs[1])
+	})
+
+	t.Run("hard delete", func(t *testing.T) {
+		// given
+		mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+		var gotQuery string
+		var gotArgs []any
+		mock.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+			gotQuery = query
+			gotArgs = args
+			return 1, nil
+		}
+		ctx := testContext(t, mock)
+
+		// when
+		err := db.HardDeleteRowStruct(ctx, SoftDeleteRow{ID: 1})
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, "DELETE FROM soft_delete_users WHERE id = $1", gotQuery)
+		assertArgs(t, gotArgs, []any{1})
+	})
+}
@@ -186,3 +186,46 @@ func InsertRowStructs[S sqldb.StructWithTableName](ctx context.Context, rowStruc
 		options...,
 	)
 }
+
+// InsertRowStructReturning inserts a new row into the table for the struct
+// that rowStruct points to and writes the values generated by the database
+// for the columns of fields tagged with `default` or `readonly`
+// back into the struct. See [sqldb.InsertRowStructsReturning]
+// for how the values are returned per database.
+//
+// Table name and column names are determined by the [StructReflector] from the context.
+// Optional QueryOption can be passed to ignore mapped columns.
+func InsertRowStructReturning(ctx context.Context, rowStruct sqldb.StructWithTableName, options ...QueryOption) error {
+	conn := Conn(ctx)
+	return sqldb.InsertRowStructReturning(
+		ctx,
+		conn,
+		StructReflector(ctx),
+		QueryBuilder(ctx),
+		conn,
+		rowStruct,
+		options...,
+	)
+}
+
+// InsertRowStructsReturning inserts a slice of structs as new rows into the table
+// and writes the values generated by the database for the columns
+// of fields tagged with `default` or `readonly` back into the structs
+// of the slice in the order of the slice.
+// Multiple batches are inserted within a transaction.
+// See [sqldb.InsertRowStructsReturning] for how the values are returned per database.
+//
+// Table name and column names are determined by the [StructReflector] from the context.
+// Optional QueryOption can be passed to ignore mapped columns.
+func InsertRowStructsReturning[S sqldb.StructWithTableName](ctx context.Context, rowStructs []S, options ...QueryOption) error {
+	conn := Conn(ctx)
+	return sqldb.InsertRowStructsReturning(
+		ctx,
+		conn,
+		StructReflector(ctx),
+		QueryBuilder(ctx),
+		conn,
+		rowStructs,
+		options...,
+	)
+}
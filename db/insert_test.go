@@ -1,11 +1,13 @@
 package db_test
 
 import (
+	"context"
 	"database/sql"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/domonda/go-sqldb"
@@ -216,3 +218,51 @@ func TestInsert(t *testing.T) {
 		})
 	}
 }
+
+func TestInsertRowStructsReturning(t *testing.T) {
+	type Struct1 struct {
+		db.TableName `db:"my_table"`
+		ID           int64  `db:"id,primarykey,default"`
+		Name         string `db:"name"`
+	}
+
+	// given
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	mock.MockQuery = func(ctx context.Context, query string, args ...any) sqldb.Rows {
+		return sqldb.NewMockRows("id").WithRow(int64(1)).WithRow(int64(2))
+	}
+	ctx := testContext(t, mock)
+	rows := []Struct1{{Name: "a"}, {Name: "b"}}
+
+	// when
+	err := db.InsertRowStructsReturning(ctx, rows)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []Struct1{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, rows)
+	require.Len(t, mock.Recordings.Queries, 1)
+	assert.Equal(t, "INSERT INTO my_table(name) SELECT name FROM (VALUES(COALESCE($1,(NULL::my_table).name),1),($2,2)) AS v(name,sqldb_ordinal) ORDER BY sqldb_ordinal RETURNING id", mock.Recordings.Queries[0].Query)
+}
+
+func TestInsertRowStructReturning(t *testing.T) {
+	type Struct1 struct {
+		db.TableName `db:"my_table"`
+		ID           int64  `db:"id,primarykey,default"`
+		Name         string `db:"name"`
+	}
+
+	// given
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	mock.MockQuery = func(ctx context.Context, query string, args ...any) sqldb.Rows {
+		return sqldb.NewMockRows("id").WithRow(int64(7))
+	}
+	ctx := testContext(t, mock)
+	row := &Struct1{Name: "a"}
+
+	// when
+	err := db.InsertRowStructReturning(ctx, row)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, &Struct1{ID: 7, Name: "a"}, row)
+}
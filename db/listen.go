@@ -20,7 +20,7 @@ import (
 // Panics from callbacks will be recovered and logged.
 // Returns errors.ErrUnsupported if the connection does not implement sqldb.ListenerConnection.
 func ListenOnChannel(ctx context.Context, channel string, onNotify sqldb.OnNotifyFunc, onUnlisten sqldb.OnUnlistenFunc) error {
-	listener, ok := Conn(ctx).(sqldb.ListenerConnection)
+	listener, ok := sqldb.ConnectionAs[sqldb.ListenerConnection](Conn(ctx))
 	if !ok {
 		return fmt.Errorf("ListenOnChannel: %w", errors.ErrUnsupported)
 	}
@@ -33,7 +33,7 @@ func ListenOnChannel(ctx context.Context, channel string, onNotify sqldb.OnNotif
 // or the listener connection is closed.
 // Returns errors.ErrUnsupported if the connection does not implement sqldb.ListenerConnection.
 func UnlistenChannel(ctx context.Context, channel string) error {
-	listener, ok := Conn(ctx).(sqldb.ListenerConnection)
+	listener, ok := sqldb.ConnectionAs[sqldb.ListenerConnection](Conn(ctx))
 	if !ok {
 		return fmt.Errorf("UnlistenChannel: %w", errors.ErrUnsupported)
 	}
@@ -43,7 +43,7 @@ func UnlistenChannel(ctx context.Context, channel string) error {
 // IsListeningOnChannel returns if a channel is listened to.
 // Returns false if the connection does not implement sqldb.ListenerConnection.
 func IsListeningOnChannel(ctx context.Context, channel string) bool {
-	listener, ok := Conn(ctx).(sqldb.ListenerConnection)
+	listener, ok := sqldb.ConnectionAs[sqldb.ListenerConnection](Conn(ctx))
 	if !ok {
 		return false
 	}
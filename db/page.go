@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// OrderByColumn is a column of the sort order of a query
+// used for keyset pagination with [QueryPage].
+type OrderByColumn = sqldb.OrderByColumn
+
+// Page is a page of rows returned by [QueryPage].
+type Page[S any] = sqldb.Page[S]
+
+// QueryPage returns a page of at most limit rows of the query
+// scanned into structs of type S using keyset (cursor) pagination.
+// An empty cursor returns the first page, else the cursor must be
+// the NextCursor or PrevCursor of a previously returned page
+// with the same orderBy columns.
+// The rows are sorted by orderBy followed by the primary key columns
+// of S as tie-breaker, and the query must not contain ORDER BY or LIMIT clauses.
+// See [sqldb.QueryPage] for details.
+// The configured [QueryBuilder] must implement [sqldb.PageQueryBuilder].
+//
+// Example:
+//
+//	page, err := db.QueryPage[User](ctx,
+//		[]db.OrderByColumn{{Column: "created_at", Desc: true}}, 50, cursor,
+//		`SELECT * FROM public.user WHERE tenant_id = $1`, tenantID,
+//	)
+func QueryPage[S any](ctx context.Context, orderBy []OrderByColumn, limit int, cursor string, query string, args ...any) (Page[S], error) {
+	builder, ok := QueryBuilder(ctx).(sqldb.PageQueryBuilder)
+	if !ok {
+		return Page[S]{}, fmt.Errorf("db.QueryPage: QueryBuilder %T does not implement sqldb.PageQueryBuilder", QueryBuilder(ctx))
+	}
+	conn := Conn(ctx)
+	return sqldb.QueryPage[S](
+		ctx,
+		conn,
+		StructReflector(ctx),
+		builder,
+		conn,
+		orderBy,
+		limit,
+		cursor,
+		query,
+		args...,
+	)
+}
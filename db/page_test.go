@@ -0,0 +1,55 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+	"github.com/domonda/go-sqldb/db"
+)
+
+func TestQueryPage(t *testing.T) {
+	type User struct {
+		ID   int64  `db:"id,primarykey"`
+		Name string `db:"name"`
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		// given
+		var gotQueries []string
+		mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+		mock.MockQuery = func(ctx context.Context, query string, args ...any) sqldb.Rows {
+			gotQueries = append(gotQueries, query)
+			if len(args) == 0 {
+				return sqldb.NewMockRows("id", "name").WithRow(int64(1), "Alice").WithRow(int64(2), "Bob")
+			}
+			return sqldb.NewMockRows("id", "name").WithRow(int64(2), "Bob")
+		}
+		ctx := testContext(t, mock)
+
+		// when
+		page1, err := db.QueryPage[User](ctx, nil, 1, "", `SELECT * FROM public.user`)
+		require.NoError(t, err)
+		page2, err := db.QueryPage[User](ctx, nil, 1, page1.NextCursor, `SELECT * FROM public.user`)
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, []User{{ID: 1, Name: "Alice"}}, page1.Rows)
+		assert.Equal(t, []User{{ID: 2, Name: "Bob"}}, page2.Rows)
+		assert.Empty(t, page2.NextCursor)
+		assert.NotEmpty(t, page2.PrevCursor)
+		assert.Equal(t, []string{
+			`SELECT * FROM (SELECT * FROM public.user) sqldb_page ORDER BY id LIMIT 2`,
+			`SELECT * FROM (SELECT * FROM public.user) sqldb_page WHERE id > $1 ORDER BY id LIMIT 2`,
+		}, gotQueries)
+	})
+
+	t.Run("QueryBuilder without PageQueryBuilder", func(t *testing.T) {
+		ctx := db.ContextWithQueryBuilder(t.Context(), struct{ sqldb.QueryBuilder }{})
+		_, err := db.QueryPage[User](ctx, nil, 1, "", `SELECT * FROM public.user`)
+		assert.ErrorContains(t, err, "does not implement sqldb.PageQueryBuilder")
+	})
+}
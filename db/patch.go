@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// Patch is a struct field type for partial updates that distinguishes
+// between a field that is not set, set to NULL, or set to a value of type T.
+// See [sqldb.Patch] for details.
+type Patch[T any] = sqldb.Patch[T]
+
+// PatchValues returns the values of the mapped fields of patchStruct
+// for [Update] without the columns of unset [Patch] fields
+// using the [StructReflector] from the context.
+//
+// Example:
+//
+//	type UserPatch struct {
+//		Name  db.Patch[string] `db:"name" json:"name,omitzero"`
+//		Email db.Patch[string] `db:"email" json:"email,omitzero"`
+//	}
+//
+//	var patch UserPatch
+//	err := json.Unmarshal(body, &patch)
+//	values, err := db.PatchValues(ctx, &patch)
+//	err = db.Update(ctx, "public.user", values, "id = $1", userID)
+//
+// See [sqldb.PatchValues] for details.
+func PatchValues(ctx context.Context, patchStruct any, options ...QueryOption) (Values, error) {
+	return sqldb.PatchValues(StructReflector(ctx), patchStruct, options...)
+}
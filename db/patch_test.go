@@ -0,0 +1,37 @@
+package db_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+	"github.com/domonda/go-sqldb/db"
+)
+
+func TestPatchValues(t *testing.T) {
+	type UserPatch struct {
+		Name  db.Patch[string] `db:"name"`
+		Email db.Patch[string] `db:"email"`
+		Age   db.Patch[int]    `db:"age"`
+	}
+
+	// given
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	ctx := testContext(t, mock)
+	var patch UserPatch
+	require.NoError(t, json.Unmarshal([]byte(`{"Name":"Alice","Email":null}`), &patch))
+
+	// when
+	values, err := db.PatchValues(ctx, &patch)
+	require.NoError(t, err)
+	err = db.Update(ctx, "users", values, "id = $1", 1)
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, db.Values{"name": "Alice", "email": nil}, values)
+	require.Len(t, mock.Recordings.Execs, 1)
+	require.Equal(t, "UPDATE users SET email=$2, name=$3 WHERE id = $1", mock.Recordings.Execs[0].Query)
+	assertArgs(t, mock.Recordings.Execs[0].Args, []any{1, nil, "Alice"})
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"iter"
 	"time"
 
 	"github.com/domonda/go-sqldb"
@@ -15,6 +16,8 @@ import (
 //
 // Useful for getting the timestamp of a
 // SQL transaction for use in Go code.
+// Can be passed as [sqldb.Clock] to [ContextWithClock]
+// to set created and updated timestamp columns.
 func CurrentTimestamp(ctx context.Context) time.Time {
 	t, err := QueryRowAs[time.Time](ctx,
 		/*sql*/ `SELECT CURRENT_TIMESTAMP`,
@@ -239,6 +242,81 @@ func QueryRowsAsSlice[T any](ctx context.Context, query string, args ...any) (ro
 	)
 }
 
+// QueryRowsIter returns an iterator over the rows of the query
+// scanned as the type T.
+// If T is a struct, column values are scanned into fields
+// using the [StructReflector] from the context.
+//
+// Rows are streamed from the database while iterating instead of
+// being loaded into memory, and the underlying rows are closed when
+// the iteration ends, including an early break out of the loop.
+// An error is yielded as last pair together with the zero value of T.
+//
+// The maximum number of rows is read from the context via
+// [MaxNumRowsFromContext]; exceeding it yields [ErrMaxNumRowsExceeded].
+//
+// Example:
+//
+//	for user, err := range db.QueryRowsIter[User](ctx, `SELECT * FROM public.user`) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(user.Name)
+//	}
+func QueryRowsIter[T any](ctx context.Context, query string, args ...any) iter.Seq2[T, error] {
+	conn := Conn(ctx)
+	return sqldb.QueryRowsIter[T](
+		ctx,
+		conn,
+		StructReflector(ctx),
+		conn,
+		MaxNumRowsFromContext(ctx),
+		query,
+		args...,
+	)
+}
+
+// QueryRowsIter2 returns an iterator over the rows of a query
+// with 2 columns scanned into a [sqldb.Tuple2].
+// See [QueryRowsIter] for the iteration and error semantics.
+//
+// Example:
+//
+//	for row, err := range db.QueryRowsIter2[int64, string](ctx, `SELECT id, name FROM public.user`) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    id, name := row.Values()
+//	}
+func QueryRowsIter2[T0, T1 any](ctx context.Context, query string, args ...any) iter.Seq2[sqldb.Tuple2[T0, T1], error] {
+	conn := Conn(ctx)
+	return sqldb.QueryRowsIter2[T0, T1](ctx, conn, conn, MaxNumRowsFromContext(ctx), query, args...)
+}
+
+// QueryRowsIter3 returns an iterator over the rows of a query
+// with 3 columns scanned into a [sqldb.Tuple3].
+// See [QueryRowsIter] for the iteration and error semantics.
+func QueryRowsIter3[T0, T1, T2 any](ctx context.Context, query string, args ...any) iter.Seq2[sqldb.Tuple3[T0, T1, T2], error] {
+	conn := Conn(ctx)
+	return sqldb.QueryRowsIter3[T0, T1, T2](ctx, conn, conn, MaxNumRowsFromContext(ctx), query, args...)
+}
+
+// QueryRowsIter4 returns an iterator over the rows of a query
+// with 4 columns scanned into a [sqldb.Tuple4].
+// See [QueryRowsIter] for the iteration and error semantics.
+func QueryRowsIter4[T0, T1, T2, T3 any](ctx context.Context, query string, args ...any) iter.Seq2[sqldb.Tuple4[T0, T1, T2, T3], error] {
+	conn := Conn(ctx)
+	return sqldb.QueryRowsIter4[T0, T1, T2, T3](ctx, conn, conn, MaxNumRowsFromContext(ctx), query, args...)
+}
+
+// QueryRowsIter5 returns an iterator over the rows of a query
+// with 5 columns scanned into a [sqldb.Tuple5].
+// See [QueryRowsIter] for the iteration and error semantics.
+func QueryRowsIter5[T0, T1, T2, T3, T4 any](ctx context.Context, query string, args ...any) iter.Seq2[sqldb.Tuple5[T0, T1, T2, T3, T4], error] {
+	conn := Conn(ctx)
+	return sqldb.QueryRowsIter5[T0, T1, T2, T3, T4](ctx, conn, conn, MaxNumRowsFromContext(ctx), query, args...)
+}
+
 // QueryRowsAsStrings scans the query result into a table of strings
 // where the first row is a header row with the column names.
 //
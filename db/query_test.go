@@ -440,3 +440,60 @@ func TestQueryStrings(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryRowsIter(t *testing.T) {
+	query := /*sql*/ `SELECT name FROM my_table`
+	newContext := func(t *testing.T) context.Context {
+		mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).
+			WithQueryResult(
+				[]string{"name"},
+				[][]driver.Value{{"Alice"}, {"Bob"}, {"Charlie"}},
+				query,
+			)
+		return testContext(t, mock)
+	}
+
+	t.Run("all rows", func(t *testing.T) {
+		ctx := newContext(t)
+		var names []string
+		for name, err := range db.QueryRowsIter[string](ctx, query) {
+			require.NoError(t, err)
+			names = append(names, name)
+		}
+		require.Equal(t, []string{"Alice", "Bob", "Charlie"}, names)
+	})
+
+	t.Run("ContextWithMaxNumRows", func(t *testing.T) {
+		ctx := db.ContextWithMaxNumRows(newContext(t), 2)
+		var names []string
+		var iterErr error
+		for name, err := range db.QueryRowsIter[string](ctx, query) {
+			if err != nil {
+				iterErr = err
+				break
+			}
+			names = append(names, name)
+		}
+		require.Equal(t, []string{"Alice", "Bob"}, names)
+		require.ErrorAs(t, iterErr, new(db.ErrMaxNumRowsExceeded))
+	})
+}
+
+func TestQueryRowsIter2(t *testing.T) {
+	query := /*sql*/ `SELECT id, name FROM my_table`
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).
+		WithQueryResult(
+			[]string{"id", "name"},
+			[][]driver.Value{{int64(1), "Alice"}, {int64(2), "Bob"}},
+			query,
+		)
+	ctx := testContext(t, mock)
+
+	names := make(map[int]string)
+	for row, err := range db.QueryRowsIter2[int, string](ctx, query) {
+		require.NoError(t, err)
+		id, name := row.Values()
+		names[id] = name
+	}
+	require.Equal(t, map[int]string{1: "Alice", 2: "Bob"}, names)
+}
@@ -47,3 +47,8 @@ func IgnoreColumns(names ...string) IgnoreColumnFunc {
 func OnlyColumns(names ...string) IgnoreColumnFunc {
 	return sqldb.OnlyColumns(names...)
 }
+
+// UpsertOptions configure the conflict handling of upserts
+// when passed as option to [UpsertRowStruct], [UpsertRowStructStmt],
+// and [UpsertRowStructs], see [sqldb.UpsertOptions].
+type UpsertOptions = sqldb.UpsertOptions
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// LoadRelations loads the related rows of the relations with the passed names
+// into the relation struct fields of rows using the connection, [StructReflector],
+// and [QueryBuilder] from the context.
+// All relations of S are loaded if no names are passed.
+// Nested relations are loaded with dot separated names like "Items.Product".
+//
+// Example:
+//
+//	type Order struct {
+//		sqldb.TableName `db:"public.order"`
+//		ID         uu.ID        `db:"id,primarykey"`
+//		CustomerID uu.ID        `db:"customer_id"`
+//		Items      []*OrderItem `rel:"has_many,fk=order_id"`
+//		Customer   *Customer    `rel:"belongs_to,fk=customer_id"`
+//	}
+//
+//	orders, err := db.QueryRowsAsSlice[Order](ctx, "SELECT * FROM public.order")
+//	err = db.LoadRelations(ctx, orders, "Customer", "Items.Product")
+//
+// See [sqldb.LoadRelations] for details.
+func LoadRelations[S any](ctx context.Context, rows []S, names ...string) error {
+	conn := Conn(ctx)
+	return sqldb.LoadRelations(
+		ctx,
+		conn,
+		StructReflector(ctx),
+		QueryBuilder(ctx),
+		conn,
+		rows,
+		names...,
+	)
+}
+
+// VerifyRelations checks that the relations of the struct type S
+// match foreign key constraints of the database
+// using the connection and [StructReflector] from the context.
+// See [sqldb.VerifyRelations] for details.
+func VerifyRelations[S any](ctx context.Context) error {
+	return sqldb.VerifyRelations[S](ctx, Conn(ctx), StructReflector(ctx))
+}
@@ -0,0 +1,74 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+	"github.com/domonda/go-sqldb/db"
+)
+
+func TestLoadRelations(t *testing.T) {
+	type Item struct {
+		sqldb.TableName `db:"items"`
+
+		ID      int64 `db:"id,primarykey"`
+		OrderID int64 `db:"order_id"`
+	}
+	type Order struct {
+		sqldb.TableName `db:"orders"`
+
+		ID    int64  `db:"id,primarykey"`
+		Items []Item `rel:"has_many,fk=order_id"`
+	}
+
+	// given
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	mock.MockQuery = func(ctx context.Context, query string, args ...any) sqldb.Rows {
+		return sqldb.NewMockRows("id", "order_id").WithRow(int64(10), int64(2))
+	}
+	ctx := testContext(t, mock)
+	orders := []Order{{ID: 1}, {ID: 2}}
+
+	// when
+	err := db.LoadRelations(ctx, orders, "Items")
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, mock.Recordings.Queries, 1)
+	assert.Equal(t, "SELECT * FROM items WHERE order_id = ANY($1)", mock.Recordings.Queries[0].Query)
+	assert.Equal(t, []any{[]int64{1, 2}}, mock.Recordings.Queries[0].Args)
+	assert.Nil(t, orders[0].Items)
+	assert.Equal(t, []Item{{ID: 10, OrderID: 2}}, orders[1].Items)
+}
+
+func TestVerifyRelations(t *testing.T) {
+	type Customer struct {
+		sqldb.TableName `db:"customers"`
+
+		ID int64 `db:"id,primarykey"`
+	}
+	type Order struct {
+		sqldb.TableName `db:"orders"`
+
+		ID         int64     `db:"id,primarykey"`
+		CustomerID int64     `db:"customer_id"`
+		Customer   *Customer `rel:"belongs_to,fk=customer_id"`
+	}
+
+	// given
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	mock.MockForeignKeys = func(ctx context.Context, table string) ([]sqldb.ForeignKeyInfo, error) {
+		return []sqldb.ForeignKeyInfo{{Columns: []string{"customer_id"}, ReferencedTable: "public.customers", ReferencedColumns: []string{"id"}}}, nil
+	}
+	ctx := testContext(t, mock)
+
+	// when
+	err := db.VerifyRelations[Order](ctx)
+
+	// then
+	require.NoError(t, err)
+}
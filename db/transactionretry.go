@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// DefaultRetryPolicy is used by [TransactionWithRetry]
+// if nil is passed as policy and for zero value fields
+// of a passed policy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Multiplier:     2,
+	Jitter:         0.5,
+}
+
+// RetryPolicy configures how [TransactionWithRetry]
+// retries transactions that failed with a retryable error.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the transaction
+	// is executed including the first attempt.
+	MaxAttempts int
+
+	// InitialBackoff is the duration to wait before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the duration to wait between attempts.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor the backoff grows with after every retry.
+	Multiplier float64
+
+	// Jitter is the fraction from 0 to 1 by which every backoff
+	// is randomly reduced to prevent concurrent transactions
+	// that failed because of each other from retrying in lockstep.
+	// Zero disables jitter.
+	Jitter float64
+
+	// TxOptions are the options used to begin every transaction attempt.
+	// Nil means the default options of the connection.
+	TxOptions *sql.TxOptions
+
+	// Retryable decides if a transaction that failed
+	// with the passed error should be retried.
+	// If nil, then sqldb.IsRetryable is used.
+	Retryable func(err error) bool
+
+	// OnRetry is called if not nil after a failed attempt
+	// before waiting for backoff to retry the transaction.
+	// The attempt number starts at 1.
+	OnRetry func(ctx context.Context, attempt int, err error, backoff time.Duration)
+}
+
+// withDefaults returns a copy of the policy with zero value
+// fields replaced by the fields of DefaultRetryPolicy.
+func (p *RetryPolicy) withDefaults() RetryPolicy {
+	if p == nil {
+		return DefaultRetryPolicy
+	}
+	r := *p
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if r.InitialBackoff <= 0 {
+		r.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	if r.Multiplier <= 0 {
+		r.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	if r.Retryable == nil {
+		r.Retryable = sqldb.IsRetryable
+	}
+	return r
+}
+
+// backoff returns the duration to wait after the failed attempt
+// with the passed number starting at 1.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	for range attempt - 1 {
+		backoff *= p.Multiplier
+		if backoff >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	backoff = min(backoff, float64(p.MaxBackoff))
+	if p.Jitter > 0 {
+		backoff -= backoff * min(p.Jitter, 1) * rand.Float64() //#nosec G404 -- jitter does not need a secure random number
+	}
+	return time.Duration(backoff)
+}
+
+// TransactionWithRetry executes txFunc within a database transaction
+// that is passed in to txFunc via the context like [TransactionOpts]
+// and retries the whole transaction with exponential backoff
+// if it failed with an error that policy.Retryable reports as retryable.
+// By default these are the errors reported by [sqldb.IsRetryable]
+// like serialization failures, deadlocks, lock timeouts, and lost connections,
+// but not connections lost during the COMMIT, because the transaction
+// might have been committed.
+//
+// If policy is nil, then DefaultRetryPolicy is used,
+// zero value fields of a passed policy are also
+// replaced by the fields of DefaultRetryPolicy.
+//
+// If the context is canceled while waiting for a retry,
+// then the last error is returned joined with the context error.
+// If all attempts failed, the error of the last attempt is returned wrapped.
+//
+// If the connection from the context is already a transaction,
+// then txFunc is executed within that transaction without retries
+// because only the whole outer transaction can be retried.
+//
+// Because txFunc may be executed multiple times, it must not have
+// side effects outside of the transaction that can't be repeated.
+func TransactionWithRetry(ctx context.Context, policy *RetryPolicy, txFunc func(context.Context) error) error {
+	if IsContextWithoutTransactions(ctx) || IsTransaction(ctx) {
+		return txFunc(ctx)
+	}
+
+	p := policy.withDefaults()
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = TransactionOpts(ctx, p.TxOptions, txFunc)
+		if err == nil || !p.Retryable(err) {
+			return err // nil or err
+		}
+		if attempt >= p.MaxAttempts {
+			return fmt.Errorf("TransactionWithRetry failed after %d attempts: %w", attempt, err)
+		}
+		backoff := p.backoff(attempt)
+		if p.OnRetry != nil {
+			p.OnRetry(ctx, attempt, err, backoff)
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// TransactionWithRetryResult executes txFunc within a database transaction
+// that is retried according to policy and returns the result of txFunc.
+// See [TransactionWithRetry] for more details.
+func TransactionWithRetryResult[T any](ctx context.Context, policy *RetryPolicy, txFunc func(context.Context) (T, error)) (result T, err error) {
+	err = TransactionWithRetry(ctx, policy, func(ctx context.Context) error {
+		result, err = txFunc(ctx)
+		return err
+	})
+	return result, err
+}
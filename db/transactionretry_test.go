@@ -0,0 +1,243 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+	"github.com/domonda/go-sqldb/db"
+)
+
+func TestTransactionWithRetry(t *testing.T) {
+	fastPolicy := func() *db.RetryPolicy {
+		return &db.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Microsecond, MaxBackoff: time.Millisecond}
+	}
+
+	t.Run("success on first attempt", func(t *testing.T) {
+		// given
+		var commits, rollbacks int
+		conn := &sqldb.MockConn{
+			MockCommit:   func() error { commits++; return nil },
+			MockRollback: func() error { rollbacks++; return nil },
+		}
+		ctx := testContext(t, conn)
+		attempts := 0
+
+		// when
+		err := db.TransactionWithRetry(ctx, fastPolicy(), func(ctx context.Context) error {
+			attempts++
+			assert.True(t, db.IsTransaction(ctx))
+			return nil
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, 1, commits)
+		assert.Equal(t, 0, rollbacks)
+	})
+
+	t.Run("retries retryable errors until success", func(t *testing.T) {
+		// given
+		var commits, rollbacks int
+		conn := &sqldb.MockConn{
+			MockCommit:   func() error { commits++; return nil },
+			MockRollback: func() error { rollbacks++; return nil },
+		}
+		ctx := testContext(t, conn)
+		attempts := 0
+		var retries []int
+
+		policy := fastPolicy()
+		policy.OnRetry = func(ctx context.Context, attempt int, err error, backoff time.Duration) {
+			retries = append(retries, attempt)
+			assert.True(t, sqldb.IsRetryable(err))
+			assert.LessOrEqual(t, backoff, policy.MaxBackoff)
+		}
+
+		// when
+		err := db.TransactionWithRetry(ctx, policy, func(ctx context.Context) error {
+			attempts++
+			switch attempts {
+			case 1:
+				return fmt.Errorf("wrapped: %w", sqldb.ErrDeadlock)
+			case 2:
+				return errors.Join(sqldb.ErrSerializationFailure, errors.New("driver error"))
+			}
+			return nil
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, []int{1, 2}, retries)
+		assert.Equal(t, 1, commits)
+		assert.Equal(t, 2, rollbacks)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		// given
+		ctx := testContext(t, new(sqldb.MockConn))
+		attempts := 0
+		wantErr := sqldb.ErrUniqueViolation{Constraint: "c"}
+
+		// when
+		err := db.TransactionWithRetry(ctx, fastPolicy(), func(ctx context.Context) error {
+			attempts++
+			return wantErr
+		})
+
+		// then
+		assert.ErrorIs(t, err, wantErr)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("fails after max attempts", func(t *testing.T) {
+		// given
+		ctx := testContext(t, new(sqldb.MockConn))
+		attempts := 0
+
+		// when
+		err := db.TransactionWithRetry(ctx, fastPolicy(), func(ctx context.Context) error {
+			attempts++
+			return sqldb.ErrLockTimeout
+		})
+
+		// then
+		assert.ErrorIs(t, err, sqldb.ErrLockTimeout)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("custom Retryable", func(t *testing.T) {
+		// given
+		ctx := testContext(t, new(sqldb.MockConn))
+		errCustom := errors.New("custom")
+		attempts := 0
+		policy := fastPolicy()
+		policy.Retryable = func(err error) bool { return errors.Is(err, errCustom) }
+
+		// when
+		err := db.TransactionWithRetry(ctx, policy, func(ctx context.Context) error {
+			attempts++
+			if attempts == 1 {
+				return errCustom
+			}
+			return sqldb.ErrDeadlock
+		})
+
+		// then
+		assert.ErrorIs(t, err, sqldb.ErrDeadlock)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("connection lost during COMMIT is not retried", func(t *testing.T) {
+		// given
+		conn := &sqldb.MockConn{
+			MockCommit: func() error { return sqldb.ErrConnectionLost },
+		}
+		ctx := testContext(t, conn)
+		attempts := 0
+
+		// when
+		err := db.TransactionWithRetry(ctx, fastPolicy(), func(ctx context.Context) error {
+			attempts++
+			return nil
+		})
+
+		// then
+		assert.ErrorIs(t, err, sqldb.ErrConnectionLost)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("canceled context stops waiting", func(t *testing.T) {
+		// given
+		ctx, cancel := context.WithCancel(testContext(t, new(sqldb.MockConn)))
+		attempts := 0
+		policy := &db.RetryPolicy{
+			MaxAttempts:    10,
+			InitialBackoff: time.Hour,
+			MaxBackoff:     time.Hour,
+			OnRetry: func(context.Context, int, error, time.Duration) {
+				cancel()
+			},
+		}
+
+		// when
+		err := db.TransactionWithRetry(ctx, policy, func(ctx context.Context) error {
+			attempts++
+			return sqldb.ErrConnectionLost
+		})
+
+		// then
+		assert.ErrorIs(t, err, sqldb.ErrConnectionLost)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("nested in transaction is not retried", func(t *testing.T) {
+		// given
+		ctx := testContext(t, new(sqldb.MockConn))
+		attempts := 0
+
+		// when
+		err := db.Transaction(ctx, func(ctx context.Context) error {
+			return db.TransactionWithRetry(ctx, fastPolicy(), func(ctx context.Context) error {
+				attempts++
+				return sqldb.ErrDeadlock
+			})
+		})
+
+		// then
+		assert.ErrorIs(t, err, sqldb.ErrDeadlock)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("TxOptions", func(t *testing.T) {
+		// given
+		var gotOpts *sql.TxOptions
+		conn := &sqldb.MockConn{
+			MockBegin: func(ctx context.Context, id uint64, opts *sql.TxOptions) (sqldb.Connection, error) {
+				gotOpts = opts
+				return &sqldb.MockConn{TxID: id}, nil
+			},
+		}
+		ctx := testContext(t, conn)
+		policy := fastPolicy()
+		policy.TxOptions = &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+		// when
+		err := db.TransactionWithRetry(ctx, policy, func(ctx context.Context) error { return nil })
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, policy.TxOptions, gotOpts)
+	})
+}
+
+func TestTransactionWithRetryResult(t *testing.T) {
+	// given
+	ctx := testContext(t, new(sqldb.MockConn))
+	attempts := 0
+	policy := &db.RetryPolicy{InitialBackoff: time.Microsecond}
+
+	// when
+	result, err := db.TransactionWithRetryResult(ctx, policy, func(ctx context.Context) (int, error) {
+		attempts++
+		if attempts < 2 {
+			return 0, sqldb.ErrSerializationFailure
+		}
+		return 42, nil
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 42, result)
+	assert.Equal(t, 2, attempts)
+}
@@ -156,3 +156,33 @@ func UpdateRowStructs[S sqldb.StructWithTableName](ctx context.Context, rowStruc
 		options...,
 	)
 }
+
+// UpdateRowStructChanges updates the row of newRowStruct identified by its
+// primary key columns, but only sets the columns whose values differ
+// from oldRowStruct. No query is executed if no values changed.
+// Table name, column names, and primary key columns are determined by
+// the [StructReflector] from the context.
+// Returns the values of the changed columns for auditing.
+// See [sqldb.UpdateRowStructChanges] for details.
+func UpdateRowStructChanges[S sqldb.StructWithTableName](ctx context.Context, oldRowStruct, newRowStruct S, options ...QueryOption) (Values, error) {
+	conn := Conn(ctx)
+	return sqldb.UpdateRowStructChanges(
+		ctx,
+		conn,
+		StructReflector(ctx),
+		QueryBuilder(ctx),
+		conn,
+		oldRowStruct,
+		newRowStruct,
+		options...,
+	)
+}
+
+// DiffRowStructs compares the mapped fields of oldRowStruct and newRowStruct
+// using the [StructReflector] from the context and returns the values
+// of newRowStruct for the columns with different values
+// or nil if all values are equal.
+// See [sqldb.DiffRowStructs] for details.
+func DiffRowStructs[S any](ctx context.Context, oldRowStruct, newRowStruct S, options ...QueryOption) (Values, error) {
+	return sqldb.DiffRowStructs(StructReflector(ctx), oldRowStruct, newRowStruct, options...)
+}
@@ -175,3 +175,31 @@ func TestUpdateRowStruct(t *testing.T) {
 		require.Equal(t, 1, execCount, "MockExec call count")
 	})
 }
+
+func TestUpdateRowStructChanges(t *testing.T) {
+	type UserRow struct {
+		db.TableName `db:"users"`
+		ID           int    `db:"id,primarykey"`
+		Name         string `db:"name"`
+		Active       bool   `db:"active"`
+	}
+
+	// given
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	ctx := testContext(t, mock)
+	oldRow := UserRow{ID: 1, Name: "Alice", Active: true}
+	newRow := UserRow{ID: 1, Name: "Alice", Active: false}
+
+	// when
+	diff, err := db.DiffRowStructs(ctx, oldRow, newRow)
+	require.NoError(t, err)
+	changes, err := db.UpdateRowStructChanges(ctx, oldRow, newRow)
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, db.Values{"active": false}, diff)
+	require.Equal(t, diff, changes)
+	require.Len(t, mock.Recordings.Execs, 1)
+	require.Equal(t, "UPDATE users SET active=$1 WHERE id = $2", mock.Recordings.Execs[0].Query)
+	assertArgs(t, mock.Recordings.Execs[0].Args, []any{false, 1})
+}
@@ -58,3 +58,33 @@ func ExampleUpsertRowStructStmt() {
 	// DEALLOCATE PREPARE stmt1;
 	// COMMIT;
 }
+
+func ExampleUpsertRowStruct_upsertOptions() {
+	type Counter struct {
+		db.TableName `db:"counter"`
+
+		ID        int64  `db:"id,primarykey"`
+		Name      string `db:"name"`
+		Count     int64  `db:"count"`
+		UpdatedAt string `db:"updated_at"`
+	}
+
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).
+		WithQueryLog(os.Stdout)
+	ctx := db.ContextWithConn(context.Background(), mock)
+	ctx = db.ContextWithQueryBuilder(ctx, postgres.QueryBuilder{})
+	ctx = db.ContextWithStructReflector(ctx, sqldb.NewTaggedStructReflector())
+
+	counter := Counter{ID: 1, Name: "visits", Count: 1, UpdatedAt: "2026-01-02"}
+	err := db.UpsertRowStruct(ctx, &counter, db.UpsertOptions{
+		UpdateColumns:     []string{"updated_at"},
+		UpdateExpressions: map[string]string{"count": "counter.count + excluded.count"},
+		Where:             "excluded.updated_at > counter.updated_at",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// INSERT INTO counter(id,name,count,updated_at) VALUES(1,'visits',1,'2026-01-02') ON CONFLICT (id) DO UPDATE SET updated_at='2026-01-02', count=counter.count + excluded.count WHERE excluded.updated_at > counter.updated_at;
+}
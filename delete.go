@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 )
 
 // DeleteRowStruct deletes a row from the table identified by the primary key columns
@@ -15,6 +16,16 @@ import (
 // in their `db` struct tag (e.g., ID int `db:"id,primarykey"`).
 // The struct must have at least one primary key field.
 // Returns a wrapped [sql.ErrNoRows] error if no row was affected by the delete.
+//
+// If the struct has a field with a `db` tag value having a ",version" suffix,
+// then the row is only deleted if its version column still has the value
+// of the field (optimistic locking) and an [ErrStaleRow] error is returned
+// instead of [sql.ErrNoRows] if no row was deleted.
+//
+// If the struct has a field with a `db` tag value having a ",softdelete" suffix,
+// then the row is not deleted, but its soft delete column is set to the
+// time of the [Clock] from ctx if it is NULL, using [SoftDeleteQueryBuilder.SoftDelete].
+// Use [HardDeleteRowStruct] to delete such rows.
 func DeleteRowStruct(ctx context.Context, conn Executor, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rowStruct StructWithTableName) error {
 	if refl == nil {
 		return errors.New("DeleteRowStruct: nil StructReflector")
@@ -23,65 +34,112 @@ func DeleteRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 	if err != nil {
 		return err
 	}
+	return deleteRowStruct(ctx, conn, refl, builder, fmtr, structVal, false)
+}
+
+func deleteRowStruct(ctx context.Context, conn Executor, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, structVal reflect.Value, hardDelete bool) error {
 	structType := structVal.Type()
 
 	var vals []any
 	// Use the cache (no user options to vary the key)
-	deleteRowStructQueryCacheMtx.RLock()
-	cached, ok := deleteRowStructQueryCache[structType][refl][builder][fmtr]
-	deleteRowStructQueryCacheMtx.RUnlock()
-	if ok {
-		vals = make([]any, len(cached.structFieldIndices))
-		for i, fieldIndex := range cached.structFieldIndices {
-			vals[i] = structVal.FieldByIndex(fieldIndex).Interface()
-		}
-		n, err := conn.ExecRowsAffected(ctx, cached.query, vals...)
-		if err != nil {
-			return WrapErrorWithQuery(err, cached.query, vals, fmtr)
-		}
-		if n == 0 {
-			return WrapErrorWithQuery(sql.ErrNoRows, cached.query, vals, fmtr)
+	// only for the queries of DeleteRowStruct
+	useCache := !hardDelete
+	if useCache {
+		deleteRowStructQueryCacheMtx.RLock()
+		cached, ok := deleteRowStructQueryCache[structType][refl][builder][fmtr]
+		deleteRowStructQueryCacheMtx.RUnlock()
+		if ok {
+			vals = make([]any, len(cached.structFieldIndices))
+			for i, fieldIndex := range cached.structFieldIndices {
+				vals[i] = structFieldValue(refl, structVal, fieldIndex, &cached.columns[i])
+			}
+			if cached.softDelete {
+				vals = softDeleteValues(ctx, vals)
+			}
+			return execDelete(ctx, conn, refl, fmtr, structVal, cached.versionIndex >= 0, cached.query, vals)
 		}
-		return nil
 	}
 
-	var columns []ColumnInfo
-	columns, cached.structFieldIndices, vals, err = refl.ReflectStructColumnsFieldIndicesAndValues(structVal, OnlyPrimaryKey)
+	var (
+		cached  queryCache
+		columns []ColumnInfo
+		err     error
+	)
+	columns, cached.structFieldIndices, vals, err = refl.ReflectStructColumnsFieldIndicesAndValues(structVal, onlyPrimaryKeyAndVersion)
 	if err != nil {
 		return err
 	}
-	if len(columns) == 0 {
-		table, _ := refl.TableNameForStruct(structType)
-		return fmt.Errorf("DeleteRowStruct of table %s: %s has no mapped primary key field", table, structType)
-	}
 	table, err := refl.TableNameForStruct(structType)
 	if err != nil {
 		return err
 	}
-	cached.query, err = builder.Delete(fmtr, table, columns)
+	if !slices.ContainsFunc(columns, func(col ColumnInfo) bool { return col.PrimaryKey }) {
+		return fmt.Errorf("DeleteRowStruct of table %s: %s has no mapped primary key field", table, structType)
+	}
+	cached.versionIndex, err = versionColumnIndex(table, columns)
 	if err != nil {
-		return fmt.Errorf("DeleteRowStruct of table %s: failed to create DELETE query: %w", table, err)
+		return err
 	}
-
-	deleteRowStructQueryCacheMtx.Lock()
-	if _, ok := deleteRowStructQueryCache[structType]; !ok {
-		deleteRowStructQueryCache[structType] = make(map[StructReflector]map[QueryBuilder]map[QueryFormatter]queryCache)
+	softDeleteCol := ""
+	if !hardDelete {
+		softDeleteCol, err = softDeleteColumn(refl, structType)
+		if err != nil {
+			return err
+		}
 	}
-	if _, ok := deleteRowStructQueryCache[structType][refl]; !ok {
-		deleteRowStructQueryCache[structType][refl] = make(map[QueryBuilder]map[QueryFormatter]queryCache)
+	if softDeleteCol != "" {
+		sqb, err := softDeleteQueryBuilder(builder)
+		if err != nil {
+			return fmt.Errorf("DeleteRowStruct of table %s: %w", table, err)
+		}
+		cached.query, err = sqb.SoftDelete(fmtr, table, columns, softDeleteCol)
+		if err != nil {
+			return fmt.Errorf("DeleteRowStruct of table %s: failed to create soft delete UPDATE query: %w", table, err)
+		}
+		cached.softDelete = true
+		vals = softDeleteValues(ctx, vals)
+	} else {
+		cached.query, err = builder.Delete(fmtr, table, columns)
+		if err != nil {
+			return fmt.Errorf("DeleteRowStruct of table %s: failed to create DELETE query: %w", table, err)
+		}
 	}
-	if _, ok := deleteRowStructQueryCache[structType][refl][builder]; !ok {
-		deleteRowStructQueryCache[structType][refl][builder] = make(map[QueryFormatter]queryCache)
+
+	cached.columns = columns
+	if useCache {
+		deleteRowStructQueryCacheMtx.Lock()
+		if _, ok := deleteRowStructQueryCache[structType]; !ok {
+			deleteRowStructQueryCache[structType] = make(map[StructReflector]map[QueryBuilder]map[QueryFormatter]queryCache)
+		}
+		if _, ok := deleteRowStructQueryCache[structType][refl]; !ok {
+			deleteRowStructQueryCache[structType][refl] = make(map[QueryBuilder]map[QueryFormatter]queryCache)
+		}
+		if _, ok := deleteRowStructQueryCache[structType][refl][builder]; !ok {
+			deleteRowStructQueryCache[structType][refl][builder] = make(map[QueryFormatter]queryCache)
+		}
+		deleteRowStructQueryCache[structType][refl][builder][fmtr] = cached
+		deleteRowStructQueryCacheMtx.Unlock()
 	}
-	deleteRowStructQueryCache[structType][refl][builder][fmtr] = cached
-	deleteRowStructQueryCacheMtx.Unlock()
 
-	n, err := conn.ExecRowsAffected(ctx, cached.query, vals...)
+	return execDelete(ctx, conn, refl, fmtr, structVal, cached.versionIndex >= 0, cached.query, vals)
+}
+
+// execDelete executes the DELETE or soft delete query of a row struct
+// and returns a wrapped [sql.ErrNoRows] error if no row was deleted,
+// or an [ErrStaleRow] error if the query has a version condition.
+func execDelete(ctx context.Context, conn Executor, refl StructReflector, fmtr QueryFormatter, structVal reflect.Value, versioned bool, query string, vals []any) error {
+	execRowsAffected := func() (int64, error) {
+		return conn.ExecRowsAffected(ctx, query, vals...)
+	}
+	if versioned {
+		return execVersioned(refl, fmtr, structVal, rowVersion{}, query, vals, execRowsAffected)
+	}
+	n, err := execRowsAffected()
 	if err != nil {
-		return WrapErrorWithQuery(err, cached.query, vals, fmtr)
+		return WrapErrorWithQuery(err, query, vals, fmtr)
 	}
 	if n == 0 {
-		return WrapErrorWithQuery(sql.ErrNoRows, cached.query, vals, fmtr)
+		return WrapErrorWithQuery(sql.ErrNoRows, query, vals, fmtr)
 	}
 	return nil
 }
@@ -94,7 +152,9 @@ func DeleteRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 // in their `db` struct tag (e.g., ID int `db:"id,primarykey"`).
 // The struct must have at least one primary key field.
 // The returned deleteFunc returns a wrapped [sql.ErrNoRows] error
-// if no row was affected by the delete.
+// if no row was affected by the delete, or an [ErrStaleRow] error
+// for structs with a version field like [DeleteRowStruct].
+// Structs with a softdelete field are soft deleted like with [DeleteRowStruct].
 // The returned closeStmt function must be called to release the prepared statement.
 func DeleteRowStructStmt[S StructWithTableName](ctx context.Context, conn Preparer, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter) (deleteFunc func(ctx context.Context, rowStruct S) error, closeStmt func() error, err error) {
 	if refl == nil {
@@ -109,22 +169,43 @@ func DeleteRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 		return nil, nil, err
 	}
 
-	columns, err := refl.ReflectStructColumns(structType, OnlyPrimaryKey)
+	columns, err := refl.ReflectStructColumns(structType, onlyPrimaryKeyAndVersion)
 	if err != nil {
 		return nil, nil, err
 	}
-	if len(columns) == 0 {
+	if !slices.ContainsFunc(columns, func(col ColumnInfo) bool { return col.PrimaryKey }) {
 		return nil, nil, fmt.Errorf("DeleteRowStructStmt of table %s: %s has no mapped primary key field", table, structType)
 	}
+	versionIndex, err := versionColumnIndex(table, columns)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	query, err := builder.Delete(fmtr, table, columns)
+	softDeleteCol, err := softDeleteColumn(refl, structType)
 	if err != nil {
-		return nil, nil, fmt.Errorf("DeleteRowStructStmt of table %s: failed to create DELETE query: %w", table, err)
+		return nil, nil, err
+	}
+
+	var query string
+	if softDeleteCol != "" {
+		sqb, err := softDeleteQueryBuilder(builder)
+		if err != nil {
+			return nil, nil, fmt.Errorf("DeleteRowStructStmt of table %s: %w", table, err)
+		}
+		query, err = sqb.SoftDelete(fmtr, table, columns, softDeleteCol)
+		if err != nil {
+			return nil, nil, fmt.Errorf("DeleteRowStructStmt of table %s: failed to create soft delete UPDATE query: %w", table, err)
+		}
+	} else {
+		query, err = builder.Delete(fmtr, table, columns)
+		if err != nil {
+			return nil, nil, fmt.Errorf("DeleteRowStructStmt of table %s: failed to create DELETE query: %w", table, err)
+		}
 	}
 
 	stmt, err := conn.Prepare(ctx, query)
 	if err != nil {
-		return nil, nil, fmt.Errorf("DeleteRowStructStmt of table %s: failed to prepare DELETE statement: %w", table, err)
+		return nil, nil, fmt.Errorf("DeleteRowStructStmt of table %s: failed to prepare statement: %w", table, err)
 	}
 
 	deleteFunc = func(ctx context.Context, rowStruct S) error {
@@ -132,10 +213,18 @@ func DeleteRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 		if err != nil {
 			return err
 		}
-		vals, err := refl.ReflectStructValues(v, OnlyPrimaryKey)
+		vals, err := refl.ReflectStructValues(v, onlyPrimaryKeyAndVersion)
 		if err != nil {
 			return err
 		}
+		if softDeleteCol != "" {
+			vals = softDeleteValues(ctx, vals)
+		}
+		if versionIndex >= 0 {
+			return execVersioned(refl, fmtr, v, rowVersion{}, query, vals, func() (int64, error) {
+				return stmt.ExecRowsAffected(ctx, vals...)
+			})
+		}
 		n, err := stmt.ExecRowsAffected(ctx, vals...)
 		if err != nil {
 			return WrapErrorWithQuery(err, query, vals, fmtr)
@@ -155,7 +244,9 @@ func DeleteRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 // Primary key columns are identified by the "primarykey" option
 // in their `db` struct tag (e.g., ID int `db:"id,primarykey"`).
 // Returns a wrapped [sql.ErrNoRows] error if no row was affected
-// by the delete of any of the structs.
+// by the delete of any of the structs, or an [ErrStaleRow] error
+// for structs with a version field like [DeleteRowStruct].
+// Structs with a softdelete field are soft deleted like with [DeleteRowStruct].
 func DeleteRowStructs[S StructWithTableName](ctx context.Context, conn Connection, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rowStructs []S) error {
 	if refl == nil {
 		return errors.New("DeleteRowStructs: nil StructReflector")
@@ -0,0 +1,217 @@
+package sqldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DiffRowStructs compares the mapped fields of oldRowStruct and newRowStruct
+// and returns the values of newRowStruct for the columns with different values
+// or nil if all values are equal.
+// Struct fields can be filtered with options like [IgnoreColumns] or [OnlyColumns].
+//
+// Values implementing [driver.Valuer] like [Nullable] are compared
+// by the driver values they return, time.Time values with time.Time.Equal,
+// and byte slices by their contents.
+func DiffRowStructs[S any](refl StructReflector, oldRowStruct, newRowStruct S, options ...QueryOption) (Values, error) {
+	if refl == nil {
+		return nil, errors.New("DiffRowStructs: nil StructReflector")
+	}
+	oldVal, newVal, err := derefStructPair(oldRowStruct, newRowStruct)
+	if err != nil {
+		return nil, err
+	}
+	columns, newVals, err := refl.ReflectStructColumnsAndValues(newVal, options...)
+	if err != nil {
+		return nil, err
+	}
+	oldVals, err := refl.ReflectStructValues(oldVal, options...)
+	if err != nil {
+		return nil, err
+	}
+	var diff Values
+	for i, col := range columns {
+		equal, err := equalValues(oldVals[i], newVals[i])
+		if err != nil {
+			return nil, fmt.Errorf("can't compare values of column %s: %w", col.Name, err)
+		}
+		if !equal {
+			if diff == nil {
+				diff = make(Values)
+			}
+			diff[col.Name] = newVals[i]
+		}
+	}
+	return diff, nil
+}
+
+// UpdateRowStructChanges updates the row of newRowStruct identified
+// by its primary key columns like [UpdateRowStruct], but only sets the columns
+// whose values differ from oldRowStruct as returned by [DiffRowStructs].
+// No query is executed if no values changed.
+// Returns the values of the changed columns for auditing.
+//
+// The primary key values of oldRowStruct and newRowStruct must be equal.
+// Read-only columns and columns with the created tag option are never updated.
+// Columns with the updated tag option and version columns are not compared,
+// but set like with [UpdateRowStruct] if any other column changed,
+// which requires newRowStruct to be a pointer for structs with a version field.
+func UpdateRowStructChanges[S StructWithTableName](ctx context.Context, conn Executor, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, oldRowStruct, newRowStruct S, options ...QueryOption) (Values, error) {
+	if refl == nil {
+		return nil, errors.New("UpdateRowStructChanges: nil StructReflector")
+	}
+	oldVal, newVal, err := derefStructPair(oldRowStruct, newRowStruct)
+	if err != nil {
+		return nil, err
+	}
+	structType := newVal.Type()
+	table, err := refl.TableNameForStruct(structType)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, IgnoreReadOnly, ignoreCreated)
+	columns, fieldIndices, newVals, err := refl.ReflectStructColumnsFieldIndicesAndValues(newVal, options...)
+	if err != nil {
+		return nil, err
+	}
+	oldVals, err := refl.ReflectStructValues(oldVal, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		diff          Values
+		updateColumns []ColumnInfo
+		updateFields  [][]int
+		vals          []any
+		hasPK         bool
+	)
+	for i, col := range columns {
+		equal, err := equalValues(oldVals[i], newVals[i])
+		if err != nil {
+			return nil, fmt.Errorf("can't compare values of column %s: %w", col.Name, err)
+		}
+		switch {
+		case col.PrimaryKey:
+			if !equal {
+				return nil, fmt.Errorf("UpdateRowStructChanges of table %s: primary key column %s of old and new %s differs", table, col.Name, structType)
+			}
+			hasPK = true
+		case col.Version || col.Updated:
+			// Set if any other column changed
+		case equal:
+			continue
+		default:
+			if diff == nil {
+				diff = make(Values)
+			}
+			diff[col.Name] = newVals[i]
+		}
+		updateColumns = append(updateColumns, col)
+		updateFields = append(updateFields, fieldIndices[i])
+		vals = append(vals, newVals[i])
+	}
+	if !hasPK {
+		return nil, fmt.Errorf("UpdateRowStructChanges of table %s: %s has no mapped primary key field", table, structType)
+	}
+	if len(diff) == 0 {
+		return nil, nil
+	}
+
+	var (
+		cached  queryCache
+		version rowVersion
+	)
+	versionIndex, err := versionColumnIndex(table, updateColumns)
+	if err != nil {
+		return nil, err
+	}
+	if versionIndex >= 0 {
+		version, err = newRowVersion(newVal, updateFields[versionIndex])
+		if err != nil {
+			return nil, err
+		}
+		updateColumns = versionCondition(updateColumns, versionIndex)
+		vals = append(vals, vals[versionIndex])
+		vals[versionIndex] = version.next.Interface()
+	}
+	cached.query, err = builder.UpdateColumns(fmtr, table, updateColumns)
+	if err != nil {
+		return nil, err
+	}
+	vals = reorderForUpdate(updateColumns, vals)
+	cached.versionIndex = versionIndexForUpdate(updateColumns, versionIndex)
+	cached.timestamps, err = timestampFieldsOf(refl, structType, reorderForUpdate(updateColumns, updateColumns), false)
+	if err != nil {
+		return nil, err
+	}
+	err = execUpdateRowStruct(ctx, conn, refl, fmtr, newVal, cached, version, vals)
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// derefStructPair dereferences oldRowStruct and newRowStruct
+// and returns an error if they are not structs of the same type.
+func derefStructPair(oldRowStruct, newRowStruct any) (oldVal, newVal reflect.Value, err error) {
+	oldVal, err = derefStruct(reflect.ValueOf(oldRowStruct))
+	if err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+	newVal, err = derefStruct(reflect.ValueOf(newRowStruct))
+	if err != nil {
+		return reflect.Value{}, reflect.Value{}, err
+	}
+	if oldVal.Type() != newVal.Type() {
+		return reflect.Value{}, reflect.Value{}, fmt.Errorf("can't compare row structs of different types %s and %s", oldVal.Type(), newVal.Type())
+	}
+	return oldVal, newVal, nil
+}
+
+// equalValues returns if a and b are equal column values
+// using the values returned by driver.Valuer implementations,
+// time.Time.Equal for times and the contents of byte slices.
+func equalValues(a, b any) (bool, error) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		return va.IsValid() == vb.IsValid(), nil
+	}
+	if va.Type() != vb.Type() {
+		return false, nil
+	}
+	if va.Kind() == reflect.Pointer {
+		if va.IsNil() || vb.IsNil() {
+			return va.IsNil() == vb.IsNil(), nil
+		}
+	}
+	if valuerA, ok := a.(driver.Valuer); ok {
+		da, err := valuerA.Value()
+		if err != nil {
+			return false, err
+		}
+		db, err := b.(driver.Valuer).Value()
+		if err != nil {
+			return false, err
+		}
+		// Prevent endless recursion for Valuers returning themselves
+		if reflect.TypeOf(da) == va.Type() || reflect.TypeOf(db) == va.Type() {
+			return reflect.DeepEqual(da, db), nil
+		}
+		return equalValues(da, db)
+	}
+	switch {
+	case va.Kind() == reflect.Pointer:
+		return equalValues(va.Elem().Interface(), vb.Elem().Interface())
+	case va.Type() == typeOfTime:
+		return a.(time.Time).Equal(b.(time.Time)), nil
+	case va.Kind() == reflect.Slice && va.Type().Elem().Kind() == reflect.Uint8:
+		return bytes.Equal(va.Bytes(), vb.Bytes()) && va.IsNil() == vb.IsNil(), nil
+	}
+	return reflect.DeepEqual(a, b), nil
+}
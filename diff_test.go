@@ -0,0 +1,232 @@
+package sqldb
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type diffTestStruct struct {
+	TableName `db:"diff_table"`
+
+	ID       int64             `db:"id,primarykey"`
+	Name     string            `db:"name"`
+	Email    Nullable[string]  `db:"email"`
+	Born     time.Time         `db:"born"`
+	Data     []byte            `db:"data"`
+	Comment  *string           `db:"comment"`
+	Score    sql.NullFloat64   `db:"score"`
+	Computed string            `db:"computed,readonly"`
+	Tags     map[string]string `db:"tags"`
+}
+
+func TestDiffRowStructs(t *testing.T) {
+	born := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+	comment := "comment"
+	base := diffTestStruct{
+		ID:      1,
+		Name:    "Alice",
+		Email:   Nullable[string]{Val: "alice@example.com", Valid: true},
+		Born:    born,
+		Data:    []byte("data"),
+		Comment: &comment,
+		Score:   sql.NullFloat64{Float64: 1.5, Valid: true},
+		Tags:    map[string]string{"a": "b"},
+	}
+
+	for _, scenario := range []struct {
+		name    string
+		change  func(s *diffTestStruct)
+		options []QueryOption
+		want    Values
+	}{
+		{
+			name:   "no changes",
+			change: func(s *diffTestStruct) {},
+		},
+		{
+			name: "equal values of different instances",
+			change: func(s *diffTestStruct) {
+				otherComment := "comment"
+				s.Born = born.In(time.FixedZone("CET", 3600))
+				s.Data = []byte("data")
+				s.Comment = &otherComment
+				s.Tags = map[string]string{"a": "b"}
+			},
+		},
+		{
+			name:   "string",
+			change: func(s *diffTestStruct) { s.Name = "Bob" },
+			want:   Values{"name": "Bob"},
+		},
+		{
+			name:   "Nullable set to NULL",
+			change: func(s *diffTestStruct) { s.Email = Nullable[string]{} },
+			want:   Values{"email": Nullable[string]{}},
+		},
+		{
+			name:   "invalid Nullable with different value",
+			change: func(s *diffTestStruct) { s.Email = Nullable[string]{Val: "bob@example.com"} },
+			want:   Values{"email": Nullable[string]{Val: "bob@example.com"}},
+		},
+		{
+			name:   "time",
+			change: func(s *diffTestStruct) { s.Born = born.Add(time.Second) },
+			want:   Values{"born": born.Add(time.Second)},
+		},
+		{
+			name:   "bytes",
+			change: func(s *diffTestStruct) { s.Data = []byte("changed") },
+			want:   Values{"data": []byte("changed")},
+		},
+		{
+			name:   "nil pointer",
+			change: func(s *diffTestStruct) { s.Comment = nil },
+			want:   Values{"comment": (*string)(nil)},
+		},
+		{
+			name:   "sql.NullFloat64",
+			change: func(s *diffTestStruct) { s.Score.Float64 = 2 },
+			want:   Values{"score": sql.NullFloat64{Float64: 2, Valid: true}},
+		},
+		{
+			name:   "map",
+			change: func(s *diffTestStruct) { s.Tags = map[string]string{"a": "c"} },
+			want:   Values{"tags": map[string]string{"a": "c"}},
+		},
+		{
+			name:    "ignored columns",
+			change:  func(s *diffTestStruct) { s.Name = "Bob"; s.Computed = "x" },
+			options: []QueryOption{IgnoreReadOnly, IgnoreColumns("name")},
+		},
+	} {
+		t.Run(scenario.name, func(t *testing.T) {
+			// given
+			changed := base
+			scenario.change(&changed)
+
+			// when
+			diff, err := DiffRowStructs(NewTaggedStructReflector(), base, changed, scenario.options...)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, scenario.want, diff)
+		})
+	}
+
+	t.Run("invalid Nullable equals NULL", func(t *testing.T) {
+		// Invalid Nullable values are NULL regardless of Val
+		diff, err := DiffRowStructs(NewTaggedStructReflector(),
+			&diffTestStruct{Email: Nullable[string]{Val: "a"}},
+			&diffTestStruct{Email: Nullable[string]{Val: "b"}},
+		)
+		require.NoError(t, err)
+		assert.Nil(t, diff)
+	})
+
+	t.Run("different types", func(t *testing.T) {
+		_, err := DiffRowStructs[any](NewTaggedStructReflector(), diffTestStruct{}, versionTestStruct{})
+		require.Error(t, err)
+	})
+}
+
+func TestUpdateRowStructChanges(t *testing.T) {
+	t.Run("updates changed columns", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		oldRow := diffTestStruct{ID: 1, Name: "Alice", Data: []byte("data")}
+		newRow := oldRow
+		newRow.Name = "Bob"
+		newRow.Data = []byte("changed")
+		newRow.Computed = "ignored"
+
+		// when
+		diff, err := UpdateRowStructChanges(t.Context(), conn, refl, builder, fmtr, oldRow, newRow)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Values{"name": "Bob", "data": []byte("changed")}, diff)
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, "UPDATE diff_table SET name=$1, data=$2 WHERE id = $3", conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{"Bob", []byte("changed"), int64(1)}, conn.Recordings.Execs[0].Args)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		row := diffTestStruct{ID: 1, Name: "Alice"}
+
+		// when
+		diff, err := UpdateRowStructChanges(t.Context(), conn, refl, builder, fmtr, row, row)
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, diff)
+		assert.Empty(t, conn.Recordings.Execs)
+	})
+
+	t.Run("different primary key", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		_, err := UpdateRowStructChanges(t.Context(), conn, refl, builder, fmtr, diffTestStruct{ID: 1}, diffTestStruct{ID: 2})
+		require.Error(t, err)
+		assert.Empty(t, conn.Recordings.Execs)
+	})
+
+	t.Run("version", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(1)
+		oldRow := versionTestStruct{ID: 1, Name: "Alice", Version: 3}
+		newRow := &versionTestStruct{ID: 1, Name: "Bob", Version: 3}
+
+		// when
+		diff, err := UpdateRowStructChanges(t.Context(), conn, refl, builder, fmtr, &oldRow, newRow)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Values{"name": "Bob"}, diff)
+		assert.Equal(t, int32(4), newRow.Version)
+		assert.Equal(t, int32(3), oldRow.Version)
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, "UPDATE versioned_table SET name=$1, version=$2 WHERE id = $3 AND version = $4", conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{"Bob", int32(4), int64(1), int32(3)}, conn.Recordings.Execs[0].Args)
+	})
+
+	t.Run("stale row", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(0)
+		oldRow := &versionTestStruct{ID: 1, Name: "Alice", Version: 3}
+		newRow := &versionTestStruct{ID: 1, Name: "Bob", Version: 3}
+
+		// when
+		_, err := UpdateRowStructChanges(t.Context(), conn, refl, builder, fmtr, oldRow, newRow)
+
+		// then
+		require.ErrorAs(t, err, new(ErrStaleRow))
+		assert.Equal(t, int32(3), newRow.Version)
+	})
+
+	t.Run("timestamps", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		oldRow := &timestampsTestStruct{ID: 1, Name: "Alice", CreatedAt: created}
+		newRow := &timestampsTestStruct{ID: 1, Name: "Bob"}
+
+		// when
+		diff, err := UpdateRowStructChanges(timestampsTestContext(t), conn, refl, builder, fmtr, oldRow, newRow)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Values{"name": "Bob"}, diff)
+		assert.Equal(t, timestampsTestNow, *newRow.UpdatedAt)
+		assert.True(t, newRow.CreatedAt.IsZero(), "created must not be set by update")
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, "UPDATE timestamps_table SET name=$1, updated_at=$2 WHERE id = $3", conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{"Bob", timestampsTestNow, int64(1)}, conn.Recordings.Execs[0].Args)
+	})
+}
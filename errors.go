@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 )
 
 // ReplaceErrNoRows returns the passed replacement error
@@ -78,6 +79,104 @@ const ErrDeadlock sentinelError = "deadlock detected"
 // transaction. The caller should retry the transaction.
 const ErrSerializationFailure sentinelError = "serialization failure"
 
+// ErrLockTimeout indicates that a lock could not be acquired
+// within the lock timeout of the database or immediately
+// when the lock was requested with NOWAIT.
+// The caller may retry the transaction.
+const ErrLockTimeout sentinelError = "lock timeout"
+
+// ErrConnectionLost indicates that the connection to the database
+// was lost or broken while executing a statement.
+// The caller may retry the whole transaction with a new connection,
+// but a statement outside of a transaction might have been executed.
+const ErrConnectionLost sentinelError = "connection lost"
+
+// ErrStmtInvalidated indicates that a prepared statement can't be
+// executed anymore because the database objects it depends on
+// have changed since it was prepared, for example
+// PostgreSQL's "cached plan must not change result type".
+// The statement has to be prepared again.
+const ErrStmtInvalidated sentinelError = "prepared statement invalidated"
+
+// ErrInvalidPageCursor is returned by [QueryPage] for a cursor
+// that can't be decoded or was created for a different sort order.
+const ErrInvalidPageCursor sentinelError = "invalid page cursor"
+
+// ErrBatchAborted is the error of statements of a [Batch]
+// that were not executed because a previous statement
+// of the batch failed with a database error.
+const ErrBatchAborted sentinelError = "batch aborted"
+
+// IsRetryable returns true if err is or wraps an error
+// that indicates a transient failure where retrying
+// the whole transaction might succeed:
+// [ErrSerializationFailure], [ErrDeadlock], [ErrLockTimeout],
+// or [ErrConnectionLost].
+//
+// [ErrConnectionLost] is not retryable if the connection was lost
+// while committing the transaction with [Transaction] or [IsolatedTransaction],
+// because the transaction might have been committed before
+// the connection was lost and would be executed twice by a retry.
+//
+// [ErrQueryCanceled] is not retryable because it is typically
+// caused by a canceled context that would also cancel the retry.
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrConnectionLost) {
+		var commitErr commitError
+		return !errors.As(err, &commitErr)
+	}
+	return errors.Is(err, ErrSerializationFailure) ||
+		errors.Is(err, ErrDeadlock) ||
+		errors.Is(err, ErrLockTimeout)
+}
+
+// commitError wraps the error of a transaction COMMIT
+// so that [IsRetryable] can tell it apart from errors
+// that happened before the COMMIT was sent.
+type commitError struct {
+	err error
+}
+
+func (e commitError) Error() string { return e.err.Error() }
+
+func (e commitError) Unwrap() error { return e.err }
+
+// TimeoutLimit names the limit that fired for an [ErrQueryTimeout].
+type TimeoutLimit string
+
+const (
+	// QueryTimeoutLimit is the timeout for a single statement from
+	// [Config.DefaultQueryTimeout] or [ContextWithQueryTimeout],
+	// or the server-side statement timeout.
+	QueryTimeoutLimit TimeoutLimit = "query"
+
+	// TransactionTimeoutLimit is the timeout for a whole transaction
+	// from [Config.DefaultTransactionTimeout].
+	TransactionTimeoutLimit TimeoutLimit = "transaction"
+)
+
+// ErrQueryTimeout is returned when a statement or transaction
+// exceeded a timeout enforced by [TimeoutInterceptor]
+// or a server-side statement timeout.
+// Limit tells which timeout fired.
+// It unwraps to context.DeadlineExceeded.
+type ErrQueryTimeout struct {
+	Limit TimeoutLimit
+	// Timeout is zero if not known, like for server-side timeouts.
+	Timeout time.Duration
+}
+
+func (e ErrQueryTimeout) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("%s timeout of %s exceeded", e.Limit, e.Timeout)
+	}
+	return string(e.Limit) + " timeout exceeded"
+}
+
+func (ErrQueryTimeout) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
 // ErrRaisedException represents an exception explicitly raised by the database.
 type ErrRaisedException struct {
 	Message string
@@ -246,3 +345,85 @@ type ErrMaxNumRowsExceeded struct {
 func (e ErrMaxNumRowsExceeded) Error() string {
 	return fmt.Sprintf("max number of rows (%d) exceeded", e.MaxNumRows)
 }
+
+// ErrStaleRow is returned by [UpdateRowStruct], [UpsertRowStruct],
+// [DeleteRowStruct], and their batch variants for structs with a
+// `version` tagged field when no row was affected because the row
+// was changed or deleted since the version was read (optimistic locking).
+type ErrStaleRow struct {
+	Table      string
+	PrimaryKey []any
+}
+
+// Error implements the error interface.
+func (e ErrStaleRow) Error() string {
+	return fmt.Sprintf("stale row of table %s with primary key %v", e.Table, e.PrimaryKey)
+}
+
+// ErrorKind returns the name of the generic error that err is or wraps,
+// for example "ErrUniqueViolation" or "ErrDeadlock",
+// as low-cardinality classification for logs, metrics, and traces.
+//
+// The most specific kind is returned, so an [ErrUniqueViolation]
+// is classified as "ErrUniqueViolation" and not as the
+// [ErrIntegrityConstraintViolation] it unwraps to.
+// An empty string is returned for a nil error
+// and "other" for an error of no known kind.
+func ErrorKind(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, sql.ErrNoRows):
+		return "ErrNoRows"
+	case errors.As(err, new(ErrUniqueViolation)):
+		return "ErrUniqueViolation"
+	case errors.As(err, new(ErrForeignKeyViolation)):
+		return "ErrForeignKeyViolation"
+	case errors.As(err, new(ErrNotNullViolation)):
+		return "ErrNotNullViolation"
+	case errors.As(err, new(ErrCheckViolation)):
+		return "ErrCheckViolation"
+	case errors.As(err, new(ErrExclusionViolation)):
+		return "ErrExclusionViolation"
+	case errors.As(err, new(ErrRestrictViolation)):
+		return "ErrRestrictViolation"
+	case errors.As(err, new(ErrIntegrityConstraintViolation)):
+		return "ErrIntegrityConstraintViolation"
+	case errors.As(err, new(ErrRaisedException)):
+		return "ErrRaisedException"
+	case errors.Is(err, ErrDeadlock):
+		return "ErrDeadlock"
+	case errors.Is(err, ErrSerializationFailure):
+		return "ErrSerializationFailure"
+	case errors.Is(err, ErrLockTimeout):
+		return "ErrLockTimeout"
+	case errors.Is(err, ErrConnectionLost):
+		return "ErrConnectionLost"
+	case errors.Is(err, ErrStmtInvalidated):
+		return "ErrStmtInvalidated"
+	case errors.As(err, new(ErrQueryTimeout)):
+		return "ErrQueryTimeout"
+	case errors.Is(err, ErrQueryCanceled), errors.Is(err, context.Canceled):
+		return "ErrQueryCanceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "DeadlineExceeded"
+	case errors.As(err, new(ErrMaxNumRowsExceeded)):
+		return "ErrMaxNumRowsExceeded"
+	case errors.Is(err, ErrNoDatabaseConnection):
+		return "ErrNoDatabaseConnection"
+	case errors.Is(err, ErrWithinTransaction):
+		return "ErrWithinTransaction"
+	case errors.Is(err, ErrNotWithinTransaction):
+		return "ErrNotWithinTransaction"
+	case errors.Is(err, ErrNullValueNotAllowed):
+		return "ErrNullValueNotAllowed"
+	case errors.Is(err, ErrInvalidPageCursor):
+		return "ErrInvalidPageCursor"
+	case errors.Is(err, ErrBatchAborted):
+		return "ErrBatchAborted"
+	case errors.As(err, new(ErrStaleRow)):
+		return "ErrStaleRow"
+	default:
+		return "other"
+	}
+}
@@ -79,6 +79,35 @@ func TestSentinelErrors(t *testing.T) {
 	assert.Equal(t, "not within a transaction", ErrNotWithinTransaction.Error())
 	assert.Equal(t, "null value not allowed", ErrNullValueNotAllowed.Error())
 	assert.Equal(t, "deadlock detected", ErrDeadlock.Error())
+	assert.Equal(t, "lock timeout", ErrLockTimeout.Error())
+	assert.Equal(t, "connection lost", ErrConnectionLost.Error())
+	assert.Equal(t, "prepared statement invalidated", ErrStmtInvalidated.Error())
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "ErrSerializationFailure", err: ErrSerializationFailure, want: true},
+		{name: "joined ErrDeadlock", err: errors.Join(ErrDeadlock, errors.New("driver error")), want: true},
+		{name: "wrapped ErrLockTimeout", err: fmt.Errorf("wrap: %w", ErrLockTimeout), want: true},
+		{name: "ErrConnectionLost", err: ErrConnectionLost, want: true},
+		{name: "ErrConnectionLost from COMMIT", err: fmt.Errorf("transaction 1 COMMIT error: %w", commitError{ErrConnectionLost}), want: false},
+		{name: "ErrSerializationFailure from COMMIT", err: fmt.Errorf("transaction 1 COMMIT error: %w", commitError{ErrSerializationFailure}), want: true},
+		{name: "ErrQueryCanceled", err: ErrQueryCanceled, want: false},
+		{name: "context.DeadlineExceeded", err: context.DeadlineExceeded, want: false},
+		{name: "ErrUniqueViolation", err: ErrUniqueViolation{Constraint: "c"}, want: false},
+		{name: "sql.ErrNoRows", err: sql.ErrNoRows, want: false},
+		{name: "other", err: errors.New("other"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRetryable(tt.err))
+		})
+	}
 }
 
 func TestErrRaisedException(t *testing.T) {
@@ -223,3 +252,46 @@ WHERE b = '2'
 		})
 	}
 }
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "ErrNoRows", err: sql.ErrNoRows, want: "ErrNoRows"},
+		{name: "wrapped ErrNoRows", err: fmt.Errorf("wrap: %w", sql.ErrNoRows), want: "ErrNoRows"},
+		{name: "ErrUniqueViolation", err: ErrUniqueViolation{Constraint: "c"}, want: "ErrUniqueViolation"},
+		{name: "joined ErrUniqueViolation", err: errors.Join(ErrUniqueViolation{}, errors.New("driver error")), want: "ErrUniqueViolation"},
+		{name: "ErrForeignKeyViolation", err: ErrForeignKeyViolation{}, want: "ErrForeignKeyViolation"},
+		{name: "ErrNotNullViolation", err: ErrNotNullViolation{}, want: "ErrNotNullViolation"},
+		{name: "ErrCheckViolation", err: ErrCheckViolation{}, want: "ErrCheckViolation"},
+		{name: "ErrExclusionViolation", err: ErrExclusionViolation{}, want: "ErrExclusionViolation"},
+		{name: "ErrRestrictViolation", err: ErrRestrictViolation{}, want: "ErrRestrictViolation"},
+		{name: "ErrIntegrityConstraintViolation", err: ErrIntegrityConstraintViolation{}, want: "ErrIntegrityConstraintViolation"},
+		{name: "ErrRaisedException", err: ErrRaisedException{Message: "m"}, want: "ErrRaisedException"},
+		{name: "ErrDeadlock", err: errors.Join(ErrDeadlock, errors.New("driver error")), want: "ErrDeadlock"},
+		{name: "ErrSerializationFailure", err: ErrSerializationFailure, want: "ErrSerializationFailure"},
+		{name: "ErrLockTimeout", err: errors.Join(ErrLockTimeout, errors.New("driver error")), want: "ErrLockTimeout"},
+		{name: "ErrConnectionLost", err: ErrConnectionLost, want: "ErrConnectionLost"},
+		{name: "ErrStmtInvalidated", err: ErrStmtInvalidated, want: "ErrStmtInvalidated"},
+		{name: "ErrInvalidPageCursor", err: ErrInvalidPageCursor, want: "ErrInvalidPageCursor"},
+		{name: "ErrBatchAborted", err: ErrBatchAborted, want: "ErrBatchAborted"},
+		{name: "ErrStaleRow", err: fmt.Errorf("wrapped: %w", ErrStaleRow{Table: "t", PrimaryKey: []any{1}}), want: "ErrStaleRow"},
+		{name: "ErrQueryCanceled", err: ErrQueryCanceled, want: "ErrQueryCanceled"},
+		{name: "context.Canceled", err: context.Canceled, want: "ErrQueryCanceled"},
+		{name: "context.DeadlineExceeded", err: context.DeadlineExceeded, want: "DeadlineExceeded"},
+		{name: "ErrMaxNumRowsExceeded", err: ErrMaxNumRowsExceeded{MaxNumRows: 1}, want: "ErrMaxNumRowsExceeded"},
+		{name: "ErrNoDatabaseConnection", err: ErrNoDatabaseConnection, want: "ErrNoDatabaseConnection"},
+		{name: "ErrWithinTransaction", err: ErrWithinTransaction, want: "ErrWithinTransaction"},
+		{name: "ErrNotWithinTransaction", err: ErrNotWithinTransaction, want: "ErrNotWithinTransaction"},
+		{name: "ErrNullValueNotAllowed", err: ErrNullValueNotAllowed, want: "ErrNullValueNotAllowed"},
+		{name: "other", err: errors.New("connection refused"), want: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ErrorKind(tt.err))
+		})
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"time"
 )
 
-var _ Connection = &genericTx{}
+var (
+	_ Connection = &genericTx{}
+	_ StmtBinder = &genericTx{}
+)
 
 type genericTx struct {
 	// The parent non-transaction connection is needed
@@ -81,6 +84,10 @@ func (conn *genericTx) Prepare(ctx context.Context, query string) (Stmt, error)
 	return NewStmt(stmt, query, conn.wrapErr), nil
 }
 
+func (conn *genericTx) BindStmt(ctx context.Context, stmt Stmt) (Stmt, error) {
+	return BindStmtToTx(ctx, conn.tx, stmt)
+}
+
 func (conn *genericTx) DefaultIsolationLevel() sql.IsolationLevel {
 	return conn.parent.defaultIsolationLevel
 }
@@ -143,8 +150,9 @@ func (conn *genericTx) Close() error {
 }
 
 // genericTxWithQueryBuilder wraps a [genericTx] with a non-nil [QueryBuilder].
-// Implements [QueryBuilder], [UpsertQueryBuilder], and [ReturningQueryBuilder]
-// via delegation.
+// Implements [QueryBuilder], [UpsertQueryBuilder], [UpsertOptionsQueryBuilder],
+// [ReturningQueryBuilder], [RowsReturningQueryBuilder], [PageQueryBuilder],
+// [SoftDeleteQueryBuilder], and [RelationQueryBuilder] via delegation.
 type genericTxWithQueryBuilder struct {
 	*genericTx
 	QueryBuilder
@@ -166,6 +174,14 @@ func (conn *genericTxWithQueryBuilder) Upsert(formatter QueryFormatter, table st
 	return uqb.Upsert(formatter, table, columns)
 }
 
+func (conn *genericTxWithQueryBuilder) UpsertWithOptions(formatter QueryFormatter, table string, columns []ColumnInfo, options UpsertOptions) (string, error) {
+	uoqb, ok := conn.QueryBuilder.(UpsertOptionsQueryBuilder)
+	if !ok {
+		return "", fmt.Errorf("genericTxWithQueryBuilder: QueryBuilder %T does not implement UpsertOptionsQueryBuilder", conn.QueryBuilder)
+	}
+	return uoqb.UpsertWithOptions(formatter, table, columns, options)
+}
+
 func (conn *genericTxWithQueryBuilder) InsertReturning(formatter QueryFormatter, table string, columns []ColumnInfo, returningColumns string) (string, error) {
 	rqb, ok := conn.QueryBuilder.(ReturningQueryBuilder)
 	if !ok {
@@ -174,6 +190,14 @@ func (conn *genericTxWithQueryBuilder) InsertReturning(formatter QueryFormatter,
 	return rqb.InsertReturning(formatter, table, columns, returningColumns)
 }
 
+func (conn *genericTxWithQueryBuilder) InsertRowsReturning(formatter QueryFormatter, table string, columns []ColumnInfo, numRows int, returningColumns string) (string, error) {
+	rrqb, ok := conn.QueryBuilder.(RowsReturningQueryBuilder)
+	if !ok {
+		return "", fmt.Errorf("genericTxWithQueryBuilder: QueryBuilder %T does not implement RowsReturningQueryBuilder", conn.QueryBuilder)
+	}
+	return rrqb.InsertRowsReturning(formatter, table, columns, numRows, returningColumns)
+}
+
 func (conn *genericTxWithQueryBuilder) UpdateReturning(formatter QueryFormatter, table string, values Values, returningColumns, whereCondition string, whereArgs []any) (string, []any, error) {
 	rqb, ok := conn.QueryBuilder.(ReturningQueryBuilder)
 	if !ok {
@@ -182,6 +206,38 @@ func (conn *genericTxWithQueryBuilder) UpdateReturning(formatter QueryFormatter,
 	return rqb.UpdateReturning(formatter, table, values, returningColumns, whereCondition, whereArgs)
 }
 
+func (conn *genericTxWithQueryBuilder) QueryPage(formatter QueryFormatter, query string, args []any, orderBy []OrderByColumn, after []any, limit int) (string, []any, error) {
+	pqb, ok := conn.QueryBuilder.(PageQueryBuilder)
+	if !ok {
+		return "", nil, fmt.Errorf("genericTxWithQueryBuilder: QueryBuilder %T does not implement PageQueryBuilder", conn.QueryBuilder)
+	}
+	return pqb.QueryPage(formatter, query, args, orderBy, after, limit)
+}
+
+func (conn *genericTxWithQueryBuilder) SoftDelete(formatter QueryFormatter, table string, columns []ColumnInfo, softDeleteColumn string) (string, error) {
+	sqb, ok := conn.QueryBuilder.(SoftDeleteQueryBuilder)
+	if !ok {
+		return "", fmt.Errorf("genericTxWithQueryBuilder: QueryBuilder %T does not implement SoftDeleteQueryBuilder", conn.QueryBuilder)
+	}
+	return sqb.SoftDelete(formatter, table, columns, softDeleteColumn)
+}
+
+func (conn *genericTxWithQueryBuilder) QueryRowWithPKNotDeleted(formatter QueryFormatter, table string, pkColumns []string, softDeleteColumn string) (string, error) {
+	sqb, ok := conn.QueryBuilder.(SoftDeleteQueryBuilder)
+	if !ok {
+		return "", fmt.Errorf("genericTxWithQueryBuilder: QueryBuilder %T does not implement SoftDeleteQueryBuilder", conn.QueryBuilder)
+	}
+	return sqb.QueryRowWithPKNotDeleted(formatter, table, pkColumns, softDeleteColumn)
+}
+
+func (conn *genericTxWithQueryBuilder) QueryRowsWhereIn(formatter QueryFormatter, table, column string, values []any, softDeleteColumn string) (string, []any, error) {
+	rqb, ok := conn.QueryBuilder.(RelationQueryBuilder)
+	if !ok {
+		return "", nil, fmt.Errorf("genericTxWithQueryBuilder: QueryBuilder %T does not implement RelationQueryBuilder", conn.QueryBuilder)
+	}
+	return rqb.QueryRowsWhereIn(formatter, table, column, values, softDeleteColumn)
+}
+
 // Begin overrides [genericTx.Begin] to propagate the [QueryBuilder]
 // to nested transactions.
 func (conn *genericTxWithQueryBuilder) Begin(ctx context.Context, id uint64, opts *sql.TxOptions) (Connection, error) {
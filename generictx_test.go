@@ -2,6 +2,7 @@ package sqldb
 
 import (
 	"database/sql"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -306,6 +307,22 @@ func TestGenericTxWithQueryBuilder_UpdateReturning_NotSupported(t *testing.T) {
 	assert.Contains(t, err.Error(), "does not implement ReturningQueryBuilder")
 }
 
+func TestGenericTxWithQueryBuilder_QueryPage_NotSupported(t *testing.T) {
+	// given - a QueryBuilder that hides the PageQueryBuilder of StdQueryBuilder
+	tx := newTestGenericTx(t, nil, 1)
+	conn := &genericTxWithQueryBuilder{
+		genericTx:    tx,
+		QueryBuilder: struct{ QueryBuilder }{StdQueryBuilder{}},
+	}
+
+	// when
+	_, _, err := conn.QueryPage(tx.parent, "SELECT * FROM test_table", nil, []OrderByColumn{{Column: "id"}}, nil, 10)
+
+	// then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not implement PageQueryBuilder")
+}
+
 func TestGenericTxWithQueryBuilder_DelegatesConfig(t *testing.T) {
 	// given
 	tx := newTestGenericTx(t, nil, 1)
@@ -337,3 +354,10 @@ func TestGenericTxWithQueryBuilder_DelegatesTransaction(t *testing.T) {
 	assert.Equal(t, uint64(99), state.ID)
 	assert.Equal(t, opts, state.Opts)
 }
+
+func TestGenericTx_BindStmt_Unsupported(t *testing.T) {
+	tx := newTestGenericTx(t, nil, 1)
+
+	_, err := tx.BindStmt(t.Context(), &MockStmt{Prepared: "SELECT 1"})
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+}
@@ -94,6 +94,12 @@ func InsertUnique(ctx context.Context, conn Executor, builder UpsertQueryBuilder
 // (e.g., sqldb.TableName `db:"my_table"`).
 // Column names are derived from the `db` struct tags of the struct's fields.
 // Optional QueryOption can be passed to ignore mapped columns.
+//
+// Fields with a `db` tag value having a ",updated" suffix
+// and fields with a ",created" suffix that have the zero value
+// are inserted with the time of the [Clock] from ctx (default time.Now),
+// see [ContextWithClock]. If rowStruct is a pointer, then the fields
+// are set to that time after the row was inserted.
 func InsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rowStruct StructWithTableName, options ...QueryOption) error {
 	if refl == nil {
 		return errors.New("InsertRowStruct: nil StructReflector")
@@ -116,13 +122,15 @@ func InsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 		if ok {
 			vals = make([]any, len(cached.structFieldIndices))
 			for i, fieldIndex := range cached.structFieldIndices {
-				vals[i] = structVal.FieldByIndex(fieldIndex).Interface()
+				vals[i] = structFieldValue(refl, structVal, fieldIndex, &cached.columns[i])
 			}
+			now := cached.timestamps.now(ctx)
+			cached.timestamps.setValues(structVal, vals, now)
 			err = conn.Exec(ctx, cached.query, vals...)
 			if err != nil {
 				return WrapErrorWithQuery(err, cached.query, vals, fmtr)
 			}
-			return nil
+			return cached.timestamps.writeBack(structVal, now)
 		}
 	}
 	var cached queryCache
@@ -135,10 +143,15 @@ func InsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 	if err != nil {
 		return err
 	}
+	cached.timestamps, err = timestampFieldsOf(refl, structType, columns, true)
+	if err != nil {
+		return err
+	}
 	cached.query, err = builder.Insert(fmtr, table, columns)
 	if err != nil {
 		return fmt.Errorf("failed to create INSERT query: %w", err)
 	}
+	cached.columns = columns
 	if useCache {
 		insertRowStructQueryCacheMtx.Lock()
 		if _, ok := insertRowStructQueryCache[structType]; !ok {
@@ -154,11 +167,13 @@ func InsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 		insertRowStructQueryCacheMtx.Unlock()
 	}
 
+	now := cached.timestamps.now(ctx)
+	cached.timestamps.setValues(structVal, vals, now)
 	err = conn.Exec(ctx, cached.query, vals...)
 	if err != nil {
 		return WrapErrorWithQuery(err, cached.query, vals, fmtr)
 	}
-	return nil
+	return cached.timestamps.writeBack(structVal, now)
 }
 
 // InsertRowStructStmt prepares an INSERT statement for the struct type S
@@ -166,6 +181,8 @@ func InsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 // The table name is derived from the `db` struct tag of an embedded sqldb.TableName field
 // (e.g., sqldb.TableName `db:"my_table"`).
 // Column names are derived from the `db` struct tags of the struct's fields.
+// Fields with the created or updated tag option are inserted
+// with the time of the [Clock] from ctx like with [InsertRowStruct].
 // The returned closeStmt function must be called to release the prepared statement.
 func InsertRowStructStmt[S StructWithTableName](ctx context.Context, conn Preparer, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, options ...QueryOption) (insertFunc func(ctx context.Context, rowStruct S) error, closeStmt func() error, err error) {
 	if refl == nil {
@@ -185,6 +202,11 @@ func InsertRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 		return nil, nil, err
 	}
 
+	timestamps, err := timestampFieldsOf(refl, structType, columns, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	query, err := builder.Insert(fmtr, table, columns)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create INSERT query: %w", err)
@@ -204,11 +226,13 @@ func InsertRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 		if err != nil {
 			return err
 		}
+		now := timestamps.now(ctx)
+		timestamps.setValues(strct, vals, now)
 		err = stmt.Exec(ctx, vals...)
 		if err != nil {
 			return WrapErrorWithQuery(err, query, vals, fmtr)
 		}
-		return nil
+		return timestamps.writeBack(strct, now)
 	}
 	return insertFunc, stmt.Close, nil
 }
@@ -220,6 +244,8 @@ func InsertRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 // (e.g., sqldb.TableName `db:"my_table"`).
 // Column names are derived from the `db` struct tags of the struct's fields.
 // Optional QueryOption can be passed to ignore mapped columns.
+// Fields with the created or updated tag option are inserted
+// with the time of the [Clock] from ctx like with [InsertRowStruct].
 //
 // conflictTarget is a comma-separated list of column names identifying
 // the uniqueness target. The name keeps PostgreSQL terminology but each
@@ -250,6 +276,12 @@ func InsertUniqueRowStruct(ctx context.Context, conn Executor, refl StructReflec
 	if err != nil {
 		return false, err
 	}
+	timestamps, err := timestampFieldsOf(refl, structVal.Type(), columns, true)
+	if err != nil {
+		return false, err
+	}
+	now := timestamps.now(ctx)
+	timestamps.setValues(structVal, vals, now)
 
 	if strings.HasPrefix(conflictTarget, "(") && strings.HasSuffix(conflictTarget, ")") {
 		conflictTarget = conflictTarget[1 : len(conflictTarget)-1]
@@ -264,7 +296,10 @@ func InsertUniqueRowStruct(ctx context.Context, conn Executor, refl StructReflec
 	if err != nil {
 		return false, WrapErrorWithQuery(err, query, vals, fmtr)
 	}
-	return n > 0, nil
+	if n == 0 {
+		return false, nil
+	}
+	return true, timestamps.writeBack(structVal, now)
 }
 
 // InsertRowStructs inserts a slice of structs as new rows into the table for the given struct type.
@@ -284,6 +319,9 @@ func InsertUniqueRowStruct(ctx context.Context, conn Executor, refl StructReflec
 // (e.g., sqldb.TableName `db:"my_table"`).
 // Column names are derived from the `db` struct tags of the struct's fields.
 // Optional QueryOption can be passed to ignore mapped columns.
+// Fields with the created or updated tag option are inserted
+// with the same time of the [Clock] from ctx for all rows like with [InsertRowStruct]
+// and the fields of the slice elements are set to that time after all rows were inserted.
 func InsertRowStructs[S StructWithTableName](ctx context.Context, conn Connection, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rowStructs []S, options ...QueryOption) error {
 	if refl == nil {
 		return errors.New("InsertRowStructs: nil StructReflector")
@@ -314,6 +352,11 @@ func InsertRowStructs[S StructWithTableName](ctx context.Context, conn Connectio
 	if numCols == 0 {
 		return fmt.Errorf("InsertRowStructs: no columns mapped for struct %s", structType)
 	}
+	timestamps, err := timestampFieldsOf(refl, structType, columns, true)
+	if err != nil {
+		return err
+	}
+	now := timestamps.now(ctx)
 	rowsPerBatch := fmtr.MaxArgs() / numCols
 	if rowsPerBatch < 1 {
 		return fmt.Errorf("InsertRowStructs: MaxArgs() %d is less than number of columns %d", fmtr.MaxArgs(), numCols)
@@ -336,11 +379,32 @@ func InsertRowStructs[S StructWithTableName](ctx context.Context, conn Connectio
 			if err != nil {
 				return nil, err
 			}
+			timestamps.setValues(structVal, rowVals, now)
 			vals = append(vals, rowVals...)
 		}
 		return vals, nil
 	}
 
+	// writeBackTimestamps sets the timestamp fields
+	// of all structs after all rows were inserted
+	writeBackTimestamps := func() error {
+		if len(timestamps) == 0 {
+			return nil
+		}
+		for i := range rowStructs {
+			// Pointer to the slice element so that
+			// non-pointer structs of the slice can be written to
+			structVal, err := derefStruct(reflect.ValueOf(&rowStructs[i]))
+			if err != nil {
+				return err
+			}
+			if err = timestamps.writeBack(structVal, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// All rows fit in a single batch: no transaction, no prepare
 	if numFullBatches <= 1 && numRemainderRows == 0 {
 		query, err := builder.InsertRows(fmtr, table, columns, numTotalRows)
@@ -355,11 +419,11 @@ func InsertRowStructs[S StructWithTableName](ctx context.Context, conn Connectio
 		if err != nil {
 			return WrapErrorWithQuery(err, query, vals, fmtr)
 		}
-		return nil
+		return writeBackTimestamps()
 	}
 
 	// Multiple batches: wrap in a transaction
-	return Transaction(ctx, conn, nil, func(tx Connection) error {
+	err = Transaction(ctx, conn, nil, func(tx Connection) error {
 		fullBatchQuery, err := builder.InsertRows(fmtr, table, columns, rowsPerBatch)
 		if err != nil {
 			return fmt.Errorf("failed to create INSERT query: %w", err)
@@ -421,4 +485,8 @@ func InsertRowStructs[S StructWithTableName](ctx context.Context, conn Connectio
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	return writeBackTimestamps()
 }
@@ -0,0 +1,288 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// InsertRowsReturner is implemented by connections to databases
+// that have no RETURNING clause for multi-row INSERT queries
+// but can return the values generated by the database for the inserted rows
+// in another way, like MSSQL, MySQL, and Oracle.
+// It is used by [InsertRowStructReturning] and [InsertRowStructsReturning]
+// instead of a RETURNING query built with a [ReturningQueryBuilder].
+type InsertRowsReturner interface {
+	// InsertRowsReturningInto inserts numRows rows with the values for columns
+	// where vals holds the values of all rows one after another,
+	// like the arguments for [QueryBuilder.InsertRows].
+	// The values of returningColumns of every inserted row are scanned
+	// in the order of returningColumns into the destinations
+	// returned by dest for the index of the row.
+	InsertRowsReturningInto(ctx context.Context, table string, columns []ColumnInfo, numRows int, vals []any, returningColumns []ColumnInfo, dest func(rowIndex int) []any) error
+}
+
+// InsertRowStructReturning inserts a new row into the table for the struct
+// that rowStruct points to and writes the values generated by the database
+// for the columns of fields tagged with `default` or `readonly`
+// back into the struct, like serial IDs, DEFAULT now() timestamps,
+// or trigger-populated columns.
+// The columns of fields tagged with `default` or `readonly` are not inserted.
+//
+// The table name is derived from the `db` struct tag of an embedded sqldb.TableName field
+// (e.g., sqldb.TableName `db:"my_table"`).
+// Column names are derived from the `db` struct tags of the struct's fields.
+// Optional QueryOption can be passed to ignore mapped columns.
+//
+// See [InsertRowStructsReturning] for how the values are returned per database.
+func InsertRowStructReturning(ctx context.Context, conn Connection, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rowStruct StructWithTableName, options ...QueryOption) error {
+	if refl == nil {
+		return errors.New("InsertRowStructReturning: nil StructReflector")
+	}
+	v := reflect.ValueOf(rowStruct)
+	if v.Kind() != reflect.Pointer {
+		return fmt.Errorf("InsertRowStructReturning: expected pointer to struct, but got %T", rowStruct)
+	}
+	structVal, err := derefStruct(v)
+	if err != nil {
+		return err
+	}
+	return insertRowStructsReturning(ctx, conn, refl, builder, fmtr, structVal.Type(), []reflect.Value{structVal}, options)
+}
+
+// InsertRowStructsReturning inserts a slice of structs as new rows into the table
+// and writes the values generated by the database for the columns
+// of fields tagged with `default` or `readonly` back into the structs
+// of the slice, like serial IDs, DEFAULT now() timestamps,
+// or trigger-populated columns.
+// The columns of fields tagged with `default` or `readonly` are not inserted.
+//
+// Rows are batched into multi-row INSERT statements respecting
+// the driver's MaxArgs() limit like [InsertRowStructs],
+// and multiple batches are executed within a transaction.
+// The generated values are written into the struct of the row
+// they were generated for:
+//   - Connections implementing [InsertRowsReturner] return them
+//     in a database specific way (e.g. mssqlconn, mysqlconn, oraconn),
+//     also if wrapped with interceptors whose InsertRowsReturningInto
+//     hooks are called.
+//   - Else the builder must implement [ReturningQueryBuilder]
+//     for a RETURNING clause (e.g. pqconn, sqliteconn).
+//     Builders that also implement [RowsReturningQueryBuilder]
+//     insert multiple rows per query that returns the rows
+//     in the order of the inserted values (e.g. pqconn),
+//     else one row per query is inserted (e.g. sqliteconn).
+//
+// The table name is derived from the `db` struct tag of an embedded sqldb.TableName field
+// (e.g., sqldb.TableName `db:"my_table"`).
+// Column names are derived from the `db` struct tags of the struct's fields.
+// Optional QueryOption can be passed to ignore mapped columns.
+// Fields with the created or updated tag option are inserted
+// with the time of the [Clock] from ctx like with [InsertRowStructs].
+func InsertRowStructsReturning[S StructWithTableName](ctx context.Context, conn Connection, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rowStructs []S, options ...QueryOption) error {
+	if refl == nil {
+		return errors.New("InsertRowStructsReturning: nil StructReflector")
+	}
+	if len(rowStructs) == 0 {
+		return nil
+	}
+	structType := reflect.TypeFor[S]()
+	for structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	structVals := make([]reflect.Value, len(rowStructs))
+	for i := range rowStructs {
+		// Pointer to the slice element so that non-pointer
+		// structs of the slice can be written to
+		structVal, err := derefStruct(reflect.ValueOf(&rowStructs[i]))
+		if err != nil {
+			return fmt.Errorf("InsertRowStructsReturning row %d: %w", i, err)
+		}
+		structVals[i] = structVal
+	}
+	return insertRowStructsReturning(ctx, conn, refl, builder, fmtr, structType, structVals, options)
+}
+
+func insertRowStructsReturning(ctx context.Context, conn Connection, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, structType reflect.Type, structVals []reflect.Value, options []QueryOption) error {
+	table, err := refl.TableNameForStruct(structType)
+	if err != nil {
+		return err
+	}
+	allColumns, err := refl.ReflectStructColumns(structType, options...)
+	if err != nil {
+		return err
+	}
+	var (
+		columns              []ColumnInfo
+		returningColumns     []ColumnInfo
+		returningColumnNames []string
+	)
+	for _, col := range allColumns {
+		if col.HasDefault || col.ReadOnly {
+			returningColumns = append(returningColumns, col)
+			returningColumnNames = append(returningColumnNames, col.Name)
+		} else {
+			columns = append(columns, col)
+		}
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("InsertRowStructsReturning: no columns to insert for struct %s", structType)
+	}
+	if len(returningColumns) == 0 {
+		return fmt.Errorf("InsertRowStructsReturning: no columns tagged with default or readonly to return for struct %s", structType)
+	}
+
+	_, isReturner := insertRowsReturnerOf(conn)
+	returningBuilder, isReturningBuilder := builder.(ReturningQueryBuilder)
+	rowsReturningBuilder, isRowsReturningBuilder := builder.(RowsReturningQueryBuilder)
+	if !isReturner && !isReturningBuilder {
+		return fmt.Errorf("InsertRowStructsReturning: connection does not implement InsertRowsReturner and QueryBuilder %T does not implement ReturningQueryBuilder", builder)
+	}
+
+	timestamps, err := timestampFieldsOf(refl, structType, columns, true)
+	if err != nil {
+		return err
+	}
+	now := timestamps.now(ctx)
+
+	options = append(options, IgnoreHasDefault, IgnoreReadOnly)
+	numCols := len(columns)
+	rowsPerBatch := fmtr.MaxArgs() / numCols
+	if rowsPerBatch < 1 {
+		return fmt.Errorf("InsertRowStructsReturning: MaxArgs() %d is less than number of columns %d", fmtr.MaxArgs(), numCols)
+	}
+	if !isReturner && !isRowsReturningBuilder {
+		rowsPerBatch = 1
+	}
+
+	insertReturningQuery := func(numRows int) (string, error) {
+		returning := formatReturningColumns(fmtr, returningColumnNames)
+		if isRowsReturningBuilder {
+			return rowsReturningBuilder.InsertRowsReturning(fmtr, table, columns, numRows, returning)
+		}
+		return returningBuilder.InsertReturning(fmtr, table, columns, returning)
+	}
+
+	insertBatch := func(conn Connection, batch []reflect.Value) error {
+		vals := make([]any, 0, len(batch)*numCols)
+		for _, structVal := range batch {
+			rowVals, err := refl.ReflectStructValues(structVal, options...)
+			if err != nil {
+				return err
+			}
+			timestamps.setValues(structVal, rowVals, now)
+			vals = append(vals, rowVals...)
+		}
+		var destErr error
+		dest := func(rowIndex int) []any {
+			scanables, err := refl.ScanableStructFieldsForColumns(batch[rowIndex], returningColumnNames)
+			if err != nil && destErr == nil {
+				destErr = err
+			}
+			if err != nil {
+				// Discard values to report destErr after the insert
+				scanables = make([]any, len(returningColumns))
+				for i := range scanables {
+					scanables[i] = new(any)
+				}
+			}
+			return scanables
+		}
+		if insertRowsReturningInto, ok := insertRowsReturnerOf(conn); ok {
+			err := insertRowsReturningInto(ctx, table, columns, len(batch), vals, returningColumns, dest)
+			return errors.Join(err, destErr)
+		}
+		query, err := insertReturningQuery(len(batch))
+		if err != nil {
+			return fmt.Errorf("failed to create INSERT RETURNING query: %w", err)
+		}
+		err = scanReturnedRows(conn.Query(ctx, query, vals...), len(batch), dest)
+		if err != nil {
+			return WrapErrorWithQuery(errors.Join(err, destErr), query, vals, fmtr)
+		}
+		return destErr
+	}
+
+	if len(structVals) <= rowsPerBatch {
+		err = insertBatch(conn, structVals)
+	} else {
+		err = Transaction(ctx, conn, nil, func(tx Connection) error {
+			for start := 0; start < len(structVals); start += rowsPerBatch {
+				end := min(start+rowsPerBatch, len(structVals))
+				if err := insertBatch(tx, structVals[start:end]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+	for _, structVal := range structVals {
+		if err := timestamps.writeBack(structVal, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertRowsReturnerOf returns the InsertRowsReturningInto method
+// of the [InsertRowsReturner] implementation of conn or of a connection
+// wrapped by conn chained with the InsertRowsReturningInto hooks
+// of the interceptors in between.
+func insertRowsReturnerOf(conn Connection) (InsertRowsReturningIntoFunc, bool) {
+	return unwrapConnWithHooks(conn,
+		func(conn Connection) (InsertRowsReturningIntoFunc, bool) {
+			returner, ok := conn.(InsertRowsReturner)
+			if !ok {
+				return nil, false
+			}
+			return returner.InsertRowsReturningInto, true
+		},
+		(*interceptedConn).chainInsertRowsReturningInto,
+	)
+}
+
+// scanReturnedRows scans numRows rows into the destinations
+// returned by dest for the index of the row and closes the rows.
+func scanReturnedRows(rows Rows, numRows int, dest func(rowIndex int) []any) (err error) {
+	defer func() {
+		err = errors.Join(err, rows.Close())
+	}()
+	i := 0
+	for ; rows.Next(); i++ {
+		if i == numRows {
+			return fmt.Errorf("more than %d returned rows", numRows)
+		}
+		if err := rows.Scan(dest(i)...); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if i != numRows {
+		return fmt.Errorf("%d returned rows for %d inserted rows", i, numRows)
+	}
+	return nil
+}
+
+// formatReturningColumns formats the columns as comma separated list.
+// Columns that can't be formatted are passed through
+// because they are from struct tags written by the developer.
+func formatReturningColumns(fmtr QueryFormatter, columns []string) string {
+	var b strings.Builder
+	for i, col := range columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if formatted, err := fmtr.FormatColumnName(col); err == nil {
+			col = formatted
+		}
+		b.WriteString(col)
+	}
+	return b.String()
+}
@@ -0,0 +1,283 @@
+package sqldb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type returningTestStruct struct {
+	TableName `db:"returning_table"`
+
+	ID      int64  `db:"id,primarykey,default"`
+	Name    string `db:"name"`
+	Created string `db:"created,readonly"`
+}
+
+// insertRowsReturnerConn is a Connection implementing InsertRowsReturner
+// that returns the generated values of the inserted rows in reverse order.
+type insertRowsReturnerConn struct {
+	Connection
+
+	table            string
+	columns          []ColumnInfo
+	vals             []any
+	returningColumns []ColumnInfo
+}
+
+func (c *insertRowsReturnerConn) InsertRowsReturningInto(ctx context.Context, table string, columns []ColumnInfo, numRows int, vals []any, returningColumns []ColumnInfo, dest func(rowIndex int) []any) error {
+	c.table = table
+	c.columns = columns
+	c.vals = vals
+	c.returningColumns = returningColumns
+	for rowIndex := numRows - 1; rowIndex >= 0; rowIndex-- {
+		d := dest(rowIndex)
+		*d[0].(*int64) = int64(100 + rowIndex)
+		*d[1].(*string) = "generated"
+	}
+	return nil
+}
+
+// rowsReturningTestBuilder implements RowsReturningQueryBuilder
+// by appending a RETURNING clause to a multi-row INSERT.
+type rowsReturningTestBuilder struct {
+	StdReturningQueryBuilder
+}
+
+func (b rowsReturningTestBuilder) InsertRowsReturning(formatter QueryFormatter, table string, columns []ColumnInfo, numRows int, returningColumns string) (string, error) {
+	query, err := b.InsertRows(formatter, table, columns, numRows)
+	if err != nil {
+		return "", err
+	}
+	return query + " RETURNING " + returningColumns, nil
+}
+
+// maxArgsFormatter limits the number of query arguments of a QueryFormatter.
+type maxArgsFormatter struct {
+	QueryFormatter
+
+	maxArgs int
+}
+
+func (f maxArgsFormatter) MaxArgs() int { return f.maxArgs }
+
+func TestInsertRowStructsReturning(t *testing.T) {
+	t.Run("RETURNING", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("id", "created").
+				WithRow(int64(1), "2026-01-01").
+				WithRow(int64(2), "2026-01-02")
+		}
+		items := []returningTestStruct{{Name: "Alice"}, {Name: "Bob"}}
+
+		// when
+		err := InsertRowStructsReturning(t.Context(), conn, refl, rowsReturningTestBuilder{}, fmtr, items)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []returningTestStruct{
+			{ID: 1, Name: "Alice", Created: "2026-01-01"},
+			{ID: 2, Name: "Bob", Created: "2026-01-02"},
+		}, items)
+		require.Len(t, conn.Recordings.Queries, 1)
+		assert.Equal(t, "INSERT INTO returning_table(name) VALUES($1),($2) RETURNING id,created", conn.Recordings.Queries[0].Query)
+		assert.Equal(t, []any{"Alice", "Bob"}, conn.Recordings.Queries[0].Args)
+	})
+
+	t.Run("pointers", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("id", "created").WithRow(int64(1), "2026-01-01")
+		}
+		items := []*returningTestStruct{{Name: "Alice"}}
+
+		// when
+		err := InsertRowStructsReturning(t.Context(), conn, refl, builder, fmtr, items)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, &returningTestStruct{ID: 1, Name: "Alice", Created: "2026-01-01"}, items[0])
+	})
+
+	t.Run("multiple batches in transaction", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn, refl, _, fmtr := newTestInterfaces()
+		conn = conn.WithQueryLog(log)
+		nextID := int64(0)
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			rows := NewMockRows("id", "created")
+			for range args {
+				nextID++
+				rows = rows.WithRow(nextID, "now")
+			}
+			return rows
+		}
+		items := []returningTestStruct{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+		// when
+		err := InsertRowStructsReturning(t.Context(), conn, refl, rowsReturningTestBuilder{}, maxArgsFormatter{fmtr, 2}, items)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []returningTestStruct{
+			{ID: 1, Name: "a", Created: "now"},
+			{ID: 2, Name: "b", Created: "now"},
+			{ID: 3, Name: "c", Created: "now"},
+		}, items)
+		assert.Equal(t, "BEGIN;\n"+
+			"INSERT INTO returning_table(name) VALUES('a'),('b') RETURNING id,created;\n"+
+			"INSERT INTO returning_table(name) VALUES('c') RETURNING id,created;\n"+
+			"COMMIT;\n", log.String())
+	})
+
+	t.Run("InsertRowsReturner", func(t *testing.T) {
+		// given
+		mock, refl, builder, fmtr := newTestInterfaces()
+		conn := &insertRowsReturnerConn{Connection: mock}
+		items := []returningTestStruct{{Name: "Alice"}, {Name: "Bob"}}
+
+		// when
+		err := InsertRowStructsReturning(t.Context(), conn, refl, builder, fmtr, items)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []returningTestStruct{
+			{ID: 100, Name: "Alice", Created: "generated"},
+			{ID: 101, Name: "Bob", Created: "generated"},
+		}, items)
+		assert.Equal(t, "returning_table", conn.table)
+		assert.Equal(t, []any{"Alice", "Bob"}, conn.vals)
+		require.Len(t, conn.columns, 1)
+		assert.Equal(t, "name", conn.columns[0].Name)
+		require.Len(t, conn.returningColumns, 2)
+		assert.Equal(t, "id", conn.returningColumns[0].Name)
+		assert.Equal(t, "created", conn.returningColumns[1].Name)
+		assert.Empty(t, mock.Recordings.Queries)
+	})
+
+	t.Run("InsertRowsReturningInto hook", func(t *testing.T) {
+		// given
+		var hooked []string
+		mock, refl, builder, fmtr := newTestInterfaces()
+		base := &insertRowsReturnerConn{Connection: mock}
+		conn := WrapConnection(base, Interceptor{
+			InsertRowsReturningInto: func(ctx context.Context, conn Connection, table string, columns []ColumnInfo, numRows int, vals []any, returningColumns []ColumnInfo, dest func(rowIndex int) []any, next InsertRowsReturningIntoFunc) error {
+				hooked = append(hooked, table)
+				return next(ctx, table, columns, numRows, vals, returningColumns, dest)
+			},
+		})
+		items := []returningTestStruct{{Name: "Alice"}}
+
+		// when
+		err := InsertRowStructsReturning(t.Context(), conn, refl, builder, fmtr, items)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"returning_table"}, hooked)
+		assert.Equal(t, []any{"Alice"}, base.vals)
+		assert.Equal(t, int64(100), items[0].ID)
+		assert.Empty(t, mock.Recordings.Queries)
+	})
+
+	t.Run("without RowsReturningQueryBuilder", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn, refl, _, fmtr := newTestInterfaces()
+		conn = conn.WithQueryLog(log)
+		nextID := int64(0)
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			nextID++
+			return NewMockRows("id", "created").WithRow(nextID, "now")
+		}
+		items := []returningTestStruct{{Name: "a"}, {Name: "b"}}
+
+		// when
+		err := InsertRowStructsReturning(t.Context(), conn, refl, StdReturningQueryBuilder{}, fmtr, items)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []returningTestStruct{
+			{ID: 1, Name: "a", Created: "now"},
+			{ID: 2, Name: "b", Created: "now"},
+		}, items)
+		assert.Equal(t, "BEGIN;\n"+
+			"INSERT INTO returning_table(name) VALUES('a') RETURNING id,created;\n"+
+			"INSERT INTO returning_table(name) VALUES('b') RETURNING id,created;\n"+
+			"COMMIT;\n", log.String())
+	})
+
+	t.Run("fewer returned rows", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("id", "created").WithRow(int64(1), "2026-01-01")
+		}
+		items := []returningTestStruct{{Name: "Alice"}, {Name: "Bob"}}
+
+		// when
+		err := InsertRowStructsReturning(t.Context(), conn, refl, rowsReturningTestBuilder{}, fmtr, items)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "1 returned rows for 2 inserted rows")
+	})
+
+	t.Run("no returning columns", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := InsertRowStructsReturning(t.Context(), conn, refl, builder, fmtr, []reflectTestStruct{{ID: 1}})
+		require.Error(t, err)
+	})
+
+	t.Run("no ReturningQueryBuilder", func(t *testing.T) {
+		conn, refl, _, fmtr := newTestInterfaces()
+		err := InsertRowStructsReturning(t.Context(), conn, refl, StdQueryBuilder{}, fmtr, []returningTestStruct{{Name: "Alice"}})
+		require.Error(t, err)
+		assert.Empty(t, conn.Recordings.Queries)
+	})
+
+	t.Run("empty slice", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := InsertRowStructsReturning[returningTestStruct](t.Context(), conn, refl, builder, fmtr, nil)
+		require.NoError(t, err)
+		assert.Empty(t, conn.Recordings.Queries)
+	})
+}
+
+func TestInsertRowStructReturning(t *testing.T) {
+	t.Run("pointer", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("id", "created").WithRow(int64(7), "2026-01-01")
+		}
+		item := &returningTestStruct{Name: "Alice"}
+
+		// when
+		err := InsertRowStructReturning(t.Context(), conn, refl, builder, fmtr, item)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, &returningTestStruct{ID: 7, Name: "Alice", Created: "2026-01-01"}, item)
+		require.Len(t, conn.Recordings.Queries, 1)
+		assert.Equal(t, "INSERT INTO returning_table(name) VALUES($1) RETURNING id,created", conn.Recordings.Queries[0].Query)
+	})
+
+	t.Run("non-pointer", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := InsertRowStructReturning(t.Context(), conn, refl, builder, fmtr, returningTestStruct{Name: "Alice"})
+		require.Error(t, err)
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := InsertRowStructReturning(t.Context(), conn, refl, builder, fmtr, (*returningTestStruct)(nil))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,456 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+)
+
+type (
+	// ExecFunc is the signature of the next function passed to [Interceptor.Exec].
+	ExecFunc func(ctx context.Context, query string, args []any) error
+
+	// ExecRowsAffectedFunc is the signature of the next function passed to
+	// [Interceptor.ExecRowsAffected].
+	ExecRowsAffectedFunc func(ctx context.Context, query string, args []any) (int64, error)
+
+	// QueryFunc is the signature of the next function passed to [Interceptor.Query].
+	QueryFunc func(ctx context.Context, query string, args []any) Rows
+
+	// PrepareFunc is the signature of the next function passed to [Interceptor.Prepare].
+	PrepareFunc func(ctx context.Context, query string) (Stmt, error)
+
+	// BeginFunc is the signature of the next function passed to [Interceptor.Begin].
+	BeginFunc func(ctx context.Context, id uint64, opts *sql.TxOptions) (Connection, error)
+
+	// CopyFromFunc is the signature of the next function passed to [Interceptor.CopyFrom].
+	CopyFromFunc func(ctx context.Context, table string, columns []string, rows CopyRowSource) (int64, error)
+
+	// ExecBatchFunc is the signature of the next function passed to [Interceptor.ExecBatch].
+	ExecBatchFunc func(ctx context.Context, statements []BatchStatement) []BatchResult
+
+	// InsertRowsReturningIntoFunc is the signature of the next function passed to [Interceptor.InsertRowsReturningInto].
+	InsertRowsReturningIntoFunc func(ctx context.Context, table string, columns []ColumnInfo, numRows int, vals []any, returningColumns []ColumnInfo, dest func(rowIndex int) []any) error
+)
+
+// Interceptor holds optional hook functions that are called around
+// the corresponding methods of a [Connection] wrapped with [WrapConnection].
+//
+// Every hook receives the wrapped Connection the method was called on,
+// so it can inspect Config, Transaction, or use it as QueryFormatter,
+// and a next function that continues the call with the next Interceptor
+// in the chain and finally the underlying Connection.
+// Code before calling next runs before the operation and code after
+// calling next runs after it. A hook may change the context, query, or args
+// passed to next, or return without calling next to short-circuit the call.
+//
+// A nil hook passes the call through unchanged.
+//
+// Executions of a [Stmt] returned by Prepare also run through the
+// Exec, ExecRowsAffected, and Query hooks with the prepared query.
+// A changed query passed to next is ignored for prepared statements
+// because the statement was already prepared with its query.
+type Interceptor struct {
+	// Exec is called around Connection.Exec and Stmt.Exec.
+	Exec func(ctx context.Context, conn Connection, query string, args []any, next ExecFunc) error
+
+	// ExecRowsAffected is called around Connection.ExecRowsAffected
+	// and Stmt.ExecRowsAffected.
+	ExecRowsAffected func(ctx context.Context, conn Connection, query string, args []any, next ExecRowsAffectedFunc) (int64, error)
+
+	// Query is called around Connection.Query and Stmt.Query.
+	// Note that the query is still running when next returns the Rows,
+	// wrap the returned Rows to hook into their iteration or Close.
+	Query func(ctx context.Context, conn Connection, query string, args []any, next QueryFunc) Rows
+
+	// Prepare is called around Connection.Prepare.
+	Prepare func(ctx context.Context, conn Connection, query string, next PrepareFunc) (Stmt, error)
+
+	// Begin is called around Connection.Begin.
+	// The transaction returned by the chain gets wrapped
+	// with the same interceptors after the hook returns.
+	Begin func(ctx context.Context, conn Connection, id uint64, opts *sql.TxOptions, next BeginFunc) (Connection, error)
+
+	// Commit is called around Connection.Commit.
+	Commit func(conn Connection, next func() error) error
+
+	// Rollback is called around Connection.Rollback.
+	Rollback func(conn Connection, next func() error) error
+
+	// Close is called around Connection.Close.
+	// Note that closing a transaction rolls it back
+	// without calling the Rollback hook.
+	Close func(conn Connection, next func() error) error
+
+	// CopyFrom is called around the [BulkCopier] implementation
+	// of the wrapped connection when it is used by the [CopyFrom] function.
+	// Connections without BulkCopier use INSERT statements
+	// that run through the Exec hook instead.
+	CopyFrom func(ctx context.Context, conn Connection, table string, columns []string, rows CopyRowSource, next CopyFromFunc) (int64, error)
+
+	// ExecBatch is called around the [BatchExecer] implementation
+	// of the wrapped connection when it is used by the [ExecBatch] function.
+	// Connections without BatchExecer execute the statements one after another
+	// through the ExecRowsAffected and Query hooks instead.
+	ExecBatch func(ctx context.Context, conn Connection, statements []BatchStatement, next ExecBatchFunc) []BatchResult
+
+	// InsertRowsReturningInto is called around the [InsertRowsReturner]
+	// implementation of the wrapped connection when it is used by
+	// [InsertRowStructReturning] and [InsertRowStructsReturning].
+	// Connections without InsertRowsReturner use a RETURNING query
+	// that runs through the Query hook instead.
+	InsertRowsReturningInto func(ctx context.Context, conn Connection, table string, columns []ColumnInfo, numRows int, vals []any, returningColumns []ColumnInfo, dest func(rowIndex int) []any, next InsertRowsReturningIntoFunc) error
+}
+
+// WrapConnection returns a [Connection] that calls the hooks of the passed
+// interceptors around the methods of conn. The first interceptor is the
+// outermost one, meaning its hooks run first before and last after
+// the operation.
+//
+// The interceptors propagate automatically: transactions started with Begin,
+// connections pinned with [ConnPinner.Conn], and statements returned by
+// Prepare are wrapped with the same interceptors.
+//
+// The returned Connection implements [PinnedConnection] if conn does.
+// Other optional interfaces of conn like [ListenerConnection], [ConnPinner],
+// [QueryBuilder], or [Information] are not implemented by the returned
+// Connection, use [ConnectionAs] to find them through the wrapper.
+//
+// If no interceptors are passed, conn is returned unchanged.
+func WrapConnection(conn Connection, interceptors ...Interceptor) Connection {
+	if len(interceptors) == 0 {
+		return conn
+	}
+	return wrapConnection(conn, interceptors)
+}
+
+// interceptedConn is the Connection returned by WrapConnection.
+// Optional interfaces of the wrapped connection are found
+// with ConnectionAs or with unwrapConnWithHooks for
+// interfaces that have interceptor hooks.
+type interceptedConn struct {
+	Connection
+
+	interceptors []Interceptor
+
+	// self is the Connection returned by wrapConnection,
+	// either this interceptedConn or an interceptedPinnedConn embedding it.
+	// It is passed as conn to the hooks.
+	self Connection
+
+	exec             ExecFunc
+	execRowsAffected ExecRowsAffectedFunc
+	query            QueryFunc
+	prepare          PrepareFunc
+	begin            BeginFunc
+	commit           func() error
+	rollback         func() error
+	close            func() error
+}
+
+// interceptedPinnedConn is returned by WrapConnection
+// for connections that implement PinnedConnection.
+type interceptedPinnedConn struct {
+	*interceptedConn
+}
+
+func (interceptedPinnedConn) IsPinnedConnection() bool { return true }
+
+var _ PinnedConnection = interceptedPinnedConn{}
+
+func wrapConnection(conn Connection, interceptors []Interceptor) Connection {
+	c := &interceptedConn{
+		Connection:   conn,
+		interceptors: interceptors,
+	}
+	c.self = c
+	if _, isPinned := conn.(PinnedConnection); isPinned {
+		c.self = interceptedPinnedConn{c}
+	}
+
+	c.exec = chainExec(c.self, interceptors, func(ctx context.Context, query string, args []any) error {
+		return conn.Exec(ctx, query, args...)
+	})
+	c.execRowsAffected = chainExecRowsAffected(c.self, interceptors, func(ctx context.Context, query string, args []any) (int64, error) {
+		return conn.ExecRowsAffected(ctx, query, args...)
+	})
+	c.query = chainQuery(c.self, interceptors, func(ctx context.Context, query string, args []any) Rows {
+		return conn.Query(ctx, query, args...)
+	})
+	c.prepare = conn.Prepare
+	c.begin = conn.Begin
+	c.commit = conn.Commit
+	c.rollback = conn.Rollback
+	c.close = conn.Close
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if hook := interceptors[i].Prepare; hook != nil {
+			next := c.prepare
+			c.prepare = func(ctx context.Context, query string) (Stmt, error) {
+				return hook(ctx, c.self, query, next)
+			}
+		}
+		if hook := interceptors[i].Begin; hook != nil {
+			next := c.begin
+			c.begin = func(ctx context.Context, id uint64, opts *sql.TxOptions) (Connection, error) {
+				return hook(ctx, c.self, id, opts, next)
+			}
+		}
+		if hook := interceptors[i].Commit; hook != nil {
+			next := c.commit
+			c.commit = func() error { return hook(c.self, next) }
+		}
+		if hook := interceptors[i].Rollback; hook != nil {
+			next := c.rollback
+			c.rollback = func() error { return hook(c.self, next) }
+		}
+		if hook := interceptors[i].Close; hook != nil {
+			next := c.close
+			c.close = func() error { return hook(c.self, next) }
+		}
+	}
+	return c.self
+}
+
+func chainExec(conn Connection, interceptors []Interceptor, final ExecFunc) ExecFunc {
+	next := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if hook := interceptors[i].Exec; hook != nil {
+			n := next
+			next = func(ctx context.Context, query string, args []any) error {
+				return hook(ctx, conn, query, args, n)
+			}
+		}
+	}
+	return next
+}
+
+func chainExecRowsAffected(conn Connection, interceptors []Interceptor, final ExecRowsAffectedFunc) ExecRowsAffectedFunc {
+	next := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if hook := interceptors[i].ExecRowsAffected; hook != nil {
+			n := next
+			next = func(ctx context.Context, query string, args []any) (int64, error) {
+				return hook(ctx, conn, query, args, n)
+			}
+		}
+	}
+	return next
+}
+
+func chainQuery(conn Connection, interceptors []Interceptor, final QueryFunc) QueryFunc {
+	next := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if hook := interceptors[i].Query; hook != nil {
+			n := next
+			next = func(ctx context.Context, query string, args []any) Rows {
+				return hook(ctx, conn, query, args, n)
+			}
+		}
+	}
+	return next
+}
+
+// Unwrap returns the wrapped Connection.
+func (c *interceptedConn) Unwrap() Connection {
+	return c.Connection
+}
+
+// ConnectionAs returns conn as T if conn implements T,
+// or else the first connection wrapped by conn that implements T,
+// following the Unwrap() Connection methods of wrappers
+// like the connections returned by [WrapConnection].
+// Use it to find optional interfaces like [ListenerConnection],
+// [QueryBuilder], or [Information] of wrapped connections:
+//
+//	if listener, ok := sqldb.ConnectionAs[sqldb.ListenerConnection](conn); ok {
+//		err = listener.ListenOnChannel(channel, onNotify, nil)
+//	}
+//
+// A [ConnPinner] found through connections returned by [WrapConnection]
+// wraps its pinned connections with the same interceptors.
+// Interfaces with interceptor hooks like [BulkCopier] are returned
+// without their hooks, use the functions that call the hooks instead,
+// like [CopyFrom].
+func ConnectionAs[T any](conn Connection) (T, bool) {
+	if t, ok := conn.(T); ok {
+		return t, true
+	}
+	wrapper, ok := conn.(interface{ Unwrap() Connection })
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	t, ok := ConnectionAs[T](wrapper.Unwrap())
+	if !ok {
+		return t, false
+	}
+	if c, ok := conn.(interface{ intercepted() *interceptedConn }); ok {
+		if pinner, ok := any(&t).(*ConnPinner); ok {
+			*pinner = interceptedPinner{c.intercepted(), *pinner}
+		}
+	}
+	return t, true
+}
+
+// unwrapConnWithHooks returns the function of an optional interface
+// implemented by conn or the first connection wrapped by conn
+// like ConnectionAs, chained with the hooks of every
+// intercepted connection in between by chain,
+// so that the call does not bypass the interceptors.
+func unwrapConnWithHooks[F any](conn Connection, impl func(Connection) (F, bool), chain func(c *interceptedConn, next F) F) (F, bool) {
+	if f, ok := impl(conn); ok {
+		return f, true
+	}
+	c, ok := conn.(interface{ intercepted() *interceptedConn })
+	if !ok {
+		var zero F
+		return zero, false
+	}
+	next, ok := unwrapConnWithHooks(c.intercepted().Connection, impl, chain)
+	if !ok {
+		return next, false
+	}
+	return chain(c.intercepted(), next), true
+}
+
+func (c *interceptedConn) intercepted() *interceptedConn {
+	return c
+}
+
+// chainCopyFrom returns next chained with the CopyFrom hooks of c.
+func (c *interceptedConn) chainCopyFrom(next CopyFromFunc) CopyFromFunc {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		if hook := c.interceptors[i].CopyFrom; hook != nil {
+			n := next
+			next = func(ctx context.Context, table string, columns []string, rows CopyRowSource) (int64, error) {
+				return hook(ctx, c.self, table, columns, rows, n)
+			}
+		}
+	}
+	return next
+}
+
+// chainExecBatch returns next chained with the ExecBatch hooks of c.
+func (c *interceptedConn) chainExecBatch(next ExecBatchFunc) ExecBatchFunc {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		if hook := c.interceptors[i].ExecBatch; hook != nil {
+			n := next
+			next = func(ctx context.Context, statements []BatchStatement) []BatchResult {
+				return hook(ctx, c.self, statements, n)
+			}
+		}
+	}
+	return next
+}
+
+// chainInsertRowsReturningInto returns next chained with the InsertRowsReturningInto hooks of c.
+func (c *interceptedConn) chainInsertRowsReturningInto(next InsertRowsReturningIntoFunc) InsertRowsReturningIntoFunc {
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		if hook := c.interceptors[i].InsertRowsReturningInto; hook != nil {
+			n := next
+			next = func(ctx context.Context, table string, columns []ColumnInfo, numRows int, vals []any, returningColumns []ColumnInfo, dest func(rowIndex int) []any) error {
+				return hook(ctx, c.self, table, columns, numRows, vals, returningColumns, dest, n)
+			}
+		}
+	}
+	return next
+}
+
+func (c *interceptedConn) Exec(ctx context.Context, query string, args ...any) error {
+	return c.exec(ctx, query, args)
+}
+
+func (c *interceptedConn) ExecRowsAffected(ctx context.Context, query string, args ...any) (int64, error) {
+	return c.execRowsAffected(ctx, query, args)
+}
+
+func (c *interceptedConn) Query(ctx context.Context, query string, args ...any) Rows {
+	return c.query(ctx, query, args)
+}
+
+func (c *interceptedConn) Prepare(ctx context.Context, query string) (Stmt, error) {
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s := &interceptedStmt{Stmt: stmt}
+	s.exec = chainExec(c.self, c.interceptors, func(ctx context.Context, _ string, args []any) error {
+		return stmt.Exec(ctx, args...)
+	})
+	s.execRowsAffected = chainExecRowsAffected(c.self, c.interceptors, func(ctx context.Context, _ string, args []any) (int64, error) {
+		return stmt.ExecRowsAffected(ctx, args...)
+	})
+	s.query = chainQuery(c.self, c.interceptors, func(ctx context.Context, _ string, args []any) Rows {
+		return stmt.Query(ctx, args...)
+	})
+	return s, nil
+}
+
+func (c *interceptedConn) Begin(ctx context.Context, id uint64, opts *sql.TxOptions) (Connection, error) {
+	tx, err := c.begin(ctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+	return wrapConnection(tx, c.interceptors), nil
+}
+
+func (c *interceptedConn) Commit() error {
+	return c.commit()
+}
+
+func (c *interceptedConn) Rollback() error {
+	return c.rollback()
+}
+
+func (c *interceptedConn) Close() error {
+	return c.close()
+}
+
+// interceptedStmt runs the executions of a prepared statement
+// through the Exec, ExecRowsAffected, and Query hooks.
+type interceptedStmt struct {
+	Stmt
+
+	exec             ExecFunc
+	execRowsAffected ExecRowsAffectedFunc
+	query            QueryFunc
+}
+
+func (s *interceptedStmt) Exec(ctx context.Context, args ...any) error {
+	return s.exec(contextWithPreparedStmtExecution(ctx), s.PreparedQuery(), args)
+}
+
+func (s *interceptedStmt) ExecRowsAffected(ctx context.Context, args ...any) (int64, error) {
+	return s.execRowsAffected(contextWithPreparedStmtExecution(ctx), s.PreparedQuery(), args)
+}
+
+func (s *interceptedStmt) Query(ctx context.Context, args ...any) Rows {
+	return s.query(contextWithPreparedStmtExecution(ctx), s.PreparedQuery(), args)
+}
+
+type preparedStmtExecutionCtxKey struct{}
+
+// contextWithPreparedStmtExecution marks ctx as passed to the hooks
+// for the execution of a prepared statement, so that hooks like
+// the one of StmtCache can tell them apart from Connection calls.
+func contextWithPreparedStmtExecution(ctx context.Context) context.Context {
+	return context.WithValue(ctx, preparedStmtExecutionCtxKey{}, struct{}{})
+}
+
+func isPreparedStmtExecution(ctx context.Context) bool {
+	return ctx.Value(preparedStmtExecutionCtxKey{}) != nil
+}
+
+// interceptedPinner implements ConnPinner by wrapping the pinned
+// connection of the wrapped connection with the same interceptors.
+type interceptedPinner struct {
+	conn   *interceptedConn
+	pinner ConnPinner
+}
+
+func (p interceptedPinner) Conn(ctx context.Context) (PinnedConnection, error) {
+	pinned, err := p.pinner.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return wrapConnection(pinned, p.conn.interceptors).(PinnedConnection), nil
+}
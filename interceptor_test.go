@@ -0,0 +1,386 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingInterceptor returns an Interceptor that appends
+// "<name> before <op>" and "<name> after <op>" to calls.
+func recordingInterceptor(name string, calls *[]string) Interceptor {
+	return Interceptor{
+		Exec: func(ctx context.Context, conn Connection, query string, args []any, next ExecFunc) error {
+			*calls = append(*calls, name+" before exec "+query)
+			err := next(ctx, query, args)
+			*calls = append(*calls, name+" after exec "+query)
+			return err
+		},
+		ExecRowsAffected: func(ctx context.Context, conn Connection, query string, args []any, next ExecRowsAffectedFunc) (int64, error) {
+			*calls = append(*calls, name+" before execRowsAffected "+query)
+			n, err := next(ctx, query, args)
+			*calls = append(*calls, name+" after execRowsAffected "+query)
+			return n, err
+		},
+		Query: func(ctx context.Context, conn Connection, query string, args []any, next QueryFunc) Rows {
+			*calls = append(*calls, name+" before query "+query)
+			rows := next(ctx, query, args)
+			*calls = append(*calls, name+" after query "+query)
+			return rows
+		},
+		Begin: func(ctx context.Context, conn Connection, id uint64, opts *sql.TxOptions, next BeginFunc) (Connection, error) {
+			*calls = append(*calls, name+" before begin")
+			tx, err := next(ctx, id, opts)
+			*calls = append(*calls, name+" after begin")
+			return tx, err
+		},
+		Commit: func(conn Connection, next func() error) error {
+			*calls = append(*calls, name+" before commit")
+			err := next()
+			*calls = append(*calls, name+" after commit")
+			return err
+		},
+		Rollback: func(conn Connection, next func() error) error {
+			*calls = append(*calls, name+" before rollback")
+			err := next()
+			*calls = append(*calls, name+" after rollback")
+			return err
+		},
+		Close: func(conn Connection, next func() error) error {
+			*calls = append(*calls, name+" before close")
+			err := next()
+			*calls = append(*calls, name+" after close")
+			return err
+		},
+	}
+}
+
+func TestWrapConnection_NoInterceptors(t *testing.T) {
+	conn := NewMockConn(NewQueryFormatter("$"))
+	assert.Same(t, conn, WrapConnection(conn))
+}
+
+func TestWrapConnection_Order(t *testing.T) {
+	// given
+	var calls []string
+	mock := NewMockConn(NewQueryFormatter("$"))
+	conn := WrapConnection(mock,
+		recordingInterceptor("outer", &calls),
+		recordingInterceptor("inner", &calls),
+	)
+
+	// when
+	err := conn.Exec(t.Context(), "DELETE FROM t")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"outer before exec DELETE FROM t",
+		"inner before exec DELETE FROM t",
+		"inner after exec DELETE FROM t",
+		"outer after exec DELETE FROM t",
+	}, calls)
+	require.Len(t, mock.Recordings.Execs, 1)
+	assert.Equal(t, "DELETE FROM t", mock.Recordings.Execs[0].Query)
+}
+
+func TestWrapConnection_ModifyQueryAndArgs(t *testing.T) {
+	// given
+	mock := NewMockConn(NewQueryFormatter("$"))
+	conn := WrapConnection(mock, Interceptor{
+		Exec: func(ctx context.Context, conn Connection, query string, args []any, next ExecFunc) error {
+			return next(ctx, query+" /* tagged */", append(args, "extra"))
+		},
+	})
+
+	// when
+	err := conn.Exec(t.Context(), "UPDATE t SET a = $1", 1)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, mock.Recordings.Execs, 1)
+	assert.Equal(t, "UPDATE t SET a = $1 /* tagged */", mock.Recordings.Execs[0].Query)
+	assert.Equal(t, []any{1, "extra"}, mock.Recordings.Execs[0].Args)
+}
+
+func TestWrapConnection_ShortCircuit(t *testing.T) {
+	// given
+	errBlocked := errors.New("blocked")
+	mock := NewMockConn(NewQueryFormatter("$"))
+	conn := WrapConnection(mock, Interceptor{
+		ExecRowsAffected: func(ctx context.Context, conn Connection, query string, args []any, next ExecRowsAffectedFunc) (int64, error) {
+			return 0, errBlocked
+		},
+		Query: func(ctx context.Context, conn Connection, query string, args []any, next QueryFunc) Rows {
+			return NewErrRows(errBlocked)
+		},
+	})
+
+	// when
+	_, execErr := conn.ExecRowsAffected(t.Context(), "DELETE FROM t")
+	queryErr := conn.Query(t.Context(), "SELECT 1").Err()
+
+	// then
+	assert.ErrorIs(t, execErr, errBlocked)
+	assert.ErrorIs(t, queryErr, errBlocked)
+	assert.Empty(t, mock.Recordings.Execs)
+	assert.Empty(t, mock.Recordings.Queries)
+}
+
+func TestWrapConnection_HookReceivesWrappedConn(t *testing.T) {
+	var hookConn Connection
+	conn := WrapConnection(NewMockConn(NewQueryFormatter("$")), Interceptor{
+		Exec: func(ctx context.Context, conn Connection, query string, args []any, next ExecFunc) error {
+			hookConn = conn
+			return next(ctx, query, args)
+		},
+	})
+
+	require.NoError(t, conn.Exec(t.Context(), "SELECT 1"))
+	assert.Equal(t, conn, hookConn)
+}
+
+func TestWrapConnection_Transaction(t *testing.T) {
+	// given
+	var calls []string
+	conn := WrapConnection(NewMockConn(NewQueryFormatter("$")), recordingInterceptor("i", &calls))
+
+	// when
+	err := Transaction(t.Context(), conn, nil, func(tx Connection) error {
+		assert.NotZero(t, tx.Transaction().ID)
+		return tx.Exec(t.Context(), "INSERT INTO t DEFAULT VALUES")
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"i before begin",
+		"i after begin",
+		"i before exec INSERT INTO t DEFAULT VALUES",
+		"i after exec INSERT INTO t DEFAULT VALUES",
+		"i before commit",
+		"i after commit",
+	}, calls)
+}
+
+func TestWrapConnection_TransactionRollback(t *testing.T) {
+	// given
+	var calls []string
+	errTx := errors.New("tx error")
+	conn := WrapConnection(NewMockConn(NewQueryFormatter("$")), recordingInterceptor("i", &calls))
+
+	// when
+	err := Transaction(t.Context(), conn, nil, func(tx Connection) error {
+		return errTx
+	})
+
+	// then
+	require.ErrorIs(t, err, errTx)
+	assert.Equal(t, []string{
+		"i before begin",
+		"i after begin",
+		"i before rollback",
+		"i after rollback",
+	}, calls)
+}
+
+func TestWrapConnection_TransactionClose(t *testing.T) {
+	// given
+	var calls []string
+	conn := WrapConnection(NewMockConn(NewQueryFormatter("$")), recordingInterceptor("i", &calls))
+	tx, err := conn.Begin(t.Context(), 1, nil)
+	require.NoError(t, err)
+
+	// when
+	err = tx.Close()
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"i before begin",
+		"i after begin",
+		"i before close",
+		"i after close",
+	}, calls)
+}
+
+func TestWrapConnection_Prepare(t *testing.T) {
+	// given
+	var (
+		calls    []string
+		prepared []string
+	)
+	mock := NewMockConn(NewQueryFormatter("$"))
+	i := recordingInterceptor("i", &calls)
+	i.Prepare = func(ctx context.Context, conn Connection, query string, next PrepareFunc) (Stmt, error) {
+		prepared = append(prepared, query)
+		return next(ctx, query)
+	}
+	conn := WrapConnection(mock, i)
+
+	// when
+	stmt, err := conn.Prepare(t.Context(), "DELETE FROM t WHERE id = $1")
+	require.NoError(t, err)
+	t.Cleanup(func() { stmt.Close() })
+	err = stmt.Exec(t.Context(), 1)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "DELETE FROM t WHERE id = $1", stmt.PreparedQuery())
+	assert.Equal(t, []string{"DELETE FROM t WHERE id = $1"}, prepared)
+	assert.Equal(t, []string{
+		"i before exec DELETE FROM t WHERE id = $1",
+		"i after exec DELETE FROM t WHERE id = $1",
+	}, calls)
+	require.Len(t, mock.Recordings.Execs, 1)
+	assert.Equal(t, []any{1}, mock.Recordings.Execs[0].Args)
+}
+
+func TestWrapConnection_PrepareError(t *testing.T) {
+	errPrepare := errors.New("prepare error")
+	mock := NewMockConn(NewQueryFormatter("$"))
+	mock.MockPrepare = func(ctx context.Context, query string) (Stmt, error) {
+		return nil, errPrepare
+	}
+	conn := WrapConnection(mock, Interceptor{})
+
+	stmt, err := conn.Prepare(t.Context(), "SELECT 1")
+	assert.ErrorIs(t, err, errPrepare)
+	assert.Nil(t, stmt)
+}
+
+// interceptorTestConn implements ConnPinner
+// in addition to the interfaces of MockConn.
+type interceptorTestConn struct {
+	*MockConn
+}
+
+func (c interceptorTestConn) Conn(ctx context.Context) (PinnedConnection, error) {
+	return interceptorTestPinnedConn{c.MockConn}, nil
+}
+
+// interceptorTestPinnedConn implements PinnedConnection
+// in addition to the interfaces of MockConn.
+type interceptorTestPinnedConn struct {
+	*MockConn
+}
+
+func (interceptorTestPinnedConn) IsPinnedConnection() bool { return true }
+
+// interceptorTestQueryBuilderConn implements QueryBuilder
+// in addition to Connection.
+type interceptorTestQueryBuilderConn struct {
+	Connection
+	StdQueryBuilder
+}
+
+// interceptorTestReturningQueryBuilderConn implements QueryBuilder
+// and ReturningQueryBuilder in addition to Connection.
+type interceptorTestReturningQueryBuilderConn struct {
+	Connection
+	StdReturningQueryBuilder
+}
+
+func TestConnectionAs(t *testing.T) {
+	mock := NewMockConn(NewQueryFormatter("$"))
+
+	t.Run("MockConn", func(t *testing.T) {
+		conn := WrapConnection(mock, Interceptor{})
+		_, ok := ConnectionAs[ListenerConnection](conn)
+		assert.True(t, ok)
+		_, ok = ConnectionAs[ConnPinner](conn)
+		assert.False(t, ok)
+		_, ok = ConnectionAs[QueryBuilder](conn)
+		assert.False(t, ok)
+		assert.NotImplements(t, (*PinnedConnection)(nil), conn)
+
+		found, ok := ConnectionAs[*MockConn](conn)
+		require.True(t, ok)
+		assert.Same(t, mock, found)
+	})
+
+	t.Run("ConnPinner", func(t *testing.T) {
+		// given
+		var calls []string
+		conn := WrapConnection(interceptorTestConn{mock}, recordingInterceptor("i", &calls))
+		pinner, ok := ConnectionAs[ConnPinner](conn)
+		require.True(t, ok)
+
+		// when
+		pinned, err := pinner.Conn(t.Context())
+		require.NoError(t, err)
+		require.NoError(t, pinned.Exec(t.Context(), "SELECT 1"))
+
+		// then
+		assert.True(t, pinned.IsPinnedConnection())
+		_, ok = ConnectionAs[ListenerConnection](pinned)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"i before exec SELECT 1", "i after exec SELECT 1"}, calls)
+	})
+
+	t.Run("nested ConnPinner", func(t *testing.T) {
+		// given
+		var calls []string
+		inner := WrapConnection(interceptorTestConn{mock}, recordingInterceptor("inner", &calls))
+		conn := WrapConnection(inner, recordingInterceptor("outer", &calls))
+		pinner, ok := ConnectionAs[ConnPinner](conn)
+		require.True(t, ok)
+
+		// when
+		pinned, err := pinner.Conn(t.Context())
+		require.NoError(t, err)
+		require.NoError(t, pinned.Exec(t.Context(), "SELECT 1"))
+
+		// then
+		assert.Equal(t, []string{
+			"outer before exec SELECT 1",
+			"inner before exec SELECT 1",
+			"inner after exec SELECT 1",
+			"outer after exec SELECT 1",
+		}, calls)
+	})
+
+	t.Run("QueryBuilder", func(t *testing.T) {
+		conn := WrapConnection(interceptorTestQueryBuilderConn{Connection: mock}, Interceptor{})
+		builder, ok := ConnectionAs[QueryBuilder](conn)
+		require.True(t, ok)
+		_, ok = ConnectionAs[ListenerConnection](conn)
+		assert.False(t, ok)
+
+		query, err := builder.Insert(conn, "t", []ColumnInfo{{Name: "a"}})
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO t(a) VALUES($1)", query)
+
+		_, ok = ConnectionAs[UpsertQueryBuilder](conn)
+		assert.False(t, ok)
+		_, ok = ConnectionAs[ReturningQueryBuilder](conn)
+		assert.False(t, ok)
+		pageBuilder, ok := ConnectionAs[PageQueryBuilder](conn)
+		require.True(t, ok)
+
+		query, _, err = pageBuilder.QueryPage(conn, "SELECT * FROM t", nil, []OrderByColumn{{Column: "a"}}, nil, 10)
+		require.NoError(t, err)
+		assert.Contains(t, query, "LIMIT 10")
+	})
+
+	t.Run("ReturningQueryBuilder", func(t *testing.T) {
+		conn := WrapConnection(interceptorTestReturningQueryBuilderConn{Connection: mock}, Interceptor{})
+		builder, ok := ConnectionAs[ReturningQueryBuilder](conn)
+		require.True(t, ok)
+
+		query, err := builder.InsertReturning(conn, "t", []ColumnInfo{{Name: "a"}}, "id")
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO t(a) VALUES($1) RETURNING id", query)
+	})
+
+	t.Run("PinnedConnection", func(t *testing.T) {
+		conn := WrapConnection(interceptorTestPinnedConn{mock}, Interceptor{})
+		pinned, ok := conn.(PinnedConnection)
+		require.True(t, ok)
+		assert.True(t, pinned.IsPinnedConnection())
+	})
+}
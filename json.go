@@ -0,0 +1,104 @@
+package sqldb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Ensure that JSONTypeWrapper implements TypeWrapper
+var _ TypeWrapper = JSONTypeWrapper{}
+
+// JSONTypeWrapper implements TypeWrapper for the contained types
+// by marshalling values with encoding/json.
+// It is the type-based alternative to the `json` struct tag option
+// of TaggedStructReflector for types that are always stored as JSON:
+//
+//	sqldb.NewTaggedStructReflector(sqldb.JSONTypeWrapper{reflect.TypeFor[Settings]()})
+//
+// The driver.Valuer returns the JSON as string, which works
+// for jsonb on PostgreSQL, JSON on MySQL, NVARCHAR(MAX) on SQL Server,
+// TEXT on SQLite and JSON or CLOB on Oracle.
+// Nil pointers, maps, slices, and interfaces are written as NULL.
+// Scanning NULL sets the zero value.
+type JSONTypeWrapper []reflect.Type
+
+func (tw JSONTypeWrapper) WrapAsScanner(val reflect.Value) sql.Scanner {
+	if !slices.Contains(tw, val.Type()) {
+		return nil
+	}
+	return &jsonScanner{ptr: val.Addr()}
+}
+
+func (tw JSONTypeWrapper) WrapAsValuer(val reflect.Value) driver.Valuer {
+	if !slices.Contains(tw, val.Type()) {
+		return nil
+	}
+	return jsonValuer{val: val.Interface()}
+}
+
+// jsonScanner unmarshals JSON column values into the value ptr points to.
+type jsonScanner struct {
+	ptr reflect.Value
+
+	// table and column are used for error messages if not empty
+	table  string
+	column string
+}
+
+func (s *jsonScanner) Scan(src any) error {
+	dest := s.ptr.Elem()
+	// Reset to not merge with the previous value of a reused struct
+	dest.SetZero()
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return fmt.Errorf("can't scan %T as JSON into %s", src, s.destination())
+	}
+	err := json.Unmarshal(data, s.ptr.Interface())
+	if err != nil {
+		return fmt.Errorf("can't unmarshal JSON into %s: %w", s.destination(), err)
+	}
+	return nil
+}
+
+func (s *jsonScanner) destination() string {
+	switch {
+	case s.table != "" && s.column != "":
+		return fmt.Sprintf("column %s.%s of type %s", s.table, s.column, s.ptr.Type().Elem())
+	case s.column != "":
+		return fmt.Sprintf("column %s of type %s", s.column, s.ptr.Type().Elem())
+	}
+	return s.ptr.Type().Elem().String()
+}
+
+// jsonValuer marshals val as JSON string.
+type jsonValuer struct {
+	val any
+}
+
+func (v jsonValuer) Value() (driver.Value, error) {
+	if v.val == nil {
+		return nil, nil
+	}
+	switch rv := reflect.ValueOf(v.val); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+	}
+	data, err := json.Marshal(v.val)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal %T as JSON: %w", v.val, err)
+	}
+	return string(data), nil
+}
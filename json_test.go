@@ -0,0 +1,296 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonTestSettings struct {
+	Theme string `json:"theme"`
+	Size  int    `json:"size"`
+}
+
+type jsonTestRow struct {
+	TableName `db:"json_table"`
+
+	ID       int64             `db:"id,primarykey"`
+	Settings *jsonTestSettings `db:"settings,json"`
+	Labels   map[string]string `db:"labels,json"`
+	Tags     []string          `db:"tags,json"`
+}
+
+func TestJSONTypeWrapper_WrapAsScanner(t *testing.T) {
+	tw := JSONTypeWrapper{reflect.TypeFor[jsonTestSettings](), reflect.TypeFor[[]string]()}
+
+	t.Run("nil for unsupported type", func(t *testing.T) {
+		// given
+		val := reflect.ValueOf(new(string)).Elem()
+
+		// when
+		scanner := tw.WrapAsScanner(val)
+
+		// then
+		assert.Nil(t, scanner)
+	})
+
+	t.Run("scan string into struct", func(t *testing.T) {
+		// given
+		var settings jsonTestSettings
+		val := reflect.ValueOf(&settings).Elem()
+
+		// when
+		scanner := tw.WrapAsScanner(val)
+		require.NotNil(t, scanner)
+		err := scanner.Scan(`{"theme":"dark","size":3}`)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, jsonTestSettings{Theme: "dark", Size: 3}, settings)
+	})
+
+	t.Run("scan bytes into slice", func(t *testing.T) {
+		// given
+		tags := []string{"old"}
+		val := reflect.ValueOf(&tags).Elem()
+
+		// when
+		scanner := tw.WrapAsScanner(val)
+		require.NotNil(t, scanner)
+		err := scanner.Scan([]byte(`["a","b"]`))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, tags)
+	})
+
+	t.Run("scan nil sets zero value", func(t *testing.T) {
+		// given
+		settings := jsonTestSettings{Theme: "dark", Size: 3}
+		val := reflect.ValueOf(&settings).Elem()
+
+		// when
+		scanner := tw.WrapAsScanner(val)
+		require.NotNil(t, scanner)
+		err := scanner.Scan(nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, jsonTestSettings{}, settings)
+	})
+
+	t.Run("scan unsupported type", func(t *testing.T) {
+		// given
+		var settings jsonTestSettings
+		val := reflect.ValueOf(&settings).Elem()
+
+		// when
+		scanner := tw.WrapAsScanner(val)
+		require.NotNil(t, scanner)
+		err := scanner.Scan(int64(1))
+
+		// then
+		require.Error(t, err)
+	})
+
+	t.Run("scan invalid JSON", func(t *testing.T) {
+		// given
+		var settings jsonTestSettings
+		val := reflect.ValueOf(&settings).Elem()
+
+		// when
+		scanner := tw.WrapAsScanner(val)
+		require.NotNil(t, scanner)
+		err := scanner.Scan(`{"theme":`)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sqldb.jsonTestSettings")
+	})
+}
+
+func TestJSONTypeWrapper_WrapAsValuer(t *testing.T) {
+	tw := JSONTypeWrapper{reflect.TypeFor[*jsonTestSettings](), reflect.TypeFor[map[string]string]()}
+
+	t.Run("nil for unsupported type", func(t *testing.T) {
+		// given
+		val := reflect.ValueOf(new(string)).Elem()
+
+		// when
+		valuer := tw.WrapAsValuer(val)
+
+		// then
+		assert.Nil(t, valuer)
+	})
+
+	t.Run("value of pointer", func(t *testing.T) {
+		// given
+		settings := &jsonTestSettings{Theme: "dark", Size: 3}
+		val := reflect.ValueOf(&settings).Elem()
+
+		// when
+		valuer := tw.WrapAsValuer(val)
+		require.NotNil(t, valuer)
+		v, err := valuer.Value()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `{"theme":"dark","size":3}`, v)
+	})
+
+	t.Run("nil pointer is NULL", func(t *testing.T) {
+		// given
+		var settings *jsonTestSettings
+		val := reflect.ValueOf(&settings).Elem()
+
+		// when
+		valuer := tw.WrapAsValuer(val)
+		require.NotNil(t, valuer)
+		v, err := valuer.Value()
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, v)
+	})
+
+	t.Run("nil map is NULL", func(t *testing.T) {
+		// given
+		var labels map[string]string
+		val := reflect.ValueOf(&labels).Elem()
+
+		// when
+		valuer := tw.WrapAsValuer(val)
+		require.NotNil(t, valuer)
+		v, err := valuer.Value()
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, v)
+	})
+
+	t.Run("empty map is not NULL", func(t *testing.T) {
+		// given
+		labels := map[string]string{}
+		val := reflect.ValueOf(&labels).Elem()
+
+		// when
+		valuer := tw.WrapAsValuer(val)
+		require.NotNil(t, valuer)
+		v, err := valuer.Value()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `{}`, v)
+	})
+}
+
+func TestTaggedStructReflector_JSON(t *testing.T) {
+	t.Run("scan with table and column in error", func(t *testing.T) {
+		// given
+		refl := NewTaggedStructReflector()
+		var row jsonTestRow
+		structVal := reflect.ValueOf(&row).Elem()
+
+		// when
+		scanables, err := refl.ScanableStructFieldsForColumns(structVal, []string{"id", "settings", "labels", "tags"})
+		require.NoError(t, err)
+		errSettings := scanables[1].(sql.Scanner).Scan(`[1,2]`)
+		errLabels := scanables[2].(sql.Scanner).Scan(`{"a":"b"}`)
+		errTags := scanables[3].(sql.Scanner).Scan(nil)
+
+		// then
+		require.Error(t, errSettings)
+		assert.Contains(t, errSettings.Error(), "column json_table.settings of type *sqldb.jsonTestSettings")
+		require.NoError(t, errLabels)
+		assert.Equal(t, map[string]string{"a": "b"}, row.Labels)
+		require.NoError(t, errTags)
+		assert.Nil(t, row.Tags)
+	})
+
+	t.Run("values", func(t *testing.T) {
+		// given
+		refl := NewTaggedStructReflector()
+		row := jsonTestRow{
+			ID:       1,
+			Settings: &jsonTestSettings{Theme: "dark"},
+			Tags:     []string{"a"},
+		}
+
+		// when
+		values, err := refl.ReflectStructValues(reflect.ValueOf(row))
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, values, 4)
+		assert.Equal(t, int64(1), values[0])
+		assertJSONValue(t, `{"theme":"dark","size":0}`, values[1])
+		assertJSONValue(t, nil, values[2])
+		assertJSONValue(t, `["a"]`, values[3])
+	})
+
+	t.Run("InsertRowStruct with cached query", func(t *testing.T) {
+		// given
+		conn, _, builder, fmtr := newTestInterfaces()
+		refl := NewTaggedStructReflector()
+		var gotArgs [][]any
+		conn.MockExec = func(ctx context.Context, query string, args ...any) error {
+			gotArgs = append(gotArgs, args)
+			return nil
+		}
+		row := jsonTestRow{ID: 1, Labels: map[string]string{"a": "b"}}
+
+		// when
+		err1 := InsertRowStruct(t.Context(), conn, refl, builder, fmtr, &row)
+		err2 := InsertRowStruct(t.Context(), conn, refl, builder, fmtr, &row)
+
+		// then
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		require.Len(t, gotArgs, 2)
+		for _, args := range gotArgs {
+			require.Len(t, args, 4)
+			assertJSONValue(t, nil, args[1])
+			assertJSONValue(t, `{"a":"b"}`, args[2])
+			assertJSONValue(t, nil, args[3])
+		}
+	})
+
+	t.Run("UpdateRowStruct with cached query", func(t *testing.T) {
+		// given
+		conn, _, builder, fmtr := newTestInterfaces()
+		refl := NewTaggedStructReflector()
+		conn.MockExecRowsAffected = rowsAffected(1)
+		row := jsonTestRow{ID: 1, Labels: map[string]string{"a": "b"}}
+
+		// when
+		err1 := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, row)
+		err2 := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, row)
+
+		// then
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		require.Len(t, conn.Recordings.Execs, 2)
+		for _, exec := range conn.Recordings.Execs {
+			// Non primary key columns first, then the primary key
+			require.Len(t, exec.Args, 4)
+			assertJSONValue(t, nil, exec.Args[0])
+			assertJSONValue(t, `{"a":"b"}`, exec.Args[1])
+			assertJSONValue(t, nil, exec.Args[2])
+			assert.Equal(t, int64(1), exec.Args[3])
+		}
+	})
+}
+
+func assertJSONValue(t *testing.T, want driver.Value, arg any) {
+	t.Helper()
+	valuer, ok := arg.(driver.Valuer)
+	require.True(t, ok, "%T is not a driver.Valuer", arg)
+	got, err := valuer.Value()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
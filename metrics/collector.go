@@ -0,0 +1,296 @@
+package metrics
+
+import (
+	"bufio"
+	"cmp"
+	"database/sql"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var _ interface {
+	Sink
+	http.Handler
+} = (*Collector)(nil)
+
+// DefaultBuckets are the default upper bounds in seconds
+// of the latency histogram buckets of a [Collector].
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// DefaultMaxFingerprints is the default value of Collector.MaxFingerprints.
+const DefaultMaxFingerprints = 1000
+
+// OverflowFingerprint is the fingerprint label used for all queries
+// observed after Collector.MaxFingerprints different fingerprints.
+const OverflowFingerprint = "OTHER"
+
+// Collector is a [Sink] that aggregates observations in memory
+// and exports them in the Prometheus text exposition format
+// as [http.Handler] or with WritePrometheus.
+//
+// Exported metrics:
+//   - sqldb_queries_total{driver, fingerprint}
+//   - sqldb_query_errors_total{driver, fingerprint, kind}
+//   - sqldb_query_rows_total{driver, fingerprint}
+//   - sqldb_query_duration_seconds{driver, fingerprint} histogram
+//   - sqldb_transaction_duration_seconds{driver, outcome} histogram
+//   - sqldb_pool_* gauges and counters from the sampled sql.DBStats
+type Collector struct {
+	// MaxFingerprints limits the number of different query fingerprints
+	// to bound the cardinality of the exported metrics.
+	// Queries with further fingerprints are aggregated as OverflowFingerprint.
+	// Must not be changed after the first observation.
+	MaxFingerprints int
+
+	buckets []float64
+
+	mtx          sync.Mutex
+	queries      map[queryKey]*queryStats
+	fingerprints map[string]struct{}
+	transactions map[transactionKey]*histogram
+	pools        map[string]sql.DBStats
+}
+
+type queryKey struct {
+	driver      string
+	fingerprint string
+}
+
+type queryStats struct {
+	count    uint64
+	errors   map[string]uint64 // by error kind
+	rows     uint64
+	duration histogram
+}
+
+type transactionKey struct {
+	driver  string
+	outcome string
+}
+
+// NewCollector returns a new Collector with latency histograms
+// using the passed bucket upper bounds in seconds.
+// If buckets is nil then DefaultBuckets are used.
+func NewCollector(buckets []float64) *Collector {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &Collector{
+		MaxFingerprints: DefaultMaxFingerprints,
+		buckets:         buckets,
+		queries:         make(map[queryKey]*queryStats),
+		fingerprints:    make(map[string]struct{}),
+		transactions:    make(map[transactionKey]*histogram),
+		pools:           make(map[string]sql.DBStats),
+	}
+}
+
+// ObserveQuery implements Sink.
+func (c *Collector) ObserveQuery(q QueryObservation) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	fingerprint := q.Fingerprint
+	if _, ok := c.fingerprints[fingerprint]; !ok {
+		if len(c.fingerprints) < c.MaxFingerprints {
+			c.fingerprints[fingerprint] = struct{}{}
+		} else {
+			fingerprint = OverflowFingerprint
+		}
+	}
+	key := queryKey{driver: q.Driver, fingerprint: fingerprint}
+	stats := c.queries[key]
+	if stats == nil {
+		stats = &queryStats{duration: newHistogram(c.buckets)}
+		c.queries[key] = stats
+	}
+	stats.count++
+	if q.ErrorKind != "" {
+		if stats.errors == nil {
+			stats.errors = make(map[string]uint64)
+		}
+		stats.errors[q.ErrorKind]++
+	}
+	if q.RowsAffected > 0 {
+		stats.rows += uint64(q.RowsAffected) //#nosec G115 -- checked to be positive
+	}
+	stats.duration.observe(q.Duration.Seconds())
+}
+
+// ObserveTransaction implements Sink.
+func (c *Collector) ObserveTransaction(t TransactionObservation) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	key := transactionKey{driver: t.Driver, outcome: t.Outcome}
+	h := c.transactions[key]
+	if h == nil {
+		h = new(histogram)
+		*h = newHistogram(c.buckets)
+		c.transactions[key] = h
+	}
+	h.observe(t.Duration.Seconds())
+}
+
+// ObservePoolStats implements Sink.
+func (c *Collector) ObservePoolStats(p PoolStatsObservation) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.pools[p.Driver] = p.Stats
+}
+
+// ServeHTTP implements http.Handler by writing
+// the metrics in the Prometheus text exposition format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = c.WritePrometheus(w)
+}
+
+// WritePrometheus writes the metrics to w
+// in the Prometheus text exposition format.
+func (c *Collector) WritePrometheus(w io.Writer) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	b := bufio.NewWriter(w)
+
+	queryKeys := slices.SortedFunc(maps.Keys(c.queries), func(a, b queryKey) int {
+		return cmp.Or(cmp.Compare(a.driver, b.driver), cmp.Compare(a.fingerprint, b.fingerprint))
+	})
+	queryLabels := func(key queryKey) string {
+		return labels("driver", key.driver, "fingerprint", key.fingerprint)
+	}
+
+	writeHeader(b, "sqldb_queries_total", "counter", "Number of executed queries.")
+	for _, key := range queryKeys {
+		fmt.Fprintf(b, "sqldb_queries_total%s %d\n", queryLabels(key), c.queries[key].count)
+	}
+
+	writeHeader(b, "sqldb_query_errors_total", "counter", "Number of failed queries by error kind.")
+	for _, key := range queryKeys {
+		errs := c.queries[key].errors
+		for _, kind := range slices.Sorted(maps.Keys(errs)) {
+			l := labels("driver", key.driver, "fingerprint", key.fingerprint, "kind", kind)
+			fmt.Fprintf(b, "sqldb_query_errors_total%s %d\n", l, errs[kind])
+		}
+	}
+
+	writeHeader(b, "sqldb_query_rows_total", "counter", "Number of rows affected or returned by queries.")
+	for _, key := range queryKeys {
+		fmt.Fprintf(b, "sqldb_query_rows_total%s %d\n", queryLabels(key), c.queries[key].rows)
+	}
+
+	writeHeader(b, "sqldb_query_duration_seconds", "histogram", "Query latency in seconds.")
+	for _, key := range queryKeys {
+		c.queries[key].duration.write(b, "sqldb_query_duration_seconds", "driver", key.driver, "fingerprint", key.fingerprint)
+	}
+
+	writeHeader(b, "sqldb_transaction_duration_seconds", "histogram", "Transaction duration in seconds by outcome.")
+	txKeys := slices.SortedFunc(maps.Keys(c.transactions), func(a, b transactionKey) int {
+		return cmp.Or(cmp.Compare(a.driver, b.driver), cmp.Compare(a.outcome, b.outcome))
+	})
+	for _, key := range txKeys {
+		c.transactions[key].write(b, "sqldb_transaction_duration_seconds", "driver", key.driver, "outcome", key.outcome)
+	}
+
+	drivers := slices.Sorted(maps.Keys(c.pools))
+	for _, pm := range poolMetrics {
+		writeHeader(b, pm.name, pm.typ, pm.help)
+		for _, driver := range drivers {
+			fmt.Fprintf(b, "%s%s %s\n", pm.name, labels("driver", driver), formatFloat(pm.value(c.pools[driver])))
+		}
+	}
+
+	return b.Flush()
+}
+
+var poolMetrics = []struct {
+	name  string
+	typ   string
+	help  string
+	value func(sql.DBStats) float64
+}{
+	{"sqldb_pool_max_open_connections", "gauge", "Maximum number of open connections to the database.", func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }},
+	{"sqldb_pool_open_connections", "gauge", "Number of established connections both in use and idle.", func(s sql.DBStats) float64 { return float64(s.OpenConnections) }},
+	{"sqldb_pool_in_use_connections", "gauge", "Number of connections currently in use.", func(s sql.DBStats) float64 { return float64(s.InUse) }},
+	{"sqldb_pool_idle_connections", "gauge", "Number of idle connections.", func(s sql.DBStats) float64 { return float64(s.Idle) }},
+	{"sqldb_pool_wait_count_total", "counter", "Total number of connections waited for.", func(s sql.DBStats) float64 { return float64(s.WaitCount) }},
+	{"sqldb_pool_wait_duration_seconds_total", "counter", "Total time blocked waiting for a new connection in seconds.", func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }},
+	{"sqldb_pool_max_idle_closed_total", "counter", "Total number of connections closed due to MaxIdleConns.", func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }},
+	{"sqldb_pool_max_idle_time_closed_total", "counter", "Total number of connections closed due to ConnMaxIdleTime.", func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }},
+	{"sqldb_pool_max_lifetime_closed_total", "counter", "Total number of connections closed due to ConnMaxLifetime.", func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }},
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64 // non-cumulative counts per bucket
+	count   uint64
+	sum     float64
+}
+
+func newHistogram(buckets []float64) histogram {
+	return histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+func (h *histogram) observe(v float64) {
+	h.count++
+	h.sum += v
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+}
+
+func (h *histogram) write(w io.Writer, name string, labelPairs ...string) {
+	var cumulative uint64
+	for i, upperBound := range h.buckets {
+		cumulative += h.counts[i]
+		l := labels(append(labelPairs, "le", formatFloat(upperBound))...)
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, l, cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(append(labelPairs, "le", "+Inf")...), h.count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels(labelPairs...), formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels(labelPairs...), h.count)
+}
+
+func writeHeader(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// labels formats name/value pairs as Prometheus label set.
+func labels(nameValuePairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(nameValuePairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(nameValuePairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(nameValuePairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, +1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
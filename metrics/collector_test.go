@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	// given
+	c := NewCollector([]float64{0.1, 1})
+	c.ObserveQuery(QueryObservation{Driver: "postgres", Method: "Exec", Fingerprint: `SELECT "a\b"`, Duration: 50 * time.Millisecond, RowsAffected: -1})
+	c.ObserveQuery(QueryObservation{Driver: "postgres", Method: "ExecRowsAffected", Fingerprint: `SELECT "a\b"`, Duration: 500 * time.Millisecond, RowsAffected: 2, ErrorKind: ""})
+	c.ObserveQuery(QueryObservation{Driver: "postgres", Method: "Exec", Fingerprint: `SELECT "a\b"`, Duration: 2 * time.Second, RowsAffected: -1, ErrorKind: "ErrDeadlock"})
+	c.ObserveTransaction(TransactionObservation{Driver: "postgres", Duration: time.Second, Outcome: OutcomeCommit})
+	c.ObservePoolStats(PoolStatsObservation{Driver: "postgres", Stats: sql.DBStats{OpenConnections: 4, WaitDuration: 1500 * time.Millisecond}})
+
+	// when
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// then
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE sqldb_queries_total counter",
+		`sqldb_queries_total{driver="postgres",fingerprint="SELECT \"a\\b\""} 3`,
+		`sqldb_query_errors_total{driver="postgres",fingerprint="SELECT \"a\\b\"",kind="ErrDeadlock"} 1`,
+		`sqldb_query_rows_total{driver="postgres",fingerprint="SELECT \"a\\b\""} 2`,
+		"# TYPE sqldb_query_duration_seconds histogram",
+		`sqldb_query_duration_seconds_bucket{driver="postgres",fingerprint="SELECT \"a\\b\"",le="0.1"} 1`,
+		`sqldb_query_duration_seconds_bucket{driver="postgres",fingerprint="SELECT \"a\\b\"",le="1"} 2`,
+		`sqldb_query_duration_seconds_bucket{driver="postgres",fingerprint="SELECT \"a\\b\"",le="+Inf"} 3`,
+		`sqldb_query_duration_seconds_sum{driver="postgres",fingerprint="SELECT \"a\\b\""} 2.55`,
+		`sqldb_query_duration_seconds_count{driver="postgres",fingerprint="SELECT \"a\\b\""} 3`,
+		`sqldb_transaction_duration_seconds_count{driver="postgres",outcome="commit"} 1`,
+		`sqldb_pool_open_connections{driver="postgres"} 4`,
+		`sqldb_pool_wait_duration_seconds_total{driver="postgres"} 1.5`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+}
+
+func TestCollector_MaxFingerprints(t *testing.T) {
+	// given
+	c := NewCollector(nil)
+	c.MaxFingerprints = 1
+
+	// when
+	c.ObserveQuery(QueryObservation{Driver: "d", Fingerprint: "SELECT 1"})
+	c.ObserveQuery(QueryObservation{Driver: "d", Fingerprint: "SELECT 2"})
+	c.ObserveQuery(QueryObservation{Driver: "d", Fingerprint: "SELECT 1"})
+
+	// then
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, `sqldb_queries_total{driver="d",fingerprint="SELECT 1"} 2`+"\n")
+	assert.Contains(t, body, `sqldb_queries_total{driver="d",fingerprint="OTHER"} 1`+"\n")
+	assert.NotContains(t, body, `fingerprint="SELECT 2"`)
+}
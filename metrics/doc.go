@@ -0,0 +1,21 @@
+// Package metrics collects query and transaction metrics
+// of [sqldb.Connection] operations.
+//
+// [NewInterceptor] returns a [sqldb.Interceptor] that reports every
+// query and transaction to a pluggable [Sink]. Queries are keyed by
+// a fingerprint of the normalized query with literal values replaced,
+// so that queries differing only in their literals are aggregated together.
+// [SamplePoolStats] periodically reports the connection pool statistics
+// from [sqldb.Connection.Stats] to a Sink.
+//
+// [Collector] is an in-memory Sink aggregating counters and latency histograms
+// that implements [http.Handler] to export them in the Prometheus text
+// exposition format, without depending on a metrics library:
+//
+//	collector := metrics.NewCollector(nil)
+//	conn = metrics.WrapConnection(conn, collector)
+//	go metrics.SamplePoolStats(ctx, conn, collector, 15*time.Second)
+//	http.Handle("/metrics", collector)
+//
+// Implement Sink to forward the observations to other metrics systems.
+package metrics
@@ -0,0 +1,226 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// maxCachedFingerprints limits the number of cached query fingerprints
+// so that dynamically built queries can't grow the cache without bounds.
+const maxCachedFingerprints = 10_000
+
+// Option configures the interceptor returned by [NewInterceptor].
+type Option func(*interceptor)
+
+// WithFingerprinter sets the function used to compute query fingerprints.
+// The default is sqldb.NewQueryFingerprinter.
+func WithFingerprinter(fingerprint sqldb.NormalizeQueryFunc) Option {
+	return func(i *interceptor) {
+		i.fingerprint = fingerprint
+	}
+}
+
+// WrapConnection returns conn wrapped with the interceptor
+// returned by [NewInterceptor] reporting to sink.
+func WrapConnection(conn sqldb.Connection, sink Sink, opts ...Option) sqldb.Connection {
+	return sqldb.WrapConnection(conn, NewInterceptor(sink, opts...))
+}
+
+// NewInterceptor returns a sqldb.Interceptor that reports
+// all queries and transactions to sink.
+// Use it with sqldb.WrapConnection to combine it with other interceptors.
+func NewInterceptor(sink Sink, opts ...Option) sqldb.Interceptor {
+	i := &interceptor{
+		sink:        sink,
+		fingerprint: sqldb.NewQueryFingerprinter(),
+		txStarts:    make(map[uint64]time.Time),
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return sqldb.Interceptor{
+		Exec:             i.exec,
+		ExecRowsAffected: i.execRowsAffected,
+		Query:            i.query,
+		Begin:            i.begin,
+		Commit:           i.commit,
+		Rollback:         i.rollback,
+		Close:            i.close,
+	}
+}
+
+type interceptor struct {
+	sink        Sink
+	fingerprint sqldb.NormalizeQueryFunc
+
+	fingerprints    sync.Map // map[string]string
+	numFingerprints atomic.Int64
+
+	// txStarts holds the begin times of open transactions by transaction ID
+	txStartsMtx sync.Mutex
+	txStarts    map[uint64]time.Time
+}
+
+func (i *interceptor) exec(ctx context.Context, conn sqldb.Connection, query string, args []any, next sqldb.ExecFunc) error {
+	start := time.Now()
+	err := next(ctx, query, args)
+	i.observeQuery(conn, "Exec", query, start, -1, err)
+	return err
+}
+
+func (i *interceptor) execRowsAffected(ctx context.Context, conn sqldb.Connection, query string, args []any, next sqldb.ExecRowsAffectedFunc) (int64, error) {
+	start := time.Now()
+	n, err := next(ctx, query, args)
+	rows := n
+	if err != nil {
+		rows = -1
+	}
+	i.observeQuery(conn, "ExecRowsAffected", query, start, rows, err)
+	return n, err
+}
+
+func (i *interceptor) query(ctx context.Context, conn sqldb.Connection, query string, args []any, next sqldb.QueryFunc) sqldb.Rows {
+	start := time.Now()
+	return &observedRows{
+		Rows:  next(ctx, query, args),
+		start: start,
+		observe: func(start time.Time, numRows int64, err error) {
+			i.observeQuery(conn, "Query", query, start, numRows, err)
+		},
+	}
+}
+
+func (i *interceptor) begin(ctx context.Context, conn sqldb.Connection, id uint64, opts *sql.TxOptions, next sqldb.BeginFunc) (sqldb.Connection, error) {
+	start := time.Now()
+	tx, err := next(ctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+	i.txStartsMtx.Lock()
+	i.txStarts[id] = start
+	i.txStartsMtx.Unlock()
+	return tx, nil
+}
+
+func (i *interceptor) commit(conn sqldb.Connection, next func() error) error {
+	err := next()
+	if err != nil {
+		i.observeTransaction(conn, OutcomeCommitFailed)
+	} else {
+		i.observeTransaction(conn, OutcomeCommit)
+	}
+	return err
+}
+
+func (i *interceptor) rollback(conn sqldb.Connection, next func() error) error {
+	err := next()
+	i.observeTransaction(conn, OutcomeRollback)
+	return err
+}
+
+// close observes a transaction that is rolled back
+// by closing it without Commit or Rollback.
+func (i *interceptor) close(conn sqldb.Connection, next func() error) error {
+	err := next()
+	if conn.Transaction().ID != 0 {
+		i.observeTransaction(conn, OutcomeRollback)
+	}
+	return err
+}
+
+func (i *interceptor) observeTransaction(conn sqldb.Connection, outcome string) {
+	id := conn.Transaction().ID
+	i.txStartsMtx.Lock()
+	start, ok := i.txStarts[id]
+	delete(i.txStarts, id)
+	i.txStartsMtx.Unlock()
+	if !ok {
+		return
+	}
+	i.sink.ObserveTransaction(TransactionObservation{
+		Driver:   driverName(conn),
+		Duration: time.Since(start),
+		Outcome:  outcome,
+	})
+}
+
+func (i *interceptor) observeQuery(conn sqldb.Connection, method, query string, start time.Time, rowsAffected int64, err error) {
+	i.sink.ObserveQuery(QueryObservation{
+		Driver:       driverName(conn),
+		Method:       method,
+		Fingerprint:  i.queryFingerprint(query),
+		Duration:     time.Since(start),
+		RowsAffected: rowsAffected,
+		ErrorKind:    sqldb.ErrorKind(err),
+	})
+}
+
+func (i *interceptor) queryFingerprint(query string) string {
+	if fingerprint, ok := i.fingerprints.Load(query); ok {
+		return fingerprint.(string)
+	}
+	fingerprint, err := i.fingerprint(query)
+	if err != nil {
+		fingerprint = sqldb.TrimSurroundingWhitespace(query)
+	}
+	if i.numFingerprints.Load() < maxCachedFingerprints {
+		if _, loaded := i.fingerprints.LoadOrStore(query, fingerprint); !loaded {
+			i.numFingerprints.Add(1)
+		}
+	}
+	return fingerprint
+}
+
+func driverName(conn sqldb.Connection) string {
+	if config := conn.Config(); config != nil {
+		return config.Driver
+	}
+	return ""
+}
+
+// observedRows observes the query when the rows are closed
+// or iterated to the end.
+type observedRows struct {
+	sqldb.Rows
+	start    time.Time
+	observe  func(start time.Time, numRows int64, err error)
+	numRows  int64
+	observed bool
+}
+
+func (r *observedRows) Next() bool {
+	if r.Rows.Next() {
+		r.numRows++
+		return true
+	}
+	// Rows are closed automatically after the last row
+	r.done(nil)
+	return false
+}
+
+func (r *observedRows) Close() error {
+	err := r.Rows.Close()
+	r.done(err)
+	return err
+}
+
+func (r *observedRows) done(closeErr error) {
+	if r.observed {
+		return
+	}
+	r.observed = true
+	err := r.Rows.Err()
+	if err == nil {
+		err = closeErr
+	}
+	numRows := r.numRows
+	if err != nil {
+		numRows = -1
+	}
+	r.observe(r.start, numRows, err)
+}
@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// recordingSink records all observations.
+type recordingSink struct {
+	mtx          sync.Mutex
+	queries      []QueryObservation
+	transactions []TransactionObservation
+	pools        []PoolStatsObservation
+}
+
+func (s *recordingSink) ObserveQuery(q QueryObservation) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.queries = append(s.queries, q)
+}
+
+func (s *recordingSink) ObserveTransaction(t TransactionObservation) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.transactions = append(s.transactions, t)
+}
+
+func (s *recordingSink) ObservePoolStats(p PoolStatsObservation) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.pools = append(s.pools, p)
+}
+
+func TestInterceptor_Exec(t *testing.T) {
+	// given
+	sink := new(recordingSink)
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	mock.MockExec = func(ctx context.Context, query string, args ...any) error {
+		return errors.Join(sqldb.ErrUniqueViolation{}, errors.New("driver error"))
+	}
+	conn := WrapConnection(mock, sink)
+
+	// when
+	err1 := conn.Exec(t.Context(), "UPDATE users SET name = 'Alice' WHERE id = $1", 1)
+	err2 := conn.Exec(t.Context(), "UPDATE users SET name = 'Bob' WHERE id = $1", 2)
+
+	// then
+	require.Error(t, err1)
+	require.Error(t, err2)
+	require.Len(t, sink.queries, 2)
+	q := sink.queries[0]
+	assert.Equal(t, "MockConn", q.Driver)
+	assert.Equal(t, "Exec", q.Method)
+	assert.Equal(t, "UPDATE users SET name = ? WHERE id = $1", q.Fingerprint)
+	assert.Equal(t, int64(-1), q.RowsAffected)
+	assert.Equal(t, "ErrUniqueViolation", q.ErrorKind)
+	assert.Equal(t, q.Fingerprint, sink.queries[1].Fingerprint, "same fingerprint for different literals")
+}
+
+func TestInterceptor_ExecRowsAffected(t *testing.T) {
+	// given
+	sink := new(recordingSink)
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	mock.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+		return 5, nil
+	}
+	conn := WrapConnection(mock, sink)
+
+	// when
+	n, err := conn.ExecRowsAffected(t.Context(), "DELETE FROM users")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+	require.Len(t, sink.queries, 1)
+	assert.Equal(t, "ExecRowsAffected", sink.queries[0].Method)
+	assert.Equal(t, int64(5), sink.queries[0].RowsAffected)
+	assert.Empty(t, sink.queries[0].ErrorKind)
+}
+
+func TestInterceptor_Query(t *testing.T) {
+	// given
+	sink := new(recordingSink)
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	mock.MockQuery = func(ctx context.Context, query string, args ...any) sqldb.Rows {
+		return sqldb.NewMockRows("id").WithRow(int64(1)).WithRow(int64(2)).WithRow(int64(3))
+	}
+	conn := WrapConnection(mock, sink)
+
+	// when
+	rows := conn.Query(t.Context(), "SELECT id FROM users")
+	for rows.Next() {
+	}
+	require.NoError(t, rows.Close())
+
+	// then
+	require.Len(t, sink.queries, 1, "observed once after Next returned false and Close")
+	assert.Equal(t, "Query", sink.queries[0].Method)
+	assert.Equal(t, int64(3), sink.queries[0].RowsAffected)
+}
+
+func TestInterceptor_Transactions(t *testing.T) {
+	// given
+	sink := new(recordingSink)
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	conn := WrapConnection(mock, sink)
+	errTx := errors.New("tx error")
+
+	// when
+	err1 := sqldb.Transaction(t.Context(), conn, nil, func(tx sqldb.Connection) error { return nil })
+	err2 := sqldb.Transaction(t.Context(), conn, nil, func(tx sqldb.Connection) error { return errTx })
+
+	// then
+	require.NoError(t, err1)
+	require.ErrorIs(t, err2, errTx)
+	require.Len(t, sink.transactions, 2)
+	assert.Equal(t, OutcomeCommit, sink.transactions[0].Outcome)
+	assert.Equal(t, OutcomeRollback, sink.transactions[1].Outcome)
+	assert.Equal(t, "MockConn", sink.transactions[0].Driver)
+}
+
+func TestInterceptor_TransactionClose(t *testing.T) {
+	// given
+	sink := new(recordingSink)
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	conn := WrapConnection(mock, sink)
+	tx, err := conn.Begin(t.Context(), 1, nil)
+	require.NoError(t, err)
+
+	// when
+	err = tx.Close()
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, sink.transactions, 1)
+	assert.Equal(t, OutcomeRollback, sink.transactions[0].Outcome)
+}
+
+func TestInterceptor_CommitFailed(t *testing.T) {
+	// given
+	sink := new(recordingSink)
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	mock.MockCommit = func() error { return sqldb.ErrSerializationFailure }
+	conn := WrapConnection(mock, sink)
+
+	// when
+	err := sqldb.Transaction(t.Context(), conn, nil, func(tx sqldb.Connection) error { return nil })
+
+	// then
+	require.ErrorIs(t, err, sqldb.ErrSerializationFailure)
+	require.Len(t, sink.transactions, 1)
+	assert.Equal(t, OutcomeCommitFailed, sink.transactions[0].Outcome)
+}
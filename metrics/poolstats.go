@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// SamplePoolStats reports the connection pool statistics
+// of conn to sink every interval until ctx is canceled.
+// The first sample is reported immediately.
+// SamplePoolStats blocks, so run it in its own goroutine.
+func SamplePoolStats(ctx context.Context, conn sqldb.Connection, sink Sink, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	driver := driverName(conn)
+	for {
+		sink.ObservePoolStats(PoolStatsObservation{
+			Driver: driver,
+			Stats:  conn.Stats(),
+		})
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func TestSamplePoolStats(t *testing.T) {
+	// given
+	sink := new(recordingSink)
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	mock.MockStats = func() sql.DBStats { return sql.DBStats{OpenConnections: 3, InUse: 1, Idle: 2} }
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+
+	// when
+	go func() {
+		SamplePoolStats(ctx, mock, sink, time.Millisecond)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		sink.mtx.Lock()
+		defer sink.mtx.Unlock()
+		return len(sink.pools) >= 2
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	// then
+	assert.Equal(t, "MockConn", sink.pools[0].Driver)
+	assert.Equal(t, 3, sink.pools[0].Stats.OpenConnections)
+}
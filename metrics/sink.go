@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Sink receives metric observations.
+// Implementations must be safe for concurrent use.
+type Sink interface {
+	// ObserveQuery is called after every query execution.
+	ObserveQuery(QueryObservation)
+
+	// ObserveTransaction is called after every
+	// transaction commit or rollback.
+	ObserveTransaction(TransactionObservation)
+
+	// ObservePoolStats is called by SamplePoolStats
+	// with the current connection pool statistics.
+	ObservePoolStats(PoolStatsObservation)
+}
+
+// QueryObservation describes an executed query.
+type QueryObservation struct {
+	// Driver is the Config.Driver of the connection.
+	Driver string
+
+	// Method is the called method: "Exec", "ExecRowsAffected", or "Query".
+	Method string
+
+	// Fingerprint is the normalized query with literal values replaced.
+	Fingerprint string
+
+	// Duration is the execution time of the query.
+	// For Query it is the time until the returned Rows are closed.
+	Duration time.Duration
+
+	// RowsAffected is the number of rows affected by ExecRowsAffected
+	// or returned by Query, and -1 if unknown.
+	RowsAffected int64
+
+	// ErrorKind is the error kind from sqldb.ErrorKind
+	// or an empty string if the query succeeded.
+	ErrorKind string
+}
+
+// Transaction outcomes for TransactionObservation.Outcome.
+const (
+	OutcomeCommit       = "commit"
+	OutcomeCommitFailed = "commit_failed"
+	OutcomeRollback     = "rollback"
+)
+
+// TransactionObservation describes a finished transaction.
+type TransactionObservation struct {
+	// Driver is the Config.Driver of the connection.
+	Driver string
+
+	// Duration is the time from Begin until Commit or Rollback returned.
+	Duration time.Duration
+
+	// Outcome is one of OutcomeCommit, OutcomeCommitFailed, or OutcomeRollback.
+	Outcome string
+}
+
+// PoolStatsObservation holds sampled connection pool statistics.
+type PoolStatsObservation struct {
+	// Driver is the Config.Driver of the connection.
+	Driver string
+
+	// Stats are the pool statistics returned by Connection.Stats.
+	Stats sql.DBStats
+}
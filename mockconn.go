@@ -18,6 +18,8 @@ import (
 var (
 	_ ListenerConnection = new(MockConn)
 	_ QueryFormatter     = new(MockConn)
+	_ SessionVarsSetter  = new(MockConn)
+	_ TimeoutSetter      = new(MockConn)
 )
 
 // QueryRecordings holds the recorded exec, query, and Information
@@ -85,6 +87,8 @@ type MockConn struct {
 	MockBegin                func(ctx context.Context, id uint64, opts *sql.TxOptions) (Connection, error)
 	MockCommit               func() error
 	MockRollback             func() error
+	MockSetSessionVars       func(ctx context.Context, vars map[string]string) (reset func(context.Context) error, err error)
+	MockSetTimeouts          func(ctx context.Context, queryTimeout, transactionTimeout time.Duration) (reset func(context.Context) error, err error)
 	MockListenOnChannel      func(channel string, onNotify OnNotifyFunc, onUnlisten OnUnlistenFunc) error
 	MockUnlistenChannel      func(channel string) error
 	MockIsListeningOnChannel func(channel string) bool
@@ -167,6 +171,8 @@ func (c *MockConn) Clone() *MockConn {
 		MockBegin:                c.MockBegin,
 		MockCommit:               c.MockCommit,
 		MockRollback:             c.MockRollback,
+		MockSetSessionVars:       c.MockSetSessionVars,
+		MockSetTimeouts:          c.MockSetTimeouts,
 		MockListenOnChannel:      c.MockListenOnChannel,
 		MockUnlistenChannel:      c.MockUnlistenChannel,
 		MockIsListeningOnChannel: c.MockIsListeningOnChannel,
@@ -513,6 +519,24 @@ func (c *MockConn) Rollback() error {
 	return c.MockRollback()
 }
 
+// SetSessionVars implements SessionVarsSetter by calling MockSetSessionVars
+// or returning a reset function that does nothing if MockSetSessionVars is nil.
+func (c *MockConn) SetSessionVars(ctx context.Context, vars map[string]string) (reset func(context.Context) error, err error) {
+	if c.MockSetSessionVars == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+	return c.MockSetSessionVars(ctx, vars)
+}
+
+// SetTimeouts implements TimeoutSetter by calling MockSetTimeouts
+// or returning a reset function that does nothing if MockSetTimeouts is nil.
+func (c *MockConn) SetTimeouts(ctx context.Context, queryTimeout, transactionTimeout time.Duration) (reset func(context.Context) error, err error) {
+	if c.MockSetTimeouts == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+	return c.MockSetTimeouts(ctx, queryTimeout, transactionTimeout)
+}
+
 // ListenOnChannel implements ListenerConnection by registering
 // the channel in ListeningOn and calling MockListenOnChannel
 // or returning nil if MockListenOnChannel is nil.
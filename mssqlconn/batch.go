@@ -0,0 +1,225 @@
+package mssqlconn
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/domonda/go-sqldb"
+)
+
+var (
+	_ sqldb.BatchExecer = new(connection)
+	_ sqldb.BatchExecer = new(transaction)
+	_ sqldb.BatchExecer = new(pinnedConn)
+)
+
+// ExecBatch implements [sqldb.BatchExecer], see execBatch.
+func (conn *connection) ExecBatch(ctx context.Context, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	if !canExecBatch(statements) {
+		return sqldb.ExecBatchSequentially(ctx, conn, statements)
+	}
+	return execBatch(ctx, conn.db, statements)
+}
+
+// ExecBatch implements [sqldb.BatchExecer], see execBatch.
+func (conn *transaction) ExecBatch(ctx context.Context, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	if !canExecBatch(statements) {
+		return sqldb.ExecBatchSequentially(ctx, conn, statements)
+	}
+	return execBatch(ctx, conn.tx, statements)
+}
+
+// ExecBatch implements [sqldb.BatchExecer], see execBatch.
+func (conn *pinnedConn) ExecBatch(ctx context.Context, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	if !canExecBatch(statements) {
+		return sqldb.ExecBatchSequentially(ctx, conn, statements)
+	}
+	return execBatch(ctx, conn.conn, statements)
+}
+
+// canExecBatch returns false for statements with named arguments
+// because the same name could be used by different statements.
+func canExecBatch(statements []sqldb.BatchStatement) bool {
+	if len(statements) < 2 {
+		return false
+	}
+	for _, stmt := range statements {
+		for _, arg := range stmt.Args {
+			if _, ok := arg.(sql.NamedArg); ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// execBatch executes the statements in as few round trips as possible
+// with at most [QueryFormatter.MaxArgs] arguments per round trip.
+// The statements of a round trip are joined to one batch
+// with renumbered @pN placeholders where every exec statement
+// is followed by SELECT @@ROWCOUNT so that every statement
+// returns one result set for [sqldb.ReadBatchResultSets].
+// The batch is wrapped in TRY/CATCH with THROW to stop
+// executing the statements at the first error,
+// because SQL Server continues after most statement errors.
+func execBatch(ctx context.Context, conn queryer, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	results := make([]sqldb.BatchResult, 0, len(statements))
+	for len(statements) > 0 {
+		n := batchChunkLen(statements, QueryFormatter{}.MaxArgs())
+		chunk := statements[:n]
+		statements = statements[n:]
+
+		var (
+			chunkResults []sqldb.BatchResult
+			aborted      bool
+		)
+		query, args := batchQuery(chunk)
+		rows, err := conn.QueryContext(ctx, query, args...)
+		if err != nil {
+			chunkResults = make([]sqldb.BatchResult, len(chunk))
+			sqldb.AbortBatchResults(chunkResults, 0, err)
+			aborted = true
+		} else {
+			chunkResults, aborted = sqldb.ReadBatchResultSets(rows, chunk)
+		}
+		for i := range chunkResults {
+			chunkResults[i].Err = wrapKnownErrors(chunkResults[i].Err)
+		}
+		results = append(results, chunkResults...)
+		if aborted {
+			for range statements {
+				results = append(results, sqldb.BatchResult{RowsAffected: -1, Err: sqldb.ErrBatchAborted})
+			}
+			break
+		}
+	}
+	return results
+}
+
+// batchChunkLen returns the number of statements
+// from the start that have at most maxArgs arguments,
+// but at least one statement.
+func batchChunkLen(statements []sqldb.BatchStatement, maxArgs int) int {
+	numArgs := 0
+	for i, stmt := range statements {
+		numArgs += len(stmt.Args)
+		if numArgs > maxArgs {
+			return max(i, 1)
+		}
+	}
+	return len(statements)
+}
+
+// batchQuery joins the statements to one batch with renumbered placeholders.
+// The statements are separated by semicolons on their own lines
+// so that a trailing line comment can't comment out the separator.
+func batchQuery(statements []sqldb.BatchStatement) (query string, args []any) {
+	var b strings.Builder
+	b.WriteString("BEGIN TRY\n")
+	for _, stmt := range statements {
+		b.WriteString(offsetPlaceholders(strings.TrimRight(stmt.Query, "; \t\r\n"), len(args)))
+		b.WriteString("\n;\n")
+		if stmt.ScanRows == nil {
+			b.WriteString("SELECT @@ROWCOUNT;\n")
+		}
+		args = append(args, stmt.Args...)
+	}
+	b.WriteString("END TRY\nBEGIN CATCH\nTHROW;\nEND CATCH")
+	return b.String(), args
+}
+
+// offsetPlaceholders adds offset to the numbers of all @pN placeholders
+// in the query that are not within string literals, quoted identifiers,
+// or comments.
+func offsetPlaceholders(query string, offset int) string {
+	if offset == 0 {
+		return query
+	}
+	var b strings.Builder
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == '\'' || c == '"' || c == '[':
+			// String literal or quoted identifier, closing quotes are escaped by doubling
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			j := i + 1
+			for j < len(query) {
+				if query[j] == end {
+					if j+1 < len(query) && query[j+1] == end {
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				j++
+			}
+			b.WriteString(query[i:j])
+			i = j
+
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			j := strings.IndexByte(query[i:], '\n')
+			if j < 0 {
+				j = len(query) - i
+			}
+			b.WriteString(query[i : i+j])
+			i += j
+
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			// Block comments can be nested in T-SQL
+			depth, j := 1, i+2
+			for j < len(query) && depth > 0 {
+				switch {
+				case strings.HasPrefix(query[j:], "/*"):
+					depth++
+					j += 2
+				case strings.HasPrefix(query[j:], "*/"):
+					depth--
+					j += 2
+				default:
+					j++
+				}
+			}
+			b.WriteString(query[i:j])
+			i = j
+
+		case c == '@' && i+2 < len(query) && (query[i+1] == 'p' || query[i+1] == 'P') && isDigit(query[i+2]) &&
+			(i == 0 || !isIdentifierChar(query[i-1])):
+			j := i + 2
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			if j < len(query) && isIdentifierChar(query[j]) {
+				// Variable like @p1x
+				b.WriteString(query[i:j])
+				i = j
+				continue
+			}
+			n, _ := strconv.Atoi(query[i+2 : j])
+			b.WriteString(query[i : i+2])
+			b.WriteString(strconv.Itoa(n + offset))
+			i = j
+
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '@' || c == '#' || c == '$' || isDigit(c) || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
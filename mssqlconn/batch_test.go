@@ -0,0 +1,70 @@
+package mssqlconn
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func Test_offsetPlaceholders(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		offset int
+		want   string
+	}{
+		{name: "no offset", query: "SELECT @p1", offset: 0, want: "SELECT @p1"},
+		{name: "placeholders", query: "UPDATE t SET a = @p1, b = @P2 WHERE id = @p10", offset: 3, want: "UPDATE t SET a = @p4, b = @P5 WHERE id = @p13"},
+		{name: "string literal", query: "SELECT 'it''s @p1', @p1", offset: 1, want: "SELECT 'it''s @p1', @p2"},
+		{name: "unicode string literal", query: "SELECT N'@p1' + @p1", offset: 1, want: "SELECT N'@p1' + @p2"},
+		{name: "quoted identifiers", query: `SELECT [@p1]]x], "@p1" FROM t WHERE a = @p1`, offset: 2, want: `SELECT [@p1]]x], "@p1" FROM t WHERE a = @p3`},
+		{name: "line comment", query: "SELECT @p1 -- @p1\n, @p2", offset: 1, want: "SELECT @p2 -- @p1\n, @p3"},
+		{name: "nested block comment", query: "SELECT /* @p1 /* @p1 */ @p1 */ @p1", offset: 1, want: "SELECT /* @p1 /* @p1 */ @p1 */ @p2"},
+		{name: "variables", query: "SELECT @@ROWCOUNT, @param, @p1x, @@p1, x@p1", offset: 1, want: "SELECT @@ROWCOUNT, @param, @p1x, @@p1, x@p1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, offsetPlaceholders(tt.query, tt.offset))
+		})
+	}
+}
+
+func Test_batchQuery(t *testing.T) {
+	statements := []sqldb.BatchStatement{
+		{Query: "UPDATE t SET a = @p1 WHERE id = @p2;", Args: []any{1, 2}},
+		{Query: "SELECT * FROM t WHERE id = @p1 -- comment", Args: []any{2}, ScanRows: func(sqldb.Rows) error { return nil }},
+	}
+	query, args := batchQuery(statements)
+	assert.Equal(t,
+		"BEGIN TRY\n"+
+			"UPDATE t SET a = @p1 WHERE id = @p2\n;\n"+
+			"SELECT @@ROWCOUNT;\n"+
+			"SELECT * FROM t WHERE id = @p3 -- comment\n;\n"+
+			"END TRY\nBEGIN CATCH\nTHROW;\nEND CATCH",
+		query,
+	)
+	assert.Equal(t, []any{1, 2, 2}, args)
+}
+
+func Test_batchChunkLen(t *testing.T) {
+	statements := []sqldb.BatchStatement{
+		{Args: []any{1, 2}},
+		{Args: []any{3, 4}},
+		{Args: []any{5}},
+	}
+	assert.Equal(t, 3, batchChunkLen(statements, 5))
+	assert.Equal(t, 2, batchChunkLen(statements, 4))
+	assert.Equal(t, 1, batchChunkLen(statements, 3))
+	assert.Equal(t, 1, batchChunkLen(statements, 1), "at least one statement")
+}
+
+func Test_canExecBatch(t *testing.T) {
+	stmt := sqldb.BatchStatement{Query: "SELECT @p1", Args: []any{1}}
+	assert.False(t, canExecBatch([]sqldb.BatchStatement{stmt}), "single statement")
+	assert.True(t, canExecBatch([]sqldb.BatchStatement{stmt, stmt}))
+	named := sqldb.BatchStatement{Query: "SELECT @id", Args: []any{sql.Named("id", 1)}}
+	assert.False(t, canExecBatch([]sqldb.BatchStatement{stmt, named}), "named arguments")
+}
@@ -0,0 +1,115 @@
+package mssqlconn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	mssql "github.com/microsoft/go-mssqldb"
+
+	"github.com/domonda/go-sqldb"
+)
+
+var (
+	_ sqldb.BulkCopier = new(connection)
+	_ sqldb.BulkCopier = new(transaction)
+	_ sqldb.BulkCopier = new(pinnedConn)
+)
+
+// CopyFrom implements [sqldb.BulkCopier] using the TDS bulk copy protocol
+// within a transaction that is committed after all rows were copied.
+func (conn *connection) CopyFrom(ctx context.Context, table string, columns []string, rows sqldb.CopyRowSource) (int64, error) {
+	tx, err := conn.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, wrapKnownErrors(err)
+	}
+	return copyFromInTx(ctx, tx, table, columns, rows)
+}
+
+// CopyFrom implements [sqldb.BulkCopier] using the TDS bulk copy protocol
+// within the transaction.
+func (conn *transaction) CopyFrom(ctx context.Context, table string, columns []string, rows sqldb.CopyRowSource) (int64, error) {
+	return copyFrom(ctx, conn.tx, table, columns, rows)
+}
+
+// CopyFrom implements [sqldb.BulkCopier] using the TDS bulk copy protocol
+// within a transaction on the pinned session
+// that is committed after all rows were copied.
+func (conn *pinnedConn) CopyFrom(ctx context.Context, table string, columns []string, rows sqldb.CopyRowSource) (int64, error) {
+	tx, err := conn.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, wrapKnownErrors(err)
+	}
+	return copyFromInTx(ctx, tx, table, columns, rows)
+}
+
+// copyFromInTx calls copyFrom and commits tx on success,
+// or rolls it back on error.
+func copyFromInTx(ctx context.Context, tx *sql.Tx, table string, columns []string, rows sqldb.CopyRowSource) (int64, error) {
+	numRows, err := copyFrom(ctx, tx, table, columns, rows)
+	if err != nil {
+		return 0, errors.Join(err, tx.Rollback())
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, wrapKnownErrors(err)
+	}
+	return numRows, nil
+}
+
+// copyFrom streams the rows to the server with the bulk copy
+// support of go-mssqldb that sends rows in batches.
+func copyFrom(ctx context.Context, tx *sql.Tx, table string, columns []string, rows sqldb.CopyRowSource) (numRows int64, err error) {
+	query, err := copyFromQuery(table, columns)
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, wrapKnownErrors(err)
+	}
+	defer func() {
+		err = errors.Join(err, wrapKnownErrors(stmt.Close()))
+	}()
+
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return 0, err
+		}
+		if len(vals) != len(columns) {
+			return 0, fmt.Errorf("got %d values for %d columns", len(vals), len(columns))
+		}
+		if _, err = stmt.ExecContext(ctx, vals...); err != nil {
+			return 0, wrapKnownErrors(err)
+		}
+		numRows++
+	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	// Executing without arguments sends the
+	// remaining rows and ends the bulk copy
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return 0, wrapKnownErrors(err)
+	}
+	return numRows, nil
+}
+
+// copyFromQuery returns the go-mssqldb bulk copy statement.
+// The table name is escaped because the driver uses it in SQL,
+// but the column names are only validated because the driver
+// matches them against the column metadata of the table.
+func copyFromQuery(table string, columns []string) (string, error) {
+	var fmtr QueryFormatter
+	tableName, err := fmtr.FormatTableName(table)
+	if err != nil {
+		return "", err
+	}
+	for _, column := range columns {
+		if _, err := fmtr.FormatColumnName(column); err != nil {
+			return "", err
+		}
+	}
+	return mssql.CopyIn(tableName, mssql.BulkOptions{}, columns...), nil
+}
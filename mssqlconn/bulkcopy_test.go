@@ -0,0 +1,27 @@
+package mssqlconn
+
+import (
+	"testing"
+
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_copyFromQuery(t *testing.T) {
+	t.Run("table", func(t *testing.T) {
+		query, err := copyFromQuery("dbo.users", []string{"id", "name"})
+		require.NoError(t, err)
+		assert.Equal(t, mssql.CopyIn("dbo.users", mssql.BulkOptions{}, "id", "name"), query)
+	})
+
+	t.Run("invalid table", func(t *testing.T) {
+		_, err := copyFromQuery("users; DROP TABLE users", []string{"id"})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid column", func(t *testing.T) {
+		_, err := copyFromQuery("users", []string{"id", "a]b"})
+		assert.Error(t, err)
+	})
+}
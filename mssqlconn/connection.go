@@ -45,7 +45,7 @@ func Connect(ctx context.Context, config *sqldb.Config) (sqldb.Connection, error
 		}
 		return nil, err
 	}
-	return &connection{db: db, config: config}, nil
+	return sqldb.WithDefaultTimeouts(&connection{db: db, config: config}), nil
 }
 
 // formatDSN converts a sqldb.Config to a SQL Server connection URL.
@@ -30,6 +30,8 @@ The connection uses @p1, @p2, ... placeholders and bracket identifier quoting ([
 SQL Server-specific features:
   - Pinned sessions via the sqldb.ConnPinner interface (Conn) for
     session-scoped state like sp_getapplock application locks
+  - TDS bulk copy via the sqldb.BulkCopier interface
+  - Single round trip sqldb.Batch execution via the sqldb.BatchExecer interface
   - Default isolation level is sql.LevelReadCommitted
   - EscapeIdentifier wraps identifiers in brackets when needed
   - DropAll, DropAllTables, and DropAllTypes for resetting test databases
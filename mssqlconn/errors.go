@@ -1,7 +1,9 @@
 package mssqlconn
 
 import (
+	"database/sql/driver"
 	"errors"
+	"io"
 	"slices"
 	"strings"
 
@@ -16,8 +18,10 @@ const (
 	errCannotInsertNull   = 515   // Cannot insert NULL into column
 	errConstraintConflict = 547   // Statement conflicted with FK or CHECK constraint
 	errDeadlock           = 1205  // Deadlock detected
+	errLockRequestTimeout = 1222  // Lock request time out period exceeded
 	errDupKeyRow          = 2601  // Cannot insert duplicate key row (unique index)
 	errRaisedException    = 50000 // User-defined error from RAISERROR/THROW
+	errSnapshotConflict   = 3960  // Snapshot isolation transaction aborted due to update conflict
 	errUniqueConstraint   = 2627  // Violation of UNIQUE KEY or PRIMARY KEY constraint
 )
 
@@ -27,6 +31,9 @@ func wrapKnownErrors(err error) error {
 	}
 	var e mssql.Error
 	if !errors.As(err, &e) {
+		if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return errors.Join(sqldb.ErrConnectionLost, err)
+		}
 		return err
 	}
 	msg := e.Message
@@ -44,6 +51,10 @@ func wrapKnownErrors(err error) error {
 		return errors.Join(sqldb.ErrCheckViolation{Constraint: constraint}, err)
 	case errDeadlock:
 		return errors.Join(sqldb.ErrDeadlock, err)
+	case errLockRequestTimeout:
+		return errors.Join(sqldb.ErrLockTimeout, err)
+	case errSnapshotConflict:
+		return errors.Join(sqldb.ErrSerializationFailure, err)
 	case errRaisedException:
 		return errors.Join(sqldb.ErrRaisedException{Message: msg}, err)
 	case errDupKeyRow:
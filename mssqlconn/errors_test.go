@@ -1,6 +1,7 @@
 package mssqlconn
 
 import (
+	"database/sql/driver"
 	"errors"
 	"testing"
 
@@ -17,6 +18,7 @@ func Test_wrapKnownErrors(t *testing.T) {
 		wantNil         bool
 		wantUnchanged   bool
 		wantDeadlock    bool
+		wantSentinel    error
 		wantRaised      *sqldb.ErrRaisedException
 		wantNotNull     *sqldb.ErrNotNullViolation
 		wantUnique      *sqldb.ErrUniqueViolation
@@ -39,6 +41,23 @@ func Test_wrapKnownErrors(t *testing.T) {
 			err:           mssql.Error{Number: 99999, Message: "Unknown error"},
 			wantUnchanged: true,
 		},
+		{
+			name:            "errLockRequestTimeout (1222) wraps as ErrLockTimeout",
+			err:             mssql.Error{Number: 1222, Message: "Lock request time out period exceeded."},
+			wantSentinel:    sqldb.ErrLockTimeout,
+			wantOriginalErr: true,
+		},
+		{
+			name:            "errSnapshotConflict (3960) wraps as ErrSerializationFailure",
+			err:             mssql.Error{Number: 3960, Message: "Snapshot isolation transaction aborted due to update conflict."},
+			wantSentinel:    sqldb.ErrSerializationFailure,
+			wantOriginalErr: true,
+		},
+		{
+			name:         "driver.ErrBadConn wraps as ErrConnectionLost",
+			err:          driver.ErrBadConn,
+			wantSentinel: sqldb.ErrConnectionLost,
+		},
 		{
 			name:            "errCannotInsertNull (515) wraps as ErrNotNullViolation with column name",
 			err:             mssql.Error{Number: 515, Message: "Cannot insert the value NULL into column 'email', table 'mydb.dbo.users'; column does not allow nulls. INSERT fails."},
@@ -104,6 +123,9 @@ func Test_wrapKnownErrors(t *testing.T) {
 			if scenario.wantDeadlock {
 				assert.ErrorIs(t, result, sqldb.ErrDeadlock)
 			}
+			if scenario.wantSentinel != nil {
+				assert.ErrorIs(t, result, scenario.wantSentinel)
+			}
 			if scenario.wantRaised != nil {
 				var target sqldb.ErrRaisedException
 				assert.ErrorAs(t, result, &target)
@@ -0,0 +1,229 @@
+package mssqlconn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/domonda/go-sqldb"
+)
+
+var (
+	_ sqldb.InsertRowsReturner = new(connection)
+	_ sqldb.InsertRowsReturner = new(transaction)
+	_ sqldb.InsertRowsReturner = new(pinnedConn)
+)
+
+// rowIndexColumn is the column of the MERGE source rows
+// with the index of the row that is output with the inserted values.
+const rowIndexColumn = "sqldb_row_index"
+
+// outputTableVar is the table variable the OUTPUT clause writes into.
+const outputTableVar = "@sqldb_inserted"
+
+// InsertRowsReturningInto implements [sqldb.InsertRowsReturner], see insertRowsReturningInto.
+func (conn *connection) InsertRowsReturningInto(ctx context.Context, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	return insertRowsReturningInto(ctx, conn.db, table, columns, numRows, vals, returningColumns, dest)
+}
+
+// InsertRowsReturningInto implements [sqldb.InsertRowsReturner], see insertRowsReturningInto.
+func (conn *transaction) InsertRowsReturningInto(ctx context.Context, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	return insertRowsReturningInto(ctx, conn.tx, table, columns, numRows, vals, returningColumns, dest)
+}
+
+// InsertRowsReturningInto implements [sqldb.InsertRowsReturner], see insertRowsReturningInto.
+func (conn *pinnedConn) InsertRowsReturningInto(ctx context.Context, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	return insertRowsReturningInto(ctx, conn.conn, table, columns, numRows, vals, returningColumns, dest)
+}
+
+// insertRowsReturningInto inserts the rows with a MERGE statement
+// whose OUTPUT clause writes the INSERTED values together with
+// the index of the source row into a table variable,
+// because OUTPUT without INTO is not allowed for tables with enabled triggers.
+// The rows of the table variable are then selected ordered by the row index,
+// because the order of OUTPUT rows is not guaranteed to match
+// the order of the inserted rows.
+func insertRowsReturningInto(ctx context.Context, conn queryer, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) (err error) {
+	returningTypes, err := returningColumnTypes(ctx, conn, QueryFormatter{}, table, returningColumns)
+	if err != nil {
+		return err
+	}
+	query, err := insertRowsReturningQuery(QueryFormatter{}, table, columns, numRows, returningColumns, returningTypes)
+	if err != nil {
+		return err
+	}
+	rows, err := conn.QueryContext(ctx, query, vals...)
+	if err != nil {
+		return wrapKnownErrors(err)
+	}
+	defer func() {
+		err = errors.Join(err, wrapKnownErrors(rows.Close()))
+	}()
+
+	// The row index is scanned first to get the destinations
+	// for the values of the row that are scanned in a second pass
+	placeholders := make([]any, 1+len(returningColumns))
+	for i := range placeholders {
+		placeholders[i] = new(any)
+	}
+	scanned := make([]bool, numRows)
+	for rows.Next() {
+		var rowIndex int
+		placeholders[0] = &rowIndex
+		if err := rows.Scan(placeholders...); err != nil {
+			return wrapKnownErrors(err)
+		}
+		if rowIndex < 0 || rowIndex >= numRows || scanned[rowIndex] {
+			return fmt.Errorf("invalid OUTPUT row index %d for %d inserted rows", rowIndex, numRows)
+		}
+		scanned[rowIndex] = true
+		if err := rows.Scan(append([]any{new(any)}, dest(rowIndex)...)...); err != nil {
+			return wrapKnownErrors(err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return wrapKnownErrors(err)
+	}
+	for rowIndex, ok := range scanned {
+		if !ok {
+			return fmt.Errorf("no OUTPUT row for inserted row %d", rowIndex)
+		}
+	}
+	return nil
+}
+
+// returningColumnTypes returns the SQL types of the returningColumns
+// of the table for the declaration of the OUTPUT table variable.
+// A rowversion column is returned as binary(8)
+// because rowversion values can't be inserted into a table variable.
+func returningColumnTypes(ctx context.Context, conn queryer, formatter sqldb.QueryFormatter, table string, returningColumns []sqldb.ColumnInfo) (types []string, err error) {
+	query, err := selectColumnsQuery(formatter, table, returningColumns)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx,
+		/*sql*/ `SELECT system_type_name FROM sys.dm_exec_describe_first_result_set(@p1, NULL, 0) ORDER BY column_ordinal`,
+		query,
+	)
+	if err != nil {
+		return nil, wrapKnownErrors(err)
+	}
+	defer func() {
+		err = errors.Join(err, wrapKnownErrors(rows.Close()))
+	}()
+	for rows.Next() {
+		var typ string
+		if err := rows.Scan(&typ); err != nil {
+			return nil, wrapKnownErrors(err)
+		}
+		if strings.EqualFold(typ, "timestamp") || strings.EqualFold(typ, "rowversion") {
+			typ = "binary(8)"
+		}
+		types = append(types, typ)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapKnownErrors(err)
+	}
+	if len(types) != len(returningColumns) {
+		return nil, fmt.Errorf("got %d types for %d returning columns of table %s", len(types), len(returningColumns), table)
+	}
+	return types, nil
+}
+
+// selectColumnsQuery returns a SELECT query for the columns of the table.
+func selectColumnsQuery(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo) (string, error) {
+	fmtTable, err := formatter.FormatTableName(table)
+	if err != nil {
+		return "", err
+	}
+	colNames := make([]string, len(columns))
+	for i := range columns {
+		colNames[i], err = formatter.FormatColumnName(columns[i].Name)
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf(`SELECT %s FROM %s`, strings.Join(colNames, ","), fmtTable), nil
+}
+
+// insertRowsReturningQuery builds a MERGE statement that never matches
+// to insert numRows rows and output the returningColumns of the
+// inserted rows after the index of the source row into a table variable
+// declared with returningTypes, followed by a SELECT of the table variable
+// ordered by the row index:
+//
+//	DECLARE @sqldb_inserted TABLE(sqldb_row_index int,id int,created_at datetime2(7));
+//	MERGE INTO table AS target
+//	USING (VALUES(@p1,@p2,0),(@p3,@p4,1)) AS source(col1,col2,sqldb_row_index)
+//	ON 1 = 0
+//	WHEN NOT MATCHED THEN INSERT (col1,col2) VALUES (source.col1,source.col2)
+//	OUTPUT source.sqldb_row_index,INSERTED.id,INSERTED.created_at INTO @sqldb_inserted;
+//	SELECT sqldb_row_index,id,created_at FROM @sqldb_inserted ORDER BY sqldb_row_index;
+//
+// Unlike INSERT, MERGE can reference source columns in the OUTPUT clause.
+func insertRowsReturningQuery(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo, numRows int, returningColumns []sqldb.ColumnInfo, returningTypes []string) (string, error) {
+	if numRows < 1 {
+		return "", fmt.Errorf("InsertRowsReturning: numRows must be >= 1, got %d", numRows)
+	}
+	if len(returningTypes) != len(returningColumns) {
+		return "", fmt.Errorf("InsertRowsReturning: %d types for %d returning columns", len(returningTypes), len(returningColumns))
+	}
+	fmtTable, err := formatter.FormatTableName(table)
+	if err != nil {
+		return "", err
+	}
+	colNames := make([]string, len(columns))
+	for i := range columns {
+		colNames[i], err = formatter.FormatColumnName(columns[i].Name)
+		if err != nil {
+			return "", err
+		}
+	}
+	returningNames := make([]string, len(returningColumns))
+	for i := range returningColumns {
+		returningNames[i], err = formatter.FormatColumnName(returningColumns[i].Name)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var q strings.Builder
+	fmt.Fprintf(&q, `DECLARE %s TABLE(%s int`, outputTableVar, rowIndexColumn)
+	for i, name := range returningNames {
+		fmt.Fprintf(&q, `,%s %s`, name, returningTypes[i])
+	}
+	q.WriteString(`); `)
+
+	fmt.Fprintf(&q, `MERGE INTO %s AS target USING (VALUES`, fmtTable)
+	for row := range numRows {
+		if row > 0 {
+			q.WriteByte(',')
+		}
+		q.WriteByte('(')
+		for col := range columns {
+			q.WriteString(formatter.FormatPlaceholder(row*len(columns) + col))
+			q.WriteByte(',')
+		}
+		fmt.Fprintf(&q, `%d)`, row)
+	}
+	fmt.Fprintf(&q, `) AS source(%s,%s) ON 1 = 0`, strings.Join(colNames, ","), rowIndexColumn)
+
+	fmt.Fprintf(&q, ` WHEN NOT MATCHED THEN INSERT (%s) VALUES (`, strings.Join(colNames, ","))
+	for i, colName := range colNames {
+		if i > 0 {
+			q.WriteByte(',')
+		}
+		fmt.Fprintf(&q, `source.%s`, colName)
+	}
+	fmt.Fprintf(&q, `) OUTPUT source.%s`, rowIndexColumn)
+	for _, name := range returningNames {
+		fmt.Fprintf(&q, `,INSERTED.%s`, name)
+	}
+	// MERGE requires a trailing semicolon in MSSQL
+	fmt.Fprintf(&q, ` INTO %s;`, outputTableVar)
+
+	fmt.Fprintf(&q, ` SELECT %s,%s FROM %s ORDER BY %s;`, rowIndexColumn, strings.Join(returningNames, ","), outputTableVar, rowIndexColumn)
+
+	return q.String(), nil
+}
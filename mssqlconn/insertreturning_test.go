@@ -0,0 +1,46 @@
+package mssqlconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func Test_insertRowsReturningQuery(t *testing.T) {
+	columns := []sqldb.ColumnInfo{{Name: "name"}, {Name: "user"}}
+
+	t.Run("multiple rows", func(t *testing.T) {
+		query, err := insertRowsReturningQuery(testFormatter, "accounts", columns, 2, []sqldb.ColumnInfo{{Name: "id"}, {Name: "created_at"}}, []string{"int", "datetime2(7)"})
+		require.NoError(t, err)
+		assert.Equal(t, `DECLARE @sqldb_inserted TABLE(sqldb_row_index int,id int,created_at datetime2(7));`+
+			` MERGE INTO accounts AS target`+
+			` USING (VALUES(@p1,@p2,0),(@p3,@p4,1)) AS source(name,[user],sqldb_row_index) ON 1 = 0`+
+			` WHEN NOT MATCHED THEN INSERT (name,[user]) VALUES (source.name,source.[user])`+
+			` OUTPUT source.sqldb_row_index,INSERTED.id,INSERTED.created_at INTO @sqldb_inserted;`+
+			` SELECT sqldb_row_index,id,created_at FROM @sqldb_inserted ORDER BY sqldb_row_index;`, query)
+	})
+
+	t.Run("invalid numRows", func(t *testing.T) {
+		_, err := insertRowsReturningQuery(testFormatter, "accounts", columns, 0, []sqldb.ColumnInfo{{Name: "id"}}, []string{"int"})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid returning column", func(t *testing.T) {
+		_, err := insertRowsReturningQuery(testFormatter, "accounts", columns, 1, []sqldb.ColumnInfo{{Name: "id; DROP TABLE accounts"}}, []string{"int"})
+		require.Error(t, err)
+	})
+
+	t.Run("missing returning types", func(t *testing.T) {
+		_, err := insertRowsReturningQuery(testFormatter, "accounts", columns, 1, []sqldb.ColumnInfo{{Name: "id"}}, nil)
+		require.Error(t, err)
+	})
+}
+
+func Test_selectColumnsQuery(t *testing.T) {
+	query, err := selectColumnsQuery(testFormatter, "accounts", []sqldb.ColumnInfo{{Name: "id"}, {Name: "user"}})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT id,[user] FROM accounts`, query)
+}
@@ -2,6 +2,7 @@ package mssqlconn
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/domonda/go-sqldb"
@@ -9,11 +10,16 @@ import (
 
 var _ sqldb.QueryBuilder = (*QueryBuilder)(nil)
 var _ sqldb.UpsertQueryBuilder = (*QueryBuilder)(nil)
+var _ sqldb.UpsertOptionsQueryBuilder = (*QueryBuilder)(nil)
+var _ sqldb.PageQueryBuilder = (*QueryBuilder)(nil)
+var _ sqldb.SoftDeleteQueryBuilder = (*QueryBuilder)(nil)
+var _ sqldb.RelationQueryBuilder = (*QueryBuilder)(nil)
 
 // QueryBuilder implements [sqldb.QueryBuilder] and [sqldb.UpsertQueryBuilder]
 // using MSSQL-specific syntax.
 // It embeds [sqldb.StdQueryBuilder] for standard SQL operations
-// and overrides upsert methods with MSSQL MERGE syntax.
+// and overrides upsert methods with MSSQL MERGE syntax
+// and [sqldb.PageQueryBuilder.QueryPage] with OFFSET/FETCH.
 // It does not implement [sqldb.ReturningQueryBuilder].
 type QueryBuilder struct {
 	sqldb.StdQueryBuilder
@@ -34,37 +40,70 @@ func (b QueryBuilder) InsertUnique(formatter sqldb.QueryFormatter, table string,
 		conflictCols[i] = strings.TrimSpace(conflictCols[i])
 	}
 
-	return b.buildMerge(formatter, table, columns, conflictCols, false)
+	return b.buildMerge(formatter, table, columns, conflictCols, nil, "")
 }
 
 // Upsert builds a MERGE statement that inserts a new row or updates
 // an existing one when the primary key columns conflict.
 func (b QueryBuilder) Upsert(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo) (query string, err error) {
-	hasNonPK := false
-	for i := range columns {
-		if !columns[i].PrimaryKey {
-			hasNonPK = true
-			break
-		}
+	return b.UpsertWithOptions(formatter, table, columns, sqldb.UpsertOptions{})
+}
+
+// UpsertWithOptions builds a MERGE statement with the conflict columns
+// of the options as MERGE ON keys that updates the update columns
+// and update expressions of the options when matched.
+// Update expressions and the WHERE condition reference the existing row
+// as target.column and the proposed row as source.column.
+// If the options have a VersionColumn, the existing row is only updated
+// if its version is the proposed version minus one.
+// See [sqldb.UpsertOptions] for the full contract and security model.
+func (b QueryBuilder) UpsertWithOptions(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo, options sqldb.UpsertOptions) (query string, err error) {
+	conflictCols, assignments, err := options.Resolve(columns)
+	if err != nil {
+		return "", err
 	}
-	if !hasNonPK {
-		return "", fmt.Errorf("Upsert requires at least one non-primary-key column")
+	where := options.Where
+	if options.VersionColumn != "" {
+		versionColumn, err := formatter.FormatColumnName(options.VersionColumn)
+		if err != nil {
+			return "", err
+		}
+		where = options.VersionWhere("target."+versionColumn, "source."+versionColumn)
 	}
+	return b.buildMerge(formatter, table, columns, conflictCols, assignments, where)
+}
 
-	var pkCols []string
-	for i := range columns {
-		if columns[i].PrimaryKey {
-			pkCols = append(pkCols, columns[i].Name)
+// QueryPage builds a keyset pagination query with the keyset condition
+// expanded to an OR-chain because MSSQL does not support row value
+// comparisons, and OFFSET 0 ROWS FETCH NEXT limit ROWS ONLY
+// instead of LIMIT. See [sqldb.PageQueryBuilder] for the contract.
+func (QueryBuilder) QueryPage(formatter sqldb.QueryFormatter, query string, args []any, orderBy []sqldb.OrderByColumn, after []any, limit int) (pageQuery string, pageArgs []any, err error) {
+	if limit < 1 {
+		return "", nil, fmt.Errorf("QueryPage: limit must be >= 1, got %d", limit)
+	}
+	var q strings.Builder
+	fmt.Fprintf(&q, `SELECT * FROM (%s) %s`, query, sqldb.PageDerivedTableName)
+	pageArgs = args
+	if after != nil {
+		condition, conditionArgs, err := sqldb.KeysetCondition(formatter, orderBy, after, len(args), false)
+		if err != nil {
+			return "", nil, err
 		}
+		fmt.Fprintf(&q, ` WHERE %s`, condition)
+		pageArgs = append(slices.Clip(args), conditionArgs...)
 	}
-
-	return b.buildMerge(formatter, table, columns, pkCols, true)
+	orderByClause, err := sqldb.OrderByClause(formatter, orderBy)
+	if err != nil {
+		return "", nil, err
+	}
+	fmt.Fprintf(&q, ` ORDER BY %s OFFSET 0 ROWS FETCH NEXT %d ROWS ONLY`, orderByClause, limit)
+	return q.String(), pageArgs, nil
 }
 
 // buildMerge generates a MERGE INTO ... USING ... statement.
-// If withUpdate is true, a WHEN MATCHED THEN UPDATE clause is added
-// for non-primary-key columns.
-func (QueryBuilder) buildMerge(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo, conflictCols []string, withUpdate bool) (string, error) {
+// If assignments are passed, a WHEN MATCHED THEN UPDATE clause is added
+// that is restricted by the where condition if not empty.
+func (QueryBuilder) buildMerge(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo, conflictCols []string, assignments []sqldb.UpsertAssignment, where string) (string, error) {
 	fmtTable, err := formatter.FormatTableName(table)
 	if err != nil {
 		return "", err
@@ -109,24 +148,26 @@ func (QueryBuilder) buildMerge(formatter sqldb.QueryFormatter, table string, col
 		fmt.Fprintf(&q, `target.%s = source.%s`, colName, colName)
 	}
 
-	// WHEN MATCHED THEN UPDATE SET (only if withUpdate)
-	if withUpdate {
-		q.WriteString(` WHEN MATCHED THEN UPDATE SET`)
-		first := true
-		for i := range columns {
-			if columns[i].PrimaryKey {
-				continue
-			}
-			if first {
-				first = false
-			} else {
+	// WHEN MATCHED [AND (where)] THEN UPDATE SET (only with assignments)
+	if len(assignments) > 0 {
+		q.WriteString(` WHEN MATCHED`)
+		if where != "" {
+			fmt.Fprintf(&q, ` AND (%s)`, where)
+		}
+		q.WriteString(` THEN UPDATE SET`)
+		for i, assignment := range assignments {
+			if i > 0 {
 				q.WriteByte(',')
 			}
-			colName, err := formatter.FormatColumnName(columns[i].Name)
+			colName, err := formatter.FormatColumnName(assignment.Column)
 			if err != nil {
 				return "", err
 			}
-			fmt.Fprintf(&q, ` target.%s = source.%s`, colName, colName)
+			value := assignment.Expression
+			if assignment.ColumnIndex >= 0 {
+				value = "source." + colName
+			}
+			fmt.Fprintf(&q, ` target.%s = %s`, colName, value)
 		}
 	}
 
@@ -1,6 +1,7 @@
 package mssqlconn
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/domonda/go-sqldb"
@@ -68,6 +69,81 @@ func TestQueryBuilder_Upsert(t *testing.T) {
 	})
 }
 
+func TestQueryBuilder_UpsertWithOptions(t *testing.T) {
+	b := QueryBuilder{}
+	columns := []sqldb.ColumnInfo{
+		{Name: "id", PrimaryKey: true},
+		{Name: "email"},
+		{Name: "count"},
+		{Name: "version"},
+	}
+
+	tests := []struct {
+		name    string
+		options sqldb.UpsertOptions
+		want    string
+	}{
+		{
+			name: "conflict columns, update columns, and expressions",
+			options: sqldb.UpsertOptions{
+				ConflictColumns:   []string{"email"},
+				UpdateColumns:     []string{"version"},
+				UpdateExpressions: map[string]string{"count": "target.count + source.count"},
+			},
+			want: `MERGE INTO counters WITH (HOLDLOCK) AS target` +
+				` USING (VALUES(@p1,@p2,@p3,@p4)) AS source(id,email,count,version)` +
+				` ON target.email = source.email` +
+				` WHEN MATCHED THEN UPDATE SET target.version = source.version, target.count = target.count + source.count` +
+				` WHEN NOT MATCHED THEN INSERT (id,email,count,version) VALUES (source.id,source.email,source.count,source.version);`,
+		},
+		{
+			name: "where condition",
+			options: sqldb.UpsertOptions{
+				UpdateColumns: []string{"count", "version"},
+				Where:         "source.version > target.version",
+			},
+			want: `MERGE INTO counters WITH (HOLDLOCK) AS target` +
+				` USING (VALUES(@p1,@p2,@p3,@p4)) AS source(id,email,count,version)` +
+				` ON target.id = source.id` +
+				` WHEN MATCHED AND (source.version > target.version) THEN UPDATE SET target.count = source.count, target.version = source.version` +
+				` WHEN NOT MATCHED THEN INSERT (id,email,count,version) VALUES (source.id,source.email,source.count,source.version);`,
+		},
+		{
+			name: "version column",
+			options: sqldb.UpsertOptions{
+				UpdateColumns: []string{"count"},
+				VersionColumn: "version",
+			},
+			want: `MERGE INTO counters WITH (HOLDLOCK) AS target` +
+				` USING (VALUES(@p1,@p2,@p3,@p4)) AS source(id,email,count,version)` +
+				` ON target.id = source.id` +
+				` WHEN MATCHED AND (target.version = source.version - 1) THEN UPDATE SET target.count = source.count, target.version = source.version` +
+				` WHEN NOT MATCHED THEN INSERT (id,email,count,version) VALUES (source.id,source.email,source.count,source.version);`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := b.UpsertWithOptions(testFormatter, "counters", columns, tt.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n  %s\nwant:\n  %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("update conflict column error", func(t *testing.T) {
+		_, err := b.UpsertWithOptions(testFormatter, "counters", columns, sqldb.UpsertOptions{
+			ConflictColumns: []string{"email"},
+			UpdateColumns:   []string{"email"},
+		})
+		if err == nil {
+			t.Error("expected error for updating the MERGE ON column")
+		}
+	})
+}
+
 func TestQueryBuilder_InsertUnique(t *testing.T) {
 	b := QueryBuilder{}
 
@@ -120,7 +196,48 @@ func TestQueryBuilder_implements_interfaces(t *testing.T) {
 	if _, ok := b.(sqldb.UpsertQueryBuilder); !ok {
 		t.Error("QueryBuilder should implement sqldb.UpsertQueryBuilder")
 	}
+	if _, ok := b.(sqldb.SoftDeleteQueryBuilder); !ok {
+		t.Error("QueryBuilder should implement sqldb.SoftDeleteQueryBuilder")
+	}
+	if _, ok := b.(sqldb.RelationQueryBuilder); !ok {
+		t.Error("QueryBuilder should implement sqldb.RelationQueryBuilder")
+	}
 	if _, ok := b.(sqldb.ReturningQueryBuilder); ok {
 		t.Error("QueryBuilder should NOT implement sqldb.ReturningQueryBuilder")
 	}
 }
+
+func TestQueryBuilder_QueryPage(t *testing.T) {
+	b := QueryBuilder{}
+	orderBy := []sqldb.OrderByColumn{{Column: "created_at"}, {Column: "id"}}
+
+	t.Run("first page", func(t *testing.T) {
+		query, args, err := b.QueryPage(testFormatter, "SELECT * FROM t WHERE x = @p1", []any{1}, orderBy, nil, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `SELECT * FROM (SELECT * FROM t WHERE x = @p1) sqldb_page ORDER BY created_at, id OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY`
+		if query != want {
+			t.Errorf("got:\n  %s\nwant:\n  %s", query, want)
+		}
+		if len(args) != 1 {
+			t.Errorf("QueryPage() args = %v", args)
+		}
+	})
+
+	t.Run("after", func(t *testing.T) {
+		query, args, err := b.QueryPage(testFormatter, "SELECT * FROM t WHERE x = @p1", []any{1}, orderBy, []any{"2024", 5}, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `SELECT * FROM (SELECT * FROM t WHERE x = @p1) sqldb_page` +
+			` WHERE (created_at > @p2 OR (created_at = @p3 AND id > @p4))` +
+			` ORDER BY created_at, id OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY`
+		if query != want {
+			t.Errorf("got:\n  %s\nwant:\n  %s", query, want)
+		}
+		if fmt.Sprint(args) != "[1 2024 2024 5]" {
+			t.Errorf("QueryPage() args = %v", args)
+		}
+	})
+}
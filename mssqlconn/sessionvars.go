@@ -0,0 +1,84 @@
+package mssqlconn
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SetSessionVars implements sqldb.SessionVarsSetter by setting the variables
+// with sp_set_session_context so they can be read with SESSION_CONTEXT(N'name').
+// The session context outlives transactions, so the returned reset function
+// restores the values the keys had before.
+func (conn *transaction) SetSessionVars(ctx context.Context, vars map[string]string) (reset func(context.Context) error, err error) {
+	names := slices.Sorted(maps.Keys(vars))
+
+	prev := make([]sql.NullString, len(names))
+	dest := make([]any, len(names))
+	nameArgs := make([]any, len(names))
+	for i, name := range names {
+		dest[i] = &prev[i]
+		nameArgs[i] = name
+	}
+	err = conn.tx.QueryRowContext(ctx, selectSessionContextQuery(len(names)), nameArgs...).Scan(dest...)
+	if err != nil {
+		return nil, wrapKnownErrors(err)
+	}
+
+	setQuery := setSessionContextQuery(len(names))
+	args := make([]any, 0, 2*len(names))
+	for _, name := range names {
+		args = append(args, name, vars[name])
+	}
+	err = conn.Exec(ctx, setQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		for i := range prev {
+			if prev[i].Valid {
+				args[2*i+1] = prev[i].String
+			} else {
+				args[2*i+1] = nil
+			}
+		}
+		return conn.Exec(ctx, setQuery, args...)
+	}, nil
+}
+
+// selectSessionContextQuery returns a query selecting the session context
+// values for the keys passed as the numKeys arguments.
+func selectSessionContextQuery(numKeys int) string {
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	for i := range numKeys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("CAST(SESSION_CONTEXT(@p")
+		b.WriteString(strconv.Itoa(i + 1))
+		b.WriteString(") AS nvarchar(4000))")
+	}
+	return b.String()
+}
+
+// setSessionContextQuery returns a statement setting the session context
+// for numKeys pairs of key and value arguments.
+func setSessionContextQuery(numKeys int) string {
+	var b strings.Builder
+	for i := range numKeys {
+		b.WriteString("EXEC sp_set_session_context @key = @p")
+		b.WriteString(strconv.Itoa(2*i + 1))
+		b.WriteString(", @value = @p")
+		b.WriteString(strconv.Itoa(2*i + 2))
+		b.WriteString(";")
+		if i < numKeys-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package mssqlconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_selectSessionContextQuery(t *testing.T) {
+	assert.Equal(t,
+		"SELECT CAST(SESSION_CONTEXT(@p1) AS nvarchar(4000))",
+		selectSessionContextQuery(1),
+	)
+	assert.Equal(t,
+		"SELECT CAST(SESSION_CONTEXT(@p1) AS nvarchar(4000)), CAST(SESSION_CONTEXT(@p2) AS nvarchar(4000))",
+		selectSessionContextQuery(2),
+	)
+}
+
+func Test_setSessionContextQuery(t *testing.T) {
+	assert.Equal(t,
+		"EXEC sp_set_session_context @key = @p1, @value = @p2;",
+		setSessionContextQuery(1),
+	)
+	assert.Equal(t,
+		"EXEC sp_set_session_context @key = @p1, @value = @p2;\nEXEC sp_set_session_context @key = @p3, @value = @p4;",
+		setSessionContextQuery(2),
+	)
+}
@@ -33,11 +33,20 @@ func TestConnectionSuite(t *testing.T) {
 				name  NVARCHAR(255) NOT NULL,
 				score INT NOT NULL DEFAULT 0
 			)`,
-			// SQL Server does not support RETURNING clause
+			CreateReturningTable: /*sql*/ `CREATE TABLE conntest_returning (
+				id    INT IDENTITY(1,1) PRIMARY KEY,
+				name  NVARCHAR(255) NOT NULL,
+				score INT NOT NULL DEFAULT 0
+			)`,
 			CreateMailAddressTable: /*sql*/ `CREATE TABLE conntest_mail_address (
 				id    INT PRIMARY KEY,
 				email NVARCHAR(255)
 			)`,
+			CreateJSONTable: /*sql*/ `CREATE TABLE conntest_json (
+				id   INT PRIMARY KEY,
+				data NVARCHAR(MAX) CHECK (ISJSON(data) = 1),
+				tags NVARCHAR(MAX) CHECK (ISJSON(tags) = 1)
+			)`,
 			CreateInfoParent: /*sql*/ `CREATE TABLE conntest_info_parent (
 				id1 INT NOT NULL,
 				id2 INT NOT NULL,
@@ -64,6 +73,7 @@ func TestConnectionSuite(t *testing.T) {
 		SupportsReadOnlyTransaction:  false, // SQL Server does not support read-only transactions
 		SupportsCustomIsolationLevel: true,
 		ExecAfterClosedTxErrors:      true,
+		SessionVarQuery:              `SELECT CAST(SESSION_CONTEXT(N'conntest.tenant_id') AS nvarchar(4000))`,
 		Information: conntest.InformationFeatures{
 			SupportsRoutines: true,
 		},
@@ -0,0 +1,43 @@
+package mssqlconn
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// SetTimeouts implements sqldb.TimeoutSetter by setting LOCK_TIMEOUT
+// to queryTimeout, or to transactionTimeout if queryTimeout is zero,
+// so that waiting for locks fails server-side with ErrLockTimeout.
+// SQL Server has no statement timeout, statements are limited
+// client-side by the context deadline.
+// LOCK_TIMEOUT outlives transactions, so the returned reset function
+// restores the previous value.
+func (conn *transaction) SetTimeouts(ctx context.Context, queryTimeout, transactionTimeout time.Duration) (reset func(context.Context) error, err error) {
+	timeout := queryTimeout
+	if timeout <= 0 {
+		timeout = transactionTimeout
+	}
+	if timeout.Milliseconds() <= 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var prev int64
+	err = conn.tx.QueryRowContext(ctx, `SELECT @@LOCK_TIMEOUT`).Scan(&prev)
+	if err != nil {
+		return nil, wrapKnownErrors(err)
+	}
+	err = conn.Exec(ctx, setLockTimeoutQuery(timeout.Milliseconds()))
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return conn.Exec(ctx, setLockTimeoutQuery(prev))
+	}, nil
+}
+
+// setLockTimeoutQuery returns a statement setting LOCK_TIMEOUT
+// to ms milliseconds, where -1 means waiting forever.
+func setLockTimeoutQuery(ms int64) string {
+	return "SET LOCK_TIMEOUT " + strconv.FormatInt(ms, 10)
+}
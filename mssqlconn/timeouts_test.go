@@ -0,0 +1,12 @@
+package mssqlconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_setLockTimeoutQuery(t *testing.T) {
+	assert.Equal(t, "SET LOCK_TIMEOUT 1500", setLockTimeoutQuery(1500))
+	assert.Equal(t, "SET LOCK_TIMEOUT -1", setLockTimeoutQuery(-1))
+}
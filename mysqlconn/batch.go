@@ -0,0 +1,105 @@
+package mysqlconn
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/domonda/go-sqldb"
+)
+
+var (
+	_ sqldb.BatchExecer = new(connection)
+	_ sqldb.BatchExecer = new(transaction)
+	_ sqldb.BatchExecer = new(pinnedConn)
+)
+
+// ExecBatch implements [sqldb.BatchExecer], see execBatch.
+func (conn *connection) ExecBatch(ctx context.Context, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	if !canExecBatch(conn.config, statements) {
+		return sqldb.ExecBatchSequentially(ctx, conn, statements)
+	}
+	return execBatch(ctx, conn.db, statements)
+}
+
+// ExecBatch implements [sqldb.BatchExecer], see execBatch.
+func (conn *transaction) ExecBatch(ctx context.Context, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	if !canExecBatch(conn.parent.config, statements) {
+		return sqldb.ExecBatchSequentially(ctx, conn, statements)
+	}
+	return execBatch(ctx, conn.tx, statements)
+}
+
+// ExecBatch implements [sqldb.BatchExecer], see execBatch.
+func (conn *pinnedConn) ExecBatch(ctx context.Context, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	if !canExecBatch(conn.parent.config, statements) {
+		return sqldb.ExecBatchSequentially(ctx, conn, statements)
+	}
+	return execBatch(ctx, conn.conn, statements)
+}
+
+// canExecBatch returns true if the statements can be executed
+// as one multi-statement query, which the driver only supports
+// with the multiStatements=true DSN parameter.
+// Multiple statements can't be prepared by the server,
+// so statements with arguments also need interpolateParams=true
+// to let the driver interpolate the arguments.
+func canExecBatch(config *sqldb.Config, statements []sqldb.BatchStatement) bool {
+	if len(statements) < 2 || !configParamTrue(config, "multiStatements") {
+		return false
+	}
+	for _, stmt := range statements {
+		if len(stmt.Args) > 0 {
+			return configParamTrue(config, "interpolateParams")
+		}
+	}
+	return true
+}
+
+func configParamTrue(config *sqldb.Config, name string) bool {
+	value, _ := strconv.ParseBool(config.Extra[name])
+	return value
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// execBatch executes the statements in a single round trip
+// as one multi-statement query where every exec statement
+// is followed by SELECT ROW_COUNT() so that every statement
+// returns one result set for [sqldb.ReadBatchResultSets].
+// MySQL stops executing the statements at the first error.
+func execBatch(ctx context.Context, conn queryer, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	query, args := batchQuery(statements)
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		results := make([]sqldb.BatchResult, len(statements))
+		sqldb.AbortBatchResults(results, 0, wrapKnownErrors(err))
+		return results
+	}
+	results, _ := sqldb.ReadBatchResultSets(rows, statements)
+	for i := range results {
+		results[i].Err = wrapKnownErrors(results[i].Err)
+	}
+	return results
+}
+
+// batchQuery joins the statements to one multi-statement query.
+// The statements are separated by semicolons on their own lines
+// so that a trailing line comment can't comment out the separator.
+func batchQuery(statements []sqldb.BatchStatement) (query string, args []any) {
+	var b strings.Builder
+	for i, stmt := range statements {
+		if i > 0 {
+			b.WriteString("\n;\n")
+		}
+		b.WriteString(strings.TrimRight(stmt.Query, "; \t\r\n"))
+		if stmt.ScanRows == nil {
+			b.WriteString("\n;\nSELECT ROW_COUNT()")
+		}
+		args = append(args, stmt.Args...)
+	}
+	return b.String(), args
+}
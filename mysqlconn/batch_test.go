@@ -0,0 +1,38 @@
+package mysqlconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func Test_batchQuery(t *testing.T) {
+	statements := []sqldb.BatchStatement{
+		{Query: "UPDATE t SET a = ? WHERE id = ?;", Args: []any{1, 2}},
+		{Query: "SELECT * FROM t WHERE id = ? -- comment", Args: []any{2}, ScanRows: func(sqldb.Rows) error { return nil }},
+		{Query: "DELETE FROM t"},
+	}
+	query, args := batchQuery(statements)
+	assert.Equal(t,
+		"UPDATE t SET a = ? WHERE id = ?\n;\nSELECT ROW_COUNT()\n;\n"+
+			"SELECT * FROM t WHERE id = ? -- comment\n;\n"+
+			"DELETE FROM t\n;\nSELECT ROW_COUNT()",
+		query,
+	)
+	assert.Equal(t, []any{1, 2, 2}, args)
+}
+
+func Test_canExecBatch(t *testing.T) {
+	withArgs := sqldb.BatchStatement{Query: "DELETE FROM t WHERE id = ?", Args: []any{1}}
+	noArgs := sqldb.BatchStatement{Query: "DELETE FROM t"}
+	multi := &sqldb.Config{Extra: map[string]string{"multiStatements": "true"}}
+	interpolate := &sqldb.Config{Extra: map[string]string{"multiStatements": "true", "interpolateParams": "true"}}
+
+	assert.False(t, canExecBatch(&sqldb.Config{}, []sqldb.BatchStatement{noArgs, noArgs}), "multiStatements not enabled")
+	assert.False(t, canExecBatch(multi, []sqldb.BatchStatement{noArgs}), "single statement")
+	assert.True(t, canExecBatch(multi, []sqldb.BatchStatement{noArgs, noArgs}))
+	assert.False(t, canExecBatch(multi, []sqldb.BatchStatement{noArgs, withArgs}), "arguments without interpolateParams")
+	assert.True(t, canExecBatch(interpolate, []sqldb.BatchStatement{noArgs, withArgs}))
+}
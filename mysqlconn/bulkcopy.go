@@ -0,0 +1,238 @@
+package mysqlconn
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+
+	"github.com/domonda/go-sqldb"
+)
+
+var (
+	_ sqldb.BulkCopier = new(connection)
+	_ sqldb.BulkCopier = new(transaction)
+	_ sqldb.BulkCopier = new(pinnedConn)
+)
+
+var copyReaderCounter atomic.Uint64
+
+// CopyFrom implements [sqldb.BulkCopier] using LOAD DATA LOCAL INFILE
+// within a transaction that is committed after all rows were copied.
+func (conn *connection) CopyFrom(ctx context.Context, table string, columns []string, rows sqldb.CopyRowSource) (int64, error) {
+	tx, err := conn.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, wrapKnownErrors(err)
+	}
+	return copyFromInTx(ctx, tx, conn.config, table, columns, rows)
+}
+
+// CopyFrom implements [sqldb.BulkCopier] using LOAD DATA LOCAL INFILE
+// within the transaction.
+func (conn *transaction) CopyFrom(ctx context.Context, table string, columns []string, rows sqldb.CopyRowSource) (int64, error) {
+	return copyFrom(ctx, conn.tx, conn.parent.config, table, columns, rows)
+}
+
+// CopyFrom implements [sqldb.BulkCopier] using LOAD DATA LOCAL INFILE
+// within a transaction on the pinned session
+// that is committed after all rows were copied.
+func (conn *pinnedConn) CopyFrom(ctx context.Context, table string, columns []string, rows sqldb.CopyRowSource) (int64, error) {
+	tx, err := conn.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, wrapKnownErrors(err)
+	}
+	return copyFromInTx(ctx, tx, conn.parent.config, table, columns, rows)
+}
+
+// copyFromInTx calls copyFrom and commits tx on success,
+// or rolls it back on error.
+func copyFromInTx(ctx context.Context, tx *sql.Tx, config *sqldb.Config, table string, columns []string, rows sqldb.CopyRowSource) (int64, error) {
+	numRows, err := copyFrom(ctx, tx, config, table, columns, rows)
+	if err != nil {
+		return 0, errors.Join(err, tx.Rollback())
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, wrapKnownErrors(err)
+	}
+	return numRows, nil
+}
+
+// copyFrom streams the rows as tab separated text through an io.Pipe
+// registered as reader handler with the driver for LOAD DATA LOCAL INFILE.
+// The server must have the local_infile system variable enabled.
+//
+// With LOCAL, rows with duplicate keys or invalid values are skipped
+// or adjusted like with IGNORE instead of failing the statement,
+// so an error is returned if the number of rows affected by the statement
+// differs from the number of written rows.
+// The transaction must then be rolled back to discard the copied rows.
+func copyFrom(ctx context.Context, tx *sql.Tx, config *sqldb.Config, table string, columns []string, rows sqldb.CopyRowSource) (int64, error) {
+	readerName := "sqldb_copy_" + strconv.FormatUint(copyReaderCounter.Add(1), 10)
+	query, err := copyFromQuery(readerName, table, columns)
+	if err != nil {
+		return 0, err
+	}
+	loc := time.UTC
+	if driverConfig, err := mysqldriver.ParseDSN(formatDSN(config)); err == nil && driverConfig.Loc != nil {
+		loc = driverConfig.Loc
+	}
+
+	pr, pw := io.Pipe()
+	writeDone := make(chan struct{})
+	var written int64
+	go func() {
+		defer close(writeDone)
+		var err error
+		written, err = writeCopyRows(pw, rows, len(columns), loc)
+		pw.CloseWithError(err)
+	}()
+	mysqldriver.RegisterReaderHandler(readerName, func() io.Reader { return pr })
+	defer mysqldriver.DeregisterReaderHandler(readerName)
+
+	result, err := tx.ExecContext(ctx, query)
+	// Unblock the writer if the driver did not read all rows
+	pr.Close()
+	<-writeDone
+	if err != nil {
+		return 0, wrapKnownErrors(err)
+	}
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if numRows != written {
+		return numRows, fmt.Errorf("copied %d of %d rows, LOAD DATA LOCAL skipped rows with duplicate keys or invalid values", numRows, written)
+	}
+	return numRows, nil
+}
+
+// copyFromQuery returns the LOAD DATA LOCAL INFILE statement
+// reading from the driver reader handler with readerName.
+func copyFromQuery(readerName, table string, columns []string) (string, error) {
+	var fmtr QueryFormatter
+	tableName, err := fmtr.FormatTableName(table)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString("LOAD DATA LOCAL INFILE 'Reader::")
+	b.WriteString(readerName)
+	b.WriteString("' INTO TABLE ")
+	b.WriteString(tableName)
+	b.WriteString(` CHARACTER SET utf8mb4 FIELDS TERMINATED BY '\t' ESCAPED BY '\\' LINES TERMINATED BY '\n' (`)
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		columnName, err := fmtr.FormatColumnName(column)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(columnName)
+	}
+	b.WriteByte(')')
+	return b.String(), nil
+}
+
+// writeCopyRows writes the rows in the text format
+// of the statement returned by copyFromQuery
+// and returns the number of written rows.
+func writeCopyRows(w io.Writer, rows sqldb.CopyRowSource, numColumns int, loc *time.Location) (numRows int64, err error) {
+	bw := bufio.NewWriter(w)
+	var line []byte
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return numRows, err
+		}
+		if len(vals) != numColumns {
+			return numRows, fmt.Errorf("got %d values for %d columns", len(vals), numColumns)
+		}
+		line = line[:0]
+		for i, val := range vals {
+			if i > 0 {
+				line = append(line, '\t')
+			}
+			line, err = appendCopyValue(line, val, loc)
+			if err != nil {
+				return numRows, fmt.Errorf("column %d: %w", i, err)
+			}
+		}
+		line = append(line, '\n')
+		if _, err = bw.Write(line); err != nil {
+			return numRows, err
+		}
+		numRows++
+	}
+	if err := rows.Err(); err != nil {
+		return numRows, err
+	}
+	return numRows, bw.Flush()
+}
+
+// appendCopyValue appends val as LOAD DATA text field to b.
+func appendCopyValue(b []byte, val any, loc *time.Location) ([]byte, error) {
+	val, err := driver.DefaultParameterConverter.ConvertValue(val)
+	if err != nil {
+		return b, err
+	}
+	switch v := val.(type) {
+	case nil:
+		return append(b, `\N`...), nil
+	case int64:
+		return strconv.AppendInt(b, v, 10), nil
+	case uint64:
+		return strconv.AppendUint(b, v, 10), nil
+	case float64:
+		return strconv.AppendFloat(b, v, 'g', -1, 64), nil
+	case bool:
+		if v {
+			return append(b, '1'), nil
+		}
+		return append(b, '0'), nil
+	case time.Time:
+		if v.IsZero() {
+			// Not converted to loc because that could
+			// shift the zero time before year 1
+			return append(b, "0001-01-01 00:00:00"...), nil
+		}
+		return v.In(loc).AppendFormat(b, "2006-01-02 15:04:05.999999"), nil
+	case string:
+		return appendEscapedCopyText(b, v), nil
+	case []byte:
+		return appendEscapedCopyText(b, string(v)), nil
+	default:
+		return b, fmt.Errorf("unsupported value type %T", val)
+	}
+}
+
+// appendEscapedCopyText appends str to b escaping the characters
+// that have a special meaning in LOAD DATA text fields.
+func appendEscapedCopyText(b []byte, str string) []byte {
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; c {
+		case '\\':
+			b = append(b, `\\`...)
+		case '\t':
+			b = append(b, `\t`...)
+		case '\n':
+			b = append(b, `\n`...)
+		case '\r':
+			b = append(b, `\r`...)
+		case 0:
+			b = append(b, `\0`...)
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
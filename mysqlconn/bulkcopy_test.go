@@ -0,0 +1,63 @@
+package mysqlconn
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func Test_copyFromQuery(t *testing.T) {
+	query, err := copyFromQuery("sqldb_copy_1", "users", []string{"id", "name"})
+	require.NoError(t, err)
+	assert.Equal(t,
+		"LOAD DATA LOCAL INFILE 'Reader::sqldb_copy_1' INTO TABLE users CHARACTER SET utf8mb4 "+
+			`FIELDS TERMINATED BY '\t' ESCAPED BY '\\' LINES TERMINATED BY '\n' `+
+			"(id, name)",
+		query,
+	)
+
+	_, err = copyFromQuery("sqldb_copy_1", "users", []string{"id", "a`b"})
+	assert.Error(t, err)
+}
+
+func Test_writeCopyRows(t *testing.T) {
+	t.Run("values", func(t *testing.T) {
+		// given
+		loc := time.FixedZone("UTC+2", 2*60*60)
+		ts := time.Date(2024, 1, 2, 10, 4, 5, 123000, time.UTC)
+		rows := sqldb.CopyRowsFromSlice([][]any{
+			{int64(1), "a\tb\nc\\d\r\x00", true, nil, 1.5, ts},
+			{uint(2), []byte("x"), false, (*string)(nil), float32(0.25), time.Time{}},
+		})
+		var buf bytes.Buffer
+
+		// when
+		numRows, err := writeCopyRows(&buf, rows, 6, loc)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), numRows)
+		assert.Equal(t,
+			"1\ta\\tb\\nc\\\\d\\r\\0\t1\t\\N\t1.5\t2024-01-02 12:04:05.000123\n"+
+				"2\tx\t0\t\\N\t0.25\t0001-01-01 00:00:00\n",
+			buf.String(),
+		)
+	})
+
+	t.Run("wrong number of values", func(t *testing.T) {
+		rows := sqldb.CopyRowsFromSlice([][]any{{1, 2}})
+		_, err := writeCopyRows(new(bytes.Buffer), rows, 1, time.UTC)
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported value", func(t *testing.T) {
+		rows := sqldb.CopyRowsFromSlice([][]any{{struct{}{}}})
+		_, err := writeCopyRows(new(bytes.Buffer), rows, 1, time.UTC)
+		assert.Error(t, err)
+	})
+}
@@ -44,7 +44,7 @@ func Connect(ctx context.Context, config *sqldb.Config) (sqldb.Connection, error
 		}
 		return nil, err
 	}
-	return &connection{db: db, config: config}, nil
+	return sqldb.WithDefaultTimeouts(&connection{db: db, config: config}), nil
 
 }
 
@@ -30,6 +30,8 @@ The connection uses ? placeholders and backtick identifier quoting.
 MySQL/MariaDB-specific features:
   - Pinned sessions via the sqldb.ConnPinner interface (Conn) for
     session-scoped state like GET_LOCK named locks
+  - LOAD DATA LOCAL INFILE bulk loading via the sqldb.BulkCopier interface
+  - Multi-statement sqldb.Batch execution via the sqldb.BatchExecer interface
   - Default isolation level is sql.LevelRepeatableRead
   - EscapeIdentifier wraps identifiers in backticks when needed
   - DropAllTables disables foreign key checks to drop tables in any order
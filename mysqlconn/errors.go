@@ -1,7 +1,9 @@
 package mysqlconn
 
 import (
+	"database/sql/driver"
 	"errors"
+	"io"
 	"slices"
 	"strings"
 
@@ -17,10 +19,15 @@ const (
 	errDupEntry         = 1062 // Duplicate entry '%s' for key '%s'
 	errNoReferencedRow  = 1216 // FK child-side insert/update failed (old)
 	errRowIsReferenced  = 1217 // FK parent-side delete/update failed (old)
+	errLockWaitTimeout  = 1205 // Lock wait timeout exceeded; try restarting transaction
 	errDeadlock         = 1213 // Deadlock found when trying to get lock
+	errQueryInterrupted = 1317 // Query execution was interrupted
+	errNeedReprepare    = 1615 // Prepared statement needs to be re-prepared
+	errQueryTimeout     = 3024 // Query execution was interrupted, maximum statement execution time exceeded
 	errSignal           = 1644 // Unhandled user-defined exception (SIGNAL)
 	errRowIsReferenced2 = 1451 // FK parent-side delete/update failed
 	errNoReferencedRow2 = 1452 // FK child-side insert/update failed
+	errLockNowait       = 3572 // Statement aborted because lock(s) could not be acquired immediately and NOWAIT is set
 	errCheckViolated    = 3819 // Check constraint '%s' is violated
 )
 
@@ -30,12 +37,23 @@ func wrapKnownErrors(err error) error {
 	}
 	var e *mysqldriver.MySQLError
 	if !errors.As(err, &e) {
+		if errors.Is(err, mysqldriver.ErrInvalidConn) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return errors.Join(sqldb.ErrConnectionLost, err)
+		}
 		return err
 	}
 	msg := e.Message
 	switch e.Number {
 	case errDeadlock:
 		return errors.Join(sqldb.ErrDeadlock, err)
+	case errLockWaitTimeout, errLockNowait:
+		return errors.Join(sqldb.ErrLockTimeout, err)
+	case errQueryInterrupted:
+		return errors.Join(sqldb.ErrQueryCanceled, err)
+	case errQueryTimeout:
+		return errors.Join(sqldb.ErrQueryTimeout{Limit: sqldb.QueryTimeoutLimit}, err)
+	case errNeedReprepare:
+		return errors.Join(sqldb.ErrStmtInvalidated, err)
 	case errSignal:
 		return errors.Join(sqldb.ErrRaisedException{Message: msg}, err)
 	case errBadNullError:
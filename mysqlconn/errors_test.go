@@ -1,6 +1,7 @@
 package mysqlconn
 
 import (
+	"database/sql/driver"
 	"errors"
 	"testing"
 
@@ -17,6 +18,7 @@ func Test_wrapKnownErrors(t *testing.T) {
 		wantNil         bool
 		wantUnchanged   bool
 		wantDeadlock    bool
+		wantSentinel    error
 		wantRaised      *sqldb.ErrRaisedException
 		wantNotNull     *sqldb.ErrNotNullViolation
 		wantUnique      *sqldb.ErrUniqueViolation
@@ -51,6 +53,61 @@ func Test_wrapKnownErrors(t *testing.T) {
 			wantDeadlock:    true,
 			wantOriginalErr: true,
 		},
+		{
+			name: "errLockWaitTimeout (1205) wraps as ErrLockTimeout",
+			err: &mysqldriver.MySQLError{
+				Number:  1205,
+				Message: "Lock wait timeout exceeded; try restarting transaction",
+			},
+			wantSentinel:    sqldb.ErrLockTimeout,
+			wantOriginalErr: true,
+		},
+		{
+			name: "errLockNowait (3572) wraps as ErrLockTimeout",
+			err: &mysqldriver.MySQLError{
+				Number:  3572,
+				Message: "Statement aborted because lock(s) could not be acquired immediately and NOWAIT is set.",
+			},
+			wantSentinel:    sqldb.ErrLockTimeout,
+			wantOriginalErr: true,
+		},
+		{
+			name: "errQueryInterrupted (1317) wraps as ErrQueryCanceled",
+			err: &mysqldriver.MySQLError{
+				Number:  1317,
+				Message: "Query execution was interrupted",
+			},
+			wantSentinel:    sqldb.ErrQueryCanceled,
+			wantOriginalErr: true,
+		},
+		{
+			name: "errQueryTimeout (3024) wraps as ErrQueryTimeout",
+			err: &mysqldriver.MySQLError{
+				Number:  3024,
+				Message: "Query execution was interrupted, maximum statement execution time exceeded",
+			},
+			wantSentinel:    sqldb.ErrQueryTimeout{Limit: sqldb.QueryTimeoutLimit},
+			wantOriginalErr: true,
+		},
+		{
+			name: "errNeedReprepare (1615) wraps as ErrStmtInvalidated",
+			err: &mysqldriver.MySQLError{
+				Number:  1615,
+				Message: "Prepared statement needs to be re-prepared",
+			},
+			wantSentinel:    sqldb.ErrStmtInvalidated,
+			wantOriginalErr: true,
+		},
+		{
+			name:         "ErrInvalidConn wraps as ErrConnectionLost",
+			err:          mysqldriver.ErrInvalidConn,
+			wantSentinel: sqldb.ErrConnectionLost,
+		},
+		{
+			name:         "driver.ErrBadConn wraps as ErrConnectionLost",
+			err:          driver.ErrBadConn,
+			wantSentinel: sqldb.ErrConnectionLost,
+		},
 		{
 			name: "errSignal (1644) wraps as ErrRaisedException",
 			err: &mysqldriver.MySQLError{
@@ -149,6 +206,10 @@ func Test_wrapKnownErrors(t *testing.T) {
 			if scenario.wantDeadlock {
 				assert.ErrorIs(t, result, sqldb.ErrDeadlock)
 			}
+			if scenario.wantSentinel != nil {
+				assert.ErrorIs(t, result, scenario.wantSentinel)
+				assert.ErrorIs(t, result, scenario.err, "original error should be preserved")
+			}
 			if scenario.wantRaised != nil {
 				var target sqldb.ErrRaisedException
 				assert.ErrorAs(t, result, &target)
@@ -0,0 +1,133 @@
+package mysqlconn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/domonda/go-sqldb"
+)
+
+var (
+	_ sqldb.InsertRowsReturner = new(connection)
+	_ sqldb.InsertRowsReturner = new(transaction)
+	_ sqldb.InsertRowsReturner = new(pinnedConn)
+)
+
+// InsertRowsReturningInto implements [sqldb.InsertRowsReturner], see insertRowsReturningInto.
+func (conn *connection) InsertRowsReturningInto(ctx context.Context, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	return insertRowsReturningInto(ctx, conn.db, table, columns, numRows, vals, returningColumns, dest)
+}
+
+// InsertRowsReturningInto implements [sqldb.InsertRowsReturner], see insertRowsReturningInto.
+func (conn *transaction) InsertRowsReturningInto(ctx context.Context, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	return insertRowsReturningInto(ctx, conn.tx, table, columns, numRows, vals, returningColumns, dest)
+}
+
+// InsertRowsReturningInto implements [sqldb.InsertRowsReturner], see insertRowsReturningInto.
+func (conn *pinnedConn) InsertRowsReturningInto(ctx context.Context, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	return insertRowsReturningInto(ctx, conn.conn, table, columns, numRows, vals, returningColumns, dest)
+}
+
+type execQueryer interface {
+	queryer
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertRowsReturningInto inserts the rows with a multi-row INSERT
+// and selects the returningColumns of the inserted rows by their
+// AUTO_INCREMENT primary key starting at LAST_INSERT_ID(),
+// which is the value generated for the first inserted row.
+//
+// InnoDB allocates consecutive AUTO_INCREMENT values for all rows
+// of an INSERT with a known number of rows in every innodb_autoinc_lock_mode,
+// so the first numRows rows ordered by the key starting at LAST_INSERT_ID()
+// are the inserted rows in the order of the VALUES list,
+// also with an auto_increment_increment other than 1.
+// The single primary key column of returningColumns must be
+// the AUTO_INCREMENT column of the table.
+func insertRowsReturningInto(ctx context.Context, conn execQueryer, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) (err error) {
+	insertQuery, err := QueryBuilder{}.InsertRows(QueryFormatter{}, table, columns, numRows)
+	if err != nil {
+		return err
+	}
+	selectQuery, err := insertRowsReturningSelectQuery(QueryFormatter{}, table, returningColumns, numRows)
+	if err != nil {
+		return err
+	}
+
+	result, err := conn.ExecContext(ctx, insertQuery, vals...)
+	if err != nil {
+		return wrapKnownErrors(err)
+	}
+	firstID, err := result.LastInsertId()
+	if err != nil {
+		return wrapKnownErrors(err)
+	}
+	if firstID == 0 {
+		return fmt.Errorf("no AUTO_INCREMENT value generated for INSERT into %s", table)
+	}
+
+	rows, err := conn.QueryContext(ctx, selectQuery, firstID)
+	if err != nil {
+		return wrapKnownErrors(err)
+	}
+	defer func() {
+		err = errors.Join(err, wrapKnownErrors(rows.Close()))
+	}()
+	rowIndex := 0
+	for ; rows.Next(); rowIndex++ {
+		if err := rows.Scan(dest(rowIndex)...); err != nil {
+			return wrapKnownErrors(err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return wrapKnownErrors(err)
+	}
+	if rowIndex != numRows {
+		return fmt.Errorf("selected %d of %d inserted rows from %s", rowIndex, numRows, table)
+	}
+	return nil
+}
+
+// insertRowsReturningSelectQuery builds the query selecting the returningColumns
+// of numRows inserted rows by the AUTO_INCREMENT primary key
+// that must be the only primary key column of returningColumns:
+//
+//	SELECT id,created_at FROM table WHERE id >= ? ORDER BY id LIMIT 2
+func insertRowsReturningSelectQuery(formatter sqldb.QueryFormatter, table string, returningColumns []sqldb.ColumnInfo, numRows int) (string, error) {
+	fmtTable, err := formatter.FormatTableName(table)
+	if err != nil {
+		return "", err
+	}
+	var (
+		idColumn string
+		colNames = make([]string, len(returningColumns))
+	)
+	for i, col := range returningColumns {
+		colNames[i], err = formatter.FormatColumnName(col.Name)
+		if err != nil {
+			return "", err
+		}
+		if col.PrimaryKey {
+			if idColumn != "" {
+				return "", fmt.Errorf("MySQL can only return generated values for a single AUTO_INCREMENT primary key column, but %s has multiple", table)
+			}
+			idColumn = colNames[i]
+		}
+	}
+	if idColumn == "" {
+		return "", fmt.Errorf("MySQL can only return generated values with an AUTO_INCREMENT primary key column tagged with default or readonly, but %s has none", table)
+	}
+	return fmt.Sprintf(
+		`SELECT %s FROM %s WHERE %s >= %s ORDER BY %s LIMIT %d`,
+		strings.Join(colNames, ","),
+		fmtTable,
+		idColumn,
+		formatter.FormatPlaceholder(0),
+		idColumn,
+		numRows,
+	), nil
+}
@@ -0,0 +1,31 @@
+package mysqlconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func Test_insertRowsReturningSelectQuery(t *testing.T) {
+	t.Run("primary key", func(t *testing.T) {
+		returning := []sqldb.ColumnInfo{{Name: "id", PrimaryKey: true, HasDefault: true}, {Name: "created_at", HasDefault: true}, {Name: "Status", ReadOnly: true}}
+		query, err := insertRowsReturningSelectQuery(QueryFormatter{}, "accounts", returning, 3)
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT id,created_at,`Status` FROM accounts WHERE id >= ? ORDER BY id LIMIT 3", query)
+	})
+
+	t.Run("no primary key", func(t *testing.T) {
+		returning := []sqldb.ColumnInfo{{Name: "created_at", HasDefault: true}}
+		_, err := insertRowsReturningSelectQuery(QueryFormatter{}, "accounts", returning, 1)
+		require.Error(t, err)
+	})
+
+	t.Run("composite primary key", func(t *testing.T) {
+		returning := []sqldb.ColumnInfo{{Name: "a", PrimaryKey: true, HasDefault: true}, {Name: "b", PrimaryKey: true, HasDefault: true}}
+		_, err := insertRowsReturningSelectQuery(QueryFormatter{}, "accounts", returning, 1)
+		require.Error(t, err)
+	})
+}
@@ -2,6 +2,7 @@ package mysqlconn
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/domonda/go-sqldb"
@@ -9,6 +10,9 @@ import (
 
 var _ sqldb.QueryBuilder = (*QueryBuilder)(nil)
 var _ sqldb.UpsertQueryBuilder = (*QueryBuilder)(nil)
+var _ sqldb.UpsertOptionsQueryBuilder = (*QueryBuilder)(nil)
+var _ sqldb.SoftDeleteQueryBuilder = (*QueryBuilder)(nil)
+var _ sqldb.RelationQueryBuilder = (*QueryBuilder)(nil)
 
 // QueryBuilder implements [sqldb.QueryBuilder] and [sqldb.UpsertQueryBuilder]
 // using MySQL-specific syntax.
@@ -76,15 +80,47 @@ func (b QueryBuilder) InsertUnique(formatter sqldb.QueryFormatter, table string,
 // non-primary key columns are updated using VALUES(col) syntax
 // for compatibility with both MySQL and MariaDB.
 func (b QueryBuilder) Upsert(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo) (query string, err error) {
-	hasNonPK := false
-	for i := range columns {
-		if !columns[i].PrimaryKey {
-			hasNonPK = true
-			break
+	return b.UpsertWithOptions(formatter, table, columns, sqldb.UpsertOptions{})
+}
+
+// UpsertWithOptions builds an INSERT ... ON DUPLICATE KEY UPDATE query
+// with the update columns, update expressions, and WHERE condition of the options.
+// Update columns are set to VALUES(col) for compatibility with both MySQL and MariaDB.
+// Update expressions and the WHERE condition reference the existing row
+// with unqualified column names and the proposed row as VALUES(column).
+// If the options have a VersionColumn, the existing row is only updated
+// if its version is the proposed version minus one.
+//
+// MySQL detects conflicts on all unique indexes of the table,
+// so the conflict columns of the options are only validated
+// and excluded from the default update columns.
+//
+// ON DUPLICATE KEY UPDATE has no WHERE clause, so every assignment
+// becomes col = IF(condition, value, col). MySQL evaluates
+// the assignments from left to right with the already updated values,
+// so the assignment of a column referenced by the condition is moved
+// to the end, where updating it can't change the condition
+// of the other assignments. An error is returned if the condition
+// references more than one updated column.
+// See [sqldb.UpsertOptions] for the full contract and security model.
+func (b QueryBuilder) UpsertWithOptions(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo, options sqldb.UpsertOptions) (query string, err error) {
+	_, assignments, err := options.Resolve(columns)
+	if err != nil {
+		return "", err
+	}
+	where := options.Where
+	if options.VersionColumn != "" {
+		versionColumn, err := formatter.FormatColumnName(options.VersionColumn)
+		if err != nil {
+			return "", err
 		}
+		where = options.VersionWhere(versionColumn, "VALUES("+versionColumn+")")
 	}
-	if !hasNonPK {
-		return "", fmt.Errorf("Upsert requires at least one non-primary-key column")
+	if where != "" {
+		assignments, err = conditionColumnAssignmentLast(where, assignments)
+		if err != nil {
+			return "", err
+		}
 	}
 
 	var q strings.Builder
@@ -94,21 +130,49 @@ func (b QueryBuilder) Upsert(formatter sqldb.QueryFormatter, table string, colum
 	}
 	q.WriteString(insert)
 	q.WriteString(` ON DUPLICATE KEY UPDATE`)
-	first := true
-	for i := range columns {
-		if columns[i].PrimaryKey {
-			continue
-		}
-		if first {
-			first = false
-		} else {
+	for i, assignment := range assignments {
+		if i > 0 {
 			q.WriteByte(',')
 		}
-		columnName, err := formatter.FormatColumnName(columns[i].Name)
+		columnName, err := formatter.FormatColumnName(assignment.Column)
 		if err != nil {
 			return "", err
 		}
-		fmt.Fprintf(&q, ` %s=VALUES(%s)`, columnName, columnName)
+		value := assignment.Expression
+		if assignment.ColumnIndex >= 0 {
+			value = fmt.Sprintf(`VALUES(%s)`, columnName)
+		}
+		if where == "" {
+			fmt.Fprintf(&q, ` %s=%s`, columnName, value)
+		} else {
+			fmt.Fprintf(&q, ` %s=IF(%s, %s, %s)`, columnName, where, value, columnName)
+		}
 	}
 	return q.String(), nil
 }
+
+// conditionColumnAssignmentLast returns the assignments with the assignment
+// of the column referenced by condition moved to the end,
+// or an error if condition references more than one assigned column.
+// Identifiers of condition are compared case-insensitively
+// with the column names.
+func conditionColumnAssignmentLast(condition string, assignments []sqldb.UpsertAssignment) ([]sqldb.UpsertAssignment, error) {
+	identifiers := strings.FieldsFunc(condition, func(r rune) bool {
+		return !(r == '_' || r == '$' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127)
+	})
+	referenced := -1
+	for i, assignment := range assignments {
+		if !slices.ContainsFunc(identifiers, func(ident string) bool { return strings.EqualFold(ident, assignment.Column) }) {
+			continue
+		}
+		if referenced >= 0 {
+			return nil, fmt.Errorf("upsert condition %q references the updated columns %q and %q, but MySQL evaluates it with the already updated values", condition, assignments[referenced].Column, assignment.Column)
+		}
+		referenced = i
+	}
+	if referenced < 0 || referenced == len(assignments)-1 {
+		return assignments, nil
+	}
+	reordered := slices.Delete(slices.Clone(assignments), referenced, referenced+1)
+	return append(reordered, assignments[referenced]), nil
+}
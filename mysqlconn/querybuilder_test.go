@@ -60,6 +60,79 @@ func TestQueryBuilder_Upsert(t *testing.T) {
 	})
 }
 
+func TestQueryBuilder_UpsertWithOptions(t *testing.T) {
+	b := QueryBuilder{}
+	columns := []sqldb.ColumnInfo{
+		{Name: "id", PrimaryKey: true},
+		{Name: "name"},
+		{Name: "count"},
+		{Name: "version"},
+	}
+
+	tests := []struct {
+		name    string
+		options sqldb.UpsertOptions
+		want    string
+	}{
+		{
+			name: "update columns and expressions",
+			options: sqldb.UpsertOptions{
+				UpdateColumns:     []string{"name"},
+				UpdateExpressions: map[string]string{"count": "count + VALUES(count)"},
+			},
+			want: "INSERT INTO counters(id,name,count,version) VALUES(?,?,?,?) ON DUPLICATE KEY UPDATE name=VALUES(name), count=count + VALUES(count)",
+		},
+		{
+			name: "where condition column updated last",
+			options: sqldb.UpsertOptions{
+				UpdateColumns: []string{"version", "name"},
+				Where:         "VALUES(version) > version",
+			},
+			want: "INSERT INTO counters(id,name,count,version) VALUES(?,?,?,?) ON DUPLICATE KEY UPDATE" +
+				" name=IF(VALUES(version) > version, VALUES(name), name)," +
+				" version=IF(VALUES(version) > version, VALUES(version), version)",
+		},
+		{
+			name: "version column",
+			options: sqldb.UpsertOptions{
+				UpdateColumns: []string{"name"},
+				VersionColumn: "version",
+			},
+			want: "INSERT INTO counters(id,name,count,version) VALUES(?,?,?,?) ON DUPLICATE KEY UPDATE" +
+				" name=IF(version = VALUES(version) - 1, VALUES(name), name)," +
+				" version=IF(version = VALUES(version) - 1, VALUES(version), version)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := b.UpsertWithOptions(testFormatter, "counters", columns, tt.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n  %s\nwant:\n  %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("where condition references multiple updated columns error", func(t *testing.T) {
+		_, err := b.UpsertWithOptions(testFormatter, "counters", columns, sqldb.UpsertOptions{
+			UpdateColumns: []string{"name", "count"},
+			Where:         "VALUES(count) > count OR name IS NULL",
+		})
+		if err == nil {
+			t.Error("expected error for condition referencing multiple updated columns")
+		}
+	})
+
+	t.Run("conflict column not inserted error", func(t *testing.T) {
+		_, err := b.UpsertWithOptions(testFormatter, "counters", columns, sqldb.UpsertOptions{ConflictColumns: []string{"slug"}})
+		if err == nil {
+			t.Error("expected error for conflict column that is not inserted")
+		}
+	})
+}
+
 func TestQueryBuilder_InsertUnique(t *testing.T) {
 	b := QueryBuilder{}
 
@@ -106,6 +179,12 @@ func TestQueryBuilder_implements_interfaces(t *testing.T) {
 	if _, ok := b.(sqldb.UpsertQueryBuilder); !ok {
 		t.Error("QueryBuilder should implement sqldb.UpsertQueryBuilder")
 	}
+	if _, ok := b.(sqldb.SoftDeleteQueryBuilder); !ok {
+		t.Error("QueryBuilder should implement sqldb.SoftDeleteQueryBuilder")
+	}
+	if _, ok := b.(sqldb.RelationQueryBuilder); !ok {
+		t.Error("QueryBuilder should implement sqldb.RelationQueryBuilder")
+	}
 	if _, ok := b.(sqldb.ReturningQueryBuilder); ok {
 		t.Error("QueryBuilder should NOT implement sqldb.ReturningQueryBuilder")
 	}
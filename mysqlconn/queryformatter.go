@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/domonda/go-sqldb"
 )
@@ -108,7 +109,10 @@ func EscapeIdentifier(ident string) string {
 // Uses backtick identifier quoting, ? placeholders, and backslash+quote escaping for strings.
 type QueryFormatter struct{}
 
-var _ sqldb.QueryFormatter = QueryFormatter{}
+var (
+	_ sqldb.QueryFormatter     = QueryFormatter{}
+	_ sqldb.QueryTimeoutHinter = QueryFormatter{}
+)
 
 // FormatTableName implements [sqldb.QueryFormatter.FormatTableName].
 func (QueryFormatter) FormatTableName(name string) (string, error) {
@@ -175,3 +179,25 @@ func (QueryFormatter) MaxArgs() int {
 func (f QueryFormatter) SubstitutePlaceholders(query string, args []any) (string, error) {
 	return sqldb.SubstitutePlaceholders(f, query, args)
 }
+
+// QueryWithTimeoutHint implements [sqldb.QueryTimeoutHinter]
+// by inserting a MAX_EXECUTION_TIME optimizer hint after the SELECT keyword
+// of a query starting with SELECT.
+// Other statements don't support the hint and are returned unchanged,
+// as well as queries that already have a MAX_EXECUTION_TIME hint.
+func (QueryFormatter) QueryWithTimeoutHint(query string, timeout time.Duration) string {
+	ms := timeout.Milliseconds()
+	if ms <= 0 {
+		return query
+	}
+	trimmed := strings.TrimLeft(query, " \t\r\n")
+	const selectKeyword = "SELECT"
+	if len(trimmed) <= len(selectKeyword) ||
+		!strings.EqualFold(trimmed[:len(selectKeyword)], selectKeyword) ||
+		!strings.ContainsRune(" \t\r\n", rune(trimmed[len(selectKeyword)])) ||
+		strings.Contains(strings.ToUpper(trimmed), "MAX_EXECUTION_TIME") {
+		return query
+	}
+	pos := len(query) - len(trimmed) + len(selectKeyword)
+	return query[:pos] + fmt.Sprintf(" /*+ MAX_EXECUTION_TIME(%d) */", ms) + query[pos:]
+}
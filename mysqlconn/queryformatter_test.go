@@ -3,6 +3,7 @@ package mysqlconn
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestEscapeIdentifier(t *testing.T) {
@@ -202,3 +203,28 @@ func TestQueryFormatter_FormatStringLiteral(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryFormatter_QueryWithTimeoutHint(t *testing.T) {
+	f := QueryFormatter{}
+	tests := []struct {
+		name    string
+		query   string
+		timeout time.Duration
+		want    string
+	}{
+		{name: "select", query: "SELECT * FROM t", timeout: time.Second, want: "SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM t"},
+		{name: "lowercase", query: "select 1", timeout: 2500 * time.Millisecond, want: "select /*+ MAX_EXECUTION_TIME(2500) */ 1"},
+		{name: "leading whitespace", query: "\n\tSELECT 1", timeout: time.Second, want: "\n\tSELECT /*+ MAX_EXECUTION_TIME(1000) */ 1"},
+		{name: "existing hint", query: "SELECT /*+ MAX_EXECUTION_TIME(5) */ 1", timeout: time.Second, want: "SELECT /*+ MAX_EXECUTION_TIME(5) */ 1"},
+		{name: "update", query: "UPDATE t SET a = 1", timeout: time.Second, want: "UPDATE t SET a = 1"},
+		{name: "word starting with select", query: "SELECTED", timeout: time.Second, want: "SELECTED"},
+		{name: "sub millisecond", query: "SELECT 1", timeout: time.Microsecond, want: "SELECT 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.QueryWithTimeoutHint(tt.query, tt.timeout); got != tt.want {
+				t.Errorf("QueryWithTimeoutHint(%q, %s) = %q, want %q", tt.query, tt.timeout, got, tt.want)
+			}
+		})
+	}
+}
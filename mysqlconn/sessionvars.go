@@ -0,0 +1,78 @@
+package mysqlconn
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// SetSessionVars implements sqldb.SessionVarsSetter by setting the variables
+// as user variables that can be read with @`name`.
+// MySQL has no transaction scoped variables, so the returned reset function
+// restores the values the user variables had before.
+func (conn *transaction) SetSessionVars(ctx context.Context, vars map[string]string) (reset func(context.Context) error, err error) {
+	names := slices.Sorted(maps.Keys(vars))
+
+	prev := make([]sql.NullString, len(names))
+	dest := make([]any, len(names))
+	for i := range prev {
+		dest[i] = &prev[i]
+	}
+	err = conn.tx.QueryRowContext(ctx, selectUserVarsQuery(names)).Scan(dest...)
+	if err != nil {
+		return nil, wrapKnownErrors(err)
+	}
+
+	setQuery := setUserVarsQuery(names)
+	args := make([]any, len(names))
+	for i, name := range names {
+		args[i] = vars[name]
+	}
+	err = conn.Exec(ctx, setQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		for i := range prev {
+			if prev[i].Valid {
+				args[i] = prev[i].String
+			} else {
+				args[i] = nil
+			}
+		}
+		return conn.Exec(ctx, setQuery, args...)
+	}, nil
+}
+
+// userVar returns the name as quoted MySQL user variable.
+func userVar(name string) string {
+	return "@`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func selectUserVarsQuery(names []string) string {
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(userVar(name))
+	}
+	return b.String()
+}
+
+func setUserVarsQuery(names []string) string {
+	var b strings.Builder
+	b.WriteString("SET ")
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(userVar(name))
+		b.WriteString(" = ?")
+	}
+	return b.String()
+}
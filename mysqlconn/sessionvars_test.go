@@ -0,0 +1,33 @@
+package mysqlconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_userVar(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "tenant_id", want: "@`tenant_id`"},
+		{name: "app.tenant_id", want: "@`app.tenant_id`"},
+		{name: "with`backtick", want: "@`with``backtick`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, userVar(tt.name))
+		})
+	}
+}
+
+func Test_selectUserVarsQuery(t *testing.T) {
+	assert.Equal(t, "SELECT @`a`", selectUserVarsQuery([]string{"a"}))
+	assert.Equal(t, "SELECT @`app.tenant_id`, @`app.user_id`", selectUserVarsQuery([]string{"app.tenant_id", "app.user_id"}))
+}
+
+func Test_setUserVarsQuery(t *testing.T) {
+	assert.Equal(t, "SET @`a` = ?", setUserVarsQuery([]string{"a"}))
+	assert.Equal(t, "SET @`app.tenant_id` = ?, @`app.user_id` = ?", setUserVarsQuery([]string{"app.tenant_id", "app.user_id"}))
+}
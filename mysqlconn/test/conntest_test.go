@@ -33,11 +33,20 @@ func TestConnectionSuite(t *testing.T) {
 				name  TEXT NOT NULL,
 				score INT NOT NULL DEFAULT 0
 			)`,
-			// MySQL does not support RETURNING clause
+			CreateReturningTable: /*sql*/ `CREATE TABLE conntest_returning (
+				id    INT AUTO_INCREMENT PRIMARY KEY,
+				name  TEXT NOT NULL,
+				score INT NOT NULL DEFAULT 0
+			)`,
 			CreateMailAddressTable: /*sql*/ `CREATE TABLE conntest_mail_address (
 				id    INT PRIMARY KEY,
 				email TEXT
 			)`,
+			CreateJSONTable: /*sql*/ `CREATE TABLE conntest_json (
+				id   INT PRIMARY KEY,
+				data JSON,
+				tags JSON
+			)`,
 			CreateInfoParent: /*sql*/ `CREATE TABLE conntest_info_parent (
 				id1 INT NOT NULL,
 				id2 INT NOT NULL,
@@ -64,6 +73,7 @@ func TestConnectionSuite(t *testing.T) {
 		SupportsReadOnlyTransaction:  true,
 		SupportsCustomIsolationLevel: true,
 		ExecAfterClosedTxErrors:      true,
+		SessionVarQuery:              "SELECT @`conntest.tenant_id`",
 		Information: conntest.InformationFeatures{
 			SupportsRoutines: true,
 		},
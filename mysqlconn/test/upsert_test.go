@@ -85,6 +85,40 @@ func TestUpsertRowStruct(t *testing.T) {
 	})
 }
 
+func TestUpsertRowStruct_UpsertOptions(t *testing.T) {
+	conn, err := mysqlconn.Connect(t.Context(), testConfig())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	createMySQLUpsertTable(t, conn)
+
+	ctx := t.Context()
+	qb := mysqlconn.QueryBuilder{}
+	options := sqldb.UpsertOptions{
+		UpdateColumns:     []string{"score"},
+		UpdateExpressions: map[string]string{"name": "CONCAT(name, '+', VALUES(name))"},
+		Where:             "VALUES(score) > score",
+	}
+	queryRow := func(t *testing.T) upsertRow {
+		t.Helper()
+		rows := conn.Query(ctx,
+			/*sql*/ `SELECT id, name, score FROM test_upsert WHERE id = ?`, 1,
+		)
+		require.True(t, rows.Next())
+		var got upsertRow
+		require.NoError(t, rows.Scan(&got.ID, &got.Name, &got.Score))
+		require.NoError(t, rows.Close())
+		return got
+	}
+
+	require.NoError(t, sqldb.UpsertRowStruct(ctx, conn, refl, qb, conn, &upsertRow{ID: 1, Name: "a", Score: 1}, options))
+	require.NoError(t, sqldb.UpsertRowStruct(ctx, conn, refl, qb, conn, &upsertRow{ID: 1, Name: "b", Score: 2}, options))
+	assert.Equal(t, upsertRow{ID: 1, Name: "a+b", Score: 2}, queryRow(t), "updated because score is greater")
+
+	require.NoError(t, sqldb.UpsertRowStruct(ctx, conn, refl, qb, conn, &upsertRow{ID: 1, Name: "c", Score: 2}, options))
+	assert.Equal(t, upsertRow{ID: 1, Name: "a+b", Score: 2}, queryRow(t), "not updated because score is not greater")
+}
+
 func TestUpsertRowStructs(t *testing.T) {
 	conn, err := mysqlconn.Connect(t.Context(), testConfig())
 	require.NoError(t, err)
@@ -50,7 +50,7 @@ func Connect(ctx context.Context, config *sqldb.Config, lowercaseColumns bool) (
 		}
 		return nil, err
 	}
-	return &connection{db: db, config: config, lowercaseColumns: lowercaseColumns}, nil
+	return sqldb.WithDefaultTimeouts(&connection{db: db, config: config, lowercaseColumns: lowercaseColumns}), nil
 }
 
 // formatDSN converts a sqldb.Config to an Oracle connection URL
@@ -1,7 +1,9 @@
 package oraconn
 
 import (
+	"database/sql/driver"
 	"errors"
+	"io"
 	"slices"
 	"strings"
 
@@ -14,13 +16,18 @@ import (
 // https://docs.oracle.com/en/database/oracle/oracle-database/23/errmg/
 const (
 	errUniqueViolation      = 1     // ORA-00001: unique constraint violated
+	errResourceBusy         = 54    // ORA-00054: resource busy and acquire with NOWAIT specified or timeout expired
 	errDeadlock             = 60    // ORA-00060: deadlock detected while waiting for resource
 	errCannotInsertNull     = 1400  // ORA-01400: cannot insert NULL
 	errQueryCanceled        = 1013  // ORA-01013: user requested cancel of current operation
 	errFKParentNotFound     = 2291  // ORA-02291: integrity constraint - parent key not found
 	errFKChildRecordFound   = 2292  // ORA-02292: integrity constraint - child record found
 	errCheckViolation       = 2290  // ORA-02290: check constraint violated
+	errEndOfFileOnChannel   = 3113  // ORA-03113: end-of-file on communication channel
+	errNotConnected         = 3114  // ORA-03114: not connected to ORACLE
+	errConnectionLost       = 3135  // ORA-03135: connection lost contact
 	errSerializationFailure = 8177  // ORA-08177: can't serialize access for this transaction
+	errResourceBusyTimeout  = 30006 // ORA-30006: resource busy; acquire with WAIT timeout expired
 	errRaisedUserException  = 20000 // ORA-20000 through ORA-20999: user-defined exceptions
 )
 
@@ -30,6 +37,9 @@ func wrapKnownErrors(err error) error {
 	}
 	var oraErr *network.OracleError
 	if !errors.As(err, &oraErr) {
+		if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return errors.Join(sqldb.ErrConnectionLost, err)
+		}
 		return err
 	}
 	constraint := extractConstraintName(oraErr.ErrMsg)
@@ -48,6 +58,10 @@ func wrapKnownErrors(err error) error {
 		return errors.Join(sqldb.ErrSerializationFailure, err)
 	case oraErr.ErrCode == errQueryCanceled:
 		return errors.Join(sqldb.ErrQueryCanceled, err)
+	case oraErr.ErrCode == errResourceBusy || oraErr.ErrCode == errResourceBusyTimeout:
+		return errors.Join(sqldb.ErrLockTimeout, err)
+	case oraErr.ErrCode == errEndOfFileOnChannel || oraErr.ErrCode == errNotConnected || oraErr.ErrCode == errConnectionLost:
+		return errors.Join(sqldb.ErrConnectionLost, err)
 	case oraErr.ErrCode >= errRaisedUserException && oraErr.ErrCode <= 20999:
 		return errors.Join(sqldb.ErrRaisedException{Message: oraErr.ErrMsg}, err)
 	}
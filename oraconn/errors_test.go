@@ -1,7 +1,9 @@
 package oraconn
 
 import (
+	"database/sql/driver"
 	"errors"
+	"io"
 	"testing"
 
 	"github.com/sijms/go-ora/v2/network"
@@ -120,6 +122,31 @@ func Test_wrapKnownErrors(t *testing.T) {
 			inputErr:     &network.OracleError{ErrCode: 1013, ErrMsg: "ORA-01013: user requested cancel of current operation"},
 			wantSentinel: sqldb.ErrQueryCanceled,
 		},
+		{
+			name:         "ORA-08177 serialization failure wraps to ErrSerializationFailure",
+			inputErr:     &network.OracleError{ErrCode: 8177, ErrMsg: "ORA-08177: can't serialize access for this transaction"},
+			wantSentinel: sqldb.ErrSerializationFailure,
+		},
+		{
+			name:         "ORA-00054 resource busy wraps to ErrLockTimeout",
+			inputErr:     &network.OracleError{ErrCode: 54, ErrMsg: "ORA-00054: resource busy and acquire with NOWAIT specified or timeout expired"},
+			wantSentinel: sqldb.ErrLockTimeout,
+		},
+		{
+			name:         "ORA-30006 resource busy with WAIT timeout wraps to ErrLockTimeout",
+			inputErr:     &network.OracleError{ErrCode: 30006, ErrMsg: "ORA-30006: resource busy; acquire with WAIT timeout expired"},
+			wantSentinel: sqldb.ErrLockTimeout,
+		},
+		{
+			name:         "ORA-03113 end-of-file on communication channel wraps to ErrConnectionLost",
+			inputErr:     &network.OracleError{ErrCode: 3113, ErrMsg: "ORA-03113: end-of-file on communication channel"},
+			wantSentinel: sqldb.ErrConnectionLost,
+		},
+		{
+			name:         "ORA-03135 connection lost contact wraps to ErrConnectionLost",
+			inputErr:     &network.OracleError{ErrCode: 3135, ErrMsg: "ORA-03135: connection lost contact"},
+			wantSentinel: sqldb.ErrConnectionLost,
+		},
 		{
 			name:        "ORA-20000 raised exception wraps to ErrRaisedException",
 			inputErr:    &network.OracleError{ErrCode: 20000, ErrMsg: "custom raised message"},
@@ -216,6 +243,19 @@ func Test_wrapKnownErrors(t *testing.T) {
 	}
 }
 
+func Test_wrapKnownErrors_connectionLost(t *testing.T) {
+	for _, inputErr := range []error{driver.ErrBadConn, io.ErrUnexpectedEOF} {
+		t.Run(inputErr.Error(), func(t *testing.T) {
+			// when
+			result := wrapKnownErrors(inputErr)
+
+			// then
+			assert.ErrorIs(t, result, sqldb.ErrConnectionLost)
+			assert.ErrorIs(t, result, inputErr, "original error should be preserved")
+		})
+	}
+}
+
 func TestIsUniqueViolation(t *testing.T) {
 	for _, scenario := range []struct {
 		name string
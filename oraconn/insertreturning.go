@@ -0,0 +1,121 @@
+package oraconn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/domonda/go-sqldb"
+)
+
+var (
+	_ sqldb.InsertRowsReturner = new(connection)
+	_ sqldb.InsertRowsReturner = new(transaction)
+	_ sqldb.InsertRowsReturner = new(pinnedConn)
+)
+
+// InsertRowsReturningInto implements [sqldb.InsertRowsReturner], see insertRowsReturningInto.
+// Multiple rows are inserted within a transaction
+// that is committed after all rows were inserted.
+func (conn *connection) InsertRowsReturningInto(ctx context.Context, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	if numRows == 1 {
+		return insertRowsReturningInto(ctx, conn.db, table, columns, numRows, vals, returningColumns, dest)
+	}
+	tx, err := conn.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapKnownErrors(err)
+	}
+	return insertRowsReturningIntoTx(ctx, tx, table, columns, numRows, vals, returningColumns, dest)
+}
+
+// InsertRowsReturningInto implements [sqldb.InsertRowsReturner], see insertRowsReturningInto.
+func (conn *transaction) InsertRowsReturningInto(ctx context.Context, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	return insertRowsReturningInto(ctx, conn.tx, table, columns, numRows, vals, returningColumns, dest)
+}
+
+// InsertRowsReturningInto implements [sqldb.InsertRowsReturner], see insertRowsReturningInto.
+// Multiple rows are inserted within a transaction on the pinned session
+// that is committed after all rows were inserted.
+func (conn *pinnedConn) InsertRowsReturningInto(ctx context.Context, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	if numRows == 1 {
+		return insertRowsReturningInto(ctx, conn.conn, table, columns, numRows, vals, returningColumns, dest)
+	}
+	tx, err := conn.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapKnownErrors(err)
+	}
+	return insertRowsReturningIntoTx(ctx, tx, table, columns, numRows, vals, returningColumns, dest)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertRowsReturningIntoTx calls insertRowsReturningInto
+// and commits tx on success, or rolls it back on error.
+func insertRowsReturningIntoTx(ctx context.Context, tx *sql.Tx, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	err := insertRowsReturningInto(ctx, tx, table, columns, numRows, vals, returningColumns, dest)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return wrapKnownErrors(tx.Commit())
+}
+
+// insertRowsReturningInto executes one INSERT with a RETURNING INTO clause
+// per row because Oracle supports RETURNING INTO only for single-row inserts.
+// The returned values are bound to the destinations as [sql.Out] arguments.
+func insertRowsReturningInto(ctx context.Context, conn execer, table string, columns []sqldb.ColumnInfo, numRows int, vals []any, returningColumns []sqldb.ColumnInfo, dest func(rowIndex int) []any) error {
+	if len(vals) != numRows*len(columns) {
+		return fmt.Errorf("InsertRowsReturningInto: expected %d values for %d rows, got %d", numRows*len(columns), numRows, len(vals))
+	}
+	query, err := insertReturningIntoQuery(QueryFormatter{}, table, columns, returningColumns)
+	if err != nil {
+		return err
+	}
+	args := make([]any, len(columns)+len(returningColumns))
+	for rowIndex := range numRows {
+		copy(args, vals[rowIndex*len(columns):(rowIndex+1)*len(columns)])
+		for i, d := range dest(rowIndex) {
+			args[len(columns)+i] = sql.Out{Dest: d}
+		}
+		if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+			return wrapKnownErrors(err)
+		}
+	}
+	return nil
+}
+
+// insertReturningIntoQuery builds a single-row INSERT query
+// with a RETURNING INTO clause binding the returningColumns
+// to the placeholders following the placeholders of the columns:
+//
+//	INSERT INTO table(name,email) VALUES(:1,:2) RETURNING id,created_at INTO :3,:4
+func insertReturningIntoQuery(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo, returningColumns []sqldb.ColumnInfo) (string, error) {
+	query, err := QueryBuilder{}.Insert(formatter, table, columns)
+	if err != nil {
+		return "", err
+	}
+	var q strings.Builder
+	q.WriteString(query)
+	q.WriteString(` RETURNING `)
+	for i, col := range returningColumns {
+		if i > 0 {
+			q.WriteByte(',')
+		}
+		colName, err := formatter.FormatColumnName(col.Name)
+		if err != nil {
+			return "", err
+		}
+		q.WriteString(colName)
+	}
+	q.WriteString(` INTO `)
+	for i := range returningColumns {
+		if i > 0 {
+			q.WriteByte(',')
+		}
+		q.WriteString(formatter.FormatPlaceholder(len(columns) + i))
+	}
+	return q.String(), nil
+}
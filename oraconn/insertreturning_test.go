@@ -0,0 +1,27 @@
+package oraconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func Test_insertReturningIntoQuery(t *testing.T) {
+	columns := []sqldb.ColumnInfo{{Name: "name"}, {Name: "email"}}
+
+	t.Run("returning columns", func(t *testing.T) {
+		returning := []sqldb.ColumnInfo{{Name: "id", PrimaryKey: true, HasDefault: true}, {Name: "created_at", HasDefault: true}}
+		query, err := insertReturningIntoQuery(QueryFormatter{}, "accounts", columns, returning)
+		require.NoError(t, err)
+		assert.Equal(t, `INSERT INTO accounts(name,email) VALUES(:1,:2) RETURNING id,created_at INTO :3,:4`, query)
+	})
+
+	t.Run("invalid returning column", func(t *testing.T) {
+		returning := []sqldb.ColumnInfo{{Name: "id; DROP TABLE accounts"}}
+		_, err := insertReturningIntoQuery(QueryFormatter{}, "accounts", columns, returning)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,67 @@
+package oraconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func TestQueryBuilder_UpsertWithOptions(t *testing.T) {
+	b := QueryBuilder{}
+	columns := []sqldb.ColumnInfo{
+		{Name: "id", PrimaryKey: true},
+		{Name: "count"},
+		{Name: "version"},
+	}
+
+	for _, scenario := range []struct {
+		name      string
+		options   sqldb.UpsertOptions
+		wantQuery string
+	}{
+		{
+			name: "zero options like Upsert",
+			wantQuery: `MERGE INTO counters target` +
+				` USING (SELECT :1 AS id, :2 AS count, :3 AS version FROM DUAL) source` +
+				` ON (target.id = source.id)` +
+				` WHEN MATCHED THEN UPDATE SET target.count = source.count, target.version = source.version` +
+				` WHEN NOT MATCHED THEN INSERT (id,count,version) VALUES (source.id,source.count,source.version)`,
+		},
+		{
+			name: "expression and where condition",
+			options: sqldb.UpsertOptions{
+				UpdateColumns:     []string{"version"},
+				UpdateExpressions: map[string]string{"count": "target.count + source.count"},
+				Where:             "source.version > target.version",
+			},
+			wantQuery: `MERGE INTO counters target` +
+				` USING (SELECT :1 AS id, :2 AS count, :3 AS version FROM DUAL) source` +
+				` ON (target.id = source.id)` +
+				` WHEN MATCHED THEN UPDATE SET target.version = source.version, target.count = target.count + source.count` +
+				` WHERE source.version > target.version` +
+				` WHEN NOT MATCHED THEN INSERT (id,count,version) VALUES (source.id,source.count,source.version)`,
+		},
+		{
+			name:    "version column",
+			options: sqldb.UpsertOptions{VersionColumn: "version"},
+			wantQuery: `MERGE INTO counters target` +
+				` USING (SELECT :1 AS id, :2 AS count, :3 AS version FROM DUAL) source` +
+				` ON (target.id = source.id)` +
+				` WHEN MATCHED THEN UPDATE SET target.count = source.count, target.version = source.version` +
+				` WHERE target.version = source.version - 1` +
+				` WHEN NOT MATCHED THEN INSERT (id,count,version) VALUES (source.id,source.count,source.version)`,
+		},
+	} {
+		t.Run(scenario.name, func(t *testing.T) {
+			// when
+			query, err := b.UpsertWithOptions(QueryFormatter{}, "counters", columns, scenario.options)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, scenario.wantQuery, query)
+		})
+	}
+}
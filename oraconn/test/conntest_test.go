@@ -33,11 +33,20 @@ func TestConnectionSuite(t *testing.T) {
 				name  VARCHAR2(255) NOT NULL,
 				score NUMBER(10) DEFAULT 0 NOT NULL
 			)`,
-			// Oracle does not support RETURNING via QueryBuilder
+			CreateReturningTable: /*sql*/ `CREATE TABLE conntest_returning (
+				id    NUMBER(10) GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+				name  VARCHAR2(255) NOT NULL,
+				score NUMBER(10) DEFAULT 0 NOT NULL
+			)`,
 			CreateMailAddressTable: /*sql*/ `CREATE TABLE conntest_mail_address (
 				id    NUMBER(10) PRIMARY KEY,
 				email VARCHAR2(255)
 			)`,
+			CreateJSONTable: /*sql*/ `CREATE TABLE conntest_json (
+				id   NUMBER(10) PRIMARY KEY,
+				data CLOB CHECK (data IS JSON),
+				tags CLOB CHECK (tags IS JSON)
+			)`,
 			CreateInfoParent: /*sql*/ `CREATE TABLE conntest_info_parent (
 				id1 NUMBER(10) NOT NULL,
 				id2 NUMBER(10) NOT NULL,
@@ -75,6 +75,10 @@ func (conn *transaction) Prepare(ctx context.Context, query string) (sqldb.Stmt,
 	return sqldb.NewStmt(stmt, query, wrapKnownErrors), nil
 }
 
+func (conn *transaction) BindStmt(ctx context.Context, stmt sqldb.Stmt) (sqldb.Stmt, error) {
+	return sqldb.BindStmtToTx(ctx, conn.tx, stmt)
+}
+
 func (*transaction) DefaultIsolationLevel() sql.IsolationLevel {
 	return sql.LevelReadCommitted // Oracle default
 }
@@ -0,0 +1,40 @@
+/*
+Package otelsqldb traces github.com/domonda/go-sqldb.Connection
+operations with OpenTelemetry.
+
+It is a separate module so that the core sqldb module
+does not depend on OpenTelemetry.
+
+Basic usage:
+
+	import (
+		"github.com/domonda/go-sqldb"
+		"github.com/domonda/go-sqldb/db"
+		"github.com/domonda/go-sqldb/otelsqldb"
+		"github.com/domonda/go-sqldb/pqconn"
+	)
+
+	conn, err := pqconn.Connect(ctx, config)
+	if err != nil {
+		panic(err)
+	}
+	db.SetConn(otelsqldb.WrapConnection(conn))
+
+Every Exec, ExecRowsAffected, Query, and Prepare call creates a client span
+named after the SQL operation (e.g. SELECT, INSERT) with the attributes:
+  - db.system: the Config.Driver of the connection
+  - db.query.text: the query normalized by sqldb.NewQueryNormalizer
+  - db.operation.name: the first keyword of the query
+  - db.rows_affected: for ExecRowsAffected
+  - db.response.returned_rows: for Query, recorded when the Rows are closed
+  - error.type: the generic error kind from sqldb.ErrorKind
+  - sqldb.transaction.id: if the query runs within a transaction
+
+Begin starts a transaction span that ends with Commit or Rollback.
+Query spans of the transaction are nested under the transaction span.
+
+Query arguments are only recorded with the WithArgs option,
+formatted with sqldb.FormatValue so that sqldb.Secret values
+like the ones wrapped with sqldb.KeepSecret are always redacted.
+*/
+package otelsqldb
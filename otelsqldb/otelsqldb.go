@@ -0,0 +1,331 @@
+package otelsqldb
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// InstrumentationName is the name of the OpenTelemetry tracer
+// used by this package.
+const InstrumentationName = "github.com/domonda/go-sqldb/otelsqldb"
+
+// Attribute keys used for spans in addition to
+// the OpenTelemetry semantic convention keys.
+const (
+	// RowsAffectedKey is the attribute key for the number of rows
+	// affected by ExecRowsAffected.
+	RowsAffectedKey = attribute.Key("db.rows_affected")
+
+	// TransactionIDKey is the attribute key for the
+	// sqldb.TransactionState.ID of a transaction.
+	TransactionIDKey = attribute.Key("sqldb.transaction.id")
+
+	// TransactionOutcomeKey is the attribute key for the outcome
+	// of a transaction span, either "commit" or "rollback".
+	TransactionOutcomeKey = attribute.Key("sqldb.transaction.outcome")
+)
+
+const (
+	dbSystemKey                = attribute.Key("db.system")
+	dbQueryTextKey             = attribute.Key("db.query.text")
+	dbOperationNameKey         = attribute.Key("db.operation.name")
+	dbResponseReturnedRowsKey  = attribute.Key("db.response.returned_rows")
+	dbQueryParameterKeyPrefix  = "db.query.parameter."
+	dbTransactionIsolationKey  = attribute.Key("db.transaction.isolation_level")
+	dbTransactionReadOnlyKey   = attribute.Key("db.transaction.read_only")
+	errorTypeKey               = attribute.Key("error.type")
+	transactionSpanName        = "TRANSACTION"
+	defaultOperationSpanName   = "SQL"
+	prepareOperationSpanPrefix = "PREPARE "
+)
+
+// Option configures the tracing of [NewInterceptor] and [WrapConnection].
+type Option func(*tracer)
+
+// WithTracerProvider sets the TracerProvider used to create spans.
+// The global TracerProvider from otel.GetTracerProvider is used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(t *tracer) {
+		t.provider = provider
+	}
+}
+
+// WithNormalizeQuery sets the function used to normalize queries
+// for the db.query.text attribute. The default is sqldb.NewQueryNormalizer.
+// Pass sqldb.NoChangeNormalizeQuery to record queries unchanged.
+func WithNormalizeQuery(normalize sqldb.NormalizeQueryFunc) Option {
+	return func(t *tracer) {
+		t.normalize = normalize
+	}
+}
+
+// WithArgs enables recording query arguments as db.query.parameter.<index>
+// attributes formatted with sqldb.FormatValue.
+// Arguments implementing sqldb.Secret are always recorded redacted.
+func WithArgs() Option {
+	return func(t *tracer) {
+		t.recordArgs = true
+	}
+}
+
+// WithAttributes adds attributes to every span.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(t *tracer) {
+		t.attrs = append(t.attrs, attrs...)
+	}
+}
+
+// WrapConnection returns conn wrapped with the tracing
+// interceptor returned by [NewInterceptor].
+func WrapConnection(conn sqldb.Connection, opts ...Option) sqldb.Connection {
+	return sqldb.WrapConnection(conn, NewInterceptor(opts...))
+}
+
+// NewInterceptor returns a sqldb.Interceptor that creates
+// OpenTelemetry spans for queries and transactions.
+// Use it with sqldb.WrapConnection to combine it with other interceptors.
+func NewInterceptor(opts ...Option) sqldb.Interceptor {
+	t := &tracer{
+		provider:  otel.GetTracerProvider(),
+		normalize: sqldb.NewQueryNormalizer(),
+		txSpans:   make(map[uint64]trace.Span),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.tracer = t.provider.Tracer(InstrumentationName)
+	return sqldb.Interceptor{
+		Exec:             t.exec,
+		ExecRowsAffected: t.execRowsAffected,
+		Query:            t.query,
+		Prepare:          t.prepare,
+		Begin:            t.begin,
+		Commit:           t.commit,
+		Rollback:         t.rollback,
+		Close:            t.close,
+	}
+}
+
+type tracer struct {
+	provider   trace.TracerProvider
+	tracer     trace.Tracer
+	normalize  sqldb.NormalizeQueryFunc
+	recordArgs bool
+	attrs      []attribute.KeyValue
+
+	// txSpans holds the spans of open transactions by transaction ID
+	txSpansMtx sync.Mutex
+	txSpans    map[uint64]trace.Span
+}
+
+func (t *tracer) exec(ctx context.Context, conn sqldb.Connection, query string, args []any, next sqldb.ExecFunc) error {
+	ctx, span := t.startQuerySpan(ctx, conn, "", query, args)
+	defer span.End()
+
+	err := next(ctx, query, args)
+	setError(span, err)
+	return err
+}
+
+func (t *tracer) execRowsAffected(ctx context.Context, conn sqldb.Connection, query string, args []any, next sqldb.ExecRowsAffectedFunc) (int64, error) {
+	ctx, span := t.startQuerySpan(ctx, conn, "", query, args)
+	defer span.End()
+
+	n, err := next(ctx, query, args)
+	if err == nil {
+		span.SetAttributes(RowsAffectedKey.Int64(n))
+	}
+	setError(span, err)
+	return n, err
+}
+
+func (t *tracer) query(ctx context.Context, conn sqldb.Connection, query string, args []any, next sqldb.QueryFunc) sqldb.Rows {
+	ctx, span := t.startQuerySpan(ctx, conn, "", query, args)
+	// The span is ended when the rows are closed
+	return &tracedRows{Rows: next(ctx, query, args), span: span}
+}
+
+func (t *tracer) prepare(ctx context.Context, conn sqldb.Connection, query string, next sqldb.PrepareFunc) (sqldb.Stmt, error) {
+	ctx, span := t.startQuerySpan(ctx, conn, prepareOperationSpanPrefix, query, nil)
+	defer span.End()
+
+	stmt, err := next(ctx, query)
+	setError(span, err)
+	return stmt, err
+}
+
+func (t *tracer) begin(ctx context.Context, conn sqldb.Connection, id uint64, opts *sql.TxOptions, next sqldb.BeginFunc) (sqldb.Connection, error) {
+	attrs := append(t.connAttributes(conn), TransactionIDKey.Int64(int64(id))) //#nosec G115 -- transaction IDs don't overflow int64
+	if opts != nil {
+		attrs = append(attrs,
+			dbTransactionIsolationKey.String(opts.Isolation.String()),
+			dbTransactionReadOnlyKey.Bool(opts.ReadOnly),
+		)
+	}
+	ctx, span := t.tracer.Start(
+		t.parentContext(ctx, conn),
+		transactionSpanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	tx, err := next(ctx, id, opts)
+	if err != nil {
+		setError(span, err)
+		span.End()
+		return nil, err
+	}
+	t.txSpansMtx.Lock()
+	t.txSpans[id] = span
+	t.txSpansMtx.Unlock()
+	return tx, nil
+}
+
+func (t *tracer) commit(conn sqldb.Connection, next func() error) error {
+	return t.endTransaction(conn, "commit", next)
+}
+
+func (t *tracer) rollback(conn sqldb.Connection, next func() error) error {
+	return t.endTransaction(conn, "rollback", next)
+}
+
+// close ends the span of a transaction that is
+// rolled back by closing it without Commit or Rollback.
+func (t *tracer) close(conn sqldb.Connection, next func() error) error {
+	if conn.Transaction().ID == 0 {
+		return next()
+	}
+	return t.endTransaction(conn, "rollback", next)
+}
+
+func (t *tracer) endTransaction(conn sqldb.Connection, outcome string, next func() error) error {
+	id := conn.Transaction().ID
+	t.txSpansMtx.Lock()
+	span, ok := t.txSpans[id]
+	delete(t.txSpans, id)
+	t.txSpansMtx.Unlock()
+
+	err := next()
+	if ok {
+		span.SetAttributes(TransactionOutcomeKey.String(outcome))
+		setError(span, err)
+		span.End()
+	}
+	return err
+}
+
+// parentContext returns ctx with the span of the transaction
+// of conn as parent span if conn is a traced transaction.
+func (t *tracer) parentContext(ctx context.Context, conn sqldb.Connection) context.Context {
+	id := conn.Transaction().ID
+	if id == 0 {
+		return ctx
+	}
+	t.txSpansMtx.Lock()
+	span, ok := t.txSpans[id]
+	t.txSpansMtx.Unlock()
+	if !ok {
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, span)
+}
+
+func (t *tracer) connAttributes(conn sqldb.Connection) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(t.attrs)+8)
+	attrs = append(attrs, t.attrs...)
+	if config := conn.Config(); config != nil && config.Driver != "" {
+		attrs = append(attrs, dbSystemKey.String(config.Driver))
+	}
+	return attrs
+}
+
+func (t *tracer) startQuerySpan(ctx context.Context, conn sqldb.Connection, spanNamePrefix, query string, args []any) (context.Context, trace.Span) {
+	attrs := t.connAttributes(conn)
+	operation := sqldb.QueryKeyword(query)
+	if operation != "" {
+		attrs = append(attrs, dbOperationNameKey.String(operation))
+	}
+	if normalized, err := t.normalize(query); err == nil {
+		// Queries that could not be normalized are not recorded
+		attrs = append(attrs, dbQueryTextKey.String(normalized))
+	}
+	if id := conn.Transaction().ID; id != 0 {
+		attrs = append(attrs, TransactionIDKey.Int64(int64(id))) //#nosec G115 -- transaction IDs don't overflow int64
+	}
+	if t.recordArgs {
+		for i, arg := range args {
+			// FormatValue redacts sqldb.Secret values
+			value, err := sqldb.FormatValue(arg)
+			if err != nil {
+				value = "<" + err.Error() + ">"
+			}
+			attrs = append(attrs, attribute.String(dbQueryParameterKeyPrefix+strconv.Itoa(i), value))
+		}
+	}
+	spanName := spanNamePrefix + operation
+	if operation == "" {
+		spanName = spanNamePrefix + defaultOperationSpanName
+	}
+	return t.tracer.Start(
+		t.parentContext(ctx, conn),
+		spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func setError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.SetAttributes(errorTypeKey.String(sqldb.ErrorKind(err)))
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// tracedRows ends the query span when the rows are closed
+// or iterated to the end.
+type tracedRows struct {
+	sqldb.Rows
+	span    trace.Span
+	numRows int64
+	ended   bool
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		r.numRows++
+		return true
+	}
+	// Rows are closed automatically after the last row
+	r.end(nil)
+	return false
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	r.end(err)
+	return err
+}
+
+func (r *tracedRows) end(closeErr error) {
+	if r.ended {
+		return
+	}
+	r.ended = true
+	r.span.SetAttributes(dbResponseReturnedRowsKey.Int64(r.numRows))
+	err := r.Rows.Err()
+	if err == nil {
+		err = closeErr
+	}
+	setError(r.span, err)
+	r.span.End()
+}
@@ -0,0 +1,181 @@
+package otelsqldb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func newTestConn(t *testing.T, opts ...Option) (*sqldb.MockConn, sqldb.Connection, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	conn := WrapConnection(mock, append([]Option{WithTracerProvider(provider)}, opts...)...)
+	return mock, conn, exporter
+}
+
+func attrs(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestExec(t *testing.T) {
+	// given
+	_, conn, exporter := newTestConn(t)
+
+	// when
+	err := conn.Exec(t.Context(), "UPDATE users\n\tSET name = $1\n\tWHERE id = $2", "name", 1)
+
+	// then
+	require.NoError(t, err)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "UPDATE", spans[0].Name)
+	a := attrs(spans[0])
+	assert.Equal(t, "MockConn", a[dbSystemKey].AsString())
+	assert.Equal(t, "UPDATE", a[dbOperationNameKey].AsString())
+	assert.Equal(t, "UPDATE users SET name = $1 WHERE id = $2", a[dbQueryTextKey].AsString())
+	assert.NotContains(t, a, attribute.Key(dbQueryParameterKeyPrefix+"0"), "args are only recorded with WithArgs")
+}
+
+func TestExecRowsAffected(t *testing.T) {
+	// given
+	mock, conn, exporter := newTestConn(t)
+	mock.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+		return 3, nil
+	}
+
+	// when
+	n, err := conn.ExecRowsAffected(t.Context(), "DELETE FROM users")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, int64(3), attrs(spans[0])[RowsAffectedKey].AsInt64())
+}
+
+func TestExecError(t *testing.T) {
+	// given
+	mock, conn, exporter := newTestConn(t)
+	mock.MockExec = func(ctx context.Context, query string, args ...any) error {
+		return errors.Join(sqldb.ErrUniqueViolation{Constraint: "users_email_key"}, errors.New("driver error"))
+	}
+
+	// when
+	err := conn.Exec(t.Context(), "INSERT INTO users(email) VALUES($1)", "a@example.com")
+
+	// then
+	require.Error(t, err)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "ErrUniqueViolation", attrs(spans[0])[errorTypeKey].AsString())
+}
+
+func TestQuery(t *testing.T) {
+	// given
+	mock, conn, exporter := newTestConn(t)
+	mock.MockQuery = func(ctx context.Context, query string, args ...any) sqldb.Rows {
+		return sqldb.NewMockRows("id").WithRow(int64(1)).WithRow(int64(2))
+	}
+
+	// when
+	var ids []int
+	err := sqldb.QueryCallback(t.Context(), conn, sqldb.NewTaggedStructReflector(), conn, func(id int) { ids = append(ids, id) }, "SELECT id FROM users")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ids)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "SELECT", spans[0].Name)
+	assert.Equal(t, int64(2), attrs(spans[0])[dbResponseReturnedRowsKey].AsInt64())
+}
+
+func TestWithArgs_RedactsSecrets(t *testing.T) {
+	// given
+	_, conn, exporter := newTestConn(t, WithArgs())
+
+	// when
+	err := conn.Exec(t.Context(), "UPDATE users SET password = $1 WHERE id = $2", sqldb.KeepSecret("hunter2"), 7)
+
+	// then
+	require.NoError(t, err)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	a := attrs(spans[0])
+	assert.NotContains(t, a[attribute.Key(dbQueryParameterKeyPrefix+"0")].AsString(), "hunter2")
+	assert.Equal(t, "7", a[attribute.Key(dbQueryParameterKeyPrefix+"1")].AsString())
+}
+
+func TestTransaction(t *testing.T) {
+	// given
+	_, conn, exporter := newTestConn(t)
+
+	// when
+	err := sqldb.Transaction(t.Context(), conn, nil, func(tx sqldb.Connection) error {
+		return tx.Exec(t.Context(), "INSERT INTO users DEFAULT VALUES")
+	})
+
+	// then
+	require.NoError(t, err)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	insertSpan, txSpan := spans[0], spans[1]
+	assert.Equal(t, "INSERT", insertSpan.Name)
+	assert.Equal(t, transactionSpanName, txSpan.Name)
+	assert.Equal(t, txSpan.SpanContext.SpanID(), insertSpan.Parent.SpanID(), "query span nested under transaction span")
+	assert.Equal(t, "commit", attrs(txSpan)[TransactionOutcomeKey].AsString())
+	assert.Equal(t, attrs(txSpan)[TransactionIDKey], attrs(insertSpan)[TransactionIDKey])
+}
+
+func TestTransactionRollback(t *testing.T) {
+	// given
+	_, conn, exporter := newTestConn(t)
+	errTx := errors.New("tx error")
+
+	// when
+	err := sqldb.Transaction(t.Context(), conn, nil, func(tx sqldb.Connection) error {
+		return errTx
+	})
+
+	// then
+	require.ErrorIs(t, err, errTx)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "rollback", attrs(spans[0])[TransactionOutcomeKey].AsString())
+}
+
+func TestTransactionClose(t *testing.T) {
+	// given
+	_, conn, exporter := newTestConn(t)
+	tx, err := conn.Begin(t.Context(), 1, nil)
+	require.NoError(t, err)
+
+	// when
+	err = tx.Close()
+
+	// then
+	require.NoError(t, err)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, transactionSpanName, spans[0].Name)
+	assert.Equal(t, "rollback", attrs(spans[0])[TransactionOutcomeKey].AsString())
+}
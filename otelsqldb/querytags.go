@@ -0,0 +1,28 @@
+package otelsqldb
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// QueryTags is a sqldb.QueryTagsFunc returning the W3C traceparent
+// and tracestate of the span in the context as query tags,
+// or nil if the context has no valid span.
+//
+// Pass it to sqldb.QueryCommentInterceptor after the tracing interceptor,
+// so that statements are commented with the traceparent of their span
+// and slow queries in the database logs can be correlated with traces:
+//
+//	conn = sqldb.WrapConnection(conn,
+//		otelsqldb.NewInterceptor(),
+//		sqldb.QueryCommentInterceptor(otelsqldb.QueryTags),
+//	)
+func QueryTags(ctx context.Context) map[string]string {
+	carrier := make(propagation.MapCarrier, 2)
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
@@ -0,0 +1,39 @@
+package otelsqldb
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func TestQueryTags(t *testing.T) {
+	t.Run("no span", func(t *testing.T) {
+		assert.Nil(t, QueryTags(t.Context()))
+	})
+
+	t.Run("commented with traceparent of query span", func(t *testing.T) {
+		// given
+		provider := sdktrace.NewTracerProvider()
+		t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+		log := new(bytes.Buffer)
+		mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).WithQueryLog(log)
+		conn := sqldb.WrapConnection(mock,
+			NewInterceptor(WithTracerProvider(provider)),
+			sqldb.QueryCommentInterceptor(QueryTags),
+		)
+
+		// when
+		err := conn.Exec(t.Context(), "DELETE FROM t")
+
+		// then
+		require.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^DELETE FROM t /\*traceparent='00-[0-9a-f]{32}-[0-9a-f]{16}-01'\*/;\n$`), log.String())
+	})
+}
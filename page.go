@@ -0,0 +1,351 @@
+package sqldb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// PageDerivedTableName is the alias of the derived table
+// that wraps the base query of a keyset pagination query
+// built by a [PageQueryBuilder].
+const PageDerivedTableName = "sqldb_page"
+
+// OrderByColumn is a column of the sort order of a query
+// used for keyset pagination with [QueryPage].
+type OrderByColumn struct {
+	Column string
+	Desc   bool
+}
+
+// String returns the column name followed by " DESC"
+// for a descending sort order.
+func (c OrderByColumn) String() string {
+	if c.Desc {
+		return c.Column + " DESC"
+	}
+	return c.Column
+}
+
+// OrderByClause returns the comma separated orderBy columns
+// formatted with the formatter for use after the ORDER BY keyword.
+func OrderByClause(formatter QueryFormatter, orderBy []OrderByColumn) (string, error) {
+	if len(orderBy) == 0 {
+		return "", fmt.Errorf("no order by columns")
+	}
+	var b strings.Builder
+	for i, col := range orderBy {
+		column, err := formatter.FormatColumnName(col.Column)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(column)
+		if col.Desc {
+			b.WriteString(" DESC")
+		}
+	}
+	return b.String(), nil
+}
+
+// KeysetCondition returns a boolean expression that is true for rows
+// sorted by orderBy after the row with the orderBy column values after,
+// together with the args for its placeholders
+// that are numbered starting at firstParamIndex.
+//
+// If rowValues is true and all columns have the same direction,
+// then a row value comparison like (a, b) > ($1, $2) is returned,
+// else the comparison is expanded to an OR-chain like
+// (a > $1 OR (a = $2 AND b > $3)) for databases that
+// don't support row value comparisons or for mixed directions.
+// Every placeholder is bound to its own arg so that the condition
+// also works with drivers that bind placeholders by order of appearance.
+//
+// The orderBy columns must not be nullable
+// because NULL values are not comparable.
+func KeysetCondition(formatter QueryFormatter, orderBy []OrderByColumn, after []any, firstParamIndex int, rowValues bool) (condition string, args []any, err error) {
+	if len(orderBy) == 0 {
+		return "", nil, fmt.Errorf("no order by columns")
+	}
+	if len(after) != len(orderBy) {
+		return "", nil, fmt.Errorf("got %d keyset values for %d order by columns", len(after), len(orderBy))
+	}
+	columns := make([]string, len(orderBy))
+	sameDirection := true
+	for i, col := range orderBy {
+		columns[i], err = formatter.FormatColumnName(col.Column)
+		if err != nil {
+			return "", nil, err
+		}
+		sameDirection = sameDirection && col.Desc == orderBy[0].Desc
+	}
+	op := func(desc bool) string {
+		if desc {
+			return "<"
+		}
+		return ">"
+	}
+
+	var b strings.Builder
+	if len(orderBy) == 1 {
+		fmt.Fprintf(&b, `%s %s %s`, columns[0], op(orderBy[0].Desc), formatter.FormatPlaceholder(firstParamIndex))
+		return b.String(), slices.Clone(after), nil
+	}
+	if rowValues && sameDirection {
+		b.WriteString(`(`)
+		b.WriteString(strings.Join(columns, `, `))
+		fmt.Fprintf(&b, `) %s (`, op(orderBy[0].Desc))
+		for i := range after {
+			if i > 0 {
+				b.WriteString(`, `)
+			}
+			b.WriteString(formatter.FormatPlaceholder(firstParamIndex + i))
+		}
+		b.WriteString(`)`)
+		return b.String(), slices.Clone(after), nil
+	}
+
+	b.WriteString(`(`)
+	for i := range orderBy {
+		if i > 0 {
+			b.WriteString(` OR (`)
+		}
+		for j := range i {
+			fmt.Fprintf(&b, `%s = %s AND `, columns[j], formatter.FormatPlaceholder(firstParamIndex+len(args)))
+			args = append(args, after[j])
+		}
+		fmt.Fprintf(&b, `%s %s %s`, columns[i], op(orderBy[i].Desc), formatter.FormatPlaceholder(firstParamIndex+len(args)))
+		args = append(args, after[i])
+		if i > 0 {
+			b.WriteString(`)`)
+		}
+	}
+	b.WriteString(`)`)
+	return b.String(), args, nil
+}
+
+// Page is a page of rows returned by [QueryPage].
+type Page[S any] struct {
+	// Rows of the page in the requested sort order.
+	Rows []S
+
+	// NextCursor is the cursor for the page after this one
+	// or empty if there are no more rows.
+	NextCursor string
+
+	// PrevCursor is the cursor for the page before this one
+	// or empty if this is the first page.
+	PrevCursor string
+}
+
+// QueryPage returns a page of at most limit rows of the query
+// scanned into structs of type S using keyset (cursor) pagination.
+//
+// The query must not contain ORDER BY or LIMIT clauses,
+// it is wrapped as derived table by the [PageQueryBuilder]
+// which adds the sort order, the keyset condition, and the limit.
+//
+// The rows are sorted by the orderBy columns followed by the
+// primary key columns of S not already in orderBy
+// in the direction of the last orderBy column, so that the
+// sort order is unique and ties are broken consistently.
+// If orderBy is empty, then the rows are sorted by the primary key columns.
+// All sort columns must be mapped to fields of S and must not be nullable.
+//
+// An empty cursor returns the first page, else the cursor must be
+// the NextCursor or PrevCursor of a page returned by QueryPage
+// with the same sort order, or an error wrapping [ErrInvalidPageCursor]
+// is returned. Cursors are URL safe base64 encoded JSON
+// of the sort column values of the first or last row of a page.
+// They are not encrypted or signed and must not be trusted
+// for authorization, but they are only used as query arguments.
+//
+// Example:
+//
+//	page, err := sqldb.QueryPage[User](ctx, conn, refl, builder, conn,
+//		[]sqldb.OrderByColumn{{Column: "created_at", Desc: true}}, 50, cursor,
+//		"SELECT * FROM users WHERE tenant_id = $1", tenantID,
+//	)
+func QueryPage[S any](ctx context.Context, conn Querier, refl StructReflector, builder PageQueryBuilder, fmtr QueryFormatter, orderBy []OrderByColumn, limit int, cursor string, query string, args ...any) (page Page[S], err error) {
+	structType := reflect.TypeFor[S]()
+	if structType.Kind() != reflect.Struct {
+		return Page[S]{}, fmt.Errorf("QueryPage: expected struct type but got %s", structType)
+	}
+	if limit < 1 {
+		return Page[S]{}, fmt.Errorf("QueryPage: limit must be >= 1, got %d", limit)
+	}
+	orderBy, fieldIndices, err := pageOrderBy(refl, structType, orderBy)
+	if err != nil {
+		return Page[S]{}, fmt.Errorf("QueryPage: %w", err)
+	}
+
+	var (
+		after  []any
+		before bool
+	)
+	if cursor != "" {
+		after, before, err = decodePageCursor(cursor, orderBy, structType, fieldIndices)
+		if err != nil {
+			return Page[S]{}, fmt.Errorf("QueryPage: %w", err)
+		}
+	}
+	queryOrderBy := orderBy
+	if before {
+		// Query the rows before the cursor in reverse order
+		queryOrderBy = make([]OrderByColumn, len(orderBy))
+		for i, col := range orderBy {
+			queryOrderBy[i] = OrderByColumn{Column: col.Column, Desc: !col.Desc}
+		}
+	}
+	// Query one more row than the limit to know if there are more rows
+	pageQuery, pageArgs, err := builder.QueryPage(fmtr, query, args, queryOrderBy, after, limit+1)
+	if err != nil {
+		return Page[S]{}, fmt.Errorf("QueryPage: %w", err)
+	}
+	rows, err := QueryRowsAsSlice[S](ctx, conn, refl, fmtr, UnlimitedMaxNumRows, pageQuery, pageArgs...)
+	if err != nil {
+		return Page[S]{}, err
+	}
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	if before {
+		slices.Reverse(rows)
+	}
+	page.Rows = rows
+
+	rowValues := func(row *S) []any {
+		structVal := reflect.ValueOf(row).Elem()
+		values := make([]any, len(fieldIndices))
+		for i, index := range fieldIndices {
+			if field, err := structVal.FieldByIndexErr(index); err == nil {
+				values[i] = field.Interface()
+			} // else NULL field of nil inline struct pointer
+		}
+		return values
+	}
+	switch {
+	case len(rows) == 0 && cursor != "":
+		// Allow navigating back from an empty page
+		if before {
+			page.NextCursor, err = encodePageCursor(orderBy, after, false)
+		} else {
+			page.PrevCursor, err = encodePageCursor(orderBy, after, true)
+		}
+	case len(rows) > 0:
+		if more || before {
+			page.NextCursor, err = encodePageCursor(orderBy, rowValues(&rows[len(rows)-1]), false)
+			if err != nil {
+				break
+			}
+		}
+		if (more && before) || (!before && cursor != "") {
+			page.PrevCursor, err = encodePageCursor(orderBy, rowValues(&rows[0]), true)
+		}
+	}
+	if err != nil {
+		return Page[S]{}, fmt.Errorf("QueryPage: %w", err)
+	}
+	return page, nil
+}
+
+// pageOrderBy returns the passed orderBy columns followed by the
+// primary key columns of structType that are not already in orderBy,
+// and the struct field indices of all returned columns.
+func pageOrderBy(refl StructReflector, structType reflect.Type, orderBy []OrderByColumn) ([]OrderByColumn, [][]int, error) {
+	pkColumns, err := refl.PrimaryKeyColumnsOfStruct(structType)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Append the primary key columns in the direction of the last
+	// orderBy column so that a row value comparison can be used
+	desc := len(orderBy) > 0 && orderBy[len(orderBy)-1].Desc
+	orderBy = slices.Clip(orderBy)
+	for _, pkCol := range pkColumns {
+		if !slices.ContainsFunc(orderBy, func(col OrderByColumn) bool { return col.Column == pkCol }) {
+			orderBy = append(orderBy, OrderByColumn{Column: pkCol, Desc: desc})
+		}
+	}
+	if len(orderBy) == 0 {
+		return nil, nil, fmt.Errorf("no order by columns passed and %s has no primary key", structType)
+	}
+
+	columns, indices, _, err := refl.ReflectStructColumnsFieldIndicesAndValues(reflect.New(structType).Elem())
+	if err != nil {
+		return nil, nil, err
+	}
+	fieldIndices := make([][]int, len(orderBy))
+	for i, col := range orderBy {
+		c := slices.IndexFunc(columns, func(c ColumnInfo) bool { return c.Name == col.Column })
+		if c < 0 {
+			return nil, nil, fmt.Errorf("order by column %q is not mapped to a field of %s", col.Column, structType)
+		}
+		fieldIndices[i] = indices[c]
+	}
+	return orderBy, fieldIndices, nil
+}
+
+// pageCursor is the JSON payload of a [QueryPage] cursor.
+type pageCursor struct {
+	OrderBy []string          `json:"o"`
+	Values  []json.RawMessage `json:"v"`
+	Before  bool              `json:"b,omitempty"`
+}
+
+func encodePageCursor(orderBy []OrderByColumn, values []any, before bool) (string, error) {
+	c := pageCursor{
+		OrderBy: make([]string, len(orderBy)),
+		Values:  make([]json.RawMessage, len(values)),
+		Before:  before,
+	}
+	for i, col := range orderBy {
+		c.OrderBy[i] = col.String()
+	}
+	for i, val := range values {
+		j, err := json.Marshal(val)
+		if err != nil {
+			return "", fmt.Errorf("can't encode value of column %s for page cursor: %w", orderBy[i].Column, err)
+		}
+		c.Values[i] = j
+	}
+	j, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(j), nil
+}
+
+// decodePageCursor decodes the cursor values into the
+// types of the struct fields of the orderBy columns.
+func decodePageCursor(cursor string, orderBy []OrderByColumn, structType reflect.Type, fieldIndices [][]int) (values []any, before bool, err error) {
+	j, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrInvalidPageCursor, err)
+	}
+	var c pageCursor
+	if err = json.Unmarshal(j, &c); err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrInvalidPageCursor, err)
+	}
+	if len(c.OrderBy) != len(orderBy) || len(c.Values) != len(orderBy) {
+		return nil, false, fmt.Errorf("%w: sort order does not match", ErrInvalidPageCursor)
+	}
+	values = make([]any, len(orderBy))
+	for i, col := range orderBy {
+		if c.OrderBy[i] != col.String() {
+			return nil, false, fmt.Errorf("%w: sort order does not match", ErrInvalidPageCursor)
+		}
+		val := reflect.New(structType.FieldByIndex(fieldIndices[i]).Type)
+		if err = json.Unmarshal(c.Values[i], val.Interface()); err != nil {
+			return nil, false, fmt.Errorf("%w: column %s: %w", ErrInvalidPageCursor, col.Column, err)
+		}
+		values[i] = val.Elem().Interface()
+	}
+	return values, c.Before, nil
+}
@@ -0,0 +1,228 @@
+package sqldb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pageTestRow struct {
+	ID        int64     `db:"id,primarykey"`
+	CreatedAt time.Time `db:"created_at"`
+	Name      string    `db:"name"`
+}
+
+func TestKeysetCondition(t *testing.T) {
+	fmtr := NewQueryFormatter("$")
+	after := []any{"x", 2, 3}
+
+	tests := []struct {
+		name      string
+		orderBy   []OrderByColumn
+		after     []any
+		rowValues bool
+		want      string
+		wantArgs  []any
+	}{
+		{
+			name:     "single column",
+			orderBy:  []OrderByColumn{{Column: "id"}},
+			after:    []any{1},
+			want:     `id > $3`,
+			wantArgs: []any{1},
+		},
+		{
+			name:     "single column descending",
+			orderBy:  []OrderByColumn{{Column: "id", Desc: true}},
+			after:    []any{1},
+			want:     `id < $3`,
+			wantArgs: []any{1},
+		},
+		{
+			name:      "row values",
+			orderBy:   []OrderByColumn{{Column: "a"}, {Column: "b"}, {Column: "c"}},
+			after:     after,
+			rowValues: true,
+			want:      `(a, b, c) > ($3, $4, $5)`,
+			wantArgs:  after,
+		},
+		{
+			name:      "row values descending",
+			orderBy:   []OrderByColumn{{Column: "a", Desc: true}, {Column: "b", Desc: true}, {Column: "c", Desc: true}},
+			after:     after,
+			rowValues: true,
+			want:      `(a, b, c) < ($3, $4, $5)`,
+			wantArgs:  after,
+		},
+		{
+			name:     "OR-chain",
+			orderBy:  []OrderByColumn{{Column: "a"}, {Column: "b"}, {Column: "c"}},
+			after:    after,
+			want:     `(a > $3 OR (a = $4 AND b > $5) OR (a = $6 AND b = $7 AND c > $8))`,
+			wantArgs: []any{"x", "x", 2, "x", 2, 3},
+		},
+		{
+			name:      "mixed directions",
+			orderBy:   []OrderByColumn{{Column: "a", Desc: true}, {Column: "b"}},
+			after:     []any{"x", 2},
+			rowValues: true,
+			want:      `(a < $3 OR (a = $4 AND b > $5))`,
+			wantArgs:  []any{"x", "x", 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args, err := KeysetCondition(fmtr, tt.orderBy, tt.after, 2, tt.rowValues)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, condition)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		_, _, err := KeysetCondition(fmtr, nil, nil, 0, true)
+		assert.Error(t, err)
+		_, _, err = KeysetCondition(fmtr, []OrderByColumn{{Column: "a"}}, []any{1, 2}, 0, true)
+		assert.Error(t, err)
+		_, _, err = KeysetCondition(fmtr, []OrderByColumn{{Column: "a;"}}, []any{1}, 0, true)
+		assert.Error(t, err)
+	})
+}
+
+func TestStdQueryBuilder_QueryPage(t *testing.T) {
+	fmtr := NewQueryFormatter("$")
+	orderBy := []OrderByColumn{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}
+
+	t.Run("first page", func(t *testing.T) {
+		query, args, err := StdQueryBuilder{}.QueryPage(fmtr, "SELECT * FROM t WHERE x = $1", []any{1}, orderBy, nil, 10)
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM (SELECT * FROM t WHERE x = $1) sqldb_page ORDER BY created_at DESC, id DESC LIMIT 10`, query)
+		assert.Equal(t, []any{1}, args)
+	})
+
+	t.Run("after", func(t *testing.T) {
+		query, args, err := StdQueryBuilder{}.QueryPage(fmtr, "SELECT * FROM t WHERE x = $1", []any{1}, orderBy, []any{"2024", 5}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM (SELECT * FROM t WHERE x = $1) sqldb_page WHERE (created_at, id) < ($2, $3) ORDER BY created_at DESC, id DESC LIMIT 10`, query)
+		assert.Equal(t, []any{1, "2024", 5}, args)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, _, err := StdQueryBuilder{}.QueryPage(fmtr, "SELECT * FROM t", nil, orderBy, nil, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestQueryPage(t *testing.T) {
+	const query = "SELECT * FROM t WHERE tenant = $1"
+	var (
+		t1 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		t2 = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+		t3 = time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	)
+	orderBy := []OrderByColumn{{Column: "created_at", Desc: true}}
+	rows := func(rows ...pageTestRow) *MockRows {
+		r := NewMockRows("id", "created_at", "name")
+		for _, row := range rows {
+			r.WithRow(row.ID, row.CreatedAt, row.Name)
+		}
+		return r
+	}
+
+	// given
+	conn, refl, builder, fmtr := newTestInterfaces()
+	var gotQuery string
+	var gotArgs []any
+	var result *MockRows
+	conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+		gotQuery, gotArgs = query, args
+		return result
+	}
+
+	// when first page
+	result = rows(pageTestRow{3, t3, "c"}, pageTestRow{2, t2, "b"}, pageTestRow{1, t1, "a"})
+	page1, err := QueryPage[pageTestRow](t.Context(), conn, refl, builder, fmtr, orderBy, 2, "", query, "tenant")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM (SELECT * FROM t WHERE tenant = $1) sqldb_page ORDER BY created_at DESC, id DESC LIMIT 3`, gotQuery)
+	assert.Equal(t, []any{"tenant"}, gotArgs)
+	assert.Equal(t, []pageTestRow{{3, t3, "c"}, {2, t2, "b"}}, page1.Rows)
+	assert.NotEmpty(t, page1.NextCursor)
+	assert.Empty(t, page1.PrevCursor)
+
+	// when next page
+	result = rows(pageTestRow{1, t1, "a"})
+	page2, err := QueryPage[pageTestRow](t.Context(), conn, refl, builder, fmtr, orderBy, 2, page1.NextCursor, query, "tenant")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM (SELECT * FROM t WHERE tenant = $1) sqldb_page WHERE (created_at, id) < ($2, $3) ORDER BY created_at DESC, id DESC LIMIT 3`, gotQuery)
+	assert.Equal(t, []any{"tenant", t2, int64(2)}, gotArgs)
+	assert.Equal(t, []pageTestRow{{1, t1, "a"}}, page2.Rows)
+	assert.Empty(t, page2.NextCursor)
+	assert.NotEmpty(t, page2.PrevCursor)
+
+	// when previous page
+	result = rows(pageTestRow{2, t2, "b"}, pageTestRow{3, t3, "c"})
+	page3, err := QueryPage[pageTestRow](t.Context(), conn, refl, builder, fmtr, orderBy, 2, page2.PrevCursor, query, "tenant")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM (SELECT * FROM t WHERE tenant = $1) sqldb_page WHERE (created_at, id) > ($2, $3) ORDER BY created_at, id LIMIT 3`, gotQuery)
+	assert.Equal(t, []any{"tenant", t1, int64(1)}, gotArgs)
+	assert.Equal(t, page1.Rows, page3.Rows)
+	assert.Equal(t, page1.NextCursor, page3.NextCursor)
+	assert.Empty(t, page3.PrevCursor)
+
+	// when empty page after cursor
+	result = rows()
+	page4, err := QueryPage[pageTestRow](t.Context(), conn, refl, builder, fmtr, orderBy, 2, page2.PrevCursor, query, "tenant")
+
+	// then
+	require.NoError(t, err)
+	assert.Empty(t, page4.Rows)
+	assert.NotEmpty(t, page4.NextCursor)
+	assert.Empty(t, page4.PrevCursor)
+}
+
+func TestQueryPage_errors(t *testing.T) {
+	conn, refl, builder, fmtr := newTestInterfaces()
+	conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+		return NewMockRows("id", "created_at", "name").WithRow(int64(1), time.Now(), "a").WithRow(int64(2), time.Now(), "b")
+	}
+	page, err := QueryPage[pageTestRow](t.Context(), conn, refl, builder, fmtr, nil, 1, "", "SELECT * FROM t")
+	require.NoError(t, err)
+	require.NotEmpty(t, page.NextCursor)
+
+	t.Run("cursor of different sort order", func(t *testing.T) {
+		_, err := QueryPage[pageTestRow](t.Context(), conn, refl, builder, fmtr, []OrderByColumn{{Column: "name"}}, 1, page.NextCursor, "SELECT * FROM t")
+		assert.ErrorIs(t, err, ErrInvalidPageCursor)
+	})
+
+	t.Run("malformed cursor", func(t *testing.T) {
+		_, err := QueryPage[pageTestRow](t.Context(), conn, refl, builder, fmtr, nil, 1, "not a cursor!", "SELECT * FROM t")
+		assert.ErrorIs(t, err, ErrInvalidPageCursor)
+	})
+
+	t.Run("unmapped order by column", func(t *testing.T) {
+		_, err := QueryPage[pageTestRow](t.Context(), conn, refl, builder, fmtr, []OrderByColumn{{Column: "unknown"}}, 1, "", "SELECT * FROM t")
+		assert.ErrorContains(t, err, `order by column "unknown" is not mapped`)
+	})
+
+	t.Run("no primary key", func(t *testing.T) {
+		type noPK struct {
+			Name string `db:"name"`
+		}
+		_, err := QueryPage[noPK](t.Context(), conn, refl, builder, fmtr, nil, 1, "", "SELECT * FROM t")
+		assert.ErrorContains(t, err, "has no primary key")
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, err := QueryPage[pageTestRow](t.Context(), conn, refl, builder, fmtr, nil, 0, "", "SELECT * FROM t")
+		assert.Error(t, err)
+	})
+}
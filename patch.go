@@ -0,0 +1,172 @@
+package sqldb
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// PatchField is implemented by [Patch] to let
+// [UpdateRowStruct] and [UpsertRowStruct] skip
+// the columns of struct fields that are not set.
+type PatchField interface {
+	driver.Valuer
+
+	// IsSet returns true if the field was set to a value or NULL.
+	IsSet() bool
+}
+
+// Patch is a struct field type for partial updates that distinguishes
+// between a field that is not set, set to NULL, or set to a value of type T.
+//
+// When unmarshalled from JSON, a Patch is only set if its key is present
+// and set to NULL if the JSON value is null, so that a struct
+// with Patch fields can be used for the input of HTTP PATCH requests.
+// Use the `json:",omitzero"` tag option to omit unset fields when marshalling.
+//
+// [UpdateRowStruct] and [UpsertRowStruct] don't write the columns
+// of unset Patch fields and write NULL for fields set to NULL.
+// [PatchValues] returns the values of the set fields for [Update].
+// Unset Patch fields are inserted as NULL by the insert functions.
+//
+// Implements [PatchField], [sql.Scanner], [driver.Valuer],
+// [json.Marshaler], and [json.Unmarshaler].
+type Patch[T any] struct {
+	Val   T
+	Valid bool // true if the value is not NULL
+	Set   bool // true if the field was set to a value or NULL
+}
+
+// PatchOf returns a Patch set to val.
+func PatchOf[T any](val T) Patch[T] {
+	return Patch[T]{Val: val, Valid: true, Set: true}
+}
+
+// PatchNull returns a Patch set to NULL.
+func PatchNull[T any]() Patch[T] {
+	return Patch[T]{Set: true}
+}
+
+// IsSet implements the PatchField interface.
+func (p Patch[T]) IsSet() bool {
+	return p.Set
+}
+
+// IsZero returns true if the Patch is not set
+// for the `json:",omitzero"` tag option.
+func (p Patch[T]) IsZero() bool {
+	return !p.Set
+}
+
+// Scan implements the sql.Scanner interface.
+func (p *Patch[T]) Scan(value any) error {
+	if value == nil {
+		*p = PatchNull[T]()
+		return nil
+	}
+	err := ScanDriverValue(&p.Val, value)
+	if err != nil {
+		return err
+	}
+	p.Valid = true
+	p.Set = true
+	return nil
+}
+
+// Value implements the sql/driver.Valuer interface.
+// Returns nil for unset and NULL patches.
+func (p Patch[T]) Value() (driver.Value, error) {
+	if !p.Valid {
+		return nil, nil
+	}
+	return p.Val, nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// Unset and NULL patches are marshalled as null.
+func (p Patch[T]) MarshalJSON() ([]byte, error) {
+	if !p.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(p.Val)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (p *Patch[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*p = PatchNull[T]()
+		return nil
+	}
+	var val T
+	err := json.Unmarshal(data, &val)
+	if err != nil {
+		return err
+	}
+	*p = PatchOf(val)
+	return nil
+}
+
+// hasPatchColumns returns true if any of the columns
+// is mapped to a struct field implementing [PatchField].
+func hasPatchColumns(columns []ColumnInfo) bool {
+	return slices.ContainsFunc(columns, func(col ColumnInfo) bool {
+		return col.Patch
+	})
+}
+
+// withoutUnsetPatches returns columns, fieldIndices, and vals without
+// the entries of struct fields implementing [PatchField] that are not set.
+func withoutUnsetPatches(columns []ColumnInfo, fieldIndices [][]int, vals []any) ([]ColumnInfo, [][]int, []any) {
+	var (
+		setColumns []ColumnInfo
+		setIndices [][]int
+		setVals    []any
+	)
+	for i, col := range columns {
+		if col.Patch && !vals[i].(PatchField).IsSet() {
+			continue
+		}
+		setColumns = append(setColumns, col)
+		setIndices = append(setIndices, fieldIndices[i])
+		setVals = append(setVals, vals[i])
+	}
+	return setColumns, setIndices, setVals
+}
+
+// PatchValues returns the values of the mapped fields of patchStruct
+// for the [Update] function without the columns of unset [Patch] fields.
+// The values of set Patch fields are the values returned by Patch.Value,
+// so NULL patches have nil values.
+// Struct fields can be filtered with options like [IgnoreColumns] or [OnlyColumns].
+func PatchValues(refl StructReflector, patchStruct any, options ...QueryOption) (Values, error) {
+	if refl == nil {
+		return nil, errors.New("PatchValues: nil StructReflector")
+	}
+	structVal, err := derefStruct(reflect.ValueOf(patchStruct))
+	if err != nil {
+		return nil, err
+	}
+	columns, vals, err := refl.ReflectStructColumnsAndValues(structVal, options...)
+	if err != nil {
+		return nil, err
+	}
+	values := make(Values, len(columns))
+	for i, col := range columns {
+		patch, ok := vals[i].(PatchField)
+		if !ok {
+			values[col.Name] = vals[i]
+			continue
+		}
+		if !patch.IsSet() {
+			continue
+		}
+		values[col.Name], err = patch.Value()
+		if err != nil {
+			return nil, fmt.Errorf("can't get value of column %s: %w", col.Name, err)
+		}
+	}
+	return values, nil
+}
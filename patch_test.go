@@ -0,0 +1,205 @@
+package sqldb
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type patchTestStruct struct {
+	TableName `db:"patch_table"`
+
+	ID    int64           `db:"id,primarykey" json:"id"`
+	Name  Patch[string]   `db:"name" json:"name,omitzero"`
+	Email Patch[string]   `db:"email" json:"email,omitzero"`
+	Count Patch[int64]    `db:"count" json:"count,omitzero"`
+	Tags  Patch[[]string] `db:"-" json:"tags,omitzero"`
+}
+
+func TestPatch_JSON(t *testing.T) {
+	t.Run("unmarshal", func(t *testing.T) {
+		// given
+		var patch patchTestStruct
+
+		// when
+		err := json.Unmarshal([]byte(`{"id":1,"name":"Alice","email":null}`), &patch)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, PatchOf("Alice"), patch.Name)
+		assert.Equal(t, PatchNull[string](), patch.Email)
+		assert.False(t, patch.Count.IsSet())
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		// given
+		patch := patchTestStruct{ID: 1, Name: PatchOf("Alice"), Email: PatchNull[string]()}
+
+		// when
+		data, err := json.Marshal(patch)
+
+		// then
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":1,"name":"Alice","email":null}`, string(data))
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var patch patchTestStruct
+		err := json.Unmarshal([]byte(`{"count":"x"}`), &patch)
+		require.Error(t, err)
+	})
+}
+
+func TestPatch_ScanValue(t *testing.T) {
+	var p Patch[int64]
+	require.NoError(t, p.Scan(int64(7)))
+	assert.Equal(t, PatchOf(int64(7)), p)
+	v, err := p.Value()
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), v)
+
+	require.NoError(t, p.Scan(nil))
+	assert.Equal(t, PatchNull[int64](), p)
+	v, err = p.Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+
+	v, err = Patch[int64]{}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestUpdateRowStruct_Patch(t *testing.T) {
+	t.Run("skips unset fields", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+
+		// when
+		err := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, patchTestStruct{ID: 1, Name: PatchOf("Alice"), Email: PatchNull[string]()})
+		// Must not use a cached query of the previously set fields
+		err2 := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, patchTestStruct{ID: 2, Count: PatchOf(int64(3))})
+
+		// then
+		require.NoError(t, err)
+		require.NoError(t, err2)
+		require.Len(t, conn.Recordings.Execs, 2)
+		assert.Equal(t, "UPDATE patch_table SET name=$1, email=$2 WHERE id = $3", conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{PatchOf("Alice"), PatchNull[string](), int64(1)}, conn.Recordings.Execs[0].Args)
+		assert.Equal(t, "UPDATE patch_table SET count=$1 WHERE id = $2", conn.Recordings.Execs[1].Query)
+	})
+
+	t.Run("no set fields", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, patchTestStruct{ID: 1})
+		require.Error(t, err)
+		assert.Empty(t, conn.Recordings.Execs)
+	})
+
+	t.Run("UpdateRowStructs", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn = conn.WithQueryLog(log)
+		rows := []patchTestStruct{
+			{ID: 1, Name: PatchOf("Alice")},
+			{ID: 2, Email: PatchNull[string]()},
+		}
+
+		// when
+		err := UpdateRowStructs(t.Context(), conn, refl, builder, fmtr, rows)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "BEGIN;\n"+
+			"UPDATE patch_table SET name='Alice' WHERE id = 1;\n"+
+			"UPDATE patch_table SET email=NULL WHERE id = 2;\n"+
+			"COMMIT;\n", log.String())
+	})
+
+	t.Run("UpdateRowStructStmt", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		_, _, err := UpdateRowStructStmt[patchTestStruct](t.Context(), conn, refl, builder, fmtr)
+		require.Error(t, err)
+	})
+}
+
+func TestUpsertRowStruct_Patch(t *testing.T) {
+	t.Run("skips unset fields", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+
+		// when
+		err := UpsertRowStruct(t.Context(), conn, refl, builder, fmtr, patchTestStruct{ID: 1, Email: PatchNull[string]()})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, "INSERT INTO patch_table(id,email) VALUES($1,$2) ON CONFLICT(id) DO UPDATE SET email=$2", conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{int64(1), PatchNull[string]()}, conn.Recordings.Execs[0].Args)
+	})
+
+	t.Run("UpsertRowStructs", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn = conn.WithQueryLog(log)
+		rows := []*patchTestStruct{
+			{ID: 1, Name: PatchOf("Alice")},
+			{ID: 2, Count: PatchOf(int64(1))},
+		}
+
+		// when
+		err := UpsertRowStructs(t.Context(), conn, refl, builder, fmtr, rows)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "BEGIN;\n"+
+			"INSERT INTO patch_table(id,name) VALUES(1,'Alice') ON CONFLICT(id) DO UPDATE SET name='Alice';\n"+
+			"INSERT INTO patch_table(id,count) VALUES(2,1) ON CONFLICT(id) DO UPDATE SET count=1;\n"+
+			"COMMIT;\n", log.String())
+	})
+
+	t.Run("UpsertRowStructStmt", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		_, _, err := UpsertRowStructStmt[patchTestStruct](t.Context(), conn, refl, builder, fmtr)
+		require.Error(t, err)
+	})
+}
+
+func TestPatchValues(t *testing.T) {
+	t.Run("set fields", func(t *testing.T) {
+		// given
+		patch := &patchTestStruct{ID: 1, Name: PatchOf("Alice"), Email: PatchNull[string]()}
+
+		// when
+		values, err := PatchValues(NewTaggedStructReflector(), patch, IgnorePrimaryKey)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Values{"name": "Alice", "email": nil}, values)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		// given
+		conn, _, builder, fmtr := newTestInterfaces()
+		values, err := PatchValues(NewTaggedStructReflector(), patchTestStruct{Count: PatchOf(int64(2)), Email: PatchNull[string]()}, IgnorePrimaryKey)
+		require.NoError(t, err)
+
+		// when
+		err = Update(t.Context(), conn, builder, fmtr, "patch_table", values, "id = $1", 1)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, "UPDATE patch_table SET count=$2, email=$3 WHERE id = $1", conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{1, int64(2), nil}, conn.Recordings.Execs[0].Args)
+	})
+
+	t.Run("nil StructReflector", func(t *testing.T) {
+		_, err := PatchValues(nil, patchTestStruct{})
+		require.Error(t, err)
+	})
+}
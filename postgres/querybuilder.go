@@ -7,19 +7,25 @@ package postgres
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/domonda/go-sqldb"
 )
 
 var (
-	_ sqldb.QueryBuilder          = (*QueryBuilder)(nil)
-	_ sqldb.UpsertQueryBuilder    = (*QueryBuilder)(nil)
-	_ sqldb.ReturningQueryBuilder = (*QueryBuilder)(nil)
+	_ sqldb.QueryBuilder              = (*QueryBuilder)(nil)
+	_ sqldb.UpsertQueryBuilder        = (*QueryBuilder)(nil)
+	_ sqldb.UpsertOptionsQueryBuilder = (*QueryBuilder)(nil)
+	_ sqldb.ReturningQueryBuilder     = (*QueryBuilder)(nil)
+	_ sqldb.RowsReturningQueryBuilder = (*QueryBuilder)(nil)
+	_ sqldb.SoftDeleteQueryBuilder    = (*QueryBuilder)(nil)
+	_ sqldb.RelationQueryBuilder      = (*QueryBuilder)(nil)
 )
 
 // QueryBuilder implements [sqldb.QueryBuilder], [sqldb.UpsertQueryBuilder],
-// and [sqldb.ReturningQueryBuilder] using PostgreSQL/SQLite-compatible syntax.
+// [sqldb.ReturningQueryBuilder], and [sqldb.RowsReturningQueryBuilder]
+// using PostgreSQL/SQLite-compatible syntax.
 // It embeds [sqldb.StdReturningQueryBuilder] for standard CRUD and RETURNING
 // operations and adds ON CONFLICT syntax for upserts.
 type QueryBuilder struct {
@@ -48,19 +54,67 @@ func (b QueryBuilder) InsertUnique(formatter sqldb.QueryFormatter, table string,
 	return q.String(), nil
 }
 
+// QueryRowsWhereIn builds a SELECT * query with a column = ANY($1)
+// condition that passes values as a single array argument
+// of the type of the first value.
+// Falls back to an IN list of placeholders if the values
+// don't have the same type.
+func (b QueryBuilder) QueryRowsWhereIn(formatter sqldb.QueryFormatter, table, column string, values []any, softDeleteColumn string) (query string, args []any, err error) {
+	if len(values) == 0 {
+		return "", nil, fmt.Errorf("QueryRowsWhereIn requires at least one value")
+	}
+	elemType := reflect.TypeOf(values[0])
+	if elemType == nil {
+		return b.StdReturningQueryBuilder.QueryRowsWhereIn(formatter, table, column, values, softDeleteColumn)
+	}
+	array := reflect.MakeSlice(reflect.SliceOf(elemType), len(values), len(values))
+	for i, val := range values {
+		if reflect.TypeOf(val) != elemType {
+			return b.StdReturningQueryBuilder.QueryRowsWhereIn(formatter, table, column, values, softDeleteColumn)
+		}
+		array.Index(i).Set(reflect.ValueOf(val))
+	}
+
+	var q strings.Builder
+	table, err = formatter.FormatTableName(table)
+	if err != nil {
+		return "", nil, err
+	}
+	column, err = formatter.FormatColumnName(column)
+	if err != nil {
+		return "", nil, err
+	}
+	fmt.Fprintf(&q, `SELECT * FROM %s WHERE %s = ANY(%s)`, table, column, formatter.FormatPlaceholder(0))
+	if softDeleteColumn != "" {
+		softDeleteColumn, err = formatter.FormatColumnName(softDeleteColumn)
+		if err != nil {
+			return "", nil, err
+		}
+		fmt.Fprintf(&q, ` AND %s IS NULL`, softDeleteColumn)
+	}
+	return q.String(), []any{array.Interface()}, nil
+}
+
 // Upsert builds an INSERT ... ON CONFLICT DO UPDATE SET query.
 // Primary key columns are used as the conflict target,
 // non-primary key columns are updated on conflict.
 func (b QueryBuilder) Upsert(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo) (query string, err error) {
-	hasNonPK := false
-	for i := range columns {
-		if !columns[i].PrimaryKey {
-			hasNonPK = true
-			break
-		}
-	}
-	if !hasNonPK {
-		return "", fmt.Errorf("Upsert requires at least one non-primary-key column")
+	return b.UpsertWithOptions(formatter, table, columns, sqldb.UpsertOptions{})
+}
+
+// UpsertWithOptions builds an INSERT ... ON CONFLICT DO UPDATE SET ... WHERE query
+// with the conflict target, update columns, update expressions,
+// and WHERE condition of the options.
+// If the options have a VersionColumn, the existing row is only updated
+// if its version is the proposed version minus one.
+// Update columns are set to the placeholders of their inserted values.
+// Update expressions and the WHERE condition reference the existing row
+// with the table name and the proposed row as excluded.column.
+// See [sqldb.UpsertOptions] for the full contract and security model.
+func (b QueryBuilder) UpsertWithOptions(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo, options sqldb.UpsertOptions) (query string, err error) {
+	conflictColumns, assignments, err := options.Resolve(columns)
+	if err != nil {
+		return "", err
 	}
 
 	var q strings.Builder
@@ -70,38 +124,102 @@ func (b QueryBuilder) Upsert(formatter sqldb.QueryFormatter, table string, colum
 	}
 	q.WriteString(insert)
 	q.WriteString(` ON CONFLICT (`)
-	first := true
-	for i := range columns {
-		if !columns[i].PrimaryKey {
-			continue
-		}
-		if first {
-			first = false
-		} else {
+	for i, column := range conflictColumns {
+		if i > 0 {
 			q.WriteByte(',')
 		}
-		columnName, err := formatter.FormatColumnName(columns[i].Name)
+		columnName, err := formatter.FormatColumnName(column)
 		if err != nil {
 			return "", err
 		}
 		q.WriteString(columnName)
 	}
 	q.WriteString(`) DO UPDATE SET`)
-	first = true
-	for i := range columns {
-		if columns[i].PrimaryKey {
-			continue
-		}
-		if first {
-			first = false
-		} else {
+	for i, assignment := range assignments {
+		if i > 0 {
 			q.WriteByte(',')
 		}
-		columnName, err := formatter.FormatColumnName(columns[i].Name)
+		columnName, err := formatter.FormatColumnName(assignment.Column)
 		if err != nil {
 			return "", err
 		}
-		fmt.Fprintf(&q, ` %s=%s`, columnName, formatter.FormatPlaceholder(i))
+		value := assignment.Expression
+		if assignment.ColumnIndex >= 0 {
+			value = formatter.FormatPlaceholder(assignment.ColumnIndex)
+		}
+		fmt.Fprintf(&q, ` %s=%s`, columnName, value)
+	}
+	where := options.Where
+	if options.VersionColumn != "" {
+		fmtTable, err := formatter.FormatTableName(table)
+		if err != nil {
+			return "", err
+		}
+		versionColumn, err := formatter.FormatColumnName(options.VersionColumn)
+		if err != nil {
+			return "", err
+		}
+		where = options.VersionWhere(fmtTable+"."+versionColumn, "excluded."+versionColumn)
+	}
+	if where != "" {
+		fmt.Fprintf(&q, ` WHERE %s`, where)
+	}
+	return q.String(), nil
+}
+
+// InsertRowsReturning builds a multi-row INSERT query with a RETURNING clause
+// that returns the rows in the order of the inserted values
+// for [sqldb.InsertRowStructsReturning].
+//
+// The values are selected from a VALUES list with an ordinal column
+// and inserted ordered by it, because PostgreSQL doesn't define
+// the order of rows returned by INSERT ... VALUES ... RETURNING:
+//
+//	INSERT INTO table(a,b)
+//	SELECT a,b FROM (VALUES(COALESCE($1,(NULL::table).a),COALESCE($2,(NULL::table).b),1),($3,$4,2)) AS v(a,b,sqldb_ordinal)
+//	ORDER BY sqldb_ordinal RETURNING ...
+//
+// The placeholders of the first row are coalesced with a NULL of the column
+// type so that the VALUES list has the types of the table columns.
+//
+// SECURITY: returningColumns is appended to the query verbatim. It must be
+// static SQL written by the developer and must not contain data from
+// external input.
+func (b QueryBuilder) InsertRowsReturning(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo, numRows int, returningColumns string) (query string, err error) {
+	if numRows < 1 {
+		return "", fmt.Errorf("InsertRowsReturning: numRows must be >= 1, got %d", numRows)
+	}
+	table, err = formatter.FormatTableName(table)
+	if err != nil {
+		return "", err
+	}
+	columnNames := make([]string, len(columns))
+	for i := range columns {
+		columnNames[i], err = formatter.FormatColumnName(columns[i].Name)
+		if err != nil {
+			return "", err
+		}
+	}
+	columnList := strings.Join(columnNames, ",")
+
+	var q strings.Builder
+	fmt.Fprintf(&q, `INSERT INTO %s(%s) SELECT %s FROM (VALUES`, table, columnList, columnList)
+	for row := range numRows {
+		if row > 0 {
+			q.WriteByte(',')
+		}
+		q.WriteByte('(')
+		for col, column := range columnNames {
+			placeholder := formatter.FormatPlaceholder(row*len(columns) + col)
+			if row == 0 {
+				fmt.Fprintf(&q, `COALESCE(%s,(NULL::%s).%s),`, placeholder, table, column)
+			} else {
+				q.WriteString(placeholder)
+				q.WriteByte(',')
+			}
+		}
+		fmt.Fprintf(&q, `%d)`, row+1)
 	}
+	fmt.Fprintf(&q, `) AS v(%s,sqldb_ordinal) ORDER BY sqldb_ordinal RETURNING %s`, columnList, returningColumns)
 	return q.String(), nil
 }
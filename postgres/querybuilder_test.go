@@ -132,3 +132,113 @@ func TestQueryBuilder_Upsert(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryBuilder_UpsertWithOptions(t *testing.T) {
+	b := QueryBuilder{}
+	columns := []sqldb.ColumnInfo{
+		{Name: "id", PrimaryKey: true},
+		{Name: "email"},
+		{Name: "count"},
+		{Name: "updated_at"},
+	}
+
+	for _, scenario := range []struct {
+		name      string
+		options   sqldb.UpsertOptions
+		wantQuery string
+		wantErr   string
+	}{
+		{
+			name:      "zero options like Upsert",
+			wantQuery: `INSERT INTO counter(id,email,count,updated_at) VALUES($1,$2,$3,$4) ON CONFLICT (id) DO UPDATE SET email=$2, count=$3, updated_at=$4`,
+		},
+		{
+			name: "update column and expression",
+			options: sqldb.UpsertOptions{
+				UpdateColumns:     []string{"updated_at"},
+				UpdateExpressions: map[string]string{"count": "counter.count + excluded.count"},
+			},
+			wantQuery: `INSERT INTO counter(id,email,count,updated_at) VALUES($1,$2,$3,$4) ON CONFLICT (id) DO UPDATE SET updated_at=$4, count=counter.count + excluded.count`,
+		},
+		{
+			name: "conflict columns and where condition",
+			options: sqldb.UpsertOptions{
+				ConflictColumns: []string{"email"},
+				UpdateColumns:   []string{"count"},
+				Where:           "excluded.updated_at > counter.updated_at",
+			},
+			wantQuery: `INSERT INTO counter(id,email,count,updated_at) VALUES($1,$2,$3,$4) ON CONFLICT (email) DO UPDATE SET count=$3 WHERE excluded.updated_at > counter.updated_at`,
+		},
+		{
+			name: "version column",
+			options: sqldb.UpsertOptions{
+				UpdateColumns: []string{"email"},
+				VersionColumn: "count",
+			},
+			wantQuery: `INSERT INTO counter(id,email,count,updated_at) VALUES($1,$2,$3,$4) ON CONFLICT (id) DO UPDATE SET email=$2, count=$3 WHERE counter.count = excluded.count - 1`,
+		},
+		{
+			name:    "update conflict column returns error",
+			options: sqldb.UpsertOptions{UpdateColumns: []string{"id"}},
+			wantErr: `Upsert can't update conflict column "id"`,
+		},
+	} {
+		t.Run(scenario.name, func(t *testing.T) {
+			// when
+			query, err := b.UpsertWithOptions(testFormatter, "counter", columns, scenario.options)
+
+			// then
+			if scenario.wantErr != "" {
+				require.EqualError(t, err, scenario.wantErr)
+				assert.Empty(t, query)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.wantQuery, query)
+		})
+	}
+}
+
+func TestQueryBuilder_QueryRowsWhereIn(t *testing.T) {
+	b := QueryBuilder{}
+
+	t.Run("array argument", func(t *testing.T) {
+		query, args, err := b.QueryRowsWhereIn(testFormatter, "order_items", "order_id", []any{int64(1), int64(2)}, "deleted_at")
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM order_items WHERE order_id = ANY($1) AND deleted_at IS NULL`, query)
+		assert.Equal(t, []any{[]int64{1, 2}}, args)
+	})
+
+	t.Run("mixed types", func(t *testing.T) {
+		query, args, err := b.QueryRowsWhereIn(testFormatter, "order_items", "order_id", []any{int64(1), "2"}, "")
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM order_items WHERE order_id IN ($1,$2)`, query)
+		assert.Equal(t, []any{int64(1), "2"}, args)
+	})
+
+	t.Run("no values error", func(t *testing.T) {
+		_, _, err := b.QueryRowsWhereIn(testFormatter, "order_items", "order_id", nil, "")
+		require.Error(t, err)
+	})
+}
+
+func TestQueryBuilder_InsertRowsReturning(t *testing.T) {
+	b := QueryBuilder{}
+
+	t.Run("ordered by ordinal", func(t *testing.T) {
+		// given
+		columns := []sqldb.ColumnInfo{{Name: "name"}, {Name: "score"}}
+
+		// when
+		query, err := b.InsertRowsReturning(testFormatter, "public.items", columns, 2, "id,created")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `INSERT INTO public.items(name,score) SELECT name,score FROM (VALUES(COALESCE($1,(NULL::public.items).name),COALESCE($2,(NULL::public.items).score),1),($3,$4,2)) AS v(name,score,sqldb_ordinal) ORDER BY sqldb_ordinal RETURNING id,created`, query)
+	})
+
+	t.Run("numRows < 1", func(t *testing.T) {
+		_, err := b.InsertRowsReturning(testFormatter, "items", []sqldb.ColumnInfo{{Name: "name"}}, 0, "id")
+		require.Error(t, err)
+	})
+}
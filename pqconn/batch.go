@@ -0,0 +1,93 @@
+package pqconn
+
+import (
+	"context"
+	"strings"
+
+	"github.com/domonda/go-sqldb"
+)
+
+var (
+	_ sqldb.BatchExecer = new(connection)
+	_ sqldb.BatchExecer = new(transaction)
+	_ sqldb.BatchExecer = new(pinnedConn)
+)
+
+// ExecBatch implements [sqldb.BatchExecer], see execBatch.
+func (conn *connection) ExecBatch(ctx context.Context, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	return execBatch(ctx, conn, statements)
+}
+
+// ExecBatch implements [sqldb.BatchExecer], see execBatch.
+func (conn *transaction) ExecBatch(ctx context.Context, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	return execBatch(ctx, conn, statements)
+}
+
+// ExecBatch implements [sqldb.BatchExecer], see execBatch.
+func (conn *pinnedConn) ExecBatch(ctx context.Context, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	return execBatch(ctx, conn, statements)
+}
+
+// execBatch executes consecutive exec statements without arguments
+// in one round trip as a multi-statement query and all other statements
+// one after another, because lib/pq doesn't support pipelining
+// and multiple statements per query are only supported by the
+// simple query protocol that can't pass arguments.
+//
+// All statements of a round trip get the same result because
+// PostgreSQL only reports the last affected rows count
+// and not which statement failed.
+// Outside of a transaction, the statements of a round trip
+// are executed as an implicit transaction.
+func execBatch(ctx context.Context, conn sqldb.Connection, statements []sqldb.BatchStatement) []sqldb.BatchResult {
+	merged := make([]sqldb.BatchStatement, 0, len(statements))
+	mergedLens := make([]int, 0, len(statements))
+	for i := 0; i < len(statements); {
+		j := i + 1
+		if isSimpleExec(statements[i]) {
+			for j < len(statements) && isSimpleExec(statements[j]) {
+				j++
+			}
+		}
+		if j-i == 1 {
+			merged = append(merged, statements[i])
+		} else {
+			merged = append(merged, sqldb.BatchStatement{Query: joinStatements(statements[i:j])})
+		}
+		mergedLens = append(mergedLens, j-i)
+		i = j
+	}
+	if len(merged) == len(statements) {
+		return sqldb.ExecBatchSequentially(ctx, conn, statements)
+	}
+
+	mergedResults := sqldb.ExecBatchSequentially(ctx, conn, merged)
+	results := make([]sqldb.BatchResult, 0, len(statements))
+	for i, result := range mergedResults {
+		if mergedLens[i] > 1 {
+			result.RowsAffected = -1
+		}
+		for range mergedLens[i] {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+func isSimpleExec(stmt sqldb.BatchStatement) bool {
+	return stmt.ScanRows == nil && len(stmt.Args) == 0
+}
+
+// joinStatements joins the statements to one multi-statement query.
+// The statements are separated by semicolons on their own lines
+// so that a trailing line comment can't comment out the separator.
+func joinStatements(statements []sqldb.BatchStatement) string {
+	var b strings.Builder
+	for i, stmt := range statements {
+		if i > 0 {
+			b.WriteString("\n;\n")
+		}
+		b.WriteString(strings.TrimRight(stmt.Query, "; \t\r\n"))
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package pqconn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func TestExecBatch(t *testing.T) {
+	t.Run("statements without arguments in one round trip", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).WithQueryLog(log)
+		conn.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+			return 1, nil
+		}
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) sqldb.Rows {
+			return sqldb.NewMockRows("id").WithRow(int64(1))
+		}
+		statements := []sqldb.BatchStatement{
+			{Query: "CREATE TEMP TABLE t (id int);"},
+			{Query: "INSERT INTO t VALUES (1) -- one"},
+			{Query: "UPDATE t SET id = $1", Args: []any{2}},
+			{Query: "SELECT id FROM t", ScanRows: func(sqldb.Rows) error { return nil }},
+			{Query: "DELETE FROM t"},
+		}
+
+		// when
+		results := execBatch(t.Context(), conn, statements)
+
+		// then
+		assert.Equal(t,
+			[]sqldb.BatchResult{{RowsAffected: -1}, {RowsAffected: -1}, {RowsAffected: 1}, {RowsAffected: -1}, {RowsAffected: 1}},
+			results,
+		)
+		assert.Equal(t,
+			"CREATE TEMP TABLE t (id int)\n;\nINSERT INTO t VALUES (1) -- one;\n"+
+				"UPDATE t SET id = 2;\n"+
+				"SELECT id FROM t;\n"+
+				"DELETE FROM t;\n",
+			log.String(),
+		)
+	})
+
+	t.Run("error of merged statements", func(t *testing.T) {
+		// given
+		errExec := errors.New("exec error")
+		conn := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+		conn.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+			if strings.Contains(query, "\n;\n") {
+				return 0, errExec
+			}
+			return 1, nil
+		}
+		statements := []sqldb.BatchStatement{
+			{Query: "UPDATE t SET id = $1", Args: []any{2}},
+			{Query: "INSERT INTO t VALUES (1)"},
+			{Query: "INSERT INTO t VALUES (2)"},
+			{Query: "DELETE FROM t WHERE id = $1", Args: []any{1}},
+		}
+
+		// when
+		results := execBatch(t.Context(), conn, statements)
+
+		// then
+		require.Len(t, results, 4)
+		assert.Equal(t, sqldb.BatchResult{RowsAffected: 1}, results[0])
+		assert.ErrorIs(t, results[1].Err, errExec)
+		assert.ErrorIs(t, results[2].Err, errExec)
+		assert.ErrorIs(t, results[3].Err, sqldb.ErrBatchAborted)
+	})
+}
@@ -0,0 +1,32 @@
+package pqconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_copyFromQuery(t *testing.T) {
+	t.Run("table", func(t *testing.T) {
+		query, err := copyFromQuery("users", []string{"id", "user"})
+		require.NoError(t, err)
+		assert.Equal(t, `COPY users (id, "user") FROM STDIN`, query)
+	})
+
+	t.Run("schema qualified table", func(t *testing.T) {
+		query, err := copyFromQuery("public.users", []string{"id", "name"})
+		require.NoError(t, err)
+		assert.Equal(t, `COPY public.users (id, name) FROM STDIN`, query)
+	})
+
+	t.Run("invalid table", func(t *testing.T) {
+		_, err := copyFromQuery("users; DROP TABLE users", []string{"id"})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid column", func(t *testing.T) {
+		_, err := copyFromQuery("users", []string{"id", "a b"})
+		assert.Error(t, err)
+	})
+}
@@ -46,7 +46,7 @@ func Connect(ctx context.Context, config *sqldb.Config) (sqldb.Connection, error
 		}
 	}
 
-	return &connection{db: db, config: config}, nil
+	return sqldb.WithDefaultTimeouts(&connection{db: db, config: config}), nil
 }
 
 // MustConnect creates a new sqldb.Connection using the passed sqldb.Config
@@ -0,0 +1,214 @@
+package pqconn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// DefaultCursorFetchSize is the number of rows fetched per round trip
+// by [QueryCursor] when a fetchSize of zero or less is passed.
+const DefaultCursorFetchSize = 1000
+
+var cursorCounter atomic.Uint64
+
+// QueryCursor executes the query with a server-side cursor
+// and returns [sqldb.Rows] that fetch fetchSize rows per round trip
+// with FETCH FORWARD, so arbitrarily large results can be processed
+// in constant client memory instead of lib/pq buffering the whole result.
+// A fetchSize of zero or less uses [DefaultCursorFetchSize].
+//
+// PostgreSQL cursors only exist within a transaction.
+// If conn is a transaction, then the cursor is declared within it,
+// else a transaction is begun for the lifetime of the returned Rows
+// that is committed when the Rows are closed after the last row,
+// or rolled back after an error.
+// The cursor is closed by Rows.Close, which must always be called,
+// and when the rows are exhausted.
+// If ctx is canceled, then Next returns false and Err the context error.
+//
+// Any error, including from declaring the cursor,
+// is returned by the Err method of the Rows.
+//
+// Example:
+//
+//	rows := pqconn.QueryCursor(ctx, conn, 10_000, "SELECT * FROM huge_table")
+//	defer rows.Close()
+//	for rows.Next() {
+//		...
+//	}
+//	return rows.Err()
+func QueryCursor(ctx context.Context, conn sqldb.Connection, fetchSize int, query string, args ...any) sqldb.Rows {
+	if fetchSize <= 0 {
+		fetchSize = DefaultCursorFetchSize
+	}
+	r := &cursorRows{
+		ctx:   ctx,
+		conn:  conn,
+		name:  "sqldb_cursor_" + strconv.FormatUint(cursorCounter.Add(1), 10),
+		fetch: fmt.Sprintf("FETCH FORWARD %d FROM ", fetchSize),
+		size:  fetchSize,
+	}
+	r.fetch += r.name
+	if !conn.Transaction().Active() {
+		id := sqldb.NextTransactionID()
+		tx, err := conn.Begin(ctx, id, nil)
+		if err != nil {
+			return sqldb.NewErrRows(fmt.Errorf("transaction %d BEGIN error: %w", id, err))
+		}
+		r.conn = tx
+		r.ownTx = true
+	}
+	err := r.conn.Exec(ctx, "DECLARE "+r.name+" NO SCROLL CURSOR FOR "+query, args...)
+	if err != nil {
+		if r.ownTx {
+			err = errors.Join(err, r.conn.Rollback())
+		}
+		return sqldb.NewErrRows(err)
+	}
+	return r
+}
+
+// CursorQuerier returns a [sqldb.Querier] that executes
+// every query with a server-side cursor using [QueryCursor],
+// for use with functions like [sqldb.QueryStructCallback]
+// or [sqldb.QueryRowsIter].
+//
+// Example:
+//
+//	err := sqldb.QueryStructCallback(ctx, pqconn.CursorQuerier(conn, 10_000), refl, conn,
+//		func(row ExportRow) error {
+//			return csvWriter.Write(row.Strings())
+//		},
+//		"SELECT * FROM huge_table",
+//	)
+func CursorQuerier(conn sqldb.Connection, fetchSize int) sqldb.Querier {
+	return cursorQuerier{conn: conn, fetchSize: fetchSize}
+}
+
+type cursorQuerier struct {
+	conn      sqldb.Connection
+	fetchSize int
+}
+
+func (q cursorQuerier) Query(ctx context.Context, query string, args ...any) sqldb.Rows {
+	return QueryCursor(ctx, q.conn, q.fetchSize, query, args...)
+}
+
+// cursorRows implements sqldb.Rows by fetching
+// batches of rows from a server-side cursor.
+type cursorRows struct {
+	ctx   context.Context
+	conn  sqldb.Connection
+	name  string
+	fetch string
+	size  int
+	ownTx bool
+
+	batch     sqldb.Rows // current batch, nil before the first and between fetches
+	batchRows int        // rows read from the current batch
+	columns   []string
+	done      bool // all rows have been read
+	closed    bool
+	err       error
+}
+
+// fetchBatch fetches the next batch of rows from the cursor.
+func (r *cursorRows) fetchBatch() bool {
+	if err := r.ctx.Err(); err != nil {
+		r.err = err
+		return false
+	}
+	r.batch = r.conn.Query(r.ctx, r.fetch)
+	r.batchRows = 0
+	if r.columns == nil {
+		r.columns, r.err = r.batch.Columns()
+	}
+	if r.err == nil {
+		r.err = r.batch.Err()
+	}
+	return r.err == nil
+}
+
+func (r *cursorRows) Columns() ([]string, error) {
+	if r.columns == nil && !r.closed && r.err == nil && r.batch == nil {
+		r.fetchBatch()
+	}
+	if r.columns == nil && r.err != nil {
+		return nil, r.err
+	}
+	return r.columns, nil
+}
+
+func (r *cursorRows) Scan(dest ...any) error {
+	if r.closed || r.batch == nil {
+		return errors.New("cursor rows are closed or Next was not called")
+	}
+	return r.batch.Scan(dest...)
+}
+
+func (r *cursorRows) Next() bool {
+	for !r.closed && !r.done && r.err == nil {
+		if r.batch == nil && !r.fetchBatch() {
+			break
+		}
+		if r.batch.Next() {
+			r.batchRows++
+			return true
+		}
+		err := errors.Join(r.batch.Err(), r.batch.Close())
+		r.batch = nil
+		if err != nil {
+			r.err = err
+			break
+		}
+		// A batch with less than the fetch size rows was the last one
+		r.done = r.batchRows < r.size
+	}
+	// Close the cursor and end an owned transaction
+	// as soon as all rows were read or an error happened
+	if err := r.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return false
+}
+
+func (r *cursorRows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	var err error
+	if r.batch != nil {
+		err = r.batch.Close()
+		r.batch = nil
+	}
+	if r.ownTx {
+		// Ending the transaction also closes the cursor
+		switch {
+		case r.ctx.Err() != nil:
+			// database/sql already rolled back the transaction
+			_ = r.conn.Rollback()
+			return err
+		case r.err != nil || err != nil:
+			return errors.Join(err, r.conn.Rollback())
+		default:
+			return r.conn.Commit()
+		}
+	}
+	if r.ctx.Err() != nil {
+		// The transaction of the canceled context is aborted
+		// and the cursor will be closed when it ends
+		return err
+	}
+	return errors.Join(err, r.conn.Exec(context.WithoutCancel(r.ctx), "CLOSE "+r.name))
+}
+
+func (r *cursorRows) Err() error {
+	return r.err
+}
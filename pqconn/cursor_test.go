@@ -0,0 +1,137 @@
+package pqconn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+var cursorNameRegexp = regexp.MustCompile(`sqldb_cursor_\d+`)
+
+// newCursorTestConn returns a MockConn that returns the passed
+// batches for consecutive FETCH queries and logs all statements.
+func newCursorTestConn(batches ...[]int64) (*sqldb.MockConn, *bytes.Buffer) {
+	log := new(bytes.Buffer)
+	conn := sqldb.NewMockConn(sqldb.NewQueryFormatter("$")).WithQueryLog(log)
+	numFetches := 0
+	conn.MockQuery = func(ctx context.Context, query string, args ...any) sqldb.Rows {
+		rows := sqldb.NewMockRows("id")
+		if numFetches < len(batches) {
+			for _, id := range batches[numFetches] {
+				rows.WithRow(id)
+			}
+		}
+		numFetches++
+		return rows
+	}
+	return conn, log
+}
+
+func cursorLog(log *bytes.Buffer) string {
+	return cursorNameRegexp.ReplaceAllString(log.String(), "cur")
+}
+
+func TestQueryCursor(t *testing.T) {
+	t.Run("outside of transaction", func(t *testing.T) {
+		// given
+		conn, log := newCursorTestConn([]int64{1, 2}, []int64{3})
+
+		// when
+		ids, err := sqldb.QueryRowsAsSlice[int64](t.Context(), CursorQuerier(conn, 2), nil, conn, sqldb.UnlimitedMaxNumRows, "SELECT id FROM t WHERE x = $1", 1)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 3}, ids)
+		assert.Equal(t,
+			"BEGIN;\n"+
+				"DECLARE cur NO SCROLL CURSOR FOR SELECT id FROM t WHERE x = 1;\n"+
+				"FETCH FORWARD 2 FROM cur;\n"+
+				"FETCH FORWARD 2 FROM cur;\n"+
+				"COMMIT;\n",
+			cursorLog(log),
+		)
+	})
+
+	t.Run("last batch with fetch size rows", func(t *testing.T) {
+		// given
+		conn, log := newCursorTestConn([]int64{1, 2})
+
+		// when
+		ids, err := sqldb.QueryRowsAsSlice[int64](t.Context(), CursorQuerier(conn, 2), nil, conn, sqldb.UnlimitedMaxNumRows, "SELECT id FROM t")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, ids)
+		assert.Equal(t,
+			"BEGIN;\n"+
+				"DECLARE cur NO SCROLL CURSOR FOR SELECT id FROM t;\n"+
+				"FETCH FORWARD 2 FROM cur;\n"+
+				"FETCH FORWARD 2 FROM cur;\n"+
+				"COMMIT;\n",
+			cursorLog(log),
+		)
+	})
+
+	t.Run("within transaction closed early", func(t *testing.T) {
+		// given
+		conn, log := newCursorTestConn([]int64{1, 2}, []int64{3})
+		conn.TxID = 1
+
+		// when
+		rows := QueryCursor(t.Context(), conn, 2, "SELECT id FROM t")
+		require.True(t, rows.Next())
+		var id int64
+		require.NoError(t, rows.Scan(&id))
+		err := rows.Close()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), id)
+		assert.False(t, rows.Next())
+		assert.Equal(t,
+			"DECLARE cur NO SCROLL CURSOR FOR SELECT id FROM t;\n"+
+				"FETCH FORWARD 2 FROM cur;\n"+
+				"CLOSE cur;\n",
+			cursorLog(log),
+		)
+	})
+
+	t.Run("declare error", func(t *testing.T) {
+		// given
+		errDeclare := errors.New("declare error")
+		conn, log := newCursorTestConn()
+		conn.MockExec = func(ctx context.Context, query string, args ...any) error { return errDeclare }
+
+		// when
+		rows := QueryCursor(t.Context(), conn, 0, "SELECT id FROM t")
+
+		// then
+		assert.ErrorIs(t, rows.Err(), errDeclare)
+		assert.False(t, rows.Next())
+		assert.Equal(t, "BEGIN;\nDECLARE cur NO SCROLL CURSOR FOR SELECT id FROM t;\nROLLBACK;\n", cursorLog(log))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		// given
+		conn, _ := newCursorTestConn([]int64{1, 2}, []int64{3})
+		ctx, cancel := context.WithCancel(t.Context())
+		rows := QueryCursor(ctx, conn, 2, "SELECT id FROM t")
+		require.True(t, rows.Next())
+		require.True(t, rows.Next())
+
+		// when
+		cancel()
+
+		// then
+		assert.False(t, rows.Next())
+		assert.ErrorIs(t, rows.Err(), context.Canceled)
+		assert.NoError(t, rows.Close())
+	})
+}
@@ -32,6 +32,11 @@ PostgreSQL-specific features:
   - LISTEN/NOTIFY via ListenOnChannel, UnlistenChannel, and IsListeningOnChannel
   - Pinned sessions via the sqldb.ConnPinner interface (Conn) for
     session-scoped state like pg_advisory_lock
+  - Server-side cursors via QueryCursor and CursorQuerier for
+    processing huge results in constant memory
+  - COPY FROM STDIN via the sqldb.BulkCopier interface
+  - Statements without arguments of a sqldb.Batch in one round trip
+    via the sqldb.BatchExecer interface
   - Read-only mode (sets default_transaction_read_only = on)
   - Typed error inspection (IsUniqueViolation, IsForeignKeyViolation, etc.)
   - Default isolation level is sql.LevelReadCommitted
@@ -2,8 +2,11 @@ package pqconn
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
+	"io"
 	"slices"
+	"strings"
 
 	"github.com/domonda/go-sqldb"
 	"github.com/lib/pq"
@@ -35,12 +38,30 @@ func wrapKnownErrors(err error) error {
 		case pqerror.TRSerializationFailure:
 			return errors.Join(sqldb.ErrSerializationFailure, err)
 		case pqerror.QueryCanceled:
+			if strings.Contains(e.Message, "statement timeout") {
+				return errors.Join(sqldb.ErrQueryTimeout{Limit: sqldb.QueryTimeoutLimit}, err)
+			}
 			return errors.Join(sqldb.ErrQueryCanceled, err)
+		case pqerror.LockNotAvailable:
+			return errors.Join(sqldb.ErrLockTimeout, err)
+		case pqerror.AdminShutdown, pqerror.CrashShutdown, pqerror.CannotConnectNow:
+			return errors.Join(sqldb.ErrConnectionLost, err)
 		case pqerror.ExclusionViolation:
 			return errors.Join(sqldb.ErrExclusionViolation{Constraint: e.Constraint}, err)
 		case pqerror.RaiseException:
 			return errors.Join(sqldb.ErrRaisedException{Message: e.Message}, err)
+		case pqerror.FeatureNotSupported:
+			if e.Message == "cached plan must not change result type" {
+				return errors.Join(sqldb.ErrStmtInvalidated, err)
+			}
 		}
+		if e.Code.Class() == pqerror.ClassConnectionException {
+			return errors.Join(sqldb.ErrConnectionLost, err)
+		}
+		return err
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errors.Join(sqldb.ErrConnectionLost, err)
 	}
 	return err
 }
@@ -0,0 +1,49 @@
+package pqconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// TestExecBatch verifies that a batch mixing merged statements
+// without arguments, statements with arguments, and queries
+// returns the results in statement order.
+func TestExecBatch(t *testing.T) {
+	ctx := t.Context()
+	conn := pqConnect(t)
+
+	err := sqldb.Transaction(ctx, conn, nil, func(tx sqldb.Connection) error {
+		var ids []int64
+		var batch sqldb.Batch
+		batch.Exec(`CREATE TEMP TABLE test_batch (id bigint) ON COMMIT DROP`)
+		batch.Exec(`INSERT INTO test_batch VALUES (1), (2)`)
+		batch.Exec(`INSERT INTO test_batch VALUES ($1)`, 3)
+		batch.Query(
+			func(rows sqldb.Rows) error {
+				for rows.Next() {
+					var id int64
+					if err := rows.Scan(&id); err != nil {
+						return err
+					}
+					ids = append(ids, id)
+				}
+				return rows.Err()
+			},
+			`SELECT id FROM test_batch ORDER BY id`,
+		)
+		batch.Exec(`DELETE FROM test_batch WHERE id > $1`, 1)
+
+		results, err := sqldb.ExecBatch(ctx, tx, &batch)
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+		assert.Equal(t, int64(1), results[2].RowsAffected)
+		assert.Equal(t, int64(2), results[4].RowsAffected)
+		assert.Equal(t, []int64{1, 2, 3}, ids)
+		return nil
+	})
+	require.NoError(t, err)
+}
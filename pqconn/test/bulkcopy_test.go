@@ -0,0 +1,71 @@
+package pqconn
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+	"github.com/domonda/go-sqldb/pqconn"
+)
+
+type testBulkCopyRow struct {
+	sqldb.TableName `db:"test_bulk_copy"`
+
+	ID     int64    `db:"id,primarykey"`
+	Name   string   `db:"name"`
+	Note   *string  `db:"note"`
+	Values []string `db:"vals"`
+}
+
+// TestCopyRowStructs verifies that structs are copied
+// with COPY FROM STDIN including NULL and array values.
+func TestCopyRowStructs(t *testing.T) {
+	ctx := t.Context()
+	conn := pqConnect(t)
+	err := conn.Exec(ctx,
+		/*sql*/ `
+		CREATE TABLE test_bulk_copy (
+			id   bigint PRIMARY KEY,
+			name text NOT NULL,
+			note text,
+			vals text[] NOT NULL
+		)`,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Exec(ctx, `DROP TABLE test_bulk_copy`) }) //nolint:errcheck
+
+	const numRows = 10_000
+	note := "tab\tand\nnewline"
+	rows := iter.Seq[testBulkCopyRow](func(yield func(testBulkCopyRow) bool) {
+		for i := range int64(numRows) {
+			row := testBulkCopyRow{ID: i + 1, Name: "row", Values: []string{"a", "b,c"}}
+			if i == 0 {
+				row.Note = &note
+			}
+			if !yield(row) {
+				return
+			}
+		}
+	})
+
+	copied, err := sqldb.CopyRowStructs(ctx, conn, refl, pqconn.QueryBuilder{}, conn, rows)
+	require.NoError(t, err)
+	assert.Equal(t, int64(numRows), copied)
+
+	count, err := sqldb.QueryRowAs[int64](ctx, conn, refl, conn,
+		/*sql*/ `SELECT count(*) FROM test_bulk_copy`,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int64(numRows), count)
+
+	first, err := sqldb.QueryRowAs[testBulkCopyRow](ctx, conn, refl, conn,
+		/*sql*/ `SELECT * FROM test_bulk_copy WHERE id = 1`,
+	)
+	require.NoError(t, err)
+	require.NotNil(t, first.Note)
+	assert.Equal(t, note, *first.Note)
+	assert.Equal(t, []string{"a", "b,c"}, first.Values)
+}
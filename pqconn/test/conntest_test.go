@@ -54,6 +54,11 @@ func TestConnectionSuite(t *testing.T) {
 				id    INTEGER PRIMARY KEY,
 				email TEXT
 			)`,
+			CreateJSONTable: /*sql*/ `CREATE TABLE conntest_json (
+				id   INTEGER PRIMARY KEY,
+				data JSONB,
+				tags JSONB
+			)`,
 			CreateInfoParent: /*sql*/ `CREATE TABLE conntest_info_parent (
 				id1 INTEGER NOT NULL,
 				id2 INTEGER NOT NULL,
@@ -80,6 +85,7 @@ func TestConnectionSuite(t *testing.T) {
 		SupportsReadOnlyTransaction:  true,
 		SupportsCustomIsolationLevel: true,
 		ExecAfterClosedTxErrors:      true,
+		SessionVarQuery:              `SELECT current_setting('conntest.tenant_id', true)`,
 		Information: conntest.InformationFeatures{
 			SupportsRoutines: true,
 		},
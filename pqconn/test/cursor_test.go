@@ -0,0 +1,68 @@
+package pqconn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+	"github.com/domonda/go-sqldb/pqconn"
+)
+
+// TestQueryCursor verifies that pqconn.QueryCursor streams all rows of
+// a query in batches through a server-side cursor, both in a transaction
+// it begins itself and in a transaction of the caller.
+func TestQueryCursor(t *testing.T) {
+	ctx := t.Context()
+	conn := pqConnect(t)
+	const query = /*sql*/ `SELECT i FROM generate_series(1, 2500) AS i`
+
+	t.Run("own transaction", func(t *testing.T) {
+		var sum, count int64
+		for i, err := range sqldb.QueryRowsIter[int64](ctx, pqconn.CursorQuerier(conn, 1000), nil, conn, sqldb.UnlimitedMaxNumRows, query) {
+			require.NoError(t, err)
+			sum += i
+			count++
+		}
+		assert.Equal(t, int64(2500), count)
+		assert.Equal(t, int64(2500*2501/2), sum)
+
+		// The cursor transaction was committed and the pool session released
+		assert.Equal(t, 0, conn.Stats().InUse)
+	})
+
+	t.Run("caller transaction", func(t *testing.T) {
+		err := sqldb.Transaction(ctx, conn, nil, func(tx sqldb.Connection) error {
+			rows := pqconn.QueryCursor(ctx, tx, 100, query)
+			count := 0
+			for rows.Next() && count < 150 {
+				count++
+			}
+			require.NoError(t, rows.Err())
+			require.NoError(t, rows.Close())
+			assert.Equal(t, 150, count)
+
+			// The cursor was closed, so the transaction can be used again
+			cursors, err := sqldb.QueryRowAs[int](ctx, tx, nil, tx,
+				/*sql*/ `SELECT count(*) FROM pg_cursors`,
+			)
+			require.NoError(t, err)
+			assert.Equal(t, 0, cursors)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		rows := pqconn.QueryCursor(ctx, conn, 100, query)
+		require.True(t, rows.Next())
+		cancel()
+		for rows.Next() {
+		}
+		assert.ErrorIs(t, rows.Err(), context.Canceled)
+		assert.NoError(t, rows.Close())
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/domonda/go-sqldb"
@@ -74,6 +77,68 @@ func (conn *transaction) Prepare(ctx context.Context, query string) (sqldb.Stmt,
 	return stmt{query, s}, nil
 }
 
+func (conn *transaction) BindStmt(ctx context.Context, s sqldb.Stmt) (sqldb.Stmt, error) {
+	ps, ok := s.(stmt)
+	if !ok {
+		return nil, fmt.Errorf("%w: can't bind %T to a transaction", errors.ErrUnsupported, s)
+	}
+	return stmt{ps.query, conn.tx.StmtContext(ctx, ps.std)}, nil
+}
+
+// SetSessionVars implements sqldb.SessionVarsSetter by setting the variables
+// as transaction-local configuration parameters with set_config
+// that can be read with current_setting.
+// The returned reset function does nothing because
+// the settings end with the transaction.
+func (conn *transaction) SetSessionVars(ctx context.Context, vars map[string]string) (reset func(context.Context) error, err error) {
+	names := slices.Sorted(maps.Keys(vars))
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = vars[name]
+	}
+	err = conn.Exec(ctx,
+		/*sql*/ `SELECT set_config(v.name, v.value, true) FROM unnest($1::text[], $2::text[]) AS v(name, value)`,
+		names,
+		values,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) error { return nil }, nil
+}
+
+// SetTimeouts implements sqldb.TimeoutSetter by setting
+// statement_timeout to queryTimeout and transaction_timeout
+// to transactionTimeout for the rest of the transaction.
+// Servers before PostgreSQL 17 don't have transaction_timeout,
+// the transaction timeout is then only enforced client-side
+// with the context deadline.
+// The returned reset function does nothing because
+// the settings end with the transaction.
+func (conn *transaction) SetTimeouts(ctx context.Context, queryTimeout, transactionTimeout time.Duration) (reset func(context.Context) error, err error) {
+	for _, query := range setLocalTimeoutQueries(queryTimeout, transactionTimeout) {
+		err = conn.Exec(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return func(context.Context) error { return nil }, nil
+}
+
+// setLocalTimeoutQueries returns the statements setting
+// the timeouts that are greater than zero for the transaction.
+// transaction_timeout is only set if the server knows the setting.
+func setLocalTimeoutQueries(queryTimeout, transactionTimeout time.Duration) []string {
+	var queries []string
+	if ms := queryTimeout.Milliseconds(); ms > 0 {
+		queries = append(queries, fmt.Sprintf("SET LOCAL statement_timeout = %d", ms))
+	}
+	if ms := transactionTimeout.Milliseconds(); ms > 0 {
+		queries = append(queries, fmt.Sprintf("SELECT set_config('transaction_timeout', '%d', true) WHERE current_setting('transaction_timeout', true) IS NOT NULL", ms))
+	}
+	return queries
+}
+
 func (*transaction) DefaultIsolationLevel() sql.IsolationLevel {
 	return sql.LevelReadCommitted // postgres default
 }
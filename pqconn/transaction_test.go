@@ -0,0 +1,23 @@
+package pqconn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_setLocalTimeoutQueries(t *testing.T) {
+	assert.Empty(t, setLocalTimeoutQueries(0, 0))
+	assert.Equal(t,
+		[]string{"SET LOCAL statement_timeout = 1500"},
+		setLocalTimeoutQueries(1500*time.Millisecond, 0),
+	)
+	assert.Equal(t,
+		[]string{
+			"SET LOCAL statement_timeout = 1000",
+			"SELECT set_config('transaction_timeout', '60000', true) WHERE current_setting('transaction_timeout', true) IS NOT NULL",
+		},
+		setLocalTimeoutQueries(time.Second, time.Minute),
+	)
+}
@@ -1,7 +1,10 @@
 package pqconn
 
 import (
+	"database/sql/driver"
 	"errors"
+	"fmt"
+	"io"
 	"testing"
 
 	"github.com/lib/pq"
@@ -85,6 +88,41 @@ func Test_wrapKnownErrors(t *testing.T) {
 			inputErr:     &pq.Error{Code: pqerror.QueryCanceled},
 			wantSentinel: sqldb.ErrQueryCanceled,
 		},
+		{
+			name:         "QueryCanceled by statement timeout wraps to ErrQueryTimeout",
+			inputErr:     &pq.Error{Code: pqerror.QueryCanceled, Message: "canceling statement due to statement timeout"},
+			wantSentinel: sqldb.ErrQueryTimeout{Limit: sqldb.QueryTimeoutLimit},
+		},
+		{
+			name:         "TRSerializationFailure wraps to ErrSerializationFailure",
+			inputErr:     &pq.Error{Code: pqerror.TRSerializationFailure},
+			wantSentinel: sqldb.ErrSerializationFailure,
+		},
+		{
+			name:         "LockNotAvailable wraps to ErrLockTimeout",
+			inputErr:     &pq.Error{Code: pqerror.LockNotAvailable},
+			wantSentinel: sqldb.ErrLockTimeout,
+		},
+		{
+			name:         "AdminShutdown wraps to ErrConnectionLost",
+			inputErr:     &pq.Error{Code: pqerror.AdminShutdown},
+			wantSentinel: sqldb.ErrConnectionLost,
+		},
+		{
+			name:         "connection exception class wraps to ErrConnectionLost",
+			inputErr:     &pq.Error{Code: pqerror.ConnectionFailure},
+			wantSentinel: sqldb.ErrConnectionLost,
+		},
+		{
+			name:         "cached plan FeatureNotSupported wraps to ErrStmtInvalidated",
+			inputErr:     &pq.Error{Code: pqerror.FeatureNotSupported, Message: "cached plan must not change result type"},
+			wantSentinel: sqldb.ErrStmtInvalidated,
+		},
+		{
+			name:          "other FeatureNotSupported is returned unchanged",
+			inputErr:      &pq.Error{Code: pqerror.FeatureNotSupported, Message: "other feature"},
+			wantUnchanged: true,
+		},
 		{
 			name:           "ExclusionViolation wraps to ErrExclusionViolation",
 			inputErr:       &pq.Error{Code: pqerror.ExclusionViolation, Constraint: testConstraint},
@@ -180,3 +218,21 @@ func Test_wrapKnownErrors(t *testing.T) {
 		})
 	}
 }
+
+func Test_wrapKnownErrors_connectionLost(t *testing.T) {
+	for _, inputErr := range []error{
+		driver.ErrBadConn,
+		io.ErrUnexpectedEOF,
+		fmt.Errorf("read: %w", io.ErrUnexpectedEOF),
+	} {
+		t.Run(inputErr.Error(), func(t *testing.T) {
+			// when
+			result := wrapKnownErrors(inputErr)
+
+			// then
+			assert.ErrorIs(t, result, sqldb.ErrConnectionLost)
+			assert.ErrorIs(t, result, inputErr, "original error should be preserved")
+			assert.True(t, sqldb.IsRetryable(result))
+		})
+	}
+}
@@ -84,6 +84,10 @@ func QueryRowAsStmt[T any](ctx context.Context, conn Preparer, refl StructReflec
 // Primary key columns are identified by fields with the "primarykey" option
 // in their `db` struct tag (e.g., ID int `db:"id,primarykey"`).
 // The number of pkValue+pkValues must match the number of primary key columns.
+//
+// If the struct has a field with a `db` tag value having a ",softdelete" suffix,
+// then soft deleted rows where the column of the field is not NULL
+// are not returned unless ctx was returned by [ContextWithDeleted].
 func QueryRowStruct[S StructWithTableName](ctx context.Context, conn Querier, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, pkValue any, pkValues ...any) (S, error) {
 	if refl == nil {
 		return *new(S), errors.New("QueryRowStruct: nil StructReflector")
@@ -107,7 +111,7 @@ func QueryRowStruct[S StructWithTableName](ctx context.Context, conn Querier, re
 		if cached.numPKColumns != len(pkValues) {
 			return *new(S), fmt.Errorf("got %d primary key values, but struct %s has %d primary key fields", len(pkValues), t, cached.numPKColumns)
 		}
-		return QueryRowAs[S](ctx, conn, refl, fmtr, cached.query, pkValues...)
+		return QueryRowAs[S](ctx, conn, refl, fmtr, cached.queryFor(ctx), pkValues...)
 	}
 
 	// Cache miss — build query
@@ -125,10 +129,25 @@ func QueryRowStruct[S StructWithTableName](ctx context.Context, conn Querier, re
 	if len(pkColumns) != len(pkValues) {
 		return *new(S), fmt.Errorf("got %d primary key values, but struct %s has %d primary key fields", len(pkValues), t, len(pkColumns))
 	}
-	query, err := builder.QueryRowWithPK(fmtr, table, pkColumns)
+	entry := queryRowStructCacheEntry{numPKColumns: len(pkColumns)}
+	entry.query, err = builder.QueryRowWithPK(fmtr, table, pkColumns)
 	if err != nil {
 		return *new(S), err
 	}
+	softDeleteCol, err := softDeleteColumn(refl, t)
+	if err != nil {
+		return *new(S), err
+	}
+	if softDeleteCol != "" {
+		sqb, err := softDeleteQueryBuilder(builder)
+		if err != nil {
+			return *new(S), fmt.Errorf("QueryRowStruct of table %s: %w", table, err)
+		}
+		entry.notDeletedQuery, err = sqb.QueryRowWithPKNotDeleted(fmtr, table, pkColumns, softDeleteCol)
+		if err != nil {
+			return *new(S), err
+		}
+	}
 
 	// Store in cache
 	queryRowStructCacheMtx.Lock()
@@ -141,13 +160,10 @@ func QueryRowStruct[S StructWithTableName](ctx context.Context, conn Querier, re
 	if _, ok := queryRowStructCache[t][refl][builder]; !ok {
 		queryRowStructCache[t][refl][builder] = make(map[QueryFormatter]queryRowStructCacheEntry)
 	}
-	queryRowStructCache[t][refl][builder][fmtr] = queryRowStructCacheEntry{
-		query:        query,
-		numPKColumns: len(pkColumns),
-	}
+	queryRowStructCache[t][refl][builder][fmtr] = entry
 	queryRowStructCacheMtx.Unlock()
 
-	return QueryRowAs[S](ctx, conn, refl, fmtr, query, pkValues...)
+	return QueryRowAs[S](ctx, conn, refl, fmtr, entry.queryFor(ctx), pkValues...)
 }
 
 // QueryRowStructOr queries a table row by primary key and scans it into a struct of type S.
@@ -157,6 +173,7 @@ func QueryRowStruct[S StructWithTableName](ctx context.Context, conn Querier, re
 // Primary key columns are identified by fields with the "primarykey" option
 // in their `db` struct tag (e.g., ID int `db:"id,primarykey"`).
 // The number of pkValue+pkValues must match the number of primary key columns.
+// Soft deleted rows are handled like with [QueryRowStruct].
 func QueryRowStructOr[S StructWithTableName](ctx context.Context, conn Querier, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, defaultVal S, pkValue any, pkValues ...any) (S, error) {
 	row, err := QueryRowStruct[S](ctx, conn, refl, builder, fmtr, pkValue, pkValues...)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -2,6 +2,7 @@ package sqldb
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -75,6 +76,19 @@ type UpsertQueryBuilder interface {
 	InsertUnique(formatter QueryFormatter, table string, columns []ColumnInfo, conflictTarget string) (query string, err error)
 }
 
+// UpsertOptionsQueryBuilder builds upsert queries with the conflict handling
+// configured by [UpsertOptions], see there for the per-driver syntax
+// and security model.
+// It is implemented by the [UpsertQueryBuilder] implementations
+// of the driver packages, where Upsert is equivalent to
+// UpsertWithOptions with zero UpsertOptions.
+// Use a type assertion from [QueryBuilder] to check for support:
+//
+//	uoqb, ok := builder.(UpsertOptionsQueryBuilder)
+type UpsertOptionsQueryBuilder interface {
+	UpsertWithOptions(formatter QueryFormatter, table string, columns []ColumnInfo, options UpsertOptions) (query string, err error)
+}
+
 // ReturningQueryBuilder builds queries that return result rows
 // using driver-specific syntax (e.g. PostgreSQL/SQLite RETURNING clause).
 // Not all databases support RETURNING; use a type assertion
@@ -99,7 +113,90 @@ type ReturningQueryBuilder interface {
 	UpdateReturning(formatter QueryFormatter, table string, values Values, returningColumns, whereCondition string, whereArgs []any) (query string, queryArgs []any, err error)
 }
 
-// StdQueryBuilder implements [QueryBuilder]
+// RowsReturningQueryBuilder builds multi-row INSERT queries
+// with a RETURNING clause for [InsertRowStructsReturning].
+// It is implemented by builders for databases where the order
+// of the returned rows can be guaranteed, like postgres.QueryBuilder.
+// [ReturningQueryBuilder] implementations without it
+// insert one row per query with [ReturningQueryBuilder.InsertReturning],
+// like [StdReturningQueryBuilder] for SQLite, which returns
+// the rows of a multi-row INSERT in an arbitrary order.
+// Use a type assertion from [QueryBuilder] to check for support:
+//
+//	rrqb, ok := builder.(RowsReturningQueryBuilder)
+//
+// InsertRowsReturning builds a multi-row INSERT INTO query like
+// [QueryBuilder.InsertRows] with a RETURNING clause.
+// The rows must be returned in the order of the inserted values.
+//
+// SECURITY: returningColumns is concatenated into the generated SQL
+// verbatim and must be static SQL written by the developer.
+type RowsReturningQueryBuilder interface {
+	InsertRowsReturning(formatter QueryFormatter, table string, columns []ColumnInfo, numRows int, returningColumns string) (query string, err error)
+}
+
+// PageQueryBuilder builds keyset pagination queries for [QueryPage].
+// [StdQueryBuilder] implements it with row value comparisons
+// and a LIMIT clause, drivers without support for those
+// override it (e.g. mssqlconn.QueryBuilder, oraconn.QueryBuilder).
+// Use a type assertion from [QueryBuilder] to check for support:
+//
+//	pqb, ok := builder.(PageQueryBuilder)
+//
+// QueryPage wraps the passed query as derived table and returns at most limit
+// of its rows sorted by orderBy that come after the row with the
+// orderBy column values after. A nil after returns the first rows.
+// The returned pageArgs are the passed args followed by the after values
+// bound to the placeholders of the keyset condition.
+//
+// SECURITY: query is embedded into the generated SQL verbatim
+// and must be static SQL written by the developer.
+// The orderBy column names are validated with the formatter.
+type PageQueryBuilder interface {
+	QueryPage(formatter QueryFormatter, query string, args []any, orderBy []OrderByColumn, after []any, limit int) (pageQuery string, pageArgs []any, err error)
+}
+
+// SoftDeleteQueryBuilder builds queries for tables whose rows
+// are soft deleted by setting a nullable deletion timestamp column,
+// see [ColumnInfo.SoftDelete].
+// [StdQueryBuilder] implements it with standard SQL.
+// Use a type assertion from [QueryBuilder] to check for support:
+//
+//	sqb, ok := builder.(SoftDeleteQueryBuilder)
+//
+// SoftDelete builds an UPDATE query that sets the softDeleteColumn
+// to the value of the first placeholder for the row where the columns
+// equal the values of the following placeholders
+// and the row is not already soft deleted:
+//
+//	UPDATE table SET deleted_at=$1 WHERE id = $2 AND deleted_at IS NULL
+//
+// QueryRowWithPKNotDeleted builds a query like [QueryBuilder.QueryRowWithPK]
+// that excludes soft deleted rows.
+type SoftDeleteQueryBuilder interface {
+	SoftDelete(formatter QueryFormatter, table string, columns []ColumnInfo, softDeleteColumn string) (query string, err error)
+	QueryRowWithPKNotDeleted(formatter QueryFormatter, table string, pkColumns []string, softDeleteColumn string) (query string, err error)
+}
+
+// RelationQueryBuilder builds the queries of [LoadRelations]
+// that select the rows of a table where a column
+// has one of multiple values.
+// [StdQueryBuilder] implements it with an IN list of placeholders,
+// postgres.QueryBuilder with a single array argument for = ANY.
+// Use a type assertion from [QueryBuilder] to check for support:
+//
+//	rqb, ok := builder.(RelationQueryBuilder)
+//
+// QueryRowsWhereIn builds a SELECT * query for the rows of table
+// where column equals one of values and returns it with its arguments.
+// values must not be empty.
+// If softDeleteColumn is not empty, rows where it is not NULL are excluded.
+type RelationQueryBuilder interface {
+	QueryRowsWhereIn(formatter QueryFormatter, table, column string, values []any, softDeleteColumn string) (query string, args []any, err error)
+}
+
+// StdQueryBuilder implements [QueryBuilder], [PageQueryBuilder],
+// [SoftDeleteQueryBuilder], and [RelationQueryBuilder]
 // using standard SQL for CRUD operations.
 // It does not implement [UpsertQueryBuilder] or [ReturningQueryBuilder];
 // those are provided by driver-specific builders
@@ -128,6 +225,79 @@ func (StdQueryBuilder) QueryRowWithPK(formatter QueryFormatter, table string, pk
 	return q.String(), nil
 }
 
+// QueryRowWithPKNotDeleted builds a SELECT * query filtered by primary key columns
+// that excludes rows where softDeleteColumn is not NULL.
+func (b StdQueryBuilder) QueryRowWithPKNotDeleted(formatter QueryFormatter, table string, pkColumns []string, softDeleteColumn string) (query string, err error) {
+	query, err = b.QueryRowWithPK(formatter, table, pkColumns)
+	if err != nil {
+		return "", err
+	}
+	softDeleteColumn, err = formatter.FormatColumnName(softDeleteColumn)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`%s AND %s IS NULL`, query, softDeleteColumn), nil
+}
+
+// QueryRowsWhereIn builds a SELECT * query
+// with a column IN list of placeholders for values.
+func (StdQueryBuilder) QueryRowsWhereIn(formatter QueryFormatter, table, column string, values []any, softDeleteColumn string) (query string, args []any, err error) {
+	if len(values) == 0 {
+		return "", nil, fmt.Errorf("QueryRowsWhereIn requires at least one value")
+	}
+	var q strings.Builder
+	table, err = formatter.FormatTableName(table)
+	if err != nil {
+		return "", nil, err
+	}
+	column, err = formatter.FormatColumnName(column)
+	if err != nil {
+		return "", nil, err
+	}
+	fmt.Fprintf(&q, `SELECT * FROM %s WHERE %s IN (`, table, column)
+	for i := range values {
+		if i > 0 {
+			q.WriteByte(',')
+		}
+		q.WriteString(formatter.FormatPlaceholder(i))
+	}
+	q.WriteByte(')')
+	if softDeleteColumn != "" {
+		softDeleteColumn, err = formatter.FormatColumnName(softDeleteColumn)
+		if err != nil {
+			return "", nil, err
+		}
+		fmt.Fprintf(&q, ` AND %s IS NULL`, softDeleteColumn)
+	}
+	return q.String(), values, nil
+}
+
+// QueryPage builds a keyset pagination query using a row value comparison
+// like (a, b) > ($1, $2) if all orderBy columns have the same direction
+// and a LIMIT clause. See [PageQueryBuilder] for the contract.
+func (StdQueryBuilder) QueryPage(formatter QueryFormatter, query string, args []any, orderBy []OrderByColumn, after []any, limit int) (pageQuery string, pageArgs []any, err error) {
+	if limit < 1 {
+		return "", nil, fmt.Errorf("QueryPage: limit must be >= 1, got %d", limit)
+	}
+	var q strings.Builder
+	fmt.Fprintf(&q, `SELECT * FROM (%s) %s`, query, PageDerivedTableName)
+	pageArgs = args
+	if after != nil {
+		condition, conditionArgs, err := KeysetCondition(formatter, orderBy, after, len(args), true)
+		if err != nil {
+			return "", nil, err
+		}
+		fmt.Fprintf(&q, ` WHERE %s`, condition)
+		pageArgs = append(slices.Clip(args), conditionArgs...)
+	}
+	orderByClause, err := OrderByClause(formatter, orderBy)
+	if err != nil {
+		return "", nil, err
+	}
+	fmt.Fprintf(&q, ` ORDER BY %s LIMIT %d`, orderByClause, limit)
+	return q.String(), pageArgs, nil
+}
+
 // Insert builds an INSERT INTO query for the given table and columns.
 func (StdQueryBuilder) Insert(formatter QueryFormatter, table string, columns []ColumnInfo) (query string, err error) {
 	var q strings.Builder
@@ -339,6 +509,38 @@ func (StdQueryBuilder) Delete(formatter QueryFormatter, table string, columns []
 	return q.String(), nil
 }
 
+// SoftDelete builds an UPDATE query that sets softDeleteColumn to the first
+// placeholder where the columns equal the following placeholders
+// and softDeleteColumn IS NULL.
+func (StdQueryBuilder) SoftDelete(formatter QueryFormatter, table string, columns []ColumnInfo, softDeleteColumn string) (query string, err error) {
+	if len(columns) == 0 {
+		return "", fmt.Errorf("SoftDelete requires at least one column")
+	}
+
+	var q strings.Builder
+	table, err = formatter.FormatTableName(table)
+	if err != nil {
+		return "", err
+	}
+	softDeleteColumn, err = formatter.FormatColumnName(softDeleteColumn)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(&q, `UPDATE %s SET %s=%s WHERE `, table, softDeleteColumn, formatter.FormatPlaceholder(0))
+
+	for i := range columns {
+		columnName, err := formatter.FormatColumnName(columns[i].Name)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&q, `%s = %s AND `, columnName, formatter.FormatPlaceholder(1+i))
+	}
+	fmt.Fprintf(&q, `%s IS NULL`, softDeleteColumn)
+
+	return q.String(), nil
+}
+
 // StdReturningQueryBuilder extends [StdQueryBuilder] with
 // PostgreSQL/SQLite-compatible RETURNING clause support.
 // It implements [QueryBuilder] and [ReturningQueryBuilder].
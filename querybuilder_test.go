@@ -181,6 +181,73 @@ func TestStdQueryBuilder_DeleteColumns(t *testing.T) {
 	})
 }
 
+func TestStdQueryBuilder_SoftDelete(t *testing.T) {
+	b := StdQueryBuilder{}
+
+	tests := []struct {
+		name    string
+		table   string
+		columns []ColumnInfo
+		want    string
+	}{
+		{
+			name:    "single column",
+			table:   "users",
+			columns: []ColumnInfo{{Name: "id", PrimaryKey: true}},
+			want:    `UPDATE users SET deleted_at=$1 WHERE id = $2 AND deleted_at IS NULL`,
+		},
+		{
+			name:  "composite PK",
+			table: "order_items",
+			columns: []ColumnInfo{
+				{Name: "order_id", PrimaryKey: true},
+				{Name: "item_id", PrimaryKey: true},
+			},
+			want: `UPDATE order_items SET deleted_at=$1 WHERE order_id = $2 AND item_id = $3 AND deleted_at IS NULL`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := b.SoftDelete(testFormatter, tt.table, tt.columns, "deleted_at")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("no columns error", func(t *testing.T) {
+		_, err := b.SoftDelete(testFormatter, "users", nil, "deleted_at")
+		require.Error(t, err)
+	})
+}
+
+func TestStdQueryBuilder_QueryRowWithPKNotDeleted(t *testing.T) {
+	got, err := StdQueryBuilder{}.QueryRowWithPKNotDeleted(testFormatter, "order_items", []string{"order_id", "item_id"}, "deleted_at")
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM order_items WHERE order_id = $1 AND item_id = $2 AND deleted_at IS NULL`, got)
+}
+
+func TestStdQueryBuilder_QueryRowsWhereIn(t *testing.T) {
+	b := StdQueryBuilder{}
+
+	t.Run("values", func(t *testing.T) {
+		query, args, err := b.QueryRowsWhereIn(testFormatter, "order_items", "order_id", []any{int64(1), int64(2)}, "")
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM order_items WHERE order_id IN ($1,$2)`, query)
+		assert.Equal(t, []any{int64(1), int64(2)}, args)
+	})
+
+	t.Run("soft delete column", func(t *testing.T) {
+		query, _, err := b.QueryRowsWhereIn(testFormatter, "order_items", "order_id", []any{int64(1)}, "deleted_at")
+		require.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM order_items WHERE order_id IN ($1) AND deleted_at IS NULL`, query)
+	})
+
+	t.Run("no values error", func(t *testing.T) {
+		_, _, err := b.QueryRowsWhereIn(testFormatter, "order_items", "order_id", nil, "")
+		require.Error(t, err)
+	})
+}
+
 func TestStdQueryBuilder_UpdateColumns(t *testing.T) {
 	b := StdQueryBuilder{}
 
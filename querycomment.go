@@ -0,0 +1,207 @@
+package sqldb
+
+import (
+	"context"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/go-sqllexer"
+)
+
+type (
+	queryTagsCtxKey    struct{}
+	queryCommentCtxKey struct{}
+)
+
+// ContextWithQueryTags returns a new context with the tag key and value
+// added to the query tags of the parent context, overwriting a tag
+// with the same key.
+// The tags are appended as sqlcommenter comment to every statement
+// executed with the context on a connection wrapped
+// with [QueryCommentInterceptor].
+func ContextWithQueryTags(ctx context.Context, key, value string) context.Context {
+	tags := maps.Clone(QueryTagsFromContext(ctx))
+	if tags == nil {
+		tags = make(map[string]string, 1)
+	}
+	tags[key] = value
+	return context.WithValue(ctx, queryTagsCtxKey{}, tags)
+}
+
+// QueryTagsFromContext returns the query tags added
+// to the context with [ContextWithQueryTags] or nil.
+// The returned map must not be modified.
+func QueryTagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(queryTagsCtxKey{}).(map[string]string)
+	return tags
+}
+
+// QueryTagsFunc returns additional query tags for a context,
+// like the traceparent of the current trace span.
+type QueryTagsFunc func(ctx context.Context) map[string]string
+
+// QueryCommentInterceptor returns an [Interceptor] that appends
+// the query tags from [ContextWithQueryTags] and the passed tagFuncs
+// as [sqlcommenter] comment to the statements executed with
+// Exec, ExecRowsAffected, and Query, so that queries showing up
+// in pg_stat_activity or slow query logs can be traced back to their origin.
+// Tags from the context overwrite tags from tagFuncs with the same key.
+//
+// See [AppendQueryComment] for the format and the statements
+// that are not commented. Statements returned by Connection.Prepare
+// are not commented because they are prepared once for many executions.
+// A [StmtCache] interceptor after this one in the chain caches
+// the statements by their query without the comment and prepares
+// them with the comment of the execution that prepared them,
+// so executions with different tags share one prepared statement.
+//
+// Example:
+//
+//	conn = sqldb.WrapConnection(conn, sqldb.QueryCommentInterceptor())
+//
+//	ctx = sqldb.ContextWithQueryTags(ctx, "route", "/api/users")
+//	err := conn.Exec(ctx, "DELETE FROM users WHERE id = $1", id)
+//	// Executes: DELETE FROM users WHERE id = $1 /*route='%2Fapi%2Fusers'*/
+//
+// [sqlcommenter]: https://google.github.io/sqlcommenter/spec/
+func QueryCommentInterceptor(tagFuncs ...QueryTagsFunc) Interceptor {
+	comment := func(ctx context.Context, conn Connection, query string) (context.Context, string) {
+		if isPreparedStmtExecution(ctx) {
+			return ctx, query
+		}
+		tags := QueryTagsFromContext(ctx)
+		if len(tagFuncs) > 0 {
+			merged := make(map[string]string)
+			for _, f := range tagFuncs {
+				maps.Copy(merged, f(ctx))
+			}
+			maps.Copy(merged, tags)
+			tags = merged
+		}
+		commented := AppendQueryComment(query, tags, conn)
+		if commented == query {
+			return ctx, query
+		}
+		return context.WithValue(ctx, queryCommentCtxKey{}, queryComment{query, commented}), commented
+	}
+	return Interceptor{
+		Exec: func(ctx context.Context, conn Connection, query string, args []any, next ExecFunc) error {
+			ctx, query = comment(ctx, conn, query)
+			return next(ctx, query, args)
+		},
+		ExecRowsAffected: func(ctx context.Context, conn Connection, query string, args []any, next ExecRowsAffectedFunc) (int64, error) {
+			ctx, query = comment(ctx, conn, query)
+			return next(ctx, query, args)
+		},
+		Query: func(ctx context.Context, conn Connection, query string, args []any, next QueryFunc) Rows {
+			ctx, query = comment(ctx, conn, query)
+			return next(ctx, query, args)
+		},
+	}
+}
+
+// queryComment is the context value of [QueryCommentInterceptor]
+// with the query before and after appending the comment.
+type queryComment struct {
+	query     string
+	commented string
+}
+
+// uncommentedQuery returns the query without the comment appended
+// by [QueryCommentInterceptor] if it was passed unchanged from the
+// interceptor with ctx, else the query is returned as is.
+func uncommentedQuery(ctx context.Context, query string) string {
+	c, ok := ctx.Value(queryCommentCtxKey{}).(queryComment)
+	if !ok || c.commented != query {
+		return query
+	}
+	return c.query
+}
+
+// AppendQueryComment returns the query with the tags appended as
+// [sqlcommenter] comment like /*controller='users',route='%2Fapi%2Fusers'*/
+// before trailing semicolons.
+//
+// The comment is always appended at the end of the query
+// with the tags sorted by key, so that the beginning
+// of the query stays the same for database side statistics and caches.
+// Keys and values are URL encoded, and values are quoted as string literals
+// with the FormatStringLiteral method of the passed formatter.
+//
+// Only single SELECT, INSERT, UPDATE, DELETE, MERGE, VALUES, or WITH
+// statements are commented. The query is returned unchanged if there
+// are no tags, it is another kind of statement, has multiple statements,
+// has an unterminated string literal, or already contains a comment,
+// because comments can't be nested safely.
+// Comment-like character sequences within string literals
+// and quoted identifiers don't count as comments.
+//
+// [sqlcommenter]: https://google.github.io/sqlcommenter/spec/
+func AppendQueryComment(query string, tags map[string]string, formatter QueryFormatter) string {
+	if len(tags) == 0 || !isCommentableQuery(query) {
+		return query
+	}
+	end := len(strings.TrimRight(query, " \t\r\n;"))
+
+	var b strings.Builder
+	b.WriteString(query[:end])
+	b.WriteString(" /*")
+	for i, key := range slices.Sorted(maps.Keys(tags)) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(queryCommentEscape(key))
+		b.WriteByte('=')
+		b.WriteString(formatter.FormatStringLiteral(queryCommentEscape(tags[key])))
+	}
+	b.WriteString("*/")
+	b.WriteString(query[end:])
+	return b.String()
+}
+
+// isCommentableQuery returns true if query is a single statement
+// of a kind listed in [AppendQueryComment] without comments
+// or unterminated string literals.
+func isCommentableQuery(query string) bool {
+	var (
+		first        = true
+		endStatement = false
+		lexer        = sqllexer.New(query)
+	)
+	for {
+		token := lexer.Scan()
+		switch token.Type {
+		case sqllexer.EOF:
+			return !first
+		case sqllexer.SPACE:
+			continue
+		case sqllexer.COMMENT, sqllexer.MULTILINE_COMMENT, sqllexer.INCOMPLETE_STRING, sqllexer.ERROR:
+			return false
+		case sqllexer.PUNCTUATION:
+			if token.Value == ";" {
+				endStatement = true
+				continue
+			}
+		}
+		if endStatement {
+			// Multiple statements
+			return false
+		}
+		if first {
+			switch strings.ToUpper(token.Value) {
+			case "SELECT", "INSERT", "UPDATE", "DELETE", "MERGE", "VALUES", "WITH":
+			default:
+				return false
+			}
+			first = false
+		}
+	}
+}
+
+// queryCommentEscape URL encodes s so that the result
+// contains no characters that could end the comment or string literal.
+func queryCommentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,144 @@
+package sqldb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextWithQueryTags(t *testing.T) {
+	// given
+	parent := ContextWithQueryTags(t.Context(), "controller", "users")
+
+	// when
+	ctx := ContextWithQueryTags(parent, "route", "/users")
+	ctx = ContextWithQueryTags(ctx, "controller", "admin")
+
+	// then
+	assert.Nil(t, QueryTagsFromContext(t.Context()))
+	assert.Equal(t, map[string]string{"controller": "users"}, QueryTagsFromContext(parent))
+	assert.Equal(t, map[string]string{"controller": "admin", "route": "/users"}, QueryTagsFromContext(ctx))
+}
+
+func TestAppendQueryComment(t *testing.T) {
+	tags := map[string]string{"route": "/api/users", "controller": "users"}
+	const comment = `/*controller='users',route='%2Fapi%2Fusers'*/`
+
+	tests := []struct {
+		name  string
+		query string
+		tags  map[string]string
+		want  string
+	}{
+		{name: "appended", query: "SELECT 1", tags: tags, want: "SELECT 1 " + comment},
+		{name: "before trailing semicolon", query: "SELECT 1;\n", tags: tags, want: "SELECT 1 " + comment + ";\n"},
+		{name: "no tags", query: "SELECT 1", tags: nil, want: "SELECT 1"},
+		{name: "empty query", query: " ;", tags: tags, want: " ;"},
+		{name: "existing block comment", query: "SELECT /* x */ 1", tags: tags, want: "SELECT /* x */ 1"},
+		{name: "existing line comment", query: "SELECT 1 -- x", tags: tags, want: "SELECT 1 -- x"},
+		{
+			name:  "comment-like sequences in literals",
+			query: "SELECT '--', '/*' FROM t",
+			tags:  tags,
+			want:  "SELECT '--', '/*' FROM t " + comment,
+		},
+		{name: "unterminated literal", query: "SELECT 'x", tags: tags, want: "SELECT 'x"},
+		{name: "WITH", query: "WITH x AS (SELECT 1) SELECT * FROM x", tags: tags, want: "WITH x AS (SELECT 1) SELECT * FROM x " + comment},
+		{name: "lower case", query: "delete from t", tags: tags, want: "delete from t " + comment},
+		{name: "other statement kind", query: "SET x = 1", tags: tags, want: "SET x = 1"},
+		{name: "multiple statements", query: "DELETE FROM a; DELETE FROM b", tags: tags, want: "DELETE FROM a; DELETE FROM b"},
+		{
+			name:  "escaped",
+			query: "SELECT 1",
+			tags:  map[string]string{"name with space": "it's */ done"},
+			want:  "SELECT 1 /*name%20with%20space='it%27s%20%2A%2F%20done'*/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, AppendQueryComment(tt.query, tt.tags, StdQueryFormatter{}))
+		})
+	}
+}
+
+func TestQueryCommentInterceptor(t *testing.T) {
+	traceTags := func(ctx context.Context) map[string]string {
+		return map[string]string{"traceparent": "00-1-2-01", "route": "default"}
+	}
+
+	t.Run("Exec, ExecRowsAffected, and Query", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn := WrapConnection(NewMockConn(nil).WithQueryLog(log), QueryCommentInterceptor())
+		ctx := ContextWithQueryTags(t.Context(), "route", "r")
+
+		// when
+		require.NoError(t, conn.Exec(ctx, "DELETE FROM t"))
+		_, err := conn.ExecRowsAffected(ctx, "DELETE FROM t")
+		require.NoError(t, err)
+		rows := conn.Query(ctx, "SELECT * FROM t")
+		require.NoError(t, rows.Close())
+
+		// then
+		assert.Equal(t,
+			"DELETE FROM t /*route='r'*/;\nDELETE FROM t /*route='r'*/;\nSELECT * FROM t /*route='r'*/;\n",
+			log.String(),
+		)
+	})
+
+	t.Run("context tags overwrite tag funcs", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn := WrapConnection(NewMockConn(nil).WithQueryLog(log), QueryCommentInterceptor(traceTags))
+		ctx := ContextWithQueryTags(t.Context(), "route", "r")
+
+		// when
+		err := conn.Exec(ctx, "DELETE FROM t")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "DELETE FROM t /*route='r',traceparent='00-1-2-01'*/;\n", log.String())
+	})
+
+	t.Run("without tags", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn := WrapConnection(NewMockConn(nil).WithQueryLog(log), QueryCommentInterceptor())
+
+		// when
+		err := conn.Exec(t.Context(), "DELETE FROM t")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "DELETE FROM t;\n", log.String())
+	})
+
+	t.Run("prepared statements not commented", func(t *testing.T) {
+		// given
+		var executed []string
+		base := NewMockConn(nil)
+		base.MockPrepare = func(ctx context.Context, query string) (Stmt, error) {
+			return &MockStmt{
+				Prepared: query,
+				MockExec: func(ctx context.Context, args ...any) error {
+					executed = append(executed, query)
+					return nil
+				},
+			}, nil
+		}
+		conn := WrapConnection(base, QueryCommentInterceptor())
+		ctx := ContextWithQueryTags(t.Context(), "route", "r")
+		stmt, err := conn.Prepare(ctx, "DELETE FROM t")
+		require.NoError(t, err)
+
+		// when
+		err = stmt.Exec(ctx)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"DELETE FROM t"}, executed)
+	})
+}
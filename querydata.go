@@ -1,6 +1,10 @@
 package sqldb
 
-import "github.com/DataDog/go-sqllexer"
+import (
+	"strings"
+
+	"github.com/DataDog/go-sqllexer"
+)
 
 // NormalizeQueryFunc is a function type that normalizes an SQL query string.
 type NormalizeQueryFunc func(query string) (string, error)
@@ -26,6 +30,31 @@ func NewQueryNormalizer() NormalizeQueryFunc {
 	}
 }
 
+// NewQueryFingerprinter returns a NormalizeQueryFunc that normalizes
+// SQL queries like [NewQueryNormalizer] and additionally replaces
+// literal values with ? placeholders, so that queries differing
+// only in their literal values map to the same fingerprint.
+// Placeholders for query arguments are kept unchanged.
+//
+// The fingerprint is meant for grouping queries in metrics or logs
+// without leaking literal values.
+func NewQueryFingerprinter() NormalizeQueryFunc {
+	obfuscator := sqllexer.NewObfuscator(
+		sqllexer.WithReplaceDigits(false),
+	)
+	normalizer := sqllexer.NewNormalizer(
+		sqllexer.WithCollectCommands(true),
+		sqllexer.WithCollectTables(true),
+		sqllexer.WithKeepSQLAlias(true),
+		sqllexer.WithRemoveSpaceBetweenParentheses(true),
+		sqllexer.WithKeepIdentifierQuotation(true),
+	)
+	return func(query string) (string, error) {
+		query, _, err := sqllexer.ObfuscateAndNormalize(query, obfuscator, normalizer)
+		return query, err
+	}
+}
+
 // QueryData holds an SQL query with its arguments
 // (query parameters).
 type QueryData struct {
@@ -52,3 +81,49 @@ func NewQueryData(query string, args []any, normalize NormalizeQueryFunc) (Query
 func (q *QueryData) Format(formatter QueryFormatter) string {
 	return FormatQuery(formatter, q.Query, q.Args...)
 }
+
+// queryWords returns the upper case keywords and identifiers of query
+// and its semicolons separating statements, ignoring string literals,
+// quoted identifiers, and comments.
+func queryWords(query string) []string {
+	var words []string
+	lexer := sqllexer.New(query)
+	for {
+		token := lexer.Scan()
+		switch token.Type {
+		case sqllexer.EOF:
+			return words
+		case sqllexer.COMMAND, sqllexer.KEYWORD, sqllexer.IDENT, sqllexer.FUNCTION,
+			sqllexer.CTE_INDICATOR, sqllexer.ALIAS_INDICATOR, sqllexer.PROC_INDICATOR,
+			sqllexer.BOOLEAN, sqllexer.NULL:
+			words = append(words, strings.ToUpper(token.Value))
+		case sqllexer.PUNCTUATION:
+			if token.Value == ";" {
+				words = append(words, ";")
+			}
+		}
+	}
+}
+
+// QueryKeyword returns the upper case first keyword of query
+// skipping leading whitespace and comments,
+// for example to classify statements for logging or tracing.
+func QueryKeyword(query string) string {
+	for {
+		query = strings.TrimSpace(query)
+		switch {
+		case strings.HasPrefix(query, "--"):
+			_, query, _ = strings.Cut(query, "\n")
+		case strings.HasPrefix(query, "/*"):
+			_, query, _ = strings.Cut(query, "*/")
+		default:
+			end := strings.IndexFunc(query, func(r rune) bool {
+				return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+			})
+			if end == -1 {
+				end = len(query)
+			}
+			return strings.ToUpper(query[:end])
+		}
+	}
+}
@@ -41,3 +41,56 @@ func TestNewQueryData(t *testing.T) {
 		})
 	}
 }
+
+func TestNewQueryFingerprinter(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "SELECT * FROM public.user WHERE \"name\" = $1\n\tAND age=$2",
+			want:  `SELECT * FROM public.user WHERE "name" = $1 AND age = $2`,
+		},
+		{
+			query: "SELECT * FROM public.user WHERE name = 'Alice' AND age = 30",
+			want:  `SELECT * FROM public.user WHERE name = ? AND age = ?`,
+		},
+		{
+			query: "SELECT id FROM user2 WHERE id IN (1, 2, 3)",
+			want:  `SELECT id FROM user2 WHERE id IN (?)`,
+		},
+	}
+	fingerprint := NewQueryFingerprinter()
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := fingerprint(tt.query)
+			if err != nil {
+				t.Fatalf("fingerprint(%#v) error = %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Errorf("fingerprint(%#v) = %#v, want %#v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryKeyword(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "SELECT 1", want: "SELECT"},
+		{query: "  select * from t", want: "SELECT"},
+		{query: "-- comment\nINSERT INTO t DEFAULT VALUES", want: "INSERT"},
+		{query: "/* app=x */ update t set a = 1", want: "UPDATE"},
+		{query: "WITH x AS (SELECT 1) SELECT * FROM x", want: "WITH"},
+		{query: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := QueryKeyword(tt.query); got != tt.want {
+				t.Errorf("QueryKeyword(%#v) = %#v, want %#v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"reflect"
+)
+
+// Tuple2 holds the values of a row with 2 columns
+// returned by [QueryRowsIter2].
+type Tuple2[T0, T1 any] struct {
+	V0 T0
+	V1 T1
+}
+
+// Values returns the values of the tuple.
+func (t Tuple2[T0, T1]) Values() (T0, T1) {
+	return t.V0, t.V1
+}
+
+// Tuple3 holds the values of a row with 3 columns
+// returned by [QueryRowsIter3].
+type Tuple3[T0, T1, T2 any] struct {
+	V0 T0
+	V1 T1
+	V2 T2
+}
+
+// Values returns the values of the tuple.
+func (t Tuple3[T0, T1, T2]) Values() (T0, T1, T2) {
+	return t.V0, t.V1, t.V2
+}
+
+// Tuple4 holds the values of a row with 4 columns
+// returned by [QueryRowsIter4].
+type Tuple4[T0, T1, T2, T3 any] struct {
+	V0 T0
+	V1 T1
+	V2 T2
+	V3 T3
+}
+
+// Values returns the values of the tuple.
+func (t Tuple4[T0, T1, T2, T3]) Values() (T0, T1, T2, T3) {
+	return t.V0, t.V1, t.V2, t.V3
+}
+
+// Tuple5 holds the values of a row with 5 columns
+// returned by [QueryRowsIter5].
+type Tuple5[T0, T1, T2, T3, T4 any] struct {
+	V0 T0
+	V1 T1
+	V2 T2
+	V3 T3
+	V4 T4
+}
+
+// Values returns the values of the tuple.
+func (t Tuple5[T0, T1, T2, T3, T4]) Values() (T0, T1, T2, T3, T4) {
+	return t.V0, t.V1, t.V2, t.V3, t.V4
+}
+
+// QueryRowsIter returns an iterator over the rows of the query
+// scanned as the type T.
+// If T is a struct that does not implement [sql.Scanner],
+// then the column values are scanned into the struct fields
+// using the passed reflector, else the query must return a single column.
+//
+// The query is executed when the iteration starts and every row
+// is scanned when it is yielded, so the result is never loaded
+// into memory as a whole. The underlying rows are closed when
+// the iteration ends, including an early break out of the loop.
+//
+// An error ends the iteration after it was yielded together
+// with the zero value of T as last pair, wrapped with the query.
+// Exceeding maxNumRows yields [ErrMaxNumRowsExceeded],
+// pass [UnlimitedMaxNumRows] (or any negative integer) to disable the limit.
+//
+// Example:
+//
+//	for user, err := range sqldb.QueryRowsIter[User](ctx, conn, refl, conn, sqldb.UnlimitedMaxNumRows, "SELECT * FROM users") {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(user.Name)
+//	}
+func QueryRowsIter[T any](ctx context.Context, conn Querier, refl StructReflector, fmtr QueryFormatter, maxNumRows int, query string, args ...any) iter.Seq2[T, error] {
+	t := reflect.TypeFor[T]()
+	if isNonSQLScannerStruct(t) {
+		return queryRowsIter(ctx, conn, fmtr, maxNumRows, query, args, func(rows Rows, columns []string, dest *T) error {
+			return scanStruct(rows, columns, refl, dest)
+		})
+	}
+	return queryRowsIter(ctx, conn, fmtr, maxNumRows, query, args, func(rows Rows, columns []string, dest *T) error {
+		if len(columns) > 1 {
+			return fmt.Errorf("expected single column result for type %s but got %d columns", t, len(columns))
+		}
+		return rows.Scan(dest)
+	})
+}
+
+// QueryRowsIter2 returns an iterator over the rows of a query
+// with 2 columns scanned into a [Tuple2].
+// See [QueryRowsIter] for the iteration and error semantics.
+//
+// Example:
+//
+//	for row, err := range sqldb.QueryRowsIter2[int64, string](ctx, conn, conn, sqldb.UnlimitedMaxNumRows, "SELECT id, name FROM users") {
+//		if err != nil {
+//			return err
+//		}
+//		id, name := row.Values()
+//	}
+func QueryRowsIter2[T0, T1 any](ctx context.Context, conn Querier, fmtr QueryFormatter, maxNumRows int, query string, args ...any) iter.Seq2[Tuple2[T0, T1], error] {
+	return queryRowsIter(ctx, conn, fmtr, maxNumRows, query, args, func(rows Rows, _ []string, dest *Tuple2[T0, T1]) error {
+		return rows.Scan(&dest.V0, &dest.V1)
+	})
+}
+
+// QueryRowsIter3 returns an iterator over the rows of a query
+// with 3 columns scanned into a [Tuple3].
+// See [QueryRowsIter] for the iteration and error semantics.
+func QueryRowsIter3[T0, T1, T2 any](ctx context.Context, conn Querier, fmtr QueryFormatter, maxNumRows int, query string, args ...any) iter.Seq2[Tuple3[T0, T1, T2], error] {
+	return queryRowsIter(ctx, conn, fmtr, maxNumRows, query, args, func(rows Rows, _ []string, dest *Tuple3[T0, T1, T2]) error {
+		return rows.Scan(&dest.V0, &dest.V1, &dest.V2)
+	})
+}
+
+// QueryRowsIter4 returns an iterator over the rows of a query
+// with 4 columns scanned into a [Tuple4].
+// See [QueryRowsIter] for the iteration and error semantics.
+func QueryRowsIter4[T0, T1, T2, T3 any](ctx context.Context, conn Querier, fmtr QueryFormatter, maxNumRows int, query string, args ...any) iter.Seq2[Tuple4[T0, T1, T2, T3], error] {
+	return queryRowsIter(ctx, conn, fmtr, maxNumRows, query, args, func(rows Rows, _ []string, dest *Tuple4[T0, T1, T2, T3]) error {
+		return rows.Scan(&dest.V0, &dest.V1, &dest.V2, &dest.V3)
+	})
+}
+
+// QueryRowsIter5 returns an iterator over the rows of a query
+// with 5 columns scanned into a [Tuple5].
+// See [QueryRowsIter] for the iteration and error semantics.
+func QueryRowsIter5[T0, T1, T2, T3, T4 any](ctx context.Context, conn Querier, fmtr QueryFormatter, maxNumRows int, query string, args ...any) iter.Seq2[Tuple5[T0, T1, T2, T3, T4], error] {
+	return queryRowsIter(ctx, conn, fmtr, maxNumRows, query, args, func(rows Rows, _ []string, dest *Tuple5[T0, T1, T2, T3, T4]) error {
+		return rows.Scan(&dest.V0, &dest.V1, &dest.V2, &dest.V3, &dest.V4)
+	})
+}
+
+// queryRowsIter returns an iterator that executes the query
+// and yields every row scanned with the passed scan function.
+func queryRowsIter[T any](ctx context.Context, conn Querier, fmtr QueryFormatter, maxNumRows int, query string, args []any, scan func(rows Rows, columns []string, dest *T) error) iter.Seq2[T, error] {
+	if maxNumRows < 0 {
+		maxNumRows = math.MaxInt // Practically unlimited
+	}
+	return func(yield func(T, error) bool) {
+		sqlRows := conn.Query(ctx, query, args...)
+		closed := false
+		defer func() {
+			// Close rows if yield panicked
+			if !closed {
+				_ = sqlRows.Close()
+			}
+		}()
+
+		stopped := false
+		err := func() error {
+			columns, err := sqlRows.Columns()
+			if err != nil {
+				return err
+			}
+			for numRows := 0; sqlRows.Next(); numRows++ {
+				if numRows >= maxNumRows {
+					return ErrMaxNumRowsExceeded{MaxNumRows: maxNumRows}
+				}
+				if err = ctx.Err(); err != nil {
+					return err
+				}
+				var val T
+				if err = scan(sqlRows, columns, &val); err != nil {
+					return err
+				}
+				if !yield(val, nil) {
+					stopped = true
+					return nil
+				}
+			}
+			return sqlRows.Err()
+		}()
+		err = errors.Join(err, sqlRows.Close())
+		closed = true
+		if err != nil && !stopped {
+			yield(*new(T), WrapErrorWithQuery(err, query, args, fmtr))
+		}
+	}
+}
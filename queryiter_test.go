@@ -0,0 +1,254 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRowsIter(t *testing.T) {
+	t.Run("scalar values", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		rows := NewMockRows("name").WithRow("Alice").WithRow("Bob")
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows { return rows }
+
+		// when
+		var names []string
+		for name, err := range QueryRowsIter[string](t.Context(), conn, refl, fmtr, UnlimitedMaxNumRows, "SELECT name FROM users") {
+			require.NoError(t, err)
+			names = append(names, name)
+		}
+
+		// then
+		assert.Equal(t, []string{"Alice", "Bob"}, names)
+		assert.True(t, rows.closed)
+	})
+
+	t.Run("struct values", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("id", "name", "active").
+				WithRow(int64(1), "Alice", true).
+				WithRow(int64(2), "Bob", false)
+		}
+
+		// when
+		var got []reflectTestStruct
+		for row, err := range QueryRowsIter[reflectTestStruct](t.Context(), conn, refl, fmtr, UnlimitedMaxNumRows, "SELECT id, name, active FROM test_table") {
+			require.NoError(t, err)
+			got = append(got, row)
+		}
+
+		// then
+		assert.Equal(t, []reflectTestStruct{{ID: 1, Name: "Alice", Active: true}, {ID: 2, Name: "Bob"}}, got)
+	})
+
+	t.Run("query executed when iteration starts", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		queryCount := 0
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			queryCount++
+			return NewMockRows("name")
+		}
+
+		// when
+		seq := QueryRowsIter[string](t.Context(), conn, refl, fmtr, UnlimitedMaxNumRows, "SELECT name FROM users")
+		assert.Equal(t, 0, queryCount)
+		for range seq {
+		}
+
+		// then
+		assert.Equal(t, 1, queryCount)
+	})
+
+	t.Run("break closes rows", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		rows := NewMockRows("name").WithRow("Alice").WithRow("Bob")
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows { return rows }
+
+		// when
+		var names []string
+		for name, err := range QueryRowsIter[string](t.Context(), conn, refl, fmtr, UnlimitedMaxNumRows, "SELECT name FROM users") {
+			require.NoError(t, err)
+			names = append(names, name)
+			break
+		}
+
+		// then
+		assert.Equal(t, []string{"Alice"}, names)
+		assert.True(t, rows.closed)
+	})
+
+	t.Run("panic closes rows", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		rows := NewMockRows("name").WithRow("Alice")
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows { return rows }
+
+		// when
+		assert.Panics(t, func() {
+			for range QueryRowsIter[string](t.Context(), conn, refl, fmtr, UnlimitedMaxNumRows, "SELECT name FROM users") {
+				panic("test")
+			}
+		})
+
+		// then
+		assert.True(t, rows.closed)
+	})
+
+	t.Run("maxNumRows exceeded", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("name").WithRow("Alice").WithRow("Bob").WithRow("Charlie")
+		}
+
+		// when
+		var names []string
+		var iterErr error
+		for name, err := range QueryRowsIter[string](t.Context(), conn, refl, fmtr, 2, "SELECT name FROM users") {
+			if err != nil {
+				iterErr = err
+				continue
+			}
+			names = append(names, name)
+		}
+
+		// then
+		assert.Equal(t, []string{"Alice", "Bob"}, names)
+		var maxErr ErrMaxNumRowsExceeded
+		require.ErrorAs(t, iterErr, &maxErr)
+		assert.Equal(t, 2, maxErr.MaxNumRows)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		// given
+		errQuery := errors.New("query error")
+		conn, refl, _, fmtr := newTestInterfaces()
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows { return NewErrRows(errQuery) }
+
+		// when
+		var errs []error
+		for _, err := range QueryRowsIter[string](t.Context(), conn, refl, fmtr, UnlimitedMaxNumRows, "SELECT name FROM users WHERE id = $1", 1) {
+			errs = append(errs, err)
+		}
+
+		// then
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], errQuery)
+		assert.Contains(t, errs[0].Error(), "SELECT name FROM users WHERE id = 1")
+	})
+
+	t.Run("multiple columns for scalar", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("id", "name").WithRow(int64(1), "Alice")
+		}
+
+		// when
+		var iterErr error
+		for _, err := range QueryRowsIter[string](t.Context(), conn, refl, fmtr, UnlimitedMaxNumRows, "SELECT id, name FROM users") {
+			iterErr = err
+		}
+
+		// then
+		assert.ErrorContains(t, iterErr, "expected single column result")
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("name").WithRow("Alice")
+		}
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		// when
+		var iterErr error
+		for _, err := range QueryRowsIter[string](ctx, conn, refl, fmtr, UnlimitedMaxNumRows, "SELECT name FROM users") {
+			iterErr = err
+		}
+
+		// then
+		assert.ErrorIs(t, iterErr, context.Canceled)
+	})
+}
+
+func TestQueryRowsIterN(t *testing.T) {
+	conn, _, _, fmtr := newTestInterfaces()
+
+	t.Run("QueryRowsIter2", func(t *testing.T) {
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("id", "name").WithRow(int64(1), "Alice").WithRow(int64(2), "Bob")
+		}
+		got := map[int64]string{}
+		for row, err := range QueryRowsIter2[int64, string](t.Context(), conn, fmtr, UnlimitedMaxNumRows, "SELECT id, name FROM users") {
+			require.NoError(t, err)
+			id, name := row.Values()
+			got[id] = name
+		}
+		assert.Equal(t, map[int64]string{1: "Alice", 2: "Bob"}, got)
+	})
+
+	t.Run("QueryRowsIter2 column count mismatch", func(t *testing.T) {
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("id").WithRow(int64(1))
+		}
+		var iterErr error
+		for _, err := range QueryRowsIter2[int64, string](t.Context(), conn, fmtr, UnlimitedMaxNumRows, "SELECT id FROM users") {
+			iterErr = err
+		}
+		assert.Error(t, iterErr)
+	})
+
+	t.Run("QueryRowsIter3", func(t *testing.T) {
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("a", "b", "c").WithRow(int64(1), "x", true)
+		}
+		var got []Tuple3[int64, string, bool]
+		for row, err := range QueryRowsIter3[int64, string, bool](t.Context(), conn, fmtr, UnlimitedMaxNumRows, "SELECT a, b, c FROM t") {
+			require.NoError(t, err)
+			got = append(got, row)
+		}
+		assert.Equal(t, []Tuple3[int64, string, bool]{{1, "x", true}}, got)
+	})
+
+	t.Run("QueryRowsIter4", func(t *testing.T) {
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("a", "b", "c", "d").WithRow(int64(1), "x", true, 1.5)
+		}
+		var got []Tuple4[int64, string, bool, float64]
+		for row, err := range QueryRowsIter4[int64, string, bool, float64](t.Context(), conn, fmtr, UnlimitedMaxNumRows, "SELECT a, b, c, d FROM t") {
+			require.NoError(t, err)
+			got = append(got, row)
+		}
+		assert.Equal(t, []Tuple4[int64, string, bool, float64]{{1, "x", true, 1.5}}, got)
+	})
+
+	t.Run("QueryRowsIter5", func(t *testing.T) {
+		conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("a", "b", "c", "d", "e").WithRow(int64(1), "x", true, 1.5, "y")
+		}
+		var got []Tuple5[int64, string, bool, float64, string]
+		for row, err := range QueryRowsIter5[int64, string, bool, float64, string](t.Context(), conn, fmtr, UnlimitedMaxNumRows, "SELECT a, b, c, d, e FROM t") {
+			require.NoError(t, err)
+			got = append(got, row)
+		}
+		assert.Equal(t, []Tuple5[int64, string, bool, float64, string]{{1, "x", true, 1.5, "y"}}, got)
+		v0, v1, v2, v3, v4 := got[0].Values()
+		assert.Equal(t, int64(1), v0)
+		assert.Equal(t, "x", v1)
+		assert.True(t, v2)
+		assert.Equal(t, 1.5, v3)
+		assert.Equal(t, "y", v4)
+	})
+}
@@ -0,0 +1,498 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RelationKind is the kind of a relation between
+// the tables of two structs, see [RelationInfo].
+type RelationKind string
+
+const (
+	// RelationHasOne is a relation to a single row of another table
+	// with a foreign key column referencing the own table.
+	RelationHasOne RelationKind = "has_one"
+
+	// RelationHasMany is a relation to multiple rows of another table
+	// with a foreign key column referencing the own table.
+	RelationHasMany RelationKind = "has_many"
+
+	// RelationBelongsTo is a relation to a single row of another table
+	// that is referenced by a foreign key column of the own table.
+	RelationBelongsTo RelationKind = "belongs_to"
+)
+
+// RelationInfo describes a struct field that holds
+// the related rows of another table loaded by [LoadRelations].
+type RelationInfo struct {
+	// Name is the name of the struct field
+	Name string
+
+	// Kind of the relation
+	Kind RelationKind
+
+	// ForeignKey is the foreign key column of the related table
+	// for RelationHasOne and RelationHasMany
+	// or of the own table for RelationBelongsTo.
+	ForeignKey string
+
+	// References is the column referenced by ForeignKey
+	// of the own table for RelationHasOne and RelationHasMany
+	// or of the related table for RelationBelongsTo.
+	// An empty string references the single primary key column.
+	References string
+
+	// FieldIndex is the index sequence of the struct field
+	// for reflect.Value.FieldByIndex
+	FieldIndex []int
+
+	// Type is the struct type of the related rows
+	Type reflect.Type
+}
+
+// RelationReflector is implemented by a [StructReflector]
+// that supports relations between struct types for [LoadRelations].
+// [TaggedStructReflector] implements it with the "rel" struct tag.
+type RelationReflector interface {
+	// ReflectStructRelations returns the relations of the struct type.
+	ReflectStructRelations(structType reflect.Type) ([]RelationInfo, error)
+}
+
+// relationStructType returns the struct type of the related rows
+// of a struct field with the passed type for a relation kind.
+// Has-many relations need a slice of structs or struct pointers,
+// the other kinds a struct or struct pointer.
+func relationStructType(kind RelationKind, fieldType reflect.Type) (reflect.Type, error) {
+	t := fieldType
+	switch kind {
+	case RelationHasMany:
+		if t.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("%s relation needs a slice field but got %s", kind, fieldType)
+		}
+		t = t.Elem()
+	case RelationHasOne, RelationBelongsTo:
+	default:
+		return nil, fmt.Errorf("invalid relation kind %q", kind)
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s relation needs a struct field type but got %s", kind, fieldType)
+	}
+	return t, nil
+}
+
+// relationReflector returns refl as [RelationReflector]
+// or an error if it does not implement the interface.
+func relationReflector(refl StructReflector) (RelationReflector, error) {
+	if refl == nil {
+		return nil, errors.New("nil StructReflector")
+	}
+	rr, ok := refl.(RelationReflector)
+	if !ok {
+		return nil, fmt.Errorf("StructReflector %T does not implement RelationReflector", refl)
+	}
+	return rr, nil
+}
+
+// relationReferences returns the column referenced by the foreign key
+// of relation which defaults to the single primary key column
+// of structType or the related struct type for RelationBelongsTo.
+func relationReferences(refl StructReflector, structType reflect.Type, relation RelationInfo) (string, error) {
+	if relation.References != "" {
+		return relation.References, nil
+	}
+	referencedType := structType
+	if relation.Kind == RelationBelongsTo {
+		referencedType = relation.Type
+	}
+	pkColumns, err := refl.PrimaryKeyColumnsOfStruct(referencedType)
+	if err != nil {
+		return "", err
+	}
+	if len(pkColumns) != 1 {
+		return "", fmt.Errorf("relation %s of %s needs a ref option because %s has %d primary key columns", relation.Name, structType, referencedType, len(pkColumns))
+	}
+	return pkColumns[0], nil
+}
+
+// LoadRelations loads the related rows of the relations
+// with the passed names into the relation struct fields of rows.
+// S must be a struct or a pointer to a struct
+// and refl must implement [RelationReflector].
+// All relations of S are loaded if no names are passed.
+//
+// Relations are declared with the "rel" struct tag of [TaggedStructReflector]:
+//
+//	type Order struct {
+//		sqldb.TableName `db:"orders"`
+//		ID         int64       `db:"id,primarykey"`
+//		CustomerID int64       `db:"customer_id"`
+//		Items      []OrderItem `rel:"has_many,fk=order_id"`
+//		Customer   *Customer   `rel:"belongs_to,fk=customer_id"`
+//	}
+//
+// The related rows of all rows are queried with one query per relation
+// using [RelationQueryBuilder.QueryRowsWhereIn] with the distinct
+// non NULL key values, split into multiple queries if there are more
+// values than fmtr.MaxArgs().
+// Related rows that are soft deleted are not loaded
+// unless the context was returned by [ContextWithDeleted].
+//
+// Nested relations of the related rows are loaded
+// with dot separated names like "Items.Product".
+//
+// Has-many relation fields are set to a slice of the related rows
+// or nil if there are none. Has-one and belongs-to relation fields
+// are set to the related row or the zero value if there is none.
+// Multiple related rows of a has-one relation are an error.
+func LoadRelations[S any](ctx context.Context, conn Querier, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rows []S, names ...string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	structType := reflect.TypeFor[S]()
+	for structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("LoadRelations: expected struct or pointer to struct, but got %s", reflect.TypeFor[S]())
+	}
+	rowsVal := reflect.ValueOf(rows)
+	structVals := make([]reflect.Value, len(rows))
+	for i := range rows {
+		// Slice elements are addressable
+		structVal, err := derefStruct(rowsVal.Index(i))
+		if err != nil {
+			return fmt.Errorf("LoadRelations: row %d: %w", i, err)
+		}
+		structVals[i] = structVal
+	}
+	return loadRelations(ctx, conn, refl, builder, fmtr, structType, structVals, names)
+}
+
+// loadRelations loads the relations of paths into structVals
+// and recursively the nested relations of dot separated paths.
+func loadRelations(ctx context.Context, conn Querier, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, structType reflect.Type, structVals []reflect.Value, paths []string) error {
+	rr, err := relationReflector(refl)
+	if err != nil {
+		return fmt.Errorf("LoadRelations: %w", err)
+	}
+	rqb, ok := builder.(RelationQueryBuilder)
+	if !ok {
+		return fmt.Errorf("LoadRelations: QueryBuilder %T does not implement RelationQueryBuilder", builder)
+	}
+	relations, err := rr.ReflectStructRelations(structType)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		for _, relation := range relations {
+			paths = append(paths, relation.Name)
+		}
+	}
+
+	// Group nested paths by the name of their first relation
+	var (
+		names  []string
+		nested = make(map[string][]string)
+	)
+	for _, path := range paths {
+		name, rest, hasRest := strings.Cut(path, ".")
+		if _, ok := nested[name]; !ok {
+			names = append(names, name)
+			nested[name] = nil
+		}
+		if hasRest {
+			nested[name] = append(nested[name], rest)
+		}
+	}
+
+	for _, name := range names {
+		i := slices.IndexFunc(relations, func(relation RelationInfo) bool { return relation.Name == name })
+		if i < 0 {
+			return fmt.Errorf("LoadRelations: %s has no relation %s", structType, name)
+		}
+		related, err := loadRelation(ctx, conn, refl, rqb, fmtr, structType, structVals, relations[i])
+		if err != nil {
+			return fmt.Errorf("LoadRelations: can't load relation %s of %s: %w", name, structType, err)
+		}
+		if len(nested[name]) > 0 && len(related) > 0 {
+			err = loadRelations(ctx, conn, refl, builder, fmtr, relations[i].Type, related, nested[name])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadRelation loads the related rows of relation into the fields
+// of structVals and returns the distinct addressable related structs
+// that were assigned to the fields.
+func loadRelation(ctx context.Context, conn Querier, refl StructReflector, builder RelationQueryBuilder, fmtr QueryFormatter, structType reflect.Type, structVals []reflect.Value, relation RelationInfo) ([]reflect.Value, error) {
+	table, err := refl.TableNameForStruct(relation.Type)
+	if err != nil {
+		return nil, err
+	}
+	references, err := relationReferences(refl, structType, relation)
+	if err != nil {
+		return nil, err
+	}
+	// keyColumn of structType has the values of relatedColumn
+	keyColumn, relatedColumn := references, relation.ForeignKey
+	if relation.Kind == RelationBelongsTo {
+		keyColumn, relatedColumn = relation.ForeignKey, references
+	}
+
+	// Collect the distinct non NULL key values
+	var (
+		keys = make([]any, len(structVals))
+		args []any
+		seen = make(map[any]struct{})
+	)
+	for i, structVal := range structVals {
+		val, err := columnValue(refl, structVal, keyColumn)
+		if err != nil {
+			return nil, err
+		}
+		key, arg, err := relationKey(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of column %s: %w", keyColumn, err)
+		}
+		if key == nil {
+			continue
+		}
+		keys[i] = key
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			args = append(args, arg)
+		}
+	}
+
+	related := make(map[any][]reflect.Value)
+	if len(args) > 0 {
+		softDelete := ""
+		if !IsContextWithDeleted(ctx) {
+			softDelete, err = softDeleteColumn(refl, relation.Type)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for chunk := range slices.Chunk(args, max(fmtr.MaxArgs(), 1)) {
+			query, queryArgs, err := builder.QueryRowsWhereIn(fmtr, table, relatedColumn, chunk, softDelete)
+			if err != nil {
+				return nil, err
+			}
+			structPtrs, err := queryStructPtrs(ctx, conn, refl, fmtr, relation.Type, query, queryArgs)
+			if err != nil {
+				return nil, err
+			}
+			for _, structPtr := range structPtrs {
+				val, err := columnValue(refl, structPtr.Elem(), relatedColumn)
+				if err != nil {
+					return nil, err
+				}
+				key, _, err := relationKey(val)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value of column %s: %w", relatedColumn, err)
+				}
+				related[key] = append(related[key], structPtr)
+			}
+		}
+	}
+
+	var (
+		assigned     []reflect.Value
+		assignedPtrs = make(map[uintptr]struct{})
+		addAssigned  = func(structVal reflect.Value) {
+			if structVal.Kind() == reflect.Pointer {
+				if structVal.IsNil() {
+					return
+				}
+				structVal = structVal.Elem()
+			}
+			if _, ok := assignedPtrs[structVal.Addr().Pointer()]; !ok {
+				assignedPtrs[structVal.Addr().Pointer()] = struct{}{}
+				assigned = append(assigned, structVal)
+			}
+		}
+	)
+	for i, structVal := range structVals {
+		field := structVal.FieldByIndex(relation.FieldIndex)
+		matches := related[keys[i]]
+		if relation.Kind == RelationHasMany {
+			if len(matches) == 0 {
+				field.SetZero()
+				continue
+			}
+			slice := reflect.MakeSlice(field.Type(), len(matches), len(matches))
+			for j, match := range matches {
+				if slice.Index(j).Kind() == reflect.Pointer {
+					slice.Index(j).Set(match)
+				} else {
+					slice.Index(j).Set(match.Elem())
+				}
+				addAssigned(slice.Index(j))
+			}
+			field.Set(slice)
+			continue
+		}
+		switch {
+		case len(matches) == 0:
+			field.SetZero()
+			continue
+		case len(matches) > 1:
+			return nil, fmt.Errorf("%s relation got %d rows of table %s with %s = %v", relation.Kind, len(matches), table, relatedColumn, keys[i])
+		case field.Kind() == reflect.Pointer:
+			field.Set(matches[0])
+		default:
+			field.Set(matches[0].Elem())
+		}
+		addAssigned(field)
+	}
+	return assigned, nil
+}
+
+// columnValue returns the value of the struct field
+// of structVal that is mapped to column.
+func columnValue(refl StructReflector, structVal reflect.Value, column string) (any, error) {
+	vals, err := refl.ReflectStructValues(structVal, OnlyColumns(column))
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) != 1 {
+		return nil, fmt.Errorf("no struct field of %s mapped to column %s", structVal.Type(), column)
+	}
+	return vals[0], nil
+}
+
+// relationKey converts a key column value to a driver value used
+// as query argument and a comparable map key for matching related rows.
+// Returns nil for NULL values.
+func relationKey(val any) (key, arg any, err error) {
+	arg, err = driver.DefaultParameterConverter.ConvertValue(val)
+	if err != nil || arg == nil {
+		return nil, nil, err
+	}
+	switch a := arg.(type) {
+	case []byte:
+		return string(a), arg, nil
+	case time.Time:
+		// Compare the instant without location and monotonic clock
+		return a.UTC().Round(0), arg, nil
+	}
+	return arg, arg, nil
+}
+
+// queryStructPtrs queries rows and returns them as pointers
+// to newly allocated structs of structType.
+func queryStructPtrs(ctx context.Context, conn Querier, refl StructReflector, fmtr QueryFormatter, structType reflect.Type, query string, args []any) (structPtrs []reflect.Value, err error) {
+	rows := conn.Query(ctx, query, args...)
+	defer func() {
+		err = errors.Join(err, rows.Close())
+		if err != nil {
+			err = WrapErrorWithQuery(err, query, args, fmtr)
+		}
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		structPtr := reflect.New(structType)
+		err = scanStruct(rows, columns, refl, structPtr.Interface())
+		if err != nil {
+			return nil, err
+		}
+		structPtrs = append(structPtrs, structPtr)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return structPtrs, nil
+}
+
+// VerifyRelations checks that the relations of the struct type S
+// returned by refl, which must implement [RelationReflector],
+// match single column foreign key constraints returned by
+// [Information.ForeignKeys]: the constraint of the related table
+// for has-one and has-many relations and of the table of S
+// for belongs-to relations.
+// Returns all mismatching relations joined as one error.
+func VerifyRelations[S any](ctx context.Context, info Information, refl StructReflector) error {
+	rr, err := relationReflector(refl)
+	if err != nil {
+		return fmt.Errorf("VerifyRelations: %w", err)
+	}
+	structType := reflect.TypeFor[S]()
+	for structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	relations, err := rr.ReflectStructRelations(structType)
+	if err != nil {
+		return err
+	}
+	table, err := refl.TableNameForStruct(structType)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, relation := range relations {
+		relatedTable, err := refl.TableNameForStruct(relation.Type)
+		if err != nil {
+			return err
+		}
+		references, err := relationReferences(refl, structType, relation)
+		if err != nil {
+			return err
+		}
+		fkTable, referencedTable := relatedTable, table
+		if relation.Kind == RelationBelongsTo {
+			fkTable, referencedTable = table, relatedTable
+		}
+		foreignKeys, err := info.ForeignKeys(ctx, fkTable)
+		if err != nil {
+			return fmt.Errorf("VerifyRelations: can't get foreign keys of table %s: %w", fkTable, err)
+		}
+		found := slices.ContainsFunc(foreignKeys, func(fk ForeignKeyInfo) bool {
+			return len(fk.Columns) == 1 &&
+				len(fk.ReferencedColumns) == 1 &&
+				strings.EqualFold(fk.Columns[0], relation.ForeignKey) &&
+				strings.EqualFold(fk.ReferencedColumns[0], references) &&
+				equalTableNames(fk.ReferencedTable, referencedTable)
+		})
+		if !found {
+			errs = append(errs, fmt.Errorf("relation %s of %s has no foreign key %s(%s) referencing %s(%s)", relation.Name, structType, fkTable, relation.ForeignKey, referencedTable, references))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// equalTableNames compares table names case-insensitive
+// and ignores the schema if one of the names is not schema-qualified.
+func equalTableNames(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	schemaA, nameA, qualifiedA := strings.Cut(a, ".")
+	schemaB, nameB, qualifiedB := strings.Cut(b, ".")
+	switch {
+	case qualifiedA && !qualifiedB:
+		return strings.EqualFold(nameA, schemaB)
+	case !qualifiedA && qualifiedB:
+		return strings.EqualFold(schemaA, nameB)
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type relCustomer struct {
+	TableName `db:"customers"`
+
+	ID   int64  `db:"id,primarykey"`
+	Name string `db:"name"`
+}
+
+type relProduct struct {
+	TableName `db:"products"`
+
+	ID   int64  `db:"id,primarykey"`
+	Name string `db:"name"`
+}
+
+type relItem struct {
+	TableName `db:"order_items"`
+
+	ID        int64           `db:"id,primarykey"`
+	OrderID   int64           `db:"order_id"`
+	ProductID Nullable[int64] `db:"product_id"`
+	DeletedAt *time.Time      `db:"deleted_at,softdelete"`
+
+	Product *relProduct `rel:"belongs_to,fk=product_id"`
+}
+
+type relInvoice struct {
+	TableName `db:"invoices"`
+
+	ID      int64 `db:"id,primarykey"`
+	OrderID int64 `db:"order_id"`
+}
+
+type relOrder struct {
+	TableName `db:"orders"`
+
+	ID         int64  `db:"id,primarykey"`
+	CustomerID *int64 `db:"customer_id"`
+
+	Items    []relItem    `rel:"has_many,fk=order_id"`
+	Customer *relCustomer `rel:"belongs_to,fk=customer_id"`
+	Invoice  relInvoice   `rel:"has_one,fk=order_id,ref=id"`
+}
+
+// relTestConn returns a MockConn that returns the mock rows
+// of results for the queries used as map keys.
+func relTestConn(t *testing.T, results map[string]func() *MockRows) *MockConn {
+	t.Helper()
+	conn, _, _, _ := newTestInterfaces()
+	conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+		result, ok := results[query]
+		if !ok {
+			t.Errorf("unexpected query: %s", query)
+			return NewErrRows(fmt.Errorf("unexpected query: %s", query))
+		}
+		return result()
+	}
+	return conn
+}
+
+func TestReflectStructRelations(t *testing.T) {
+	t.Run("relations", func(t *testing.T) {
+		// when
+		relations, err := NewTaggedStructReflector().ReflectStructRelations(reflect.TypeFor[relOrder]())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []RelationInfo{
+			{Name: "Items", Kind: RelationHasMany, ForeignKey: "order_id", FieldIndex: []int{3}, Type: reflect.TypeFor[relItem]()},
+			{Name: "Customer", Kind: RelationBelongsTo, ForeignKey: "customer_id", FieldIndex: []int{4}, Type: reflect.TypeFor[relCustomer]()},
+			{Name: "Invoice", Kind: RelationHasOne, ForeignKey: "order_id", References: "id", FieldIndex: []int{5}, Type: reflect.TypeFor[relInvoice]()},
+		}, relations)
+	})
+
+	t.Run("embedded struct", func(t *testing.T) {
+		type embedding struct {
+			relOrder
+		}
+		relations, err := NewTaggedStructReflector().ReflectStructRelations(reflect.TypeFor[embedding]())
+		require.NoError(t, err)
+		require.Len(t, relations, 3)
+		assert.Equal(t, []int{0, 3}, relations[0].FieldIndex)
+	})
+
+	t.Run("relation fields are not columns", func(t *testing.T) {
+		columns, err := NewTaggedStructReflector().ReflectStructColumns(reflect.TypeFor[relOrder]())
+		require.NoError(t, err)
+		assert.Equal(t, []ColumnInfo{
+			{Name: "id", Type: "int64", PrimaryKey: true},
+			{Name: "customer_id", Type: "*int64"},
+		}, columns)
+	})
+
+	for _, scenario := range []struct {
+		name     string
+		relation any
+	}{
+		{name: "invalid kind", relation: struct {
+			Rel []relItem `rel:"many,fk=order_id"`
+		}{}},
+		{name: "missing fk", relation: struct {
+			Rel []relItem `rel:"has_many"`
+		}{}},
+		{name: "invalid option", relation: struct {
+			Rel []relItem `rel:"has_many,fk=order_id,on=x"`
+		}{}},
+		{name: "has_many not a slice", relation: struct {
+			Rel relItem `rel:"has_many,fk=order_id"`
+		}{}},
+		{name: "belongs_to not a struct", relation: struct {
+			Rel int64 `rel:"belongs_to,fk=order_id"`
+		}{}},
+	} {
+		t.Run(scenario.name, func(t *testing.T) {
+			_, err := NewTaggedStructReflector().ReflectStructRelations(reflect.TypeOf(scenario.relation))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoadRelations(t *testing.T) {
+	t.Run("has_many, belongs_to, and nested", func(t *testing.T) {
+		// given
+		conn := relTestConn(t, map[string]func() *MockRows{
+			"SELECT * FROM customers WHERE id IN ($1)": func() *MockRows {
+				return NewMockRows("id", "name").WithRow(int64(10), "Alice")
+			},
+			"SELECT * FROM order_items WHERE order_id IN ($1,$2,$3) AND deleted_at IS NULL": func() *MockRows {
+				return NewMockRows("id", "order_id", "product_id", "deleted_at").WithRows([][]driver.Value{
+					{int64(100), int64(1), int64(7), nil},
+					{int64(101), int64(1), nil, nil},
+					{int64(102), int64(2), int64(7), nil},
+				})
+			},
+			"SELECT * FROM products WHERE id IN ($1)": func() *MockRows {
+				return NewMockRows("id", "name").WithRow(int64(7), "Widget")
+			},
+		})
+		_, refl, builder, fmtr := newTestInterfaces()
+		customerID := int64(10)
+		orders := []relOrder{
+			{ID: 1, CustomerID: &customerID},
+			{ID: 2, CustomerID: &customerID},
+			{ID: 3},
+		}
+
+		// when
+		err := LoadRelations(t.Context(), conn, refl, builder, fmtr, orders, "Customer", "Items.Product")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, conn.Recordings.Queries, 3)
+		assert.Equal(t, []any{int64(10)}, conn.Recordings.Queries[0].Args)
+		assert.Equal(t, []any{int64(1), int64(2), int64(3)}, conn.Recordings.Queries[1].Args)
+		assert.Equal(t, []any{int64(7)}, conn.Recordings.Queries[2].Args)
+
+		require.NotNil(t, orders[0].Customer)
+		assert.Equal(t, "Alice", orders[0].Customer.Name)
+		assert.Same(t, orders[0].Customer, orders[1].Customer)
+		assert.Nil(t, orders[2].Customer)
+
+		require.Len(t, orders[0].Items, 2)
+		assert.Equal(t, int64(100), orders[0].Items[0].ID)
+		require.NotNil(t, orders[0].Items[0].Product)
+		assert.Equal(t, "Widget", orders[0].Items[0].Product.Name)
+		assert.Nil(t, orders[0].Items[1].Product)
+		require.Len(t, orders[1].Items, 1)
+		assert.Equal(t, int64(102), orders[1].Items[0].ID)
+		assert.Same(t, orders[0].Items[0].Product, orders[1].Items[0].Product)
+		assert.Nil(t, orders[2].Items)
+	})
+
+	t.Run("has_one into pointers", func(t *testing.T) {
+		// given
+		conn := relTestConn(t, map[string]func() *MockRows{
+			"SELECT * FROM invoices WHERE order_id IN ($1,$2)": func() *MockRows {
+				return NewMockRows("id", "order_id").WithRow(int64(50), int64(2))
+			},
+		})
+		_, refl, builder, fmtr := newTestInterfaces()
+		orders := []*relOrder{
+			{ID: 1, Invoice: relInvoice{ID: 1}},
+			{ID: 2},
+		}
+
+		// when
+		err := LoadRelations(t.Context(), conn, refl, builder, fmtr, orders, "Invoice")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, relInvoice{}, orders[0].Invoice, "previous value must be reset")
+		assert.Equal(t, relInvoice{ID: 50, OrderID: 2}, orders[1].Invoice)
+	})
+
+	t.Run("has_one with multiple rows", func(t *testing.T) {
+		conn := relTestConn(t, map[string]func() *MockRows{
+			"SELECT * FROM invoices WHERE order_id IN ($1)": func() *MockRows {
+				return NewMockRows("id", "order_id").WithRows([][]driver.Value{{int64(50), int64(1)}, {int64(51), int64(1)}})
+			},
+		})
+		_, refl, builder, fmtr := newTestInterfaces()
+		err := LoadRelations(t.Context(), conn, refl, builder, fmtr, []relOrder{{ID: 1}}, "Invoice")
+		require.Error(t, err)
+	})
+
+	t.Run("all relations", func(t *testing.T) {
+		// given
+		conn := relTestConn(t, map[string]func() *MockRows{
+			"SELECT * FROM order_items WHERE order_id IN ($1)": func() *MockRows { return NewMockRows("id") },
+			"SELECT * FROM invoices WHERE order_id IN ($1)":    func() *MockRows { return NewMockRows("id") },
+		})
+		_, refl, builder, fmtr := newTestInterfaces()
+		orders := []relOrder{{ID: 1}}
+
+		// when
+		err := LoadRelations(ContextWithDeleted(t.Context()), conn, refl, builder, fmtr, orders)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, conn.Recordings.Queries, 2, "no query for the NULL customer_id")
+		assert.Equal(t, "SELECT * FROM order_items WHERE order_id IN ($1)", conn.Recordings.Queries[0].Query)
+		assert.Equal(t, "SELECT * FROM invoices WHERE order_id IN ($1)", conn.Recordings.Queries[1].Query)
+	})
+
+	t.Run("batches MaxArgs", func(t *testing.T) {
+		// given
+		conn := relTestConn(t, map[string]func() *MockRows{
+			"SELECT * FROM invoices WHERE order_id IN ($1,$2)": func() *MockRows { return NewMockRows("id", "order_id") },
+			"SELECT * FROM invoices WHERE order_id IN ($1)":    func() *MockRows { return NewMockRows("id", "order_id") },
+		})
+		_, refl, builder, _ := newTestInterfaces()
+		fmtr := relTestFormatter{QueryFormatter: NewQueryFormatter("$"), maxArgs: 2}
+		orders := []relOrder{{ID: 1}, {ID: 2}, {ID: 3}}
+
+		// when
+		err := LoadRelations(t.Context(), conn, refl, builder, fmtr, orders, "Invoice")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, conn.Recordings.Queries, 2)
+		assert.Equal(t, []any{int64(1), int64(2)}, conn.Recordings.Queries[0].Args)
+		assert.Equal(t, []any{int64(3)}, conn.Recordings.Queries[1].Args)
+	})
+
+	t.Run("unknown relation", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := LoadRelations(t.Context(), conn, refl, builder, fmtr, []relOrder{{ID: 1}}, "Items.Unknown")
+		require.Error(t, err)
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := LoadRelations[relOrder](t.Context(), conn, refl, builder, fmtr, nil)
+		require.NoError(t, err)
+		assert.Empty(t, conn.Recordings.Queries)
+	})
+}
+
+type relTestFormatter struct {
+	QueryFormatter
+	maxArgs int
+}
+
+func (f relTestFormatter) MaxArgs() int { return f.maxArgs }
+
+func TestVerifyRelations(t *testing.T) {
+	foreignKeys := map[string][]ForeignKeyInfo{
+		"order_items": {{Name: "order_items_order_id_fkey", Columns: []string{"order_id"}, ReferencedTable: "public.orders", ReferencedColumns: []string{"id"}}},
+		"invoices":    {{Name: "invoices_order_id_fkey", Columns: []string{"order_id"}, ReferencedTable: "public.orders", ReferencedColumns: []string{"id"}}},
+		"orders":      {{Name: "orders_customer_id_fkey", Columns: []string{"customer_id"}, ReferencedTable: "public.customers", ReferencedColumns: []string{"id"}}},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		// given
+		conn, refl, _, _ := newTestInterfaces()
+		conn.MockForeignKeys = func(ctx context.Context, table string) ([]ForeignKeyInfo, error) {
+			return foreignKeys[table], nil
+		}
+
+		// when
+		err := VerifyRelations[relOrder](t.Context(), conn, refl)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("missing foreign key", func(t *testing.T) {
+		// given
+		conn, refl, _, _ := newTestInterfaces()
+		conn.MockForeignKeys = func(ctx context.Context, table string) ([]ForeignKeyInfo, error) {
+			if table == "invoices" {
+				return nil, nil
+			}
+			return foreignKeys[table], nil
+		}
+
+		// when
+		err := VerifyRelations[*relOrder](t.Context(), conn, refl)
+
+		// then
+		require.ErrorContains(t, err, "relation Invoice of sqldb.relOrder has no foreign key invoices(order_id) referencing orders(id)")
+	})
+}
@@ -0,0 +1,238 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type primaryCtxKey struct{}
+
+// ContextWithPrimary returns a new context that makes
+// connections created by [NewReadWriteSplitConn] send all
+// queries to the primary connection instead of a replica.
+// Use it to read your own writes that might not
+// have been replicated yet.
+func ContextWithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryCtxKey{}, struct{}{})
+}
+
+// IsContextWithPrimary returns true if the context
+// was returned by [ContextWithPrimary].
+func IsContextWithPrimary(ctx context.Context) bool {
+	return ctx.Value(primaryCtxKey{}) != nil
+}
+
+// ReplicaBalancing is the strategy of a [ReplicaRouter]
+// to choose a replica for a query.
+type ReplicaBalancing int
+
+const (
+	// RoundRobin chooses the healthy replicas in turn.
+	RoundRobin ReplicaBalancing = iota
+
+	// LeastBusy chooses the healthy replica with the least
+	// connections in use according to Connection.Stats.
+	LeastBusy
+)
+
+// ReplicaRouter routes read-only queries to a pool of replica connections.
+// Use [NewReadWriteSplitConn] to combine it with a primary connection.
+//
+// All replicas are considered healthy until a health check
+// with CheckHealth or MonitorHealth fails to Ping them.
+type ReplicaRouter struct {
+	replicas  []*replica
+	balancing ReplicaBalancing
+	next      atomic.Uint64
+}
+
+type replica struct {
+	conn    Connection
+	healthy atomic.Bool
+}
+
+// NewReplicaRouter returns a ReplicaRouter for the passed replicas
+// using the passed balancing strategy.
+func NewReplicaRouter(balancing ReplicaBalancing, replicas ...Connection) *ReplicaRouter {
+	r := &ReplicaRouter{
+		replicas:  make([]*replica, len(replicas)),
+		balancing: balancing,
+	}
+	for i, conn := range replicas {
+		r.replicas[i] = &replica{conn: conn}
+		r.replicas[i].healthy.Store(true)
+	}
+	return r
+}
+
+// NewReadWriteSplitConn returns a Connection that sends
+// read-only Query calls outside of transactions to a healthy replica
+// of the router and everything else to the primary connection:
+// Exec, ExecRowsAffected, Prepare, Begin, queries that write
+// like INSERT ... RETURNING, and all calls within transactions
+// and pinned connections.
+// A query is read-only if it is a single SELECT statement
+// without INTO or row locking clauses like FOR UPDATE or FOR SHARE.
+// Use [ContextWithPrimary] for SELECT statements
+// that call functions with side effects.
+// If all replicas are unhealthy or the context was
+// returned by [ContextWithPrimary], queries are sent to the primary.
+//
+// The returned connection is the primary wrapped with [WrapConnection]
+// and the interceptor returned by [ReplicaRouter.Interceptor],
+// use [ConnectionAs] to find optional interfaces of the primary.
+// Closing it closes only the primary, use [ReplicaRouter.Close]
+// to close the replicas.
+func NewReadWriteSplitConn(primary Connection, router *ReplicaRouter) Connection {
+	return WrapConnection(primary, router.Interceptor())
+}
+
+// Interceptor returns an [Interceptor] that routes queries of
+// the wrapped connection like described for [NewReadWriteSplitConn].
+// Use it with [WrapConnection] to combine it with other interceptors.
+// Interceptors before it in the chain see all queries,
+// interceptors after it only the ones sent to the primary.
+func (r *ReplicaRouter) Interceptor() Interceptor {
+	return Interceptor{
+		Query: func(ctx context.Context, conn Connection, query string, args []any, next QueryFunc) Rows {
+			if conn.Transaction().Active() || IsContextWithPrimary(ctx) || !isReadOnlyQuery(query) {
+				return next(ctx, query, args)
+			}
+			if _, pinned := conn.(PinnedConnection); pinned {
+				return next(ctx, query, args)
+			}
+			replica := r.Replica()
+			if replica == nil {
+				return next(ctx, query, args)
+			}
+			return replica.Query(ctx, query, args...)
+		},
+	}
+}
+
+// isReadOnlyQuery returns true if query is a single SELECT statement
+// without INTO or row locking clauses.
+// WITH queries are not considered read-only
+// because they can contain data modifying statements.
+func isReadOnlyQuery(query string) bool {
+	words := queryWords(query)
+	if len(words) == 0 || words[0] != "SELECT" {
+		return false
+	}
+	for i, word := range words {
+		var next string
+		if i+1 < len(words) {
+			next = words[i+1]
+		}
+		switch word {
+		case "INTO", "UPDLOCK", "XLOCK", "HOLDLOCK":
+			return false
+		case ";":
+			if next != "" {
+				return false
+			}
+		case "FOR":
+			// FOR UPDATE, FOR NO KEY UPDATE, FOR SHARE, FOR KEY SHARE
+			if next == "UPDATE" || next == "NO" || next == "SHARE" || next == "KEY" {
+				return false
+			}
+		case "LOCK":
+			// MySQL LOCK IN SHARE MODE
+			if next == "IN" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Replica returns a healthy replica chosen by the balancing
+// strategy of the router or nil if no replica is healthy.
+func (r *ReplicaRouter) Replica() Connection {
+	n := len(r.replicas)
+	if n == 0 {
+		return nil
+	}
+	start := int(r.next.Add(1) % uint64(n)) //#nosec G115 -- modulo len fits into int
+	var chosen *replica
+	for i := range n {
+		rep := r.replicas[(start+i)%n]
+		if !rep.healthy.Load() {
+			continue
+		}
+		if r.balancing == RoundRobin {
+			return rep.conn
+		}
+		if chosen == nil || rep.conn.Stats().InUse < chosen.conn.Stats().InUse {
+			chosen = rep
+		}
+	}
+	if chosen == nil {
+		return nil
+	}
+	return chosen.conn
+}
+
+// NumHealthy returns the number of healthy replicas.
+func (r *ReplicaRouter) NumHealthy() int {
+	count := 0
+	for _, rep := range r.replicas {
+		if rep.healthy.Load() {
+			count++
+		}
+	}
+	return count
+}
+
+// CheckHealth pings all replicas concurrently with the passed timeout
+// and marks them as healthy or unhealthy depending on the result.
+// It returns the joined errors of all failed pings.
+// Replicas keep their health state if ctx is canceled
+// before their ping finished, because the failed ping
+// says nothing about the replica.
+func (r *ReplicaRouter) CheckHealth(ctx context.Context, timeout time.Duration) error {
+	errs := make([]error, len(r.replicas))
+	var wg sync.WaitGroup
+	for i, rep := range r.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = rep.conn.Ping(ctx, timeout)
+			if errs[i] != nil && ctx.Err() != nil {
+				return
+			}
+			rep.healthy.Store(errs[i] == nil)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// MonitorHealth calls CheckHealth every interval until ctx is canceled.
+// The first check is done immediately.
+// MonitorHealth blocks, so run it in its own goroutine.
+func (r *ReplicaRouter) MonitorHealth(ctx context.Context, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_ = r.CheckHealth(ctx, timeout)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close closes all replica connections.
+func (r *ReplicaRouter) Close() error {
+	var errs []error
+	for _, rep := range r.replicas {
+		errs = append(errs, rep.conn.Close())
+	}
+	return errors.Join(errs...)
+}
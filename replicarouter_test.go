@@ -0,0 +1,188 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReplicaTestConn(value string) *MockConn {
+	conn := NewMockConn(NewQueryFormatter("$"))
+	conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+		return NewMockRows("v").WithRow(value)
+	}
+	return conn
+}
+
+func queryValue(t *testing.T, ctx context.Context, conn Connection) string {
+	t.Helper()
+	var v string
+	require.NoError(t, QueryRow(ctx, conn, NewTaggedStructReflector(), conn, "SELECT v").Scan(&v))
+	return v
+}
+
+func TestReadWriteSplitConn_RoundRobin(t *testing.T) {
+	// given
+	primary := newReplicaTestConn("primary")
+	router := NewReplicaRouter(RoundRobin, newReplicaTestConn("r1"), newReplicaTestConn("r2"))
+	conn := NewReadWriteSplitConn(primary, router)
+
+	// when
+	got := []string{
+		queryValue(t, t.Context(), conn),
+		queryValue(t, t.Context(), conn),
+		queryValue(t, t.Context(), conn),
+	}
+
+	// then
+	assert.ElementsMatch(t, []string{"r1", "r2"}, got[:2])
+	assert.NotEqual(t, got[0], got[1])
+	assert.Equal(t, got[0], got[2])
+}
+
+func TestReadWriteSplitConn_Primary(t *testing.T) {
+	// given
+	primary := newReplicaTestConn("primary")
+	replica := newReplicaTestConn("replica")
+	conn := NewReadWriteSplitConn(primary, NewReplicaRouter(RoundRobin, replica))
+
+	t.Run("Exec", func(t *testing.T) {
+		require.NoError(t, conn.Exec(t.Context(), "DELETE FROM t"))
+		assert.Len(t, primary.Recordings.Execs, 1)
+		assert.Empty(t, replica.Recordings.Execs)
+	})
+
+	t.Run("ContextWithPrimary", func(t *testing.T) {
+		assert.Equal(t, "primary", queryValue(t, ContextWithPrimary(t.Context()), conn))
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		err := Transaction(t.Context(), conn, nil, func(tx Connection) error {
+			assert.Equal(t, "primary", queryValue(t, t.Context(), tx))
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("INSERT RETURNING", func(t *testing.T) {
+		var v string
+		err := InsertReturning(t.Context(), conn, NewTaggedStructReflector(), StdReturningQueryBuilder{}, conn, "t", Values{"a": 1}, "v").Scan(&v)
+		require.NoError(t, err)
+		assert.Equal(t, "primary", v)
+	})
+
+	t.Run("SELECT FOR UPDATE", func(t *testing.T) {
+		var v string
+		require.NoError(t, QueryRow(t.Context(), conn, NewTaggedStructReflector(), conn, "SELECT v FROM t FOR UPDATE").Scan(&v))
+		assert.Equal(t, "primary", v)
+	})
+
+	t.Run("outside transaction", func(t *testing.T) {
+		assert.Equal(t, "replica", queryValue(t, t.Context(), conn))
+	})
+}
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "SELECT v", want: true},
+		{query: "  /* comment */ select * from t where a = 'FOR UPDATE';", want: true},
+		{query: "SELECT * FROM t -- INSERT\n", want: true},
+		{query: "(SELECT 1) UNION (SELECT 2)", want: true},
+		{query: "SELECT * FROM t FOR JSON AUTO", want: true},
+		{query: "SELECT * FROM t FOR UPDATE", want: false},
+		{query: "SELECT * FROM t FOR NO KEY UPDATE", want: false},
+		{query: "SELECT * FROM t FOR SHARE", want: false},
+		{query: "SELECT * FROM t FOR KEY SHARE", want: false},
+		{query: "SELECT * FROM t LOCK IN SHARE MODE", want: false},
+		{query: "SELECT * FROM t WITH (UPDLOCK)", want: false},
+		{query: "SELECT * INTO t2 FROM t", want: false},
+		{query: "SELECT 1; DELETE FROM t", want: false},
+		{query: "INSERT INTO t(a) VALUES($1) RETURNING id", want: false},
+		{query: "UPDATE t SET a=$1 RETURNING id", want: false},
+		{query: "DELETE FROM t RETURNING id", want: false},
+		{query: "WITH d AS (DELETE FROM t RETURNING id) SELECT * FROM d", want: false},
+		{query: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, isReadOnlyQuery(tt.query))
+		})
+	}
+}
+
+func TestReadWriteSplitConn_LeastBusy(t *testing.T) {
+	// given
+	busy := newReplicaTestConn("busy")
+	busy.MockStats = func() sql.DBStats { return sql.DBStats{InUse: 5} }
+	idle := newReplicaTestConn("idle")
+	idle.MockStats = func() sql.DBStats { return sql.DBStats{InUse: 1} }
+	conn := NewReadWriteSplitConn(newReplicaTestConn("primary"), NewReplicaRouter(LeastBusy, busy, idle))
+
+	// then
+	for range 3 {
+		assert.Equal(t, "idle", queryValue(t, t.Context(), conn))
+	}
+}
+
+func TestReplicaRouter_CheckHealth(t *testing.T) {
+	// given
+	errDown := errors.New("replica down")
+	r1 := newReplicaTestConn("r1")
+	r2 := newReplicaTestConn("r2")
+	router := NewReplicaRouter(RoundRobin, r1, r2)
+	conn := NewReadWriteSplitConn(newReplicaTestConn("primary"), router)
+
+	// when one replica is down
+	r1.MockPing = func(context.Context, time.Duration) error { return errDown }
+	err := router.CheckHealth(t.Context(), time.Second)
+
+	// then
+	require.ErrorIs(t, err, errDown)
+	assert.Equal(t, 1, router.NumHealthy())
+	assert.Equal(t, "r2", queryValue(t, t.Context(), conn))
+	assert.Equal(t, "r2", queryValue(t, t.Context(), conn))
+
+	// when all replicas are down
+	r2.MockPing = func(context.Context, time.Duration) error { return errDown }
+	require.Error(t, router.CheckHealth(t.Context(), time.Second))
+
+	// then fall back to primary
+	assert.Zero(t, router.NumHealthy())
+	assert.Equal(t, "primary", queryValue(t, t.Context(), conn))
+
+	// when replicas recover
+	r1.MockPing = nil
+	r2.MockPing = nil
+	require.NoError(t, router.CheckHealth(t.Context(), time.Second))
+
+	// then
+	assert.Equal(t, 2, router.NumHealthy())
+
+	// when the context is canceled
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	r1.MockPing = func(ctx context.Context, _ time.Duration) error { return ctx.Err() }
+	require.Error(t, router.CheckHealth(ctx, time.Second))
+
+	// then the health is unchanged
+	assert.Equal(t, 2, router.NumHealthy())
+}
+
+func TestReplicaRouter_Close(t *testing.T) {
+	var closed int
+	r1 := newReplicaTestConn("r1")
+	r1.MockClose = func() error { closed++; return nil }
+	r2 := newReplicaTestConn("r2")
+	r2.MockClose = func() error { closed++; return nil }
+
+	require.NoError(t, NewReplicaRouter(RoundRobin, r1, r2).Close())
+	assert.Equal(t, 2, closed)
+}
@@ -0,0 +1,131 @@
+package sqldb
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// onlyPrimaryKeyAndVersion is an IgnoreColumnFunc that ignores
+// all columns except primary key and version columns.
+var onlyPrimaryKeyAndVersion = IgnoreColumnFunc(func(column *ColumnInfo) bool {
+	return !column.PrimaryKey && !column.Version
+})
+
+// versionColumnIndex returns the index of the column tagged as version
+// for optimistic locking or -1 if there is none.
+func versionColumnIndex(table string, columns []ColumnInfo) (int, error) {
+	index := -1
+	for i, col := range columns {
+		if !col.Version {
+			continue
+		}
+		if col.PrimaryKey {
+			return -1, fmt.Errorf("version column %s of table %s can't be a primary key column", col.Name, table)
+		}
+		if index >= 0 {
+			return -1, fmt.Errorf("table %s has multiple version columns: %s and %s", table, columns[index].Name, col.Name)
+		}
+		index = i
+	}
+	return index, nil
+}
+
+// versionCondition returns columns with an additional primary key column
+// for the version column at versionIndex, so that the query builder
+// adds it to the WHERE clause of an UPDATE query for optimistic locking
+// with the current version as value of the additional column
+// and the incremented version as value of the version column.
+func versionCondition(columns []ColumnInfo, versionIndex int) []ColumnInfo {
+	return append(slices.Clip(columns), ColumnInfo{Name: columns[versionIndex].Name, PrimaryKey: true})
+}
+
+// versionIndexForUpdate returns the index of the value of the version column
+// at versionIndex within the values reordered by reorderForUpdate
+// or -1 if versionIndex is -1.
+func versionIndexForUpdate(columns []ColumnInfo, versionIndex int) int {
+	if versionIndex < 0 {
+		return -1
+	}
+	index := 0
+	for _, col := range columns[:versionIndex] {
+		if !col.PrimaryKey {
+			index++
+		}
+	}
+	return index
+}
+
+// structFieldIndex returns the index of the struct field
+// mapped to column for reflect.Value.FieldByIndex.
+func structFieldIndex(refl StructReflector, structType reflect.Type, column string) ([]int, error) {
+	rs, err := reflectStruct(refl, structType)
+	if err != nil {
+		return nil, err
+	}
+	i, ok := rs.ColumnIndex[column]
+	if !ok {
+		return nil, fmt.Errorf("no struct field of %s mapped to column %s", structType, column)
+	}
+	return rs.Fields[i].FieldIndex, nil
+}
+
+// rowVersion is the version field of a struct for optimistic locking.
+type rowVersion struct {
+	field reflect.Value // addressable struct field
+	prev  reflect.Value // initial value of field
+	next  reflect.Value // incremented value of field
+}
+
+// newRowVersion returns the version field of structVal at fieldIndex
+// with its incremented value.
+// structVal must be addressable to write back the incremented version.
+func newRowVersion(structVal reflect.Value, fieldIndex []int) (rowVersion, error) {
+	field, err := structVal.FieldByIndexErr(fieldIndex)
+	if err != nil {
+		return rowVersion{}, fmt.Errorf("version field of %s is in a nil inline struct: %w", structVal.Type(), err)
+	}
+	if !field.CanSet() {
+		return rowVersion{}, fmt.Errorf("optimistic locking of %s requires a pointer to the struct to update its version field", structVal.Type())
+	}
+	prev := reflect.New(field.Type()).Elem()
+	prev.Set(field)
+	next := reflect.New(field.Type()).Elem()
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		next.SetInt(field.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		next.SetUint(field.Uint() + 1)
+	default:
+		return rowVersion{}, fmt.Errorf("version field of %s must be an integer, but is %s", structVal.Type(), field.Type())
+	}
+	return rowVersion{field: field, prev: prev, next: next}, nil
+}
+
+// reset sets the version field back to its initial value.
+func (v rowVersion) reset() {
+	v.field.Set(v.prev)
+}
+
+// execVersioned calls execRowsAffected for a query of a struct with a version field
+// and sets the version field to its incremented value if a row was affected,
+// else returns ErrStaleRow wrapped with the query.
+func execVersioned(refl StructReflector, fmtr QueryFormatter, structVal reflect.Value, version rowVersion, query string, vals []any, execRowsAffected func() (int64, error)) error {
+	n, err := execRowsAffected()
+	if err != nil {
+		return WrapErrorWithQuery(err, query, vals, fmtr)
+	}
+	if n == 0 {
+		return WrapErrorWithQuery(newErrStaleRow(refl, structVal), query, vals, fmtr)
+	}
+	if version.field.IsValid() {
+		version.field.Set(version.next)
+	}
+	return nil
+}
+
+func newErrStaleRow(refl StructReflector, structVal reflect.Value) ErrStaleRow {
+	table, _ := refl.TableNameForStruct(structVal.Type())
+	pk, _ := refl.ReflectStructValues(structVal, OnlyPrimaryKey)
+	return ErrStaleRow{Table: table, PrimaryKey: pk}
+}
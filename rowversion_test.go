@@ -0,0 +1,270 @@
+package sqldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionTestStruct struct {
+	TableName `db:"versioned_table"`
+
+	ID      int64  `db:"id,primarykey"`
+	Name    string `db:"name"`
+	Version int32  `db:"version,version"`
+}
+
+// rowsAffected returns a MockExecRowsAffected function
+// that returns n rows affected for every query.
+func rowsAffected(n int64) func(ctx context.Context, query string, args ...any) (int64, error) {
+	return func(ctx context.Context, query string, args ...any) (int64, error) {
+		return n, nil
+	}
+}
+
+func TestUpdateRowStruct_Version(t *testing.T) {
+	wantQuery := "UPDATE versioned_table SET name=$1, version=$2 WHERE id = $3 AND version = $4"
+
+	t.Run("increments version", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(1)
+		row := &versionTestStruct{ID: 1, Name: "Alice", Version: 3}
+
+		// when
+		err := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, row)
+		// Second update uses the cached query
+		err2 := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, row)
+
+		// then
+		require.NoError(t, err)
+		require.NoError(t, err2)
+		assert.Equal(t, int32(5), row.Version)
+		require.Len(t, conn.Recordings.Execs, 2)
+		assert.Equal(t, wantQuery, conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{"Alice", int32(4), int64(1), int32(3)}, conn.Recordings.Execs[0].Args)
+		assert.Equal(t, wantQuery, conn.Recordings.Execs[1].Query)
+		assert.Equal(t, []any{"Alice", int32(5), int64(1), int32(4)}, conn.Recordings.Execs[1].Args)
+	})
+
+	t.Run("stale row", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(0)
+		row := &versionTestStruct{ID: 7, Name: "Alice", Version: 3}
+
+		// when
+		err := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, row)
+
+		// then
+		var staleErr ErrStaleRow
+		require.ErrorAs(t, err, &staleErr)
+		assert.Equal(t, ErrStaleRow{Table: "versioned_table", PrimaryKey: []any{int64(7)}}, staleErr)
+		assert.Equal(t, int32(3), row.Version, "version must not change")
+	})
+
+	t.Run("non-pointer", func(t *testing.T) {
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, versionTestStruct{ID: 1})
+		require.Error(t, err)
+		assert.Empty(t, conn.Recordings.Execs)
+	})
+
+	t.Run("non-integer version", func(t *testing.T) {
+		type stringVersion struct {
+			TableName `db:"versioned_table"`
+
+			ID      int64  `db:"id,primarykey"`
+			Version string `db:"version,version"`
+		}
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, &stringVersion{ID: 1})
+		require.Error(t, err)
+	})
+
+	t.Run("primary key version", func(t *testing.T) {
+		type pkVersion struct {
+			TableName `db:"versioned_table"`
+
+			ID   int64  `db:"id,primarykey,version"`
+			Name string `db:"name"`
+		}
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := UpdateRowStruct(t.Context(), conn, refl, builder, fmtr, &pkVersion{ID: 1})
+		require.Error(t, err)
+	})
+}
+
+func TestUpdateRowStructs_Version(t *testing.T) {
+	t.Run("increments versions of slice elements", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(1)
+		rows := []versionTestStruct{
+			{ID: 1, Name: "Alice", Version: 1},
+			{ID: 2, Name: "Bob", Version: 5},
+		}
+
+		// when
+		err := UpdateRowStructs(t.Context(), conn, refl, builder, fmtr, rows)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), rows[0].Version)
+		assert.Equal(t, int32(6), rows[1].Version)
+	})
+
+	t.Run("stale row resets versions", func(t *testing.T) {
+		// given
+		log := new(bytes.Buffer)
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn = conn.WithQueryLog(log)
+		conn.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+			if args[2] == int64(2) {
+				return 0, nil
+			}
+			return 1, nil
+		}
+		rows := []*versionTestStruct{
+			{ID: 1, Name: "Alice", Version: 1},
+			{ID: 2, Name: "Bob", Version: 5},
+		}
+
+		// when
+		err := UpdateRowStructs(t.Context(), conn, refl, builder, fmtr, rows)
+
+		// then
+		require.ErrorAs(t, err, new(ErrStaleRow))
+		assert.Equal(t, int32(1), rows[0].Version)
+		assert.Equal(t, int32(5), rows[1].Version)
+		assert.Contains(t, log.String(), "ROLLBACK;\n")
+	})
+}
+
+func TestUpsertRowStruct_Version(t *testing.T) {
+	wantQuery := "INSERT INTO versioned_table(id,name,version) VALUES($1,$2,$3) ON CONFLICT(id) DO UPDATE SET name=$2, version=$3 WHERE versioned_table.version = excluded.version - 1"
+
+	t.Run("increments version", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		builder := testUpsertOptionsBuilder{}
+		conn.MockExecRowsAffected = rowsAffected(1)
+		row := &versionTestStruct{ID: 1, Name: "Alice", Version: 3}
+
+		// when
+		err := UpsertRowStruct(t.Context(), conn, refl, builder, fmtr, row)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), row.Version)
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, wantQuery, conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{int64(1), "Alice", int32(4)}, conn.Recordings.Execs[0].Args)
+	})
+
+	t.Run("with UpsertOptions", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		builder := testUpsertOptionsBuilder{}
+		conn.MockExecRowsAffected = rowsAffected(1)
+		row := &versionTestStruct{ID: 1, Name: "Alice", Version: 3}
+
+		// when
+		err := UpsertRowStruct(t.Context(), conn, refl, builder, fmtr, row, UpsertOptions{UpdateColumns: []string{"name"}, Where: "versioned_table.name <> excluded.name"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), row.Version)
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, "INSERT INTO versioned_table(id,name,version) VALUES($1,$2,$3) ON CONFLICT(id) DO UPDATE SET name=$2, version=$3 WHERE (versioned_table.name <> excluded.name) AND versioned_table.version = excluded.version - 1", conn.Recordings.Execs[0].Query)
+	})
+
+	t.Run("stale row", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		builder := testUpsertOptionsBuilder{}
+		conn.MockExecRowsAffected = rowsAffected(0)
+		row := &versionTestStruct{ID: 1, Name: "Alice", Version: 3}
+
+		// when
+		err := UpsertRowStruct(t.Context(), conn, refl, builder, fmtr, row)
+
+		// then
+		require.ErrorAs(t, err, new(ErrStaleRow))
+		assert.Equal(t, int32(3), row.Version)
+	})
+
+	t.Run("UpsertRowStructs", func(t *testing.T) {
+		// given
+		conn, refl, _, fmtr := newTestInterfaces()
+		builder := testUpsertOptionsBuilder{}
+		conn.MockExecRowsAffected = rowsAffected(1)
+		rows := []versionTestStruct{
+			{ID: 1, Name: "Alice", Version: 0},
+			{ID: 2, Name: "Bob", Version: 5},
+		}
+
+		// when
+		err := UpsertRowStructs(t.Context(), conn, refl, builder, fmtr, rows)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), rows[0].Version)
+		assert.Equal(t, int32(6), rows[1].Version)
+	})
+}
+
+func TestDeleteRowStruct_Version(t *testing.T) {
+	t.Run("deletes current version", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(1)
+		row := versionTestStruct{ID: 1, Name: "Alice", Version: 3}
+
+		// when
+		err := DeleteRowStruct(t.Context(), conn, refl, builder, fmtr, row)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, "DELETE FROM versioned_table WHERE id = $1 AND version = $2", conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{int64(1), int32(3)}, conn.Recordings.Execs[0].Args)
+	})
+
+	t.Run("stale row", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(0)
+		row := versionTestStruct{ID: 1, Name: "Alice", Version: 3}
+
+		// when
+		err := DeleteRowStruct(t.Context(), conn, refl, builder, fmtr, row)
+		// Second delete uses the cached query
+		err2 := DeleteRowStruct(t.Context(), conn, refl, builder, fmtr, row)
+
+		// then
+		require.ErrorAs(t, err, new(ErrStaleRow))
+		require.ErrorAs(t, err2, new(ErrStaleRow))
+		assert.False(t, errors.Is(err, sql.ErrNoRows))
+	})
+
+	t.Run("DeleteRowStructStmt", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(0)
+		deleteFunc, closeStmt, err := DeleteRowStructStmt[versionTestStruct](t.Context(), conn, refl, builder, fmtr)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, closeStmt()) })
+
+		// when
+		err = deleteFunc(t.Context(), versionTestStruct{ID: 1, Version: 3})
+
+		// then
+		require.ErrorAs(t, err, new(ErrStaleRow))
+	})
+}
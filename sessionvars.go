@@ -0,0 +1,200 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+)
+
+type sessionVarsCtxKey struct{}
+
+// ContextWithSessionVars returns a new context with the passed
+// session variables that are set for every transaction
+// begun with [Transaction] or [IsolatedTransaction] using the context,
+// for example to pass the tenant ID for row-level security policies.
+//
+// The variables are merged with variables of the parent context,
+// where the passed vars overwrite variables with the same name.
+//
+// The variables are set with transaction scope using the
+// [SessionVarsSetter] implementation of the transaction connection,
+// see [SessionVarsInterceptor] for statements outside of transactions.
+// Beginning a transaction with session variables in the context fails
+// with an error wrapping [errors.ErrUnsupported] if the transaction
+// connection does not implement SessionVarsSetter,
+// so that row-level security policies are never silently bypassed.
+// Variables added to the context of an already running transaction
+// are only set for newly begun transactions.
+func ContextWithSessionVars(ctx context.Context, vars map[string]string) context.Context {
+	if len(vars) == 0 {
+		return ctx
+	}
+	merged := maps.Clone(SessionVarsFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(vars))
+	}
+	maps.Copy(merged, vars)
+	return context.WithValue(ctx, sessionVarsCtxKey{}, merged)
+}
+
+// SessionVarsFromContext returns the session variables added
+// to the context with [ContextWithSessionVars] or nil.
+// The returned map must not be modified.
+func SessionVarsFromContext(ctx context.Context) map[string]string {
+	vars, _ := ctx.Value(sessionVarsCtxKey{}).(map[string]string)
+	return vars
+}
+
+// SessionVarsSetter is implemented by transaction connections
+// that can set session variables scoped to the transaction.
+type SessionVarsSetter interface {
+	// SetSessionVars sets the passed session variables
+	// for the rest of the transaction.
+	//
+	// Databases without transaction scoped variables
+	// have to restore the previous values with the returned
+	// reset function that must be called before the transaction
+	// is committed or rolled back so that the values don't leak
+	// to other users of the same pooled connection.
+	SetSessionVars(ctx context.Context, vars map[string]string) (reset func(context.Context) error, err error)
+}
+
+// SetSessionVars sets the passed session variables for the transaction tx
+// using the [SessionVarsSetter] implementation of tx or of a connection
+// wrapped by tx, and returns the function to reset the variables
+// before the transaction ends.
+//
+// An error wrapping [errors.ErrUnsupported] is returned
+// if the connection does not implement SessionVarsSetter
+// and an error wrapping [ErrNotWithinTransaction]
+// if tx is not a transaction.
+func SetSessionVars(ctx context.Context, tx Connection, vars map[string]string) (reset func(context.Context) error, err error) {
+	if len(vars) == 0 {
+		return resetNoSessionVars, nil
+	}
+	if !tx.Transaction().Active() {
+		return nil, fmt.Errorf("can't set session variables: %w", ErrNotWithinTransaction)
+	}
+	setter, ok := ConnectionAs[SessionVarsSetter](tx)
+	if !ok {
+		return nil, fmt.Errorf("%w: session variables with %T", errors.ErrUnsupported, tx)
+	}
+	reset, err = setter.SetSessionVars(ctx, vars)
+	if err != nil {
+		return nil, err
+	}
+	if reset == nil {
+		reset = resetNoSessionVars
+	}
+	return reset, nil
+}
+
+func resetNoSessionVars(context.Context) error { return nil }
+
+// SessionVarsInterceptor returns an [Interceptor] that executes
+// statements outside of transactions that have session variables
+// in their context added with [ContextWithSessionVars]
+// within a short implicit transaction that sets the variables,
+// so that they are never visible to other users of the connection pool.
+// The implicit transaction of a Query is committed
+// when the rows are closed or iterated to the end.
+//
+// Statements without session variables in their context
+// and statements within transactions are executed unchanged.
+//
+// Use it as the outermost interceptor because the statement
+// is executed within the implicit transaction that is wrapped
+// with all interceptors of the connection.
+// The db package applies it automatically to the connection
+// returned by db.Conn for contexts with session variables.
+func SessionVarsInterceptor() Interceptor {
+	return Interceptor{
+		Exec: func(ctx context.Context, conn Connection, query string, args []any, next ExecFunc) error {
+			if len(SessionVarsFromContext(ctx)) == 0 || conn.Transaction().Active() {
+				return next(ctx, query, args)
+			}
+			return IsolatedTransaction(ctx, conn, nil, func(tx Connection) error {
+				return tx.Exec(ctx, query, args...)
+			})
+		},
+		ExecRowsAffected: func(ctx context.Context, conn Connection, query string, args []any, next ExecRowsAffectedFunc) (n int64, err error) {
+			if len(SessionVarsFromContext(ctx)) == 0 || conn.Transaction().Active() {
+				return next(ctx, query, args)
+			}
+			err = IsolatedTransaction(ctx, conn, nil, func(tx Connection) error {
+				n, err = tx.ExecRowsAffected(ctx, query, args...)
+				return err
+			})
+			return n, err
+		},
+		Query: func(ctx context.Context, conn Connection, query string, args []any, next QueryFunc) Rows {
+			if len(SessionVarsFromContext(ctx)) == 0 || conn.Transaction().Active() {
+				return next(ctx, query, args)
+			}
+			id := NextTransactionID()
+			tx, err := conn.Begin(ctx, id, nil)
+			if err != nil {
+				return NewErrRows(fmt.Errorf("transaction %d BEGIN error: %w", id, err))
+			}
+			reset, err := setSessionVarsFromContext(ctx, tx)
+			if err != nil {
+				return NewErrRows(errors.Join(err, tx.Rollback()))
+			}
+			rows := tx.Query(ctx, query, args...)
+			if err := rows.Err(); err != nil {
+				_ = rows.Close()
+				return NewErrRows(errors.Join(err, reset(ctx), tx.Rollback()))
+			}
+			return &sessionVarsRows{Rows: rows, ctx: ctx, tx: tx, reset: reset}
+		},
+	}
+}
+
+// setSessionVarsFromContext sets the session variables from ctx for tx.
+func setSessionVarsFromContext(ctx context.Context, tx Connection) (reset func(context.Context) error, err error) {
+	return SetSessionVars(ctx, tx, SessionVarsFromContext(ctx))
+}
+
+// sessionVarsRows ends the implicit transaction of a query
+// when the rows are closed or iterated to the end.
+type sessionVarsRows struct {
+	Rows
+	ctx    context.Context
+	tx     Connection
+	reset  func(context.Context) error
+	done   bool
+	endErr error
+}
+
+func (r *sessionVarsRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.end()
+	return false
+}
+
+func (r *sessionVarsRows) Err() error {
+	return errors.Join(r.Rows.Err(), r.endErr)
+}
+
+func (r *sessionVarsRows) Close() error {
+	r.end()
+	return r.endErr
+}
+
+func (r *sessionVarsRows) end() {
+	if r.done {
+		return
+	}
+	r.done = true
+	// Rows must be closed before the transaction can end
+	closeErr := r.Rows.Close()
+	resetErr := r.reset(r.ctx)
+	if closeErr != nil || resetErr != nil || r.Rows.Err() != nil {
+		r.endErr = errors.Join(closeErr, resetErr, r.tx.Rollback())
+		return
+	}
+	r.endErr = r.tx.Commit()
+}
@@ -0,0 +1,310 @@
+package sqldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSessionVarsTestConn returns a MockConn that logs
+// the setting and resetting of session variables to its QueryLog.
+func newSessionVarsTestConn() (*MockConn, *bytes.Buffer) {
+	log := new(bytes.Buffer)
+	conn := NewMockConn(nil).WithQueryLog(log)
+	conn.MockSetSessionVars = func(ctx context.Context, vars map[string]string) (func(context.Context) error, error) {
+		var pairs []string
+		for _, name := range slices.Sorted(maps.Keys(vars)) {
+			pairs = append(pairs, name+"="+vars[name])
+		}
+		fmt.Fprintf(log, "SET %s;\n", strings.Join(pairs, ", "))
+		return func(context.Context) error {
+			fmt.Fprint(log, "RESET;\n")
+			return nil
+		}, nil
+	}
+	return conn, log
+}
+
+func TestContextWithSessionVars(t *testing.T) {
+	t.Run("no vars", func(t *testing.T) {
+		ctx := t.Context()
+		assert.Equal(t, ctx, ContextWithSessionVars(ctx, nil))
+		assert.Nil(t, SessionVarsFromContext(ctx))
+	})
+
+	t.Run("merges with parent", func(t *testing.T) {
+		// given
+		parentVars := map[string]string{"app.tenant_id": "1", "app.user_id": "2"}
+		parent := ContextWithSessionVars(t.Context(), parentVars)
+
+		// when
+		ctx := ContextWithSessionVars(parent, map[string]string{"app.user_id": "3", "app.role": "admin"})
+
+		// then
+		assert.Equal(t, map[string]string{"app.tenant_id": "1", "app.user_id": "3", "app.role": "admin"}, SessionVarsFromContext(ctx))
+		assert.Equal(t, map[string]string{"app.tenant_id": "1", "app.user_id": "2"}, SessionVarsFromContext(parent))
+		assert.Equal(t, map[string]string{"app.tenant_id": "1", "app.user_id": "2"}, parentVars, "passed map not modified")
+	})
+}
+
+func TestSetSessionVars(t *testing.T) {
+	vars := map[string]string{"app.tenant_id": "1"}
+
+	t.Run("no vars", func(t *testing.T) {
+		reset, err := SetSessionVars(t.Context(), new(MockConn), nil)
+		require.NoError(t, err)
+		require.NoError(t, reset(t.Context()))
+	})
+
+	t.Run("not within transaction", func(t *testing.T) {
+		_, err := SetSessionVars(t.Context(), new(MockConn), vars)
+		assert.ErrorIs(t, err, ErrNotWithinTransaction)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		tx := struct{ Connection }{&MockConn{TxID: 1}}
+		_, err := SetSessionVars(t.Context(), tx, vars)
+		assert.ErrorIs(t, err, errors.ErrUnsupported)
+	})
+
+	t.Run("unwraps intercepted connection", func(t *testing.T) {
+		// given
+		conn, log := newSessionVarsTestConn()
+		conn.TxID = 1
+		tx := WrapConnection(conn, Interceptor{})
+
+		// when
+		reset, err := SetSessionVars(t.Context(), tx, vars)
+
+		// then
+		require.NoError(t, err)
+		require.NoError(t, reset(t.Context()))
+		assert.Equal(t, "SET app.tenant_id=1;\nRESET;\n", log.String())
+	})
+
+	t.Run("nil reset", func(t *testing.T) {
+		tx := &MockConn{
+			TxID: 1,
+			MockSetSessionVars: func(context.Context, map[string]string) (func(context.Context) error, error) {
+				return nil, nil
+			},
+		}
+		reset, err := SetSessionVars(t.Context(), tx, vars)
+		require.NoError(t, err)
+		require.NoError(t, reset(t.Context()))
+	})
+}
+
+func TestIsolatedTransaction_SessionVars(t *testing.T) {
+	t.Run("set after BEGIN and reset before COMMIT", func(t *testing.T) {
+		// given
+		conn, log := newSessionVarsTestConn()
+		ctx := ContextWithSessionVars(t.Context(), map[string]string{"b": "2", "a": "1"})
+
+		// when
+		err := IsolatedTransaction(ctx, conn, nil, func(tx Connection) error {
+			return tx.Exec(ctx, "UPDATE t SET x = 1")
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "BEGIN;\nSET a=1, b=2;\nUPDATE t SET x = 1;\nRESET;\nCOMMIT;\n", log.String())
+	})
+
+	t.Run("reset before ROLLBACK", func(t *testing.T) {
+		// given
+		conn, log := newSessionVarsTestConn()
+		ctx := ContextWithSessionVars(t.Context(), map[string]string{"a": "1"})
+		errFunc := errors.New("txFunc error")
+
+		// when
+		err := IsolatedTransaction(ctx, conn, nil, func(tx Connection) error {
+			return errFunc
+		})
+
+		// then
+		assert.ErrorIs(t, err, errFunc)
+		assert.Equal(t, "BEGIN;\nSET a=1;\nRESET;\nROLLBACK;\n", log.String())
+	})
+
+	t.Run("no vars", func(t *testing.T) {
+		// given
+		conn, log := newSessionVarsTestConn()
+
+		// when
+		err := IsolatedTransaction(t.Context(), conn, nil, func(tx Connection) error { return nil })
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "BEGIN;\nCOMMIT;\n", log.String())
+	})
+
+	t.Run("set error rolls back", func(t *testing.T) {
+		// given
+		errSet := errors.New("set error")
+		conn, log := newSessionVarsTestConn()
+		conn.MockSetSessionVars = func(context.Context, map[string]string) (func(context.Context) error, error) {
+			return nil, errSet
+		}
+		ctx := ContextWithSessionVars(t.Context(), map[string]string{"a": "1"})
+		called := false
+
+		// when
+		err := IsolatedTransaction(ctx, conn, nil, func(tx Connection) error {
+			called = true
+			return nil
+		})
+
+		// then
+		assert.ErrorIs(t, err, errSet)
+		assert.False(t, called)
+		assert.Equal(t, "BEGIN;\nROLLBACK;\n", log.String())
+	})
+
+	t.Run("reset error prevents COMMIT", func(t *testing.T) {
+		// given
+		errReset := errors.New("reset error")
+		conn, log := newSessionVarsTestConn()
+		conn.MockSetSessionVars = func(context.Context, map[string]string) (func(context.Context) error, error) {
+			return func(context.Context) error { return errReset }, nil
+		}
+		ctx := ContextWithSessionVars(t.Context(), map[string]string{"a": "1"})
+
+		// when
+		err := IsolatedTransaction(ctx, conn, nil, func(tx Connection) error { return nil })
+
+		// then
+		assert.ErrorIs(t, err, errReset)
+		assert.Equal(t, "BEGIN;\nROLLBACK;\n", log.String())
+	})
+
+	t.Run("unsupported connection", func(t *testing.T) {
+		// given
+		conn := &MockConn{
+			MockBegin: func(ctx context.Context, id uint64, opts *sql.TxOptions) (Connection, error) {
+				return struct{ Connection }{&MockConn{TxID: id}}, nil
+			},
+		}
+		ctx := ContextWithSessionVars(t.Context(), map[string]string{"a": "1"})
+
+		// when
+		err := IsolatedTransaction(ctx, conn, nil, func(tx Connection) error { return nil })
+
+		// then
+		assert.ErrorIs(t, err, errors.ErrUnsupported)
+	})
+}
+
+func TestSessionVarsInterceptor(t *testing.T) {
+	vars := map[string]string{"app.tenant_id": "1"}
+
+	t.Run("Exec in implicit transaction", func(t *testing.T) {
+		// given
+		base, log := newSessionVarsTestConn()
+		conn := WrapConnection(base, SessionVarsInterceptor())
+		ctx := ContextWithSessionVars(t.Context(), vars)
+
+		// when
+		err := conn.Exec(ctx, "DELETE FROM t")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "BEGIN;\nSET app.tenant_id=1;\nDELETE FROM t;\nRESET;\nCOMMIT;\n", log.String())
+	})
+
+	t.Run("ExecRowsAffected in implicit transaction", func(t *testing.T) {
+		// given
+		base, log := newSessionVarsTestConn()
+		base.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+			return 3, nil
+		}
+		conn := WrapConnection(base, SessionVarsInterceptor())
+		ctx := ContextWithSessionVars(t.Context(), vars)
+
+		// when
+		n, err := conn.ExecRowsAffected(ctx, "DELETE FROM t")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+		assert.Equal(t, "BEGIN;\nSET app.tenant_id=1;\nDELETE FROM t;\nRESET;\nCOMMIT;\n", log.String())
+	})
+
+	t.Run("Query commits after rows", func(t *testing.T) {
+		// given
+		base, log := newSessionVarsTestConn()
+		base.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("v").WithRow(int64(1))
+		}
+		conn := WrapConnection(base, SessionVarsInterceptor())
+		ctx := ContextWithSessionVars(t.Context(), vars)
+
+		// when
+		rows := conn.Query(ctx, "SELECT v FROM t")
+		require.NoError(t, rows.Err())
+		assert.Equal(t, "BEGIN;\nSET app.tenant_id=1;\nSELECT v FROM t;\n", log.String())
+		for rows.Next() {
+		}
+
+		// then
+		require.NoError(t, rows.Err())
+		require.NoError(t, rows.Close())
+		assert.Equal(t, "BEGIN;\nSET app.tenant_id=1;\nSELECT v FROM t;\nRESET;\nCOMMIT;\n", log.String())
+	})
+
+	t.Run("Query error rolls back", func(t *testing.T) {
+		// given
+		errQuery := errors.New("query error")
+		base, log := newSessionVarsTestConn()
+		base.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewErrRows(errQuery)
+		}
+		conn := WrapConnection(base, SessionVarsInterceptor())
+		ctx := ContextWithSessionVars(t.Context(), vars)
+
+		// when
+		rows := conn.Query(ctx, "SELECT v FROM t")
+
+		// then
+		assert.ErrorIs(t, rows.Err(), errQuery)
+		assert.Equal(t, "BEGIN;\nSET app.tenant_id=1;\nSELECT v FROM t;\nRESET;\nROLLBACK;\n", log.String())
+	})
+
+	t.Run("without vars", func(t *testing.T) {
+		// given
+		base, log := newSessionVarsTestConn()
+		conn := WrapConnection(base, SessionVarsInterceptor())
+
+		// when
+		err := conn.Exec(t.Context(), "DELETE FROM t")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "DELETE FROM t;\n", log.String())
+	})
+
+	t.Run("within transaction", func(t *testing.T) {
+		// given
+		base, log := newSessionVarsTestConn()
+		conn := WrapConnection(base, SessionVarsInterceptor())
+		ctx := ContextWithSessionVars(t.Context(), vars)
+
+		// when
+		err := Transaction(ctx, conn, nil, func(tx Connection) error {
+			return tx.Exec(ctx, "DELETE FROM t")
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "BEGIN;\nSET app.tenant_id=1;\nDELETE FROM t;\nRESET;\nCOMMIT;\n", log.String())
+	})
+}
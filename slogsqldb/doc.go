@@ -0,0 +1,16 @@
+// Package slogsqldb logs [sqldb.Connection] operations with [log/slog].
+//
+// [WrapConnection] or [NewInterceptor] log every executed statement
+// with the query formatted by [sqldb.FormatQuery], so that arguments
+// wrapped with [sqldb.KeepSecret] are redacted, together with the
+// duration, the transaction ID, and the error classified by [sqldb.ErrorKind]:
+//
+//	conn = slogsqldb.WrapConnection(conn, logger,
+//		slogsqldb.WithSlowThreshold(100*time.Millisecond),
+//		slogsqldb.WithSampling(100),
+//	)
+//
+// The log level depends on the [StatementKind] of the query
+// and can be configured with [WithLevel].
+// Failed statements are logged at the level set with [WithErrorLevel].
+package slogsqldb
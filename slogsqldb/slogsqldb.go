@@ -0,0 +1,306 @@
+package slogsqldb
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// maxSampledQueries limits the number of queries with sampling counters
+// so that dynamically built queries can't grow the counters without bounds.
+// Queries beyond the limit are not sampled and always logged.
+const maxSampledQueries = 10_000
+
+// DefaultLevels are the default log levels per StatementKind.
+var DefaultLevels = map[StatementKind]slog.Level{
+	KindQuery: slog.LevelDebug,
+	KindDML:   slog.LevelInfo,
+	KindDDL:   slog.LevelInfo,
+	KindOther: slog.LevelInfo,
+}
+
+// Option configures the logging of [NewInterceptor] and [WrapConnection].
+type Option func(*logger)
+
+// WithSlowThreshold sets the minimum duration of statements to be logged.
+// Statements that take less time are not logged unless they fail.
+// The default of zero logs all statements.
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(l *logger) {
+		l.slowThreshold = threshold
+	}
+}
+
+// WithLevel sets the log level for statements of the passed kind.
+// See DefaultLevels for the defaults.
+func WithLevel(kind StatementKind, level slog.Level) Option {
+	return func(l *logger) {
+		l.levels[kind] = level
+	}
+}
+
+// WithErrorLevel sets the log level for failed statements
+// and transaction commits, rollbacks, or closes.
+// The default is slog.LevelError.
+func WithErrorLevel(level slog.Level) Option {
+	return func(l *logger) {
+		l.errorLevel = level
+	}
+}
+
+// WithTransactionLevel sets the log level for Begin, Commit, Rollback,
+// and Close of transactions.
+// The default is slog.LevelDebug.
+func WithTransactionLevel(level slog.Level) Option {
+	return func(l *logger) {
+		l.txLevel = level
+	}
+}
+
+// WithSampling logs only the first and then every n-th execution
+// of the same query to reduce the log volume of high-volume queries.
+// Failed statements are always logged.
+// Values of n less than 2 disable sampling, which is the default.
+func WithSampling(n int) Option {
+	return func(l *logger) {
+		l.sampleEvery = int64(n)
+	}
+}
+
+// WrapConnection returns conn wrapped with the logging
+// interceptor returned by [NewInterceptor].
+func WrapConnection(conn sqldb.Connection, log *slog.Logger, opts ...Option) sqldb.Connection {
+	return sqldb.WrapConnection(conn, NewInterceptor(log, opts...))
+}
+
+// NewInterceptor returns a sqldb.Interceptor that logs
+// statements and transactions to log.
+// If log is nil, then slog.Default() is used at the time of logging.
+// Use it with sqldb.WrapConnection to combine it with other interceptors.
+func NewInterceptor(log *slog.Logger, opts ...Option) sqldb.Interceptor {
+	l := &logger{
+		log:        log,
+		levels:     make(map[StatementKind]slog.Level, len(DefaultLevels)),
+		errorLevel: slog.LevelError,
+		txLevel:    slog.LevelDebug,
+	}
+	for kind, level := range DefaultLevels {
+		l.levels[kind] = level
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return sqldb.Interceptor{
+		Exec:             l.exec,
+		ExecRowsAffected: l.execRowsAffected,
+		Query:            l.query,
+		Begin:            l.begin,
+		Commit:           l.commit,
+		Rollback:         l.rollback,
+		Close:            l.close,
+	}
+}
+
+type logger struct {
+	log           *slog.Logger
+	slowThreshold time.Duration
+	levels        map[StatementKind]slog.Level
+	errorLevel    slog.Level
+	txLevel       slog.Level
+	sampleEvery   int64
+
+	sampleCounters    sync.Map // map[string]*atomic.Int64
+	numSampleCounters atomic.Int64
+}
+
+func (l *logger) slogger() *slog.Logger {
+	if l.log == nil {
+		return slog.Default()
+	}
+	return l.log
+}
+
+func (l *logger) exec(ctx context.Context, conn sqldb.Connection, query string, args []any, next sqldb.ExecFunc) error {
+	start := time.Now()
+	err := next(ctx, query, args)
+	l.logStatement(ctx, conn, "Exec", query, args, time.Since(start), -1, err)
+	return err
+}
+
+func (l *logger) execRowsAffected(ctx context.Context, conn sqldb.Connection, query string, args []any, next sqldb.ExecRowsAffectedFunc) (int64, error) {
+	start := time.Now()
+	n, err := next(ctx, query, args)
+	l.logStatement(ctx, conn, "ExecRowsAffected", query, args, time.Since(start), n, err)
+	return n, err
+}
+
+func (l *logger) query(ctx context.Context, conn sqldb.Connection, query string, args []any, next sqldb.QueryFunc) sqldb.Rows {
+	start := time.Now()
+	return &loggedRows{
+		Rows: next(ctx, query, args),
+		log: func(numRows int64, err error) {
+			l.logStatement(ctx, conn, "Query", query, args, time.Since(start), numRows, err)
+		},
+	}
+}
+
+func (l *logger) begin(ctx context.Context, conn sqldb.Connection, id uint64, opts *sql.TxOptions, next sqldb.BeginFunc) (sqldb.Connection, error) {
+	tx, err := next(ctx, id, opts)
+	level := l.txLevel
+	if err != nil {
+		level = l.errorLevel
+	}
+	log := l.slogger()
+	if log.Enabled(ctx, level) {
+		attrs := []slog.Attr{slog.Uint64("tx_id", id)}
+		if opts != nil {
+			attrs = append(attrs,
+				slog.String("isolation", opts.Isolation.String()),
+				slog.Bool("read_only", opts.ReadOnly),
+			)
+		}
+		attrs = appendErrorAttrs(attrs, err)
+		log.LogAttrs(ctx, level, "sqldb.Begin", attrs...)
+	}
+	return tx, err
+}
+
+func (l *logger) commit(conn sqldb.Connection, next func() error) error {
+	err := next()
+	l.logTransactionEnd(conn, "sqldb.Commit", err)
+	return err
+}
+
+func (l *logger) rollback(conn sqldb.Connection, next func() error) error {
+	err := next()
+	l.logTransactionEnd(conn, "sqldb.Rollback", err)
+	return err
+}
+
+// close logs a transaction that is rolled back
+// by closing it without Commit or Rollback.
+func (l *logger) close(conn sqldb.Connection, next func() error) error {
+	err := next()
+	if conn.Transaction().ID != 0 {
+		l.logTransactionEnd(conn, "sqldb.Close", err)
+	}
+	return err
+}
+
+func (l *logger) logTransactionEnd(conn sqldb.Connection, msg string, err error) {
+	level := l.txLevel
+	if err != nil {
+		level = l.errorLevel
+	}
+	// Commit and Rollback have no context argument
+	ctx := context.Background()
+	log := l.slogger()
+	if !log.Enabled(ctx, level) {
+		return
+	}
+	attrs := appendErrorAttrs([]slog.Attr{slog.Uint64("tx_id", conn.Transaction().ID)}, err)
+	log.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func (l *logger) logStatement(ctx context.Context, conn sqldb.Connection, method, query string, args []any, duration time.Duration, rows int64, err error) {
+	if err == nil {
+		if duration < l.slowThreshold || !l.sample(query) {
+			return
+		}
+	}
+	kind := StatementKindOf(query)
+	level := l.levels[kind]
+	if err != nil {
+		level = l.errorLevel
+	}
+	log := l.slogger()
+	if !log.Enabled(ctx, level) {
+		return
+	}
+	attrs := make([]slog.Attr, 0, 8)
+	attrs = append(attrs,
+		slog.String("query", sqldb.FormatQuery(conn, query, args...)),
+		slog.String("kind", string(kind)),
+		slog.Duration("duration", duration),
+	)
+	if id := conn.Transaction().ID; id != 0 {
+		attrs = append(attrs, slog.Uint64("tx_id", id))
+	}
+	if rows >= 0 && err == nil {
+		attrs = append(attrs, slog.Int64("rows", rows))
+	}
+	attrs = appendErrorAttrs(attrs, err)
+	log.LogAttrs(ctx, level, "sqldb."+method, attrs...)
+}
+
+// sample returns if the current execution of query should be logged.
+func (l *logger) sample(query string) bool {
+	if l.sampleEvery < 2 {
+		return true
+	}
+	counter, ok := l.sampleCounters.Load(query)
+	if !ok {
+		if l.numSampleCounters.Load() >= maxSampledQueries {
+			return true
+		}
+		var loaded bool
+		counter, loaded = l.sampleCounters.LoadOrStore(query, new(atomic.Int64))
+		if !loaded {
+			l.numSampleCounters.Add(1)
+		}
+	}
+	n := counter.(*atomic.Int64).Add(1)
+	return (n-1)%l.sampleEvery == 0
+}
+
+func appendErrorAttrs(attrs []slog.Attr, err error) []slog.Attr {
+	if err == nil {
+		return attrs
+	}
+	return append(attrs,
+		slog.String("error", err.Error()),
+		slog.String("error_kind", sqldb.ErrorKind(err)),
+	)
+}
+
+// loggedRows logs the query when the rows are closed
+// or iterated to the end.
+type loggedRows struct {
+	sqldb.Rows
+	log     func(numRows int64, err error)
+	numRows int64
+	logged  bool
+}
+
+func (r *loggedRows) Next() bool {
+	if r.Rows.Next() {
+		r.numRows++
+		return true
+	}
+	// Rows are closed automatically after the last row
+	r.done(nil)
+	return false
+}
+
+func (r *loggedRows) Close() error {
+	err := r.Rows.Close()
+	r.done(err)
+	return err
+}
+
+func (r *loggedRows) done(closeErr error) {
+	if r.logged {
+		return
+	}
+	r.logged = true
+	err := r.Rows.Err()
+	if err == nil {
+		err = closeErr
+	}
+	r.log(r.numRows, err)
+}
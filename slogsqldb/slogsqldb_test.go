@@ -0,0 +1,188 @@
+package slogsqldb
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+// recordingHandler is a slog.Handler that records all log records.
+type recordingHandler struct {
+	mtx     sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler      { return h }
+
+func recordAttrs(r slog.Record) map[string]slog.Value {
+	m := make(map[string]slog.Value)
+	r.Attrs(func(a slog.Attr) bool {
+		m[a.Key] = a.Value
+		return true
+	})
+	return m
+}
+
+func newTestConn(opts ...Option) (*sqldb.MockConn, sqldb.Connection, *recordingHandler) {
+	handler := new(recordingHandler)
+	mock := sqldb.NewMockConn(sqldb.NewQueryFormatter("$"))
+	return mock, WrapConnection(mock, slog.New(handler), opts...), handler
+}
+
+func TestExec(t *testing.T) {
+	// given
+	_, conn, handler := newTestConn()
+
+	// when
+	err := conn.Exec(t.Context(), "UPDATE users SET password = $1 WHERE id = $2", sqldb.KeepSecret("hunter2"), 7)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, handler.records, 1)
+	r := handler.records[0]
+	assert.Equal(t, "sqldb.Exec", r.Message)
+	assert.Equal(t, slog.LevelInfo, r.Level)
+	a := recordAttrs(r)
+	assert.Equal(t, "UPDATE users SET password = '***REDACTED***' WHERE id = 7", a["query"].String())
+	assert.NotContains(t, a["query"].String(), "hunter2")
+	assert.Equal(t, "dml", a["kind"].String())
+	assert.Contains(t, a, "duration")
+	assert.NotContains(t, a, "tx_id")
+}
+
+func TestError(t *testing.T) {
+	// given
+	mock, conn, handler := newTestConn(WithSlowThreshold(time.Hour))
+	mock.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+		return 0, errors.Join(sqldb.ErrForeignKeyViolation{}, errors.New("driver error"))
+	}
+
+	// when
+	_, err := conn.ExecRowsAffected(t.Context(), "DELETE FROM users WHERE id = $1", 1)
+
+	// then
+	require.Error(t, err)
+	require.Len(t, handler.records, 1, "errors are logged independent of the slow threshold")
+	r := handler.records[0]
+	assert.Equal(t, slog.LevelError, r.Level)
+	a := recordAttrs(r)
+	assert.Equal(t, "ErrForeignKeyViolation", a["error_kind"].String())
+	assert.NotContains(t, a, "rows")
+}
+
+func TestSlowThreshold(t *testing.T) {
+	// given
+	mock, conn, handler := newTestConn(WithSlowThreshold(10 * time.Millisecond))
+	mock.MockExec = func(ctx context.Context, query string, args ...any) error {
+		if query == "SLOW" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return nil
+	}
+
+	// when
+	require.NoError(t, conn.Exec(t.Context(), "FAST"))
+	require.NoError(t, conn.Exec(t.Context(), "SLOW"))
+
+	// then
+	require.Len(t, handler.records, 1)
+	assert.Equal(t, "SLOW", recordAttrs(handler.records[0])["query"].String())
+}
+
+func TestLevels(t *testing.T) {
+	// given
+	mock, conn, handler := newTestConn(WithLevel(KindDDL, slog.LevelWarn))
+	mock.MockQuery = func(ctx context.Context, query string, args ...any) sqldb.Rows {
+		return sqldb.NewMockRows("id").WithRow(int64(1)).WithRow(int64(2))
+	}
+
+	// when
+	require.NoError(t, conn.Exec(t.Context(), "CREATE TABLE t (id int)"))
+	rows := conn.Query(t.Context(), "SELECT id FROM t")
+	for rows.Next() {
+	}
+	require.NoError(t, rows.Close())
+
+	// then
+	require.Len(t, handler.records, 2)
+	assert.Equal(t, slog.LevelWarn, handler.records[0].Level)
+	assert.Equal(t, "sqldb.Query", handler.records[1].Message)
+	assert.Equal(t, slog.LevelDebug, handler.records[1].Level)
+	assert.Equal(t, int64(2), recordAttrs(handler.records[1])["rows"].Int64())
+}
+
+func TestSampling(t *testing.T) {
+	// given
+	mock, conn, handler := newTestConn(WithSampling(3))
+	mock.MockExec = func(ctx context.Context, query string, args ...any) error {
+		if len(args) > 0 {
+			return errors.New("failed")
+		}
+		return nil
+	}
+
+	// when
+	for range 7 {
+		require.NoError(t, conn.Exec(t.Context(), "UPDATE t SET a = 1"))
+	}
+	require.Error(t, conn.Exec(t.Context(), "UPDATE t SET a = $1", 1))
+
+	// then
+	assert.Len(t, handler.records, 4, "executions 1, 4, 7 and the error")
+}
+
+func TestTransaction(t *testing.T) {
+	// given
+	_, conn, handler := newTestConn()
+
+	// when
+	err := sqldb.Transaction(t.Context(), conn, nil, func(tx sqldb.Connection) error {
+		return tx.Exec(t.Context(), "INSERT INTO t DEFAULT VALUES")
+	})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, handler.records, 3)
+	assert.Equal(t, "sqldb.Begin", handler.records[0].Message)
+	assert.Equal(t, "sqldb.Exec", handler.records[1].Message)
+	assert.Equal(t, "sqldb.Commit", handler.records[2].Message)
+	txID := recordAttrs(handler.records[0])["tx_id"].Uint64()
+	assert.NotZero(t, txID)
+	assert.Equal(t, txID, recordAttrs(handler.records[1])["tx_id"].Uint64())
+	assert.Equal(t, txID, recordAttrs(handler.records[2])["tx_id"].Uint64())
+}
+
+func TestTransactionClose(t *testing.T) {
+	// given
+	_, conn, handler := newTestConn()
+	tx, err := conn.Begin(t.Context(), 1, nil)
+	require.NoError(t, err)
+
+	// when
+	err = tx.Close()
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, handler.records, 2)
+	assert.Equal(t, "sqldb.Begin", handler.records[0].Message)
+	assert.Equal(t, "sqldb.Close", handler.records[1].Message)
+	assert.Equal(t, uint64(1), recordAttrs(handler.records[1])["tx_id"].Uint64())
+}
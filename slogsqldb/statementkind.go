@@ -0,0 +1,58 @@
+package slogsqldb
+
+import "github.com/domonda/go-sqldb"
+
+// StatementKind classifies SQL statements for choosing the log level.
+type StatementKind string
+
+const (
+	// KindQuery is the kind of read-only statements like SELECT.
+	KindQuery StatementKind = "query"
+
+	// KindDML is the kind of data manipulation statements
+	// like INSERT, UPDATE, or DELETE.
+	KindDML StatementKind = "dml"
+
+	// KindDDL is the kind of data definition statements
+	// like CREATE, ALTER, or DROP.
+	KindDDL StatementKind = "ddl"
+
+	// KindOther is the kind of all other statements.
+	KindOther StatementKind = "other"
+)
+
+var statementKinds = map[string]StatementKind{
+	"SELECT":   KindQuery,
+	"WITH":     KindQuery,
+	"VALUES":   KindQuery,
+	"TABLE":    KindQuery,
+	"SHOW":     KindQuery,
+	"EXPLAIN":  KindQuery,
+	"DESCRIBE": KindQuery,
+	"INSERT":   KindDML,
+	"UPDATE":   KindDML,
+	"DELETE":   KindDML,
+	"MERGE":    KindDML,
+	"UPSERT":   KindDML,
+	"REPLACE":  KindDML,
+	"COPY":     KindDML,
+	"CREATE":   KindDDL,
+	"ALTER":    KindDDL,
+	"DROP":     KindDDL,
+	"TRUNCATE": KindDDL,
+	"RENAME":   KindDDL,
+	"COMMENT":  KindDDL,
+	"GRANT":    KindDDL,
+	"REVOKE":   KindDDL,
+}
+
+// StatementKindOf returns the StatementKind of query
+// determined by its first keyword after leading whitespace and comments.
+// A WITH query is classified as KindQuery even if it contains
+// data modifying sub-statements.
+func StatementKindOf(query string) StatementKind {
+	if kind, ok := statementKinds[sqldb.QueryKeyword(query)]; ok {
+		return kind
+	}
+	return KindOther
+}
@@ -0,0 +1,30 @@
+package slogsqldb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementKindOf(t *testing.T) {
+	tests := []struct {
+		query string
+		want  StatementKind
+	}{
+		{query: "SELECT 1", want: KindQuery},
+		{query: "  select * from t", want: KindQuery},
+		{query: "WITH x AS (SELECT 1) SELECT * FROM x", want: KindQuery},
+		{query: "-- comment\nINSERT INTO t DEFAULT VALUES", want: KindDML},
+		{query: "/* app=x */ update t set a = 1", want: KindDML},
+		{query: "DELETE FROM t", want: KindDML},
+		{query: "CREATE TABLE t (id int)", want: KindDDL},
+		{query: "drop table t", want: KindDDL},
+		{query: "SET search_path TO public", want: KindOther},
+		{query: "", want: KindOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, StatementKindOf(tt.query))
+		})
+	}
+}
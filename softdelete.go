@@ -0,0 +1,80 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+type deletedCtxKey struct{}
+
+// ContextWithDeleted returns a new context that makes
+// [QueryRowStruct] and [QueryRowStructOr] also return rows
+// that were soft deleted by setting the column of a struct field
+// with the softdelete tag option (e.g. `db:"deleted_at,softdelete"`).
+func ContextWithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deletedCtxKey{}, struct{}{})
+}
+
+// IsContextWithDeleted returns true if the context
+// was returned by [ContextWithDeleted].
+func IsContextWithDeleted(ctx context.Context) bool {
+	return ctx.Value(deletedCtxKey{}) != nil
+}
+
+// softDeleteColumn returns the name of the column of structType
+// with the softdelete tag option or an empty string if there is none.
+func softDeleteColumn(refl StructReflector, structType reflect.Type) (string, error) {
+	columns, err := refl.ReflectStructColumns(structType)
+	if err != nil {
+		return "", err
+	}
+	column := ""
+	for _, col := range columns {
+		if !col.SoftDelete {
+			continue
+		}
+		if col.PrimaryKey {
+			return "", fmt.Errorf("soft delete column %s of %s can't be a primary key column", col.Name, structType)
+		}
+		if column != "" {
+			return "", fmt.Errorf("%s has multiple soft delete columns: %s and %s", structType, column, col.Name)
+		}
+		column = col.Name
+	}
+	return column, nil
+}
+
+// softDeleteQueryBuilder returns builder as [SoftDeleteQueryBuilder]
+// or an error if it does not implement the interface.
+func softDeleteQueryBuilder(builder QueryBuilder) (SoftDeleteQueryBuilder, error) {
+	sqb, ok := builder.(SoftDeleteQueryBuilder)
+	if !ok {
+		return nil, fmt.Errorf("QueryBuilder %T does not implement SoftDeleteQueryBuilder", builder)
+	}
+	return sqb, nil
+}
+
+// softDeleteValues returns the values for a query built by
+// [SoftDeleteQueryBuilder.SoftDelete] with the time of the [Clock]
+// from ctx as deletion timestamp followed by the values of the WHERE columns.
+func softDeleteValues(ctx context.Context, vals []any) []any {
+	return append([]any{ClockFromContext(ctx)(ctx)}, vals...)
+}
+
+// HardDeleteRowStruct deletes a row from the table identified by the primary key columns
+// of the given struct like [DeleteRowStruct], but always with a DELETE query,
+// also if the struct has a field with the softdelete tag option.
+// Returns a wrapped [sql.ErrNoRows] error if no row was affected by the delete,
+// or an [ErrStaleRow] error for structs with a version field.
+func HardDeleteRowStruct(ctx context.Context, conn Executor, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rowStruct StructWithTableName) error {
+	if refl == nil {
+		return errors.New("HardDeleteRowStruct: nil StructReflector")
+	}
+	structVal, err := derefStruct(reflect.ValueOf(rowStruct))
+	if err != nil {
+		return err
+	}
+	return deleteRowStruct(ctx, conn, refl, builder, fmtr, structVal, true)
+}
@@ -0,0 +1,153 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type softDeleteTestStruct struct {
+	TableName `db:"soft_delete_table"`
+
+	ID        int64      `db:"id,primarykey"`
+	Name      string     `db:"name"`
+	DeletedAt *time.Time `db:"deleted_at,softdelete"`
+}
+
+func TestDeleteRowStruct_SoftDelete(t *testing.T) {
+	t.Run("sets soft delete column", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(1)
+		row := softDeleteTestStruct{ID: 1, Name: "Alice"}
+
+		// when
+		err := DeleteRowStruct(timestampsTestContext(t), conn, refl, builder, fmtr, row)
+		// Second delete uses the cached query
+		err2 := DeleteRowStruct(timestampsTestContext(t), conn, refl, builder, fmtr, row)
+
+		// then
+		require.NoError(t, err)
+		require.NoError(t, err2)
+		require.Len(t, conn.Recordings.Execs, 2)
+		for _, exec := range conn.Recordings.Execs {
+			assert.Equal(t, "UPDATE soft_delete_table SET deleted_at=$1 WHERE id = $2 AND deleted_at IS NULL", exec.Query)
+			require.Len(t, exec.Args, 2)
+			assert.Equal(t, timestampsTestNow, exec.Args[0], "time of the Clock from the context")
+			assert.Equal(t, int64(1), exec.Args[1])
+		}
+	})
+
+	t.Run("already deleted", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(0)
+
+		// when
+		err := DeleteRowStruct(t.Context(), conn, refl, builder, fmtr, softDeleteTestStruct{ID: 1})
+
+		// then
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("with version", func(t *testing.T) {
+		type versionedSoftDelete struct {
+			TableName `db:"soft_delete_table"`
+
+			ID        int64      `db:"id,primarykey"`
+			Version   int        `db:"version,version"`
+			DeletedAt *time.Time `db:"deleted_at,softdelete"`
+		}
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(0)
+
+		// when
+		err := DeleteRowStruct(t.Context(), conn, refl, builder, fmtr, versionedSoftDelete{ID: 1, Version: 2})
+
+		// then
+		require.ErrorAs(t, err, new(ErrStaleRow))
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, "UPDATE soft_delete_table SET deleted_at=$1 WHERE id = $2 AND version = $3 AND deleted_at IS NULL", conn.Recordings.Execs[0].Query)
+	})
+
+	t.Run("multiple soft delete columns", func(t *testing.T) {
+		type multiSoftDelete struct {
+			TableName `db:"soft_delete_table"`
+
+			ID        int64      `db:"id,primarykey"`
+			DeletedAt *time.Time `db:"deleted_at,softdelete"`
+			RemovedAt *time.Time `db:"removed_at,softdelete"`
+		}
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := DeleteRowStruct(t.Context(), conn, refl, builder, fmtr, multiSoftDelete{ID: 1})
+		require.Error(t, err)
+		assert.Empty(t, conn.Recordings.Execs)
+	})
+
+	t.Run("DeleteRowStructStmt", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(1)
+		deleteFunc, closeStmt, err := DeleteRowStructStmt[softDeleteTestStruct](t.Context(), conn, refl, builder, fmtr)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, closeStmt()) })
+
+		// when
+		err = deleteFunc(t.Context(), softDeleteTestStruct{ID: 3})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, "UPDATE soft_delete_table SET deleted_at=$1 WHERE id = $2 AND deleted_at IS NULL", conn.Recordings.Execs[0].Query)
+		assert.IsType(t, time.Time{}, conn.Recordings.Execs[0].Args[0])
+		assert.Equal(t, int64(3), conn.Recordings.Execs[0].Args[1])
+	})
+}
+
+func TestHardDeleteRowStruct(t *testing.T) {
+	// given
+	conn, refl, builder, fmtr := newTestInterfaces()
+	conn.MockExecRowsAffected = rowsAffected(1)
+
+	// when
+	err := HardDeleteRowStruct(t.Context(), conn, refl, builder, fmtr, &softDeleteTestStruct{ID: 1})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, conn.Recordings.Execs, 1)
+	assert.Equal(t, "DELETE FROM soft_delete_table WHERE id = $1", conn.Recordings.Execs[0].Query)
+	assert.Equal(t, []any{int64(1)}, conn.Recordings.Execs[0].Args)
+}
+
+func TestQueryRowStruct_SoftDelete(t *testing.T) {
+	// given
+	conn, refl, builder, fmtr := newTestInterfaces()
+	conn.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+		return NewMockRows("id", "name", "deleted_at").WithRow(int64(1), "Alice", nil)
+	}
+
+	// when
+	_, err := QueryRowStruct[softDeleteTestStruct](t.Context(), conn, refl, builder, fmtr, int64(1))
+	// Second query uses the cached queries
+	_, err2 := QueryRowStruct[softDeleteTestStruct](ContextWithDeleted(t.Context()), conn, refl, builder, fmtr, int64(1))
+	_, err3 := QueryRowStructOr(t.Context(), conn, refl, builder, fmtr, softDeleteTestStruct{}, int64(1))
+
+	// then
+	require.NoError(t, err)
+	require.NoError(t, err2)
+	require.NoError(t, err3)
+	require.Len(t, conn.Recordings.Queries, 3)
+	assert.Equal(t, "SELECT * FROM soft_delete_table WHERE id = $1 AND deleted_at IS NULL", conn.Recordings.Queries[0].Query)
+	assert.Equal(t, "SELECT * FROM soft_delete_table WHERE id = $1", conn.Recordings.Queries[1].Query)
+	assert.Equal(t, "SELECT * FROM soft_delete_table WHERE id = $1 AND deleted_at IS NULL", conn.Recordings.Queries[2].Query)
+}
+
+func TestContextWithDeleted(t *testing.T) {
+	assert.True(t, IsContextWithDeleted(ContextWithDeleted(t.Context())))
+	assert.False(t, IsContextWithDeleted(t.Context()))
+}
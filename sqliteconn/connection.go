@@ -90,10 +90,10 @@ func Connect(ctx context.Context, config *sqldb.Config) (sqldb.Connection, error
 		return nil, errors.Join(fmt.Errorf("failed to set busy_timeout: %w", err), conn.Close())
 	}
 
-	return &connection{
+	return sqldb.WithDefaultTimeouts(&connection{
 		conn:   conn,
 		config: config,
-	}, nil
+	}), nil
 }
 
 // MustConnect creates a new sqldb.Connection using the passed sqldb.Config
@@ -310,6 +310,8 @@ func bindArgs(stmt *sqlite.Stmt, args []any) error {
 			stmt.BindText(pos, v)
 		case []byte:
 			stmt.BindBytes(pos, v)
+		case time.Time:
+			stmt.BindText(pos, v.Format(time.RFC3339Nano))
 		default:
 			if valuer, ok := arg.(driver.Valuer); ok {
 				val, err := valuer.Value()
@@ -326,10 +328,13 @@ func bindArgs(stmt *sqlite.Stmt, args []any) error {
 
 // resolveDriverValueArgs resolves any driver.Valuer arguments to their
 // underlying driver.Value so they can be passed to sqlitex.Execute.
+// time.Time arguments are formatted as RFC 3339 text like in bindDriverValue.
 func resolveDriverValueArgs(args []any) ([]any, error) {
 	resolved := make([]any, len(args))
 	for i, arg := range args {
-		if valuer, ok := arg.(driver.Valuer); ok {
+		if t, ok := arg.(time.Time); ok {
+			resolved[i] = t.Format(time.RFC3339Nano)
+		} else if valuer, ok := arg.(driver.Valuer); ok {
 			val, err := valuer.Value()
 			if err != nil {
 				return nil, fmt.Errorf("driver.Valuer error at position %d: %w", i+1, err)
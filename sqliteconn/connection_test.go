@@ -351,6 +351,28 @@ func TestDriverValuerAndSQLScanner(t *testing.T) {
 	})
 }
 
+func TestTimeArgs(t *testing.T) {
+	conn := testConnection(t)
+	t.Cleanup(func() { conn.Close() })
+
+	err := conn.Exec(t.Context(), `CREATE TABLE times (id INTEGER PRIMARY KEY, ts TIMESTAMP)`)
+	require.NoError(t, err)
+
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+
+	// Exec binds time.Time as RFC 3339 text
+	err = conn.Exec(t.Context(), `INSERT INTO times (id, ts) VALUES (?, ?)`, 1, ts)
+	require.NoError(t, err)
+
+	// Query binds time.Time the same way
+	rows := conn.Query(t.Context(), `SELECT ts FROM times WHERE ts = ?`, ts)
+	t.Cleanup(func() { rows.Close() })
+	require.True(t, rows.Next())
+	var got string
+	require.NoError(t, rows.Scan(&got))
+	assert.Equal(t, "2024-05-06T07:08:09.123456789Z", got)
+}
+
 // Helper functions
 
 func readBusyTimeout(t *testing.T, conn sqldb.Connection) int64 {
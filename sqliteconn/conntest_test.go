@@ -34,6 +34,11 @@ func TestConnectionSuite(t *testing.T) {
 				id    INTEGER PRIMARY KEY,
 				email TEXT
 			)`,
+			CreateJSONTable: /*sql*/ `CREATE TABLE conntest_json (
+				id   INTEGER PRIMARY KEY,
+				data TEXT,
+				tags TEXT
+			)`,
 			CreateInfoParent: /*sql*/ `CREATE TABLE conntest_info_parent (
 				id1 INTEGER NOT NULL,
 				id2 INTEGER NOT NULL,
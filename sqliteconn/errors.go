@@ -44,14 +44,14 @@ func wrapKnownErrors(err error) error {
 		return errors.Join(sqldb.ErrIntegrityConstraintViolation{Constraint: extractConstraint(msg)}, err)
 	}
 
-	// SQLITE_INTERRUPT
+	// SQLITE_INTERRUPT, sqldb.ErrQueryCanceled also matches context.Canceled
 	if primary == sqlite.ResultInterrupt {
-		return errors.Join(context.Canceled, err)
+		return errors.Join(sqldb.ErrQueryCanceled, err)
 	}
 
-	// SQLITE_LOCKED or SQLITE_BUSY
+	// SQLITE_LOCKED or SQLITE_BUSY after the busy timeout
 	if primary == sqlite.ResultLocked || primary == sqlite.ResultBusy {
-		return err
+		return errors.Join(sqldb.ErrLockTimeout, err)
 	}
 
 	// SQLITE_READONLY
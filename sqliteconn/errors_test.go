@@ -161,6 +161,8 @@ func TestIsDatabaseLocked(t *testing.T) {
 	err = conn2.Exec(t.Context(), `INSERT INTO locktest (id, val) VALUES (?, ?)`, 2, "from_conn2")
 	require.Error(t, err)
 	assert.True(t, IsDatabaseLocked(err), "expected database locked error, got: %v", err)
+	assert.ErrorIs(t, err, sqldb.ErrLockTimeout)
+	assert.True(t, sqldb.IsRetryable(err))
 
 	// Verify IsDatabaseLocked returns false for non-lock errors
 	assert.False(t, IsDatabaseLocked(nil))
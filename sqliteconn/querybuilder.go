@@ -8,9 +8,12 @@ import (
 )
 
 var (
-	_ sqldb.QueryBuilder          = (*QueryBuilder)(nil)
-	_ sqldb.UpsertQueryBuilder    = (*QueryBuilder)(nil)
-	_ sqldb.ReturningQueryBuilder = (*QueryBuilder)(nil)
+	_ sqldb.QueryBuilder              = (*QueryBuilder)(nil)
+	_ sqldb.UpsertQueryBuilder        = (*QueryBuilder)(nil)
+	_ sqldb.UpsertOptionsQueryBuilder = (*QueryBuilder)(nil)
+	_ sqldb.ReturningQueryBuilder     = (*QueryBuilder)(nil)
+	_ sqldb.SoftDeleteQueryBuilder    = (*QueryBuilder)(nil)
+	_ sqldb.RelationQueryBuilder      = (*QueryBuilder)(nil)
 )
 
 // QueryBuilder implements [sqldb.QueryBuilder], [sqldb.UpsertQueryBuilder],
@@ -51,15 +54,22 @@ func (b QueryBuilder) InsertUnique(formatter sqldb.QueryFormatter, table string,
 // references (since SQLite's sequential ? placeholders cannot reuse arguments
 // like PostgreSQL's positional $N placeholders).
 func (b QueryBuilder) Upsert(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo) (query string, err error) {
-	hasNonPK := false
-	for i := range columns {
-		if !columns[i].PrimaryKey {
-			hasNonPK = true
-			break
-		}
-	}
-	if !hasNonPK {
-		return "", fmt.Errorf("Upsert requires at least one non-primary-key column")
+	return b.UpsertWithOptions(formatter, table, columns, sqldb.UpsertOptions{})
+}
+
+// UpsertWithOptions builds an INSERT ... ON CONFLICT DO UPDATE SET ... WHERE query
+// with the conflict target, update columns, update expressions,
+// and WHERE condition of the options.
+// If the options have a VersionColumn, the existing row is only updated
+// if its version is the proposed version minus one.
+// Update columns are set to their excluded.column values.
+// Update expressions and the WHERE condition reference the existing row
+// with the table name and the proposed row as excluded.column.
+// See [sqldb.UpsertOptions] for the full contract and security model.
+func (b QueryBuilder) UpsertWithOptions(formatter sqldb.QueryFormatter, table string, columns []sqldb.ColumnInfo, options sqldb.UpsertOptions) (query string, err error) {
+	conflictColumns, assignments, err := options.Resolve(columns)
+	if err != nil {
+		return "", err
 	}
 
 	var q strings.Builder
@@ -69,38 +79,45 @@ func (b QueryBuilder) Upsert(formatter sqldb.QueryFormatter, table string, colum
 	}
 	q.WriteString(insert)
 	q.WriteString(` ON CONFLICT(`)
-	first := true
-	for i := range columns {
-		if !columns[i].PrimaryKey {
-			continue
-		}
-		if first {
-			first = false
-		} else {
+	for i, column := range conflictColumns {
+		if i > 0 {
 			q.WriteByte(',')
 		}
-		columnName, err := formatter.FormatColumnName(columns[i].Name)
+		columnName, err := formatter.FormatColumnName(column)
 		if err != nil {
 			return "", err
 		}
 		q.WriteString(columnName)
 	}
 	q.WriteString(`) DO UPDATE SET`)
-	first = true
-	for i := range columns {
-		if columns[i].PrimaryKey {
-			continue
-		}
-		if first {
-			first = false
-		} else {
+	for i, assignment := range assignments {
+		if i > 0 {
 			q.WriteByte(',')
 		}
-		columnName, err := formatter.FormatColumnName(columns[i].Name)
+		columnName, err := formatter.FormatColumnName(assignment.Column)
 		if err != nil {
 			return "", err
 		}
-		fmt.Fprintf(&q, ` %s=excluded.%s`, columnName, columnName)
+		value := assignment.Expression
+		if assignment.ColumnIndex >= 0 {
+			value = "excluded." + columnName
+		}
+		fmt.Fprintf(&q, ` %s=%s`, columnName, value)
+	}
+	where := options.Where
+	if options.VersionColumn != "" {
+		fmtTable, err := formatter.FormatTableName(table)
+		if err != nil {
+			return "", err
+		}
+		versionColumn, err := formatter.FormatColumnName(options.VersionColumn)
+		if err != nil {
+			return "", err
+		}
+		where = options.VersionWhere(fmtTable+"."+versionColumn, "excluded."+versionColumn)
+	}
+	if where != "" {
+		fmt.Fprintf(&q, ` WHERE %s`, where)
 	}
 	return q.String(), nil
 }
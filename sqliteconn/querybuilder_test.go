@@ -0,0 +1,323 @@
+package sqliteconn
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/domonda/go-sqldb"
+)
+
+func TestQueryBuilder_UpsertWithOptions(t *testing.T) {
+	b := QueryBuilder{}
+	columns := []sqldb.ColumnInfo{
+		{Name: "id", PrimaryKey: true},
+		{Name: "count"},
+		{Name: "version"},
+	}
+
+	for _, scenario := range []struct {
+		name      string
+		options   sqldb.UpsertOptions
+		wantQuery string
+	}{
+		{
+			name:      "zero options like Upsert",
+			wantQuery: `INSERT INTO "counters"("id","count","version") VALUES(?1,?2,?3) ON CONFLICT("id") DO UPDATE SET "count"=excluded."count", "version"=excluded."version"`,
+		},
+		{
+			name: "expression and where condition",
+			options: sqldb.UpsertOptions{
+				UpdateColumns:     []string{"version"},
+				UpdateExpressions: map[string]string{"count": "counters.count + excluded.count"},
+				Where:             "excluded.version > counters.version",
+			},
+			wantQuery: `INSERT INTO "counters"("id","count","version") VALUES(?1,?2,?3) ON CONFLICT("id") DO UPDATE SET "version"=excluded."version", "count"=counters.count + excluded.count WHERE excluded.version > counters.version`,
+		},
+		{
+			name:      "version column",
+			options:   sqldb.UpsertOptions{VersionColumn: "version"},
+			wantQuery: `INSERT INTO "counters"("id","count","version") VALUES(?1,?2,?3) ON CONFLICT("id") DO UPDATE SET "count"=excluded."count", "version"=excluded."version" WHERE "counters"."version" = excluded."version" - 1`,
+		},
+	} {
+		t.Run(scenario.name, func(t *testing.T) {
+			// when
+			query, err := b.UpsertWithOptions(QueryFormatter{}, "counters", columns, scenario.options)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, scenario.wantQuery, query)
+		})
+	}
+}
+
+func TestQueryBuilder_UpsertWithOptions_Exec(t *testing.T) {
+	conn := testConnection(t)
+	t.Cleanup(func() { conn.Close() })
+	err := conn.Exec(t.Context(), `CREATE TABLE counters (id INTEGER PRIMARY KEY, count INTEGER NOT NULL, version INTEGER NOT NULL)`)
+	require.NoError(t, err)
+
+	columns := []sqldb.ColumnInfo{
+		{Name: "id", PrimaryKey: true},
+		{Name: "count"},
+		{Name: "version"},
+	}
+	query, err := QueryBuilder{}.UpsertWithOptions(conn, "counters", columns, sqldb.UpsertOptions{
+		UpdateColumns:     []string{"version"},
+		UpdateExpressions: map[string]string{"count": "counters.count + excluded.count"},
+		Where:             "excluded.version > counters.version",
+	})
+	require.NoError(t, err)
+
+	// when
+	require.NoError(t, conn.Exec(t.Context(), query, 1, 5, 1))
+	require.NoError(t, conn.Exec(t.Context(), query, 1, 3, 2))
+	require.NoError(t, conn.Exec(t.Context(), query, 1, 100, 2)) // Not newer
+
+	// then
+	rows := conn.Query(t.Context(), `SELECT count, version FROM counters WHERE id = ?`, 1)
+	t.Cleanup(func() { rows.Close() })
+	require.True(t, rows.Next())
+	var count, version int
+	require.NoError(t, rows.Scan(&count, &version))
+	assert.Equal(t, 8, count)
+	assert.Equal(t, 2, version)
+}
+
+func TestInsertRowStructsReturning(t *testing.T) {
+	type item struct {
+		sqldb.TableName `db:"items"`
+
+		ID      int64  `db:"id,primarykey,default"`
+		Name    string `db:"name"`
+		Created string `db:"created,default"`
+	}
+
+	conn := testConnection(t)
+	t.Cleanup(func() { conn.Close() })
+	err := conn.Exec(t.Context(), `CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL, created TEXT NOT NULL DEFAULT 'now')`)
+	require.NoError(t, err)
+	require.NoError(t, conn.Exec(t.Context(), `INSERT INTO items (id, name) VALUES (10, 'existing')`))
+	items := []item{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	// when
+	err = sqldb.InsertRowStructsReturning(t.Context(), conn, sqldb.NewTaggedStructReflector(), QueryBuilder{}, conn, items)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []item{
+		{ID: 11, Name: "a", Created: "now"},
+		{ID: 12, Name: "b", Created: "now"},
+		{ID: 13, Name: "c", Created: "now"},
+	}, items)
+}
+
+func TestOptimisticLocking(t *testing.T) {
+	type doc struct {
+		sqldb.TableName `db:"docs"`
+
+		ID      int64  `db:"id,primarykey"`
+		Title   string `db:"title"`
+		Version int64  `db:"version,version"`
+	}
+
+	conn := testConnection(t)
+	t.Cleanup(func() { conn.Close() })
+	err := conn.Exec(t.Context(), `CREATE TABLE docs (id INTEGER PRIMARY KEY, title TEXT NOT NULL, version INTEGER NOT NULL)`)
+	require.NoError(t, err)
+	refl := sqldb.NewTaggedStructReflector()
+
+	// Insert with version 1 by upserting version 0
+	d := &doc{ID: 1, Title: "first"}
+	require.NoError(t, sqldb.UpsertRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, d))
+	require.Equal(t, int64(1), d.Version)
+
+	// Concurrent copy of the same version
+	stale := *d
+
+	d.Title = "second"
+	require.NoError(t, sqldb.UpdateRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, d))
+	require.Equal(t, int64(2), d.Version)
+
+	stale.Title = "lost update"
+	err = sqldb.UpdateRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, &stale)
+	require.ErrorAs(t, err, new(sqldb.ErrStaleRow))
+	err = sqldb.UpsertRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, &stale)
+	require.ErrorAs(t, err, new(sqldb.ErrStaleRow))
+	err = sqldb.DeleteRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, stale)
+	require.ErrorAs(t, err, new(sqldb.ErrStaleRow))
+	assert.Equal(t, int64(1), stale.Version)
+
+	title, err := sqldb.QueryRowAs[string](t.Context(), conn, refl, conn, `SELECT title FROM docs WHERE id = 1`)
+	require.NoError(t, err)
+	assert.Equal(t, "second", title)
+
+	require.NoError(t, sqldb.DeleteRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, d))
+}
+
+func TestSoftDelete(t *testing.T) {
+	type doc struct {
+		sqldb.TableName `db:"docs"`
+
+		ID        int64          `db:"id,primarykey"`
+		Title     string         `db:"title"`
+		DeletedAt sql.NullString `db:"deleted_at,softdelete"`
+	}
+
+	conn := testConnection(t)
+	t.Cleanup(func() { conn.Close() })
+	err := conn.Exec(t.Context(), `CREATE TABLE docs (id INTEGER PRIMARY KEY, title TEXT NOT NULL, deleted_at TIMESTAMP)`)
+	require.NoError(t, err)
+	refl := sqldb.NewTaggedStructReflector()
+
+	d := &doc{ID: 1, Title: "first"}
+	require.NoError(t, sqldb.InsertRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, d))
+
+	require.NoError(t, sqldb.DeleteRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, d))
+	err = sqldb.DeleteRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, d)
+	require.ErrorIs(t, err, sql.ErrNoRows, "already soft deleted")
+
+	_, err = sqldb.QueryRowStruct[doc](t.Context(), conn, refl, QueryBuilder{}, conn, int64(1))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deleted, err := sqldb.QueryRowStruct[doc](sqldb.ContextWithDeleted(t.Context()), conn, refl, QueryBuilder{}, conn, int64(1))
+	require.NoError(t, err)
+	assert.Equal(t, "first", deleted.Title)
+	deletedAt, err := time.Parse(time.RFC3339Nano, deleted.DeletedAt.String)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), deletedAt, time.Minute)
+
+	// DeleteRowStructs uses a prepared statement
+	d2 := doc{ID: 2, Title: "second"}
+	require.NoError(t, sqldb.InsertRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, d2))
+	require.NoError(t, sqldb.DeleteRowStructs(t.Context(), conn, refl, QueryBuilder{}, conn, []doc{d2}))
+	_, err = sqldb.QueryRowStruct[doc](t.Context(), conn, refl, QueryBuilder{}, conn, int64(2))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, sqldb.HardDeleteRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, d))
+	count, err := sqldb.QueryRowAs[int](t.Context(), conn, refl, conn, `SELECT COUNT(*) FROM docs`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestTimestamps(t *testing.T) {
+	type doc struct {
+		sqldb.TableName `db:"docs"`
+
+		ID        int64          `db:"id,primarykey"`
+		Title     string         `db:"title"`
+		CreatedAt sql.NullString `db:"created_at,created"`
+		UpdatedAt sql.NullString `db:"updated_at,updated"`
+	}
+
+	conn := testConnection(t)
+	t.Cleanup(func() { conn.Close() })
+	err := conn.Exec(t.Context(), `CREATE TABLE docs (id INTEGER PRIMARY KEY, title TEXT NOT NULL, created_at TIMESTAMP NOT NULL, updated_at TIMESTAMP NOT NULL)`)
+	require.NoError(t, err)
+	refl := sqldb.NewTaggedStructReflector()
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	d := &doc{ID: 1, Title: "first"}
+	ctx := sqldb.ContextWithClock(t.Context(), func(context.Context) time.Time { return created })
+	require.NoError(t, sqldb.InsertRowStruct(ctx, conn, refl, QueryBuilder{}, conn, d))
+	assert.Equal(t, created.Format(time.RFC3339Nano), d.CreatedAt.String)
+	assert.Equal(t, created.Format(time.RFC3339Nano), d.UpdatedAt.String)
+
+	// Upsert of a new struct for the same row must not overwrite created_at
+	d = &doc{ID: 1, Title: "changed"}
+	ctx = sqldb.ContextWithClock(t.Context(), func(context.Context) time.Time { return updated })
+	require.NoError(t, sqldb.UpsertRowStruct(ctx, conn, refl, QueryBuilder{}, conn, d))
+	assert.False(t, d.CreatedAt.Valid, "created time of the existing row is not written back")
+	assert.Equal(t, updated.Format(time.RFC3339Nano), d.UpdatedAt.String)
+
+	stored, err := sqldb.QueryRowStruct[doc](t.Context(), conn, refl, QueryBuilder{}, conn, int64(1))
+	require.NoError(t, err)
+	assert.Equal(t, "changed", stored.Title)
+	assert.Equal(t, created.Format(time.RFC3339Nano), stored.CreatedAt.String)
+	assert.Equal(t, updated.Format(time.RFC3339Nano), stored.UpdatedAt.String)
+}
+
+func TestPatch(t *testing.T) {
+	type user struct {
+		sqldb.TableName `db:"users"`
+
+		ID    int64               `db:"id,primarykey"`
+		Name  sqldb.Patch[string] `db:"name"`
+		Email sqldb.Patch[string] `db:"email"`
+	}
+
+	conn := testConnection(t)
+	t.Cleanup(func() { conn.Close() })
+	err := conn.Exec(t.Context(), `CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT)`)
+	require.NoError(t, err)
+	refl := sqldb.NewTaggedStructReflector()
+	err = conn.Exec(t.Context(), `INSERT INTO users (id, name, email) VALUES (1, 'Alice', 'alice@example.com')`)
+	require.NoError(t, err)
+
+	// Unset Name keeps the value, NULL Email clears it
+	err = sqldb.UpdateRowStruct(t.Context(), conn, refl, QueryBuilder{}, conn, user{ID: 1, Email: sqldb.PatchNull[string]()})
+	require.NoError(t, err)
+
+	stored, err := sqldb.QueryRowStruct[user](t.Context(), conn, refl, QueryBuilder{}, conn, int64(1))
+	require.NoError(t, err)
+	assert.Equal(t, sqldb.PatchOf("Alice"), stored.Name)
+	assert.Equal(t, sqldb.PatchNull[string](), stored.Email)
+}
+
+func TestLoadRelations(t *testing.T) {
+	type product struct {
+		sqldb.TableName `db:"products"`
+
+		ID   int64  `db:"id,primarykey"`
+		Name string `db:"name"`
+	}
+	type item struct {
+		sqldb.TableName `db:"items"`
+
+		ID        int64    `db:"id,primarykey"`
+		OrderID   int64    `db:"order_id"`
+		ProductID int64    `db:"product_id"`
+		Product   *product `rel:"belongs_to,fk=product_id"`
+	}
+	type order struct {
+		sqldb.TableName `db:"orders"`
+
+		ID    int64   `db:"id,primarykey"`
+		Items []*item `rel:"has_many,fk=order_id"`
+	}
+
+	conn := testConnection(t)
+	t.Cleanup(func() { conn.Close() })
+	for _, query := range []string{
+		`CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL)`,
+		`CREATE TABLE orders (id INTEGER PRIMARY KEY)`,
+		`CREATE TABLE items (id INTEGER PRIMARY KEY, order_id INTEGER NOT NULL REFERENCES orders(id), product_id INTEGER NOT NULL REFERENCES products(id))`,
+		`INSERT INTO products (id, name) VALUES (1, 'Widget'), (2, 'Gadget')`,
+		`INSERT INTO orders (id) VALUES (1), (2), (3)`,
+		`INSERT INTO items (id, order_id, product_id) VALUES (1, 1, 1), (2, 1, 2), (3, 2, 1)`,
+	} {
+		require.NoError(t, conn.Exec(t.Context(), query))
+	}
+	refl := sqldb.NewTaggedStructReflector()
+
+	require.NoError(t, sqldb.VerifyRelations[order](t.Context(), conn, refl))
+	require.NoError(t, sqldb.VerifyRelations[item](t.Context(), conn, refl))
+
+	orders, err := sqldb.QueryRowsAsSlice[order](t.Context(), conn, refl, conn, sqldb.UnlimitedMaxNumRows, `SELECT * FROM orders ORDER BY id`)
+	require.NoError(t, err)
+	err = sqldb.LoadRelations(t.Context(), conn, refl, QueryBuilder{}, conn, orders, "Items.Product")
+	require.NoError(t, err)
+
+	require.Len(t, orders, 3)
+	require.Len(t, orders[0].Items, 2)
+	assert.Equal(t, "Widget", orders[0].Items[0].Product.Name)
+	assert.Equal(t, "Gadget", orders[0].Items[1].Product.Name)
+	require.Len(t, orders[1].Items, 1)
+	assert.Equal(t, "Widget", orders[1].Items[0].Product.Name)
+	assert.Nil(t, orders[2].Items)
+}
@@ -3,6 +3,8 @@ package sqldb
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"net"
 )
 
@@ -16,6 +18,28 @@ type Stmt interface {
 	Close() error
 }
 
+// StmtBinder is implemented by transaction connections
+// that can bind a Stmt prepared on their parent connection
+// to the transaction. The bound Stmt reuses the statement
+// already prepared on the underlying database session if possible
+// instead of preparing it again.
+// Closing the bound Stmt does not close the passed Stmt.
+type StmtBinder interface {
+	BindStmt(ctx context.Context, stmt Stmt) (Stmt, error)
+}
+
+// BindStmtToTx returns stmt bound to tx for implementing [StmtBinder]
+// with a [*sql.Tx]. The passed stmt must have been returned by
+// [NewStmt] for a [*sql.Stmt] prepared on the [*sql.DB] of tx,
+// else an error wrapping [errors.ErrUnsupported] is returned.
+func BindStmtToTx(ctx context.Context, tx *sql.Tx, stmt Stmt) (Stmt, error) {
+	s, ok := stmt.(wrappedStmt)
+	if !ok {
+		return nil, fmt.Errorf("%w: can't bind %T to a transaction", errors.ErrUnsupported, stmt)
+	}
+	return NewStmt(tx.StmtContext(ctx, s.stmt), s.query, s.wrapErr), nil
+}
+
 type wrappedStmt struct {
 	stmt    *sql.Stmt
 	query   string
@@ -0,0 +1,419 @@
+package sqldb
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultStmtCacheCapacity is the capacity used by [NewStmtCache]
+// for capacity values less than one.
+const DefaultStmtCacheCapacity = 256
+
+// stmtCacheRetryPrepareAfter is the duration after which
+// a query that failed to prepare is prepared again.
+const stmtCacheRetryPrepareAfter = time.Minute
+
+// StmtCacheStats are the statistics of a [StmtCache].
+type StmtCacheStats struct {
+	// Hits is the number of executions that used an already prepared statement.
+	Hits uint64
+	// Misses is the number of executions that had to prepare a statement.
+	Misses uint64
+	// Evictions is the number of statements that were closed
+	// because the capacity was exceeded.
+	Evictions uint64
+	// Invalidations is the number of statements that were closed
+	// because of an [ErrStmtInvalidated] error.
+	Invalidations uint64
+	// Size is the current number of cached statements
+	// including the queries that failed to prepare.
+	Size int
+}
+
+// StmtCache is a least recently used cache of prepared statements
+// for the queries executed on a connection.
+// Use [StmtCache.Interceptor] with [WrapConnection] to execute
+// Exec, ExecRowsAffected, and Query calls of the connection
+// as prepared statements that are prepared once on first use
+// and then reused across calls on the connection pool.
+//
+// Within transactions the cached statements are bound to the transaction
+// if the transaction connection implements [StmtBinder],
+// else the queries are executed without the cache.
+// Queries that are not cached yet are not prepared within transactions
+// because preparing them on the connection pool could block
+// while the transaction holds the last open connection.
+// Queries of pinned connections are always executed without the cache.
+//
+// Queries that fail to prepare, like ones with multiple statements,
+// are cached as such and executed without the cache
+// instead of being prepared again for a minute,
+// so that a transient failure like a lost connection
+// doesn't disable the cache for the query permanently.
+// Prepare errors that wrap [ErrConnectionLost] are not cached.
+//
+// Queries commented by a [QueryCommentInterceptor] before the cache
+// in the chain are cached by the query without the comment,
+// so that executions with different tags share one statement.
+// The statement is prepared with the comment of the execution
+// that prepared it.
+//
+// If the least recently used statement has to make room for a new one
+// or an execution fails with [ErrStmtInvalidated] because the schema
+// changed, then the statement is evicted from the cache and closed.
+type StmtCache struct {
+	conn     Connection
+	capacity int
+
+	mtx     sync.Mutex
+	entries map[string]*stmtCacheEntry
+	lru     list.List // of *stmtCacheEntry, most recently used at the front
+	closed  bool
+	stats   StmtCacheStats
+	now     func() time.Time
+}
+
+type stmtCacheEntry struct {
+	key     string    // query without a comment of QueryCommentInterceptor
+	stmt    Stmt      // nil if the query failed to prepare
+	failed  time.Time // time of the failed prepare if stmt is nil
+	elem    *list.Element
+	refs    int  // number of running executions
+	evicted bool // close stmt when refs drops to zero
+}
+
+// NewStmtCache returns a StmtCache with the passed capacity
+// that prepares statements with conn.
+// The cache must only be used to intercept conn
+// because the statements are prepared on conn.
+// Use the innermost connection without other interceptors
+// so that the hooks of interceptors added on top of the
+// cache still see every execution.
+//
+// Example:
+//
+//	cache := sqldb.NewStmtCache(conn, 500)
+//	conn = sqldb.WrapConnection(conn, cache.Interceptor())
+func NewStmtCache(conn Connection, capacity int) *StmtCache {
+	if capacity < 1 {
+		capacity = DefaultStmtCacheCapacity
+	}
+	return &StmtCache{
+		conn:     conn,
+		capacity: capacity,
+		entries:  make(map[string]*stmtCacheEntry, capacity),
+		now:      time.Now,
+	}
+}
+
+// Interceptor returns an [Interceptor] that executes
+// queries with the cached prepared statements.
+// Interceptors before it in the chain see all executions
+// like without the cache, interceptors after it
+// see only the executions that don't use the cache.
+// Executions of statements returned by Connection.Prepare
+// don't use the cache.
+func (c *StmtCache) Interceptor() Interceptor {
+	return Interceptor{
+		Exec: func(ctx context.Context, conn Connection, query string, args []any, next ExecFunc) error {
+			key := uncommentedQuery(ctx, query)
+			stmt, release := c.stmt(ctx, conn, key, query)
+			if stmt == nil {
+				return next(ctx, query, args)
+			}
+			err := stmt.Exec(ctx, args...)
+			release()
+			if c.invalidated(conn, key, err) {
+				return next(ctx, query, args)
+			}
+			return err
+		},
+		ExecRowsAffected: func(ctx context.Context, conn Connection, query string, args []any, next ExecRowsAffectedFunc) (int64, error) {
+			key := uncommentedQuery(ctx, query)
+			stmt, release := c.stmt(ctx, conn, key, query)
+			if stmt == nil {
+				return next(ctx, query, args)
+			}
+			n, err := stmt.ExecRowsAffected(ctx, args...)
+			release()
+			if c.invalidated(conn, key, err) {
+				return next(ctx, query, args)
+			}
+			return n, err
+		},
+		Query: func(ctx context.Context, conn Connection, query string, args []any, next QueryFunc) Rows {
+			key := uncommentedQuery(ctx, query)
+			stmt, release := c.stmt(ctx, conn, key, query)
+			if stmt == nil {
+				return next(ctx, query, args)
+			}
+			rows := stmt.Query(ctx, args...)
+			if err := rows.Err(); err != nil {
+				_ = rows.Close()
+				release()
+				if c.invalidated(conn, key, err) {
+					return next(ctx, query, args)
+				}
+				return NewErrRows(err)
+			}
+			return &stmtCacheRows{Rows: rows, release: release}
+		},
+	}
+}
+
+// Stats returns the current statistics of the cache.
+func (c *StmtCache) Stats() StmtCacheStats {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	stats := c.stats
+	stats.Size = len(c.entries)
+	return stats
+}
+
+// Close closes all cached statements.
+// Executions after Close don't use the cache.
+// Statements that are still executing are closed
+// after their execution finished.
+func (c *StmtCache) Close() error {
+	c.mtx.Lock()
+	c.closed = true
+	var toClose []Stmt
+	for _, e := range c.entries {
+		if stmt := c.evictLocked(e); stmt != nil {
+			toClose = append(toClose, stmt)
+		}
+	}
+	c.mtx.Unlock()
+
+	return closeStmts(toClose)
+}
+
+// stmt returns the statement cached with key to execute query on conn
+// and a function to call after the execution,
+// or nil if the query should not be executed with a cached statement.
+func (c *StmtCache) stmt(ctx context.Context, conn Connection, key, query string) (stmt Stmt, release func()) {
+	if isPreparedStmtExecution(ctx) {
+		return nil, nil
+	}
+	if _, pinned := conn.(PinnedConnection); pinned {
+		return nil, nil
+	}
+	var binder StmtBinder
+	if conn.Transaction().Active() {
+		var ok bool
+		binder, ok = ConnectionAs[StmtBinder](conn)
+		if !ok {
+			return nil, nil
+		}
+	}
+
+	e := c.acquire(ctx, key, query, binder == nil)
+	if e == nil {
+		return nil, nil
+	}
+	if binder == nil {
+		return e.stmt, func() { c.release(e) }
+	}
+	bound, err := binder.BindStmt(ctx, e.stmt)
+	if err != nil {
+		c.release(e)
+		return nil, nil
+	}
+	return bound, func() {
+		_ = bound.Close()
+		c.release(e)
+	}
+}
+
+// acquire returns the cache entry for key with an added reference,
+// preparing the statement with query if it is not cached yet
+// and prepare is true.
+// It returns nil if the statement is not cached and was not prepared,
+// could not be prepared, or the cache is closed.
+func (c *StmtCache) acquire(ctx context.Context, key, query string, prepare bool) *stmtCacheEntry {
+	c.mtx.Lock()
+	if c.closed {
+		c.mtx.Unlock()
+		return nil
+	}
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e.elem)
+		if e.stmt != nil {
+			c.stats.Hits++
+			e.refs++
+			c.mtx.Unlock()
+			return e
+		}
+		// Failed to prepare before
+		if !prepare || c.now().Sub(e.failed) < stmtCacheRetryPrepareAfter {
+			c.mtx.Unlock()
+			return nil
+		}
+		// Prepare again in case the failure was transient
+		c.evictLocked(e)
+	}
+	if !prepare {
+		c.mtx.Unlock()
+		return nil
+	}
+	c.stats.Misses++
+	c.mtx.Unlock()
+
+	// Prepare without holding the lock, so other
+	// queries can use the cache in the meantime
+	stmt, err := c.conn.Prepare(ctx, query)
+	if err != nil && (ctx.Err() != nil || errors.Is(err, ErrConnectionLost)) {
+		// Don't remember queries that failed because the
+		// context was canceled or the connection was lost
+		return nil
+	}
+
+	c.mtx.Lock()
+	if c.closed {
+		c.mtx.Unlock()
+		if stmt != nil {
+			_ = stmt.Close()
+		}
+		return nil
+	}
+	if e, ok := c.entries[key]; ok && (e.stmt != nil || stmt == nil) {
+		// Prepared concurrently by another execution
+		c.lru.MoveToFront(e.elem)
+		if e.stmt != nil {
+			e.refs++
+		}
+		c.mtx.Unlock()
+		if stmt != nil {
+			_ = stmt.Close()
+		}
+		if e.stmt == nil {
+			return nil
+		}
+		return e
+	}
+	if e, ok := c.entries[key]; ok {
+		// Failed to prepare concurrently, replace it
+		c.evictLocked(e)
+	}
+	// A failed prepare is cached with a nil stmt
+	// so that the query is not prepared again until
+	// stmtCacheRetryPrepareAfter has passed.
+	// Let the unprepared execution report the error
+	// or succeed if the query can't be prepared at all.
+	e := &stmtCacheEntry{key: key, stmt: stmt}
+	if stmt != nil {
+		e.refs = 1
+	} else {
+		e.failed = c.now()
+	}
+	e.elem = c.lru.PushFront(e)
+	c.entries[key] = e
+	var toClose []Stmt
+	for len(c.entries) > c.capacity {
+		oldest := c.lru.Back().Value.(*stmtCacheEntry)
+		c.stats.Evictions++
+		if stmt := c.evictLocked(oldest); stmt != nil {
+			toClose = append(toClose, stmt)
+		}
+	}
+	c.mtx.Unlock()
+
+	_ = closeStmts(toClose)
+	if stmt == nil {
+		return nil
+	}
+	return e
+}
+
+// release removes a reference added by acquire
+// and closes the statement of an evicted entry
+// after its last execution.
+func (c *StmtCache) release(e *stmtCacheEntry) {
+	c.mtx.Lock()
+	e.refs--
+	closeStmt := e.evicted && e.refs == 0
+	c.mtx.Unlock()
+
+	if closeStmt {
+		_ = e.stmt.Close()
+	}
+}
+
+// invalidated evicts the cached statement for key
+// if err is an [ErrStmtInvalidated] error and returns true if the
+// execution should be retried without the cache.
+// Executions within transactions are not retried because
+// the failed statement has aborted the transaction
+// for some databases.
+func (c *StmtCache) invalidated(conn Connection, key string, err error) bool {
+	if !errors.Is(err, ErrStmtInvalidated) {
+		return false
+	}
+	c.mtx.Lock()
+	var toClose Stmt
+	if e, ok := c.entries[key]; ok {
+		c.stats.Invalidations++
+		toClose = c.evictLocked(e)
+	}
+	c.mtx.Unlock()
+
+	if toClose != nil {
+		_ = toClose.Close()
+	}
+	return !conn.Transaction().Active()
+}
+
+// evictLocked removes e from the cache and returns its statement
+// if it has to be closed now because it is not executing,
+// or nil if the query failed to prepare.
+// c.mtx must be locked.
+func (c *StmtCache) evictLocked(e *stmtCacheEntry) Stmt {
+	delete(c.entries, e.key)
+	c.lru.Remove(e.elem)
+	e.evicted = true
+	if e.refs > 0 {
+		return nil
+	}
+	return e.stmt
+}
+
+func closeStmts(stmts []Stmt) error {
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// stmtCacheRows releases the cached statement
+// when the rows are closed or iterated to the end.
+type stmtCacheRows struct {
+	Rows
+	release func()
+}
+
+func (r *stmtCacheRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	// Rows are closed automatically after the last row
+	r.done()
+	return false
+}
+
+func (r *stmtCacheRows) Close() error {
+	err := r.Rows.Close()
+	r.done()
+	return err
+}
+
+func (r *stmtCacheRows) done() {
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
+}
@@ -0,0 +1,430 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stmtCacheTestConn is a MockConn that records
+// prepared, executed, and closed statements.
+type stmtCacheTestConn struct {
+	*MockConn
+
+	mtx       sync.Mutex
+	prepared  []string
+	stmtExecs []string
+	closed    []string
+	execErr   error // returned by the next statement execution
+}
+
+func newStmtCacheTestConn() *stmtCacheTestConn {
+	c := &stmtCacheTestConn{MockConn: new(MockConn)}
+	c.MockPrepare = func(ctx context.Context, query string) (Stmt, error) {
+		c.mtx.Lock()
+		c.prepared = append(c.prepared, query)
+		c.mtx.Unlock()
+		return &MockStmt{
+			Prepared: query,
+			MockExec: func(ctx context.Context, args ...any) error {
+				c.mtx.Lock()
+				defer c.mtx.Unlock()
+				c.stmtExecs = append(c.stmtExecs, query)
+				err := c.execErr
+				c.execErr = nil
+				return err
+			},
+			MockQuery: func(ctx context.Context, args ...any) Rows {
+				c.mtx.Lock()
+				defer c.mtx.Unlock()
+				c.stmtExecs = append(c.stmtExecs, query)
+				if err := c.execErr; err != nil {
+					c.execErr = nil
+					return NewErrRows(err)
+				}
+				return NewMockRows("v").WithRow(int64(1)).WithRow(int64(2))
+			},
+			MockClose: func() error {
+				c.mtx.Lock()
+				defer c.mtx.Unlock()
+				c.closed = append(c.closed, query)
+				return nil
+			},
+		}, nil
+	}
+	return c
+}
+
+// stmtBinderTx is a transaction MockConn implementing StmtBinder.
+type stmtBinderTx struct {
+	*MockConn
+	bound       []string
+	closedBound int
+}
+
+func (tx *stmtBinderTx) BindStmt(ctx context.Context, stmt Stmt) (Stmt, error) {
+	tx.bound = append(tx.bound, stmt.PreparedQuery())
+	return &MockStmt{
+		Prepared: stmt.PreparedQuery(),
+		MockExec: stmt.Exec,
+		MockClose: func() error {
+			tx.closedBound++
+			return nil
+		},
+	}, nil
+}
+
+func TestStmtCache(t *testing.T) {
+	t.Run("prepares once and reuses statement", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+
+		// when
+		for range 3 {
+			require.NoError(t, conn.Exec(t.Context(), "UPDATE t SET x = $1", 1))
+		}
+		n, err := conn.ExecRowsAffected(t.Context(), "UPDATE t SET x = $1", 2)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), n)
+		assert.Equal(t, []string{"UPDATE t SET x = $1"}, base.prepared)
+		assert.Len(t, base.stmtExecs, 3)
+		assert.Empty(t, base.Recordings.Execs, "no unprepared executions")
+		assert.Equal(t, StmtCacheStats{Hits: 3, Misses: 1, Size: 1}, cache.Stats())
+	})
+
+	t.Run("query releases statement after rows", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		cache := NewStmtCache(base, 1)
+		conn := WrapConnection(base, cache.Interceptor())
+
+		// when
+		rows := conn.Query(t.Context(), "SELECT v FROM t")
+		// evict the executing statement
+		require.NoError(t, conn.Exec(t.Context(), "DELETE FROM t"))
+
+		// then — closed after the rows are done
+		assert.NotContains(t, base.closed, "SELECT v FROM t")
+		count := 0
+		for rows.Next() {
+			count++
+		}
+		require.NoError(t, rows.Err())
+		assert.Equal(t, 2, count)
+		assert.Equal(t, []string{"SELECT v FROM t"}, base.closed)
+		assert.Equal(t, StmtCacheStats{Misses: 2, Evictions: 1, Size: 1}, cache.Stats())
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		cache := NewStmtCache(base, 2)
+		conn := WrapConnection(base, cache.Interceptor())
+
+		// when
+		require.NoError(t, conn.Exec(t.Context(), "Q1"))
+		require.NoError(t, conn.Exec(t.Context(), "Q2"))
+		require.NoError(t, conn.Exec(t.Context(), "Q1"))
+		require.NoError(t, conn.Exec(t.Context(), "Q3"))
+
+		// then
+		assert.Equal(t, []string{"Q2"}, base.closed)
+		require.NoError(t, conn.Exec(t.Context(), "Q1"))
+		assert.Equal(t, []string{"Q1", "Q2", "Q3"}, base.prepared)
+		assert.Equal(t, StmtCacheStats{Hits: 2, Misses: 3, Evictions: 1, Size: 2}, cache.Stats())
+	})
+
+	t.Run("invalidated statement is evicted and retried unprepared", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+		require.NoError(t, conn.Exec(t.Context(), "INSERT INTO t VALUES (1)"))
+		base.execErr = errors.Join(ErrStmtInvalidated, errors.New("driver error"))
+
+		// when
+		err := conn.Exec(t.Context(), "INSERT INTO t VALUES (1)")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"INSERT INTO t VALUES (1)"}, base.closed)
+		require.Len(t, base.Recordings.Execs, 1, "retried unprepared")
+		assert.Equal(t, StmtCacheStats{Hits: 1, Misses: 1, Invalidations: 1}, cache.Stats())
+	})
+
+	t.Run("invalidated query is evicted and retried unprepared", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		base.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewMockRows("v").WithRow(int64(3))
+		}
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+		base.execErr = ErrStmtInvalidated
+
+		// when
+		rows := conn.Query(t.Context(), "SELECT v FROM t")
+
+		// then
+		require.NoError(t, rows.Err())
+		require.True(t, rows.Next())
+		var v int
+		require.NoError(t, rows.Scan(&v))
+		assert.Equal(t, 3, v)
+		require.NoError(t, rows.Close())
+		assert.Equal(t, []string{"SELECT v FROM t"}, base.closed)
+		assert.Equal(t, uint64(1), cache.Stats().Invalidations)
+	})
+
+	t.Run("transaction without StmtBinder is not cached", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+		tx, err := conn.Begin(t.Context(), NextTransactionID(), nil)
+		require.NoError(t, err)
+
+		// when
+		err = tx.Exec(t.Context(), "UPDATE t SET x = 1")
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, base.prepared)
+		assert.Equal(t, StmtCacheStats{}, cache.Stats())
+	})
+
+	t.Run("transaction with StmtBinder binds cached statement", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		txConn := &stmtBinderTx{MockConn: &MockConn{TxID: 1}}
+		base.MockBegin = func(ctx context.Context, id uint64, opts *sql.TxOptions) (Connection, error) {
+			return txConn, nil
+		}
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+		require.NoError(t, conn.Exec(t.Context(), "UPDATE t SET x = 1"))
+		tx, err := conn.Begin(t.Context(), 1, nil)
+		require.NoError(t, err)
+
+		// when
+		err = tx.Exec(t.Context(), "UPDATE t SET x = 1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"UPDATE t SET x = 1"}, base.prepared)
+		assert.Equal(t, []string{"UPDATE t SET x = 1"}, txConn.bound)
+		assert.Equal(t, 1, txConn.closedBound, "bound statement closed after execution")
+		assert.Len(t, base.stmtExecs, 2)
+		assert.Empty(t, txConn.Recordings.Execs)
+		assert.Equal(t, StmtCacheStats{Hits: 1, Misses: 1, Size: 1}, cache.Stats())
+	})
+
+	t.Run("transaction does not prepare uncached query", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		txConn := &stmtBinderTx{MockConn: &MockConn{TxID: 1}}
+		base.MockBegin = func(ctx context.Context, id uint64, opts *sql.TxOptions) (Connection, error) {
+			return txConn, nil
+		}
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+		tx, err := conn.Begin(t.Context(), 1, nil)
+		require.NoError(t, err)
+
+		// when
+		err = tx.Exec(t.Context(), "UPDATE t SET x = 1")
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, base.prepared, "not prepared on the connection pool")
+		assert.Empty(t, txConn.bound)
+		assert.Len(t, txConn.Recordings.Execs, 1)
+		assert.Equal(t, StmtCacheStats{}, cache.Stats())
+	})
+
+	t.Run("invalidated statement in transaction is not retried", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		txConn := &stmtBinderTx{MockConn: &MockConn{TxID: 1}}
+		base.MockBegin = func(ctx context.Context, id uint64, opts *sql.TxOptions) (Connection, error) {
+			return txConn, nil
+		}
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+		require.NoError(t, conn.Exec(t.Context(), "UPDATE t SET x = 1"))
+		tx, err := conn.Begin(t.Context(), 1, nil)
+		require.NoError(t, err)
+		base.execErr = ErrStmtInvalidated
+
+		// when
+		err = tx.Exec(t.Context(), "UPDATE t SET x = 1")
+
+		// then
+		assert.ErrorIs(t, err, ErrStmtInvalidated)
+		assert.Empty(t, txConn.Recordings.Execs)
+		assert.Equal(t, 0, cache.Stats().Size)
+	})
+
+	t.Run("prepare error falls back to unprepared execution", func(t *testing.T) {
+		// given
+		base := new(MockConn)
+		numPrepares := 0
+		base.MockPrepare = func(ctx context.Context, query string) (Stmt, error) {
+			numPrepares++
+			return nil, errors.New("cannot insert multiple commands into a prepared statement")
+		}
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+
+		// when
+		err1 := conn.Exec(t.Context(), "DELETE FROM a; DELETE FROM b")
+		err2 := conn.Exec(t.Context(), "DELETE FROM a; DELETE FROM b")
+
+		// then
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		assert.Len(t, base.Recordings.Execs, 2)
+		assert.Equal(t, 1, numPrepares, "failed prepare is cached")
+		assert.Equal(t, StmtCacheStats{Misses: 1, Size: 1}, cache.Stats())
+	})
+
+	t.Run("failed prepare is retried after a minute", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		failPrepare := true
+		prepare := base.MockPrepare
+		base.MockPrepare = func(ctx context.Context, query string) (Stmt, error) {
+			if failPrepare {
+				return nil, errors.New("too many prepared statements")
+			}
+			return prepare(ctx, query)
+		}
+		cache := NewStmtCache(base, 10)
+		now := time.Now()
+		cache.now = func() time.Time { return now }
+		conn := WrapConnection(base, cache.Interceptor())
+		require.NoError(t, conn.Exec(t.Context(), "UPDATE t SET x = $1", 1))
+		failPrepare = false
+		require.NoError(t, conn.Exec(t.Context(), "UPDATE t SET x = $1", 2))
+		require.Equal(t, StmtCacheStats{Misses: 1, Size: 1}, cache.Stats())
+
+		// when
+		now = now.Add(time.Minute)
+		err := conn.Exec(t.Context(), "UPDATE t SET x = $1", 3)
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, base.Recordings.Execs, 2, "executions before the retry are unprepared")
+		assert.Equal(t, []string{"UPDATE t SET x = $1"}, base.stmtExecs)
+		assert.Equal(t, StmtCacheStats{Misses: 2, Size: 1}, cache.Stats())
+	})
+
+	t.Run("prepare error of lost connection is not cached", func(t *testing.T) {
+		// given
+		base := new(MockConn)
+		numPrepares := 0
+		base.MockPrepare = func(ctx context.Context, query string) (Stmt, error) {
+			numPrepares++
+			return nil, ErrConnectionLost
+		}
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+
+		// when
+		_ = conn.Exec(t.Context(), "UPDATE t SET x = 1")
+		_ = conn.Exec(t.Context(), "UPDATE t SET x = 1")
+
+		// then
+		assert.Equal(t, 2, numPrepares)
+		assert.Equal(t, StmtCacheStats{Misses: 2}, cache.Stats())
+	})
+
+	t.Run("explicitly prepared statements bypass cache", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+		stmt, err := conn.Prepare(t.Context(), "UPDATE t SET x = $1")
+		require.NoError(t, err)
+
+		// when
+		err = stmt.Exec(t.Context(), 1)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, StmtCacheStats{}, cache.Stats())
+	})
+
+	t.Run("commented queries share statement", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, QueryCommentInterceptor(), cache.Interceptor())
+
+		// when
+		for _, route := range []string{"a", "b"} {
+			ctx := ContextWithQueryTags(t.Context(), "route", route)
+			require.NoError(t, conn.Exec(ctx, "UPDATE t SET x = $1", 1))
+		}
+		require.NoError(t, conn.Exec(t.Context(), "UPDATE t SET x = $1", 1))
+
+		// then
+		assert.Empty(t, base.Recordings.Execs)
+		assert.Equal(t, []string{"UPDATE t SET x = $1 /*route='a'*/"}, base.prepared, "prepared with comment of first execution")
+		assert.Len(t, base.stmtExecs, 3)
+		assert.Equal(t, StmtCacheStats{Hits: 2, Misses: 1, Size: 1}, cache.Stats())
+	})
+
+	t.Run("invalidated commented statement is evicted", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, QueryCommentInterceptor(), cache.Interceptor())
+		ctx := ContextWithQueryTags(t.Context(), "route", "a")
+		require.NoError(t, conn.Exec(ctx, "UPDATE t SET x = $1", 1))
+		base.execErr = ErrStmtInvalidated
+
+		// when
+		err := conn.Exec(ctx, "UPDATE t SET x = $1", 2)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, base.Recordings.Execs, 1)
+		assert.Equal(t, "UPDATE t SET x = $1 /*route='a'*/", base.Recordings.Execs[0].Query)
+		assert.Equal(t, []string{"UPDATE t SET x = $1 /*route='a'*/"}, base.closed)
+		assert.Equal(t, 0, cache.Stats().Size)
+	})
+
+	t.Run("Close closes statements and disables cache", func(t *testing.T) {
+		// given
+		base := newStmtCacheTestConn()
+		cache := NewStmtCache(base, 10)
+		conn := WrapConnection(base, cache.Interceptor())
+		require.NoError(t, conn.Exec(t.Context(), "Q1"))
+
+		// when
+		err := cache.Close()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Q1"}, base.closed)
+		require.NoError(t, conn.Exec(t.Context(), "Q1"))
+		assert.Len(t, base.Recordings.Execs, 1, "executed unprepared after Close")
+		assert.Equal(t, 0, cache.Stats().Size)
+	})
+
+	t.Run("default capacity", func(t *testing.T) {
+		cache := NewStmtCache(new(MockConn), 0)
+		assert.Equal(t, DefaultStmtCacheCapacity, cache.capacity)
+	})
+}
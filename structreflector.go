@@ -12,8 +12,9 @@ type StructReflector interface {
 
 	// MapStructField returns the Column information for a reflected struct field
 	// If false is returned for use then the field is not mapped.
-	// An empty name and true for use indicates an embedded struct
-	// field whose fields should be recursively mapped.
+	// An empty name and true for use indicates an embedded or inline struct
+	// field whose fields should be recursively mapped
+	// with column.InlinePrefix prepended to their column names.
 	MapStructField(field reflect.StructField) (column ColumnInfo, use bool)
 
 	// ScanableStructFieldsForColumns returns a slice of values
@@ -1,9 +1,11 @@
 package sqldb
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -26,6 +28,17 @@ type TaggedStructReflector struct {
 	PrimaryKey string
 	ReadOnly   string
 	Default    string
+	Version    string
+	SoftDelete string
+	Created    string
+	Updated    string
+	JSON       string
+	Inline     string
+
+	// RelationTag is the struct field tag for relations
+	// loaded by LoadRelations, see ReflectStructRelations.
+	// Fields with this tag are not mapped to columns.
+	RelationTag string
 
 	// UntaggedNameFunc will be called with the struct field name to
 	// return a column name in case the struct field has no tag named NameTag.
@@ -52,7 +65,9 @@ type TaggedStructReflector struct {
 
 // NewTaggedStructReflector returns a TaggedStructReflector
 // with the default "db" struct tag for column naming,
-// "-" to ignore fields, and the flags "primarykey", "readonly", "default".
+// "-" to ignore fields, and the flags "primarykey", "readonly", "default",
+// "version", "softdelete", "created", "updated", "json", "inline",
+// and the "rel" struct tag for relations.
 // Struct fields without a "db" tag are ignored (IgnoreStructField).
 // Unmapped columns and struct fields do not cause errors.
 // Optional typeWrappers are used for custom serialization/deserialization
@@ -64,6 +79,13 @@ func NewTaggedStructReflector(typeWrappers ...TypeWrapper) *TaggedStructReflecto
 		PrimaryKey:                 "primarykey",
 		ReadOnly:                   "readonly",
 		Default:                    "default",
+		Version:                    "version",
+		SoftDelete:                 "softdelete",
+		Created:                    "created",
+		Updated:                    "updated",
+		JSON:                       "json",
+		Inline:                     "inline",
+		RelationTag:                "rel",
 		UntaggedNameFunc:           IgnoreStructField,
 		FailOnUnmappedColumns:      false,
 		FailOnUnmappedStructFields: false,
@@ -84,30 +106,58 @@ func (refl *TaggedStructReflector) MapStructField(field reflect.StructField) (co
 			// Embedded struct fields are ok if not tagged with IgnoreName
 			return ColumnInfo{}, true
 		}
-		columnName, _, _ := strings.Cut(tag, ",")
+		columnName, options, _ := strings.Cut(tag, ",")
 		columnName = strings.TrimSpace(columnName)
+		if columnName == refl.Ignore {
+			return ColumnInfo{}, false
+		}
+		if refl.hasInlineOption(options) {
+			// The tag name is only used as column prefix
+			// with the inline option because it can also be
+			// the table name of an embedded TableName
+			return ColumnInfo{InlinePrefix: columnName}, true
+		}
 		// Embedded struct fields are ok if not tagged with IgnoreName
-		return ColumnInfo{}, columnName != refl.Ignore
+		return ColumnInfo{}, true
 	}
 	if !field.IsExported() {
 		// Not exported struct fields that are not
 		// anonymously embedded structs are not ok
 		return ColumnInfo{}, false
 	}
+	if _, isRelation := field.Tag.Lookup(refl.RelationTag); isRelation && refl.RelationTag != "" {
+		// Relations are loaded by LoadRelations
+		return ColumnInfo{}, false
+	}
 
+	inline := false
 	if tag, hasTag := field.Tag.Lookup(refl.NameTag); hasTag {
 		column.Name, tag, _ = strings.Cut(tag, ",")
 		column.Name = strings.TrimSpace(column.Name)
 
 		str, tag, ok := strings.Cut(tag, ",")
 		for str != "" || ok {
-			switch strings.TrimSpace(str) {
-			case refl.PrimaryKey:
+			switch option := strings.TrimSpace(str); {
+			case option == "":
+				// Empty options don't match unset flag names
+			case option == refl.PrimaryKey:
 				column.PrimaryKey = true
-			case refl.ReadOnly:
+			case option == refl.ReadOnly:
 				column.ReadOnly = true
-			case refl.Default:
+			case option == refl.Default:
 				column.HasDefault = true
+			case option == refl.Version:
+				column.Version = true
+			case option == refl.SoftDelete:
+				column.SoftDelete = true
+			case option == refl.Created:
+				column.Created = true
+			case option == refl.Updated:
+				column.Updated = true
+			case option == refl.JSON:
+				column.JSON = true
+			case option == refl.Inline:
+				inline = true
 			}
 			str, tag, ok = strings.Cut(tag, ",")
 		}
@@ -115,14 +165,41 @@ func (refl *TaggedStructReflector) MapStructField(field reflect.StructField) (co
 		column.Name = refl.UntaggedNameFunc(field.Name)
 	}
 
+	if inline {
+		if column.Name == refl.Ignore {
+			return ColumnInfo{}, false
+		}
+		// Empty Name signals a struct field to be flattened
+		// with its tag name as prefix for the column names
+		return ColumnInfo{InlinePrefix: column.Name}, true
+	}
 	if column.Name == "" || column.Name == refl.Ignore {
 		return ColumnInfo{}, false
 	}
 	column.Type = field.Type.String()
+	column.Patch = field.Type.Implements(typeOfPatchField)
 	return column, true
 }
 
+// hasInlineOption returns if the comma separated
+// options of a struct field tag contain the Inline option.
+func (refl *TaggedStructReflector) hasInlineOption(options string) bool {
+	if refl.Inline == "" {
+		return false
+	}
+	for option := range strings.SplitSeq(options, ",") {
+		if strings.TrimSpace(option) == refl.Inline {
+			return true
+		}
+	}
+	return false
+}
+
 // ScanableStructFieldsForColumns implements StructReflector.ScanableStructFieldsForColumns.
+//
+// Fields of inline pointer-to-struct fields are scanned with
+// a sql.Scanner that allocates the struct only for non NULL values,
+// so the pointer is set to nil if all its columns are NULL.
 func (refl *TaggedStructReflector) ScanableStructFieldsForColumns(structVal reflect.Value, columns []string) (scanables []any, err error) {
 	if len(columns) == 0 {
 		return nil, errors.New("no columns")
@@ -137,12 +214,15 @@ func (refl *TaggedStructReflector) ScanableStructFieldsForColumns(structVal refl
 		if !ok {
 			continue
 		}
-		field := structVal.FieldByIndex(rs.Fields[idx].FieldIndex)
-		if scanner := refl.TypeWrappers.WrapAsScanner(field); scanner != nil {
-			scanables[i] = scanner
-		} else {
-			scanables[i] = field.Addr().Interface()
+		f := &rs.Fields[idx]
+		if f.InlinePtrIndex != nil {
+			// Reset the inline struct pointer so that it
+			// is only allocated for non NULL column values
+			structVal.FieldByIndex(f.InlinePtrIndex).SetZero()
+			scanables[i] = &inlinePtrFieldScanner{refl: refl, structVal: structVal, field: f}
+			continue
 		}
+		scanables[i] = refl.scanDest(structVal, structVal.FieldByIndex(f.FieldIndex), &f.Column)
 	}
 	for i, scanable := range scanables {
 		if scanable != nil {
@@ -217,12 +297,7 @@ func (refl *TaggedStructReflector) ReflectStructColumnsAndValues(structVal refle
 			continue
 		}
 		columns = append(columns, f.Column)
-		field := structVal.FieldByIndex(f.FieldIndex)
-		if valuer := refl.TypeWrappers.WrapAsValuer(field); valuer != nil {
-			values = append(values, valuer)
-		} else {
-			values = append(values, field.Interface())
-		}
+		values = append(values, refl.reflectedFieldValue(structVal, f))
 	}
 	return columns, values, nil
 }
@@ -243,12 +318,7 @@ func (refl *TaggedStructReflector) ReflectStructColumnsFieldIndicesAndValues(str
 		}
 		columns = append(columns, f.Column)
 		indices = append(indices, f.FieldIndex)
-		field := structVal.FieldByIndex(f.FieldIndex)
-		if valuer := refl.TypeWrappers.WrapAsValuer(field); valuer != nil {
-			values = append(values, valuer)
-		} else {
-			values = append(values, field.Interface())
-		}
+		values = append(values, refl.reflectedFieldValue(structVal, f))
 	}
 	return columns, indices, values, nil
 }
@@ -267,12 +337,7 @@ func (refl *TaggedStructReflector) ReflectStructValues(structVal reflect.Value,
 		if QueryOptionsIgnoreStructField(&f.StructField, options) {
 			continue
 		}
-		field := structVal.FieldByIndex(f.FieldIndex)
-		if valuer := refl.TypeWrappers.WrapAsValuer(field); valuer != nil {
-			values = append(values, valuer)
-		} else {
-			values = append(values, field.Interface())
-		}
+		values = append(values, refl.reflectedFieldValue(structVal, f))
 	}
 	return values, nil
 }
@@ -316,6 +381,143 @@ func (refl *TaggedStructReflector) ReflectStructColumnsAndFields(structVal refle
 	return columns, fields, nil
 }
 
+// ReflectStructRelations implements RelationReflector.ReflectStructRelations
+// by parsing the RelationTag of the fields of structType and its embedded structs.
+//
+// The tag value starts with the relation kind "has_one", "has_many",
+// or "belongs_to" followed by the options "fk=column" for the
+// foreign key column (required) and optionally "ref=column"
+// for the column referenced by the foreign key:
+//
+//	Items    []OrderItem `rel:"has_many,fk=order_id"`
+//	Customer *Customer   `rel:"belongs_to,fk=customer_id,ref=id"`
+func (refl *TaggedStructReflector) ReflectStructRelations(structType reflect.Type) (relations []RelationInfo, err error) {
+	if refl.RelationTag == "" {
+		return nil, nil
+	}
+	return refl.reflectStructRelations(structType, nil)
+}
+
+func (refl *TaggedStructReflector) reflectStructRelations(structType reflect.Type, parentIndex []int) (relations []RelationInfo, err error) {
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		tag, isRelation := field.Tag.Lookup(refl.RelationTag)
+		if !isRelation {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if column, use := refl.MapStructField(field); use && column.Name == "" {
+					embedded, err := refl.reflectStructRelations(field.Type, slices.Concat(parentIndex, field.Index))
+					if err != nil {
+						return nil, err
+					}
+					relations = append(relations, embedded...)
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			return nil, fmt.Errorf("relation field %s of %s is not exported", field.Name, structType)
+		}
+		relation := RelationInfo{
+			Name:       field.Name,
+			FieldIndex: slices.Concat(parentIndex, field.Index),
+		}
+		kind, tag, _ := strings.Cut(tag, ",")
+		relation.Kind = RelationKind(strings.TrimSpace(kind))
+		for option := range strings.SplitSeq(tag, ",") {
+			key, value, _ := strings.Cut(option, "=")
+			switch key, value = strings.TrimSpace(key), strings.TrimSpace(value); key {
+			case "":
+				// Ignore empty options
+			case "fk":
+				relation.ForeignKey = value
+			case "ref":
+				relation.References = value
+			default:
+				return nil, fmt.Errorf("invalid option %q in %s tag of relation field %s of %s", option, refl.RelationTag, field.Name, structType)
+			}
+		}
+		relation.Type, err = relationStructType(relation.Kind, field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("relation field %s of %s: %w", field.Name, structType, err)
+		}
+		if relation.ForeignKey == "" {
+			return nil, fmt.Errorf("relation field %s of %s has no fk option in its %s tag", field.Name, structType, refl.RelationTag)
+		}
+		relations = append(relations, relation)
+	}
+	return relations, nil
+}
+
+// wrapAsScanner returns a jsonScanner for JSON columns
+// or the result of refl.TypeWrappers.WrapAsScanner.
+func (refl *TaggedStructReflector) wrapAsScanner(field reflect.Value, column *ColumnInfo) sql.Scanner {
+	if column.JSON {
+		return &jsonScanner{ptr: field.Addr()}
+	}
+	return refl.TypeWrappers.WrapAsScanner(field)
+}
+
+// scanDest returns the scan destination for the field of structVal
+// mapped to column as sql.Scanner or pointer to the field.
+func (refl *TaggedStructReflector) scanDest(structVal, field reflect.Value, column *ColumnInfo) any {
+	scanner := refl.wrapAsScanner(field, column)
+	if scanner == nil {
+		return field.Addr().Interface()
+	}
+	if s, ok := scanner.(*jsonScanner); ok {
+		// Only used for error messages
+		s.table, _ = refl.TableNameForStruct(structVal.Type())
+		s.column = column.Name
+	}
+	return scanner
+}
+
+// reflectedFieldValue returns the value of the field f of structVal
+// as query argument or nil for NULL if f is a field
+// of an inline pointer-to-struct field that is nil.
+func (refl *TaggedStructReflector) reflectedFieldValue(structVal reflect.Value, f *reflectedStructField) any {
+	field, err := structVal.FieldByIndexErr(f.FieldIndex)
+	if err != nil {
+		return nil
+	}
+	return refl.fieldValue(field, &f.Column)
+}
+
+// fieldValue returns the value of field for the column
+// wrapped as driver.Valuer for JSON columns or by refl.TypeWrappers.
+// It implements structFieldValuer for the cached queries.
+func (refl *TaggedStructReflector) fieldValue(field reflect.Value, column *ColumnInfo) any {
+	if column.JSON {
+		return jsonValuer{val: field.Interface()}
+	}
+	if valuer := refl.TypeWrappers.WrapAsValuer(field); valuer != nil {
+		return valuer
+	}
+	return field.Interface()
+}
+
+// inlinePtrFieldScanner scans a column into a field
+// of an inline pointer-to-struct field that is only
+// allocated when a non NULL value is scanned.
+type inlinePtrFieldScanner struct {
+	refl      *TaggedStructReflector
+	structVal reflect.Value
+	field     *reflectedStructField
+}
+
+func (s *inlinePtrFieldScanner) Scan(src any) error {
+	if src == nil {
+		// Fields of allocated inline structs
+		// already have the zero value
+		return nil
+	}
+	field, err := fieldByIndexAlloc(s.structVal, s.field.FieldIndex)
+	if err != nil {
+		return err
+	}
+	return ScanDriverValue(s.refl.scanDest(s.structVal, field, &s.field.Column), src)
+}
+
 func (refl *TaggedStructReflector) String() string {
 	return fmt.Sprintf("NameTag: %q", refl.NameTag)
 }
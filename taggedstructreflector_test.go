@@ -1,6 +1,7 @@
 package sqldb
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
@@ -63,6 +64,11 @@ func TestNewTaggedStructReflector(t *testing.T) {
 	assert.Equal(t, "primarykey", r.PrimaryKey)
 	assert.Equal(t, "readonly", r.ReadOnly)
 	assert.Equal(t, "default", r.Default)
+	assert.Equal(t, "version", r.Version)
+	assert.Equal(t, "softdelete", r.SoftDelete)
+	assert.Equal(t, "created", r.Created)
+	assert.Equal(t, "updated", r.Updated)
+	assert.Equal(t, "json", r.JSON)
 	assert.NotNil(t, r.UntaggedNameFunc)
 	assert.Equal(t, "", r.UntaggedNameFunc("AnyField"), "default UntaggedNameFunc should be IgnoreStructField")
 	assert.False(t, r.FailOnUnmappedColumns)
@@ -122,6 +128,11 @@ func TestTaggedStructReflector_MapStructField(t *testing.T) {
 		PrimaryKey:       "pk",
 		ReadOnly:         "readonly",
 		Default:          "default",
+		Version:          "version",
+		SoftDelete:       "softdelete",
+		Created:          "created",
+		Updated:          "updated",
+		JSON:             "json",
 		UntaggedNameFunc: ToSnakeCase,
 	}
 	type AnonymousEmbedded struct{}
@@ -136,6 +147,12 @@ func TestTaggedStructReflector_MapStructField(t *testing.T) {
 		NoFlag         bool "db:\"no_flag,\""
 		MalformedFlags bool "db:\"malformed_flags,x, ,-,readonly,y,  \""
 		AnonymousEmbedded
+		Version   int "db:\"version,version\""
+		DeletedAt any "db:\"deleted_at,softdelete\""
+		CreatedAt any "db:\"created_at,created\""
+		UpdatedAt any "db:\"updated_at,updated\""
+		Patched   Patch[string]
+		Settings  map[string]any "db:\"settings,json\""
 	}]()
 
 	tests := []struct {
@@ -154,6 +171,12 @@ func TestTaggedStructReflector_MapStructField(t *testing.T) {
 		{name: "no_flag", structField: st.Field(7), wantColumn: ColumnInfo{Name: "no_flag", Type: "bool"}, wantOk: true},
 		{name: "malformed_flags", structField: st.Field(8), wantColumn: ColumnInfo{Name: "malformed_flags", Type: "bool", ReadOnly: true}, wantOk: true},
 		{name: "Embedded", structField: st.Field(9), wantColumn: ColumnInfo{}, wantOk: true},
+		{name: "version", structField: st.Field(10), wantColumn: ColumnInfo{Name: "version", Type: "int", Version: true}, wantOk: true},
+		{name: "deleted_at", structField: st.Field(11), wantColumn: ColumnInfo{Name: "deleted_at", Type: "interface {}", SoftDelete: true}, wantOk: true},
+		{name: "created_at", structField: st.Field(12), wantColumn: ColumnInfo{Name: "created_at", Type: "interface {}", Created: true}, wantOk: true},
+		{name: "updated_at", structField: st.Field(13), wantColumn: ColumnInfo{Name: "updated_at", Type: "interface {}", Updated: true}, wantOk: true},
+		{name: "patched", structField: st.Field(14), wantColumn: ColumnInfo{Name: "patched", Type: "sqldb.Patch[string]", Patch: true}, wantOk: true},
+		{name: "settings", structField: st.Field(15), wantColumn: ColumnInfo{Name: "settings", Type: "map[string]interface {}", JSON: true}, wantOk: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -968,3 +991,201 @@ func TestTaggedStructReflector_ScanableStructFieldsForColumns_Basic(t *testing.T
 		}
 	})
 }
+
+type inlineTestGeo struct {
+	Lat float64 `db:"lat"`
+	Lng float64 `db:"lng"`
+}
+
+type inlineTestAddress struct {
+	Street string         `db:"street"`
+	City   string         `db:"city"`
+	Geo    *inlineTestGeo `db:"geo_,inline"`
+}
+
+type inlineTestOrder struct {
+	TableName `db:"orders"`
+
+	ID       int64              `db:"id,primarykey"`
+	Billing  inlineTestAddress  `db:"billing_,inline"`
+	Shipping *inlineTestAddress `db:"shipping_,inline"`
+}
+
+func TestTaggedStructReflector_MapStructField_Inline(t *testing.T) {
+	r := NewTaggedStructReflector()
+	st := reflect.TypeFor[struct {
+		inlineTestAddress `db:"home_,inline"`
+		TableName         `db:"my_table"`
+
+		Billing  inlineTestAddress  `db:"billing_,inline"`
+		Shipping *inlineTestAddress `db:"shipping_,inline"`
+		NoPrefix inlineTestAddress  `db:",inline"`
+		Ignored  inlineTestAddress  `db:"-,inline"`
+	}]()
+
+	tests := []struct {
+		name       string
+		field      reflect.StructField
+		wantColumn ColumnInfo
+		wantUse    bool
+	}{
+		{name: "embedded with prefix", field: st.Field(0), wantColumn: ColumnInfo{InlinePrefix: "home_"}, wantUse: true},
+		{name: "TableName tag is no prefix", field: st.Field(1), wantColumn: ColumnInfo{}, wantUse: true},
+		{name: "struct", field: st.Field(2), wantColumn: ColumnInfo{InlinePrefix: "billing_"}, wantUse: true},
+		{name: "pointer", field: st.Field(3), wantColumn: ColumnInfo{InlinePrefix: "shipping_"}, wantUse: true},
+		{name: "no prefix", field: st.Field(4), wantColumn: ColumnInfo{}, wantUse: true},
+		{name: "ignored", field: st.Field(5), wantColumn: ColumnInfo{}, wantUse: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			column, use := r.MapStructField(tt.field)
+			assert.Equal(t, tt.wantColumn, column)
+			assert.Equal(t, tt.wantUse, use)
+		})
+	}
+}
+
+func TestTaggedStructReflector_Inline(t *testing.T) {
+	allColumns := []string{
+		"id",
+		"billing_street", "billing_city", "billing_geo_lat", "billing_geo_lng",
+		"shipping_street", "shipping_city", "shipping_geo_lat", "shipping_geo_lng",
+	}
+
+	t.Run("ReflectStructColumns", func(t *testing.T) {
+		// when
+		columns, err := reflectTestReflector.ReflectStructColumns(reflect.TypeFor[inlineTestOrder]())
+
+		// then
+		require.NoError(t, err)
+		names := make([]string, len(columns))
+		for i, col := range columns {
+			names[i] = col.Name
+		}
+		assert.Equal(t, allColumns, names)
+	})
+
+	t.Run("PrimaryKeyColumnsOfStruct", func(t *testing.T) {
+		// given
+		type row struct {
+			TableName `db:"rows"`
+
+			Key struct {
+				TenantID int64 `db:"tenant_id,primarykey"`
+				ID       int64 `db:"id,primarykey"`
+			} `db:"key_,inline"`
+			Name string `db:"name"`
+		}
+
+		// when
+		columns, err := reflectTestReflector.PrimaryKeyColumnsOfStruct(reflect.TypeFor[row]())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"key_tenant_id", "key_id"}, columns)
+	})
+
+	t.Run("ReflectStructValues with nil pointer", func(t *testing.T) {
+		// given
+		order := inlineTestOrder{
+			ID:      1,
+			Billing: inlineTestAddress{Street: "Main St", City: "Vienna", Geo: &inlineTestGeo{Lat: 1, Lng: 2}},
+		}
+
+		// when
+		values, err := reflectTestReflector.ReflectStructValues(reflect.ValueOf(order))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []any{int64(1), "Main St", "Vienna", float64(1), float64(2), nil, nil, nil, nil}, values)
+	})
+
+	t.Run("scan into nil pointers", func(t *testing.T) {
+		// given
+		var order inlineTestOrder
+		rows := NewMockRows(allColumns...).
+			WithRow(int64(1), "Main St", "Vienna", nil, nil, "Side St", nil, float64(3), float64(4))
+
+		// when
+		require.True(t, rows.Next())
+		err := scanStruct(rows, allColumns, reflectTestReflector, &order)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, inlineTestOrder{
+			ID:       1,
+			Billing:  inlineTestAddress{Street: "Main St", City: "Vienna"},
+			Shipping: &inlineTestAddress{Street: "Side St", Geo: &inlineTestGeo{Lat: 3, Lng: 4}},
+		}, order)
+	})
+
+	t.Run("scan all NULL group resets pointer", func(t *testing.T) {
+		// given
+		order := inlineTestOrder{
+			Shipping: &inlineTestAddress{Street: "Old St"},
+		}
+		rows := NewMockRows(allColumns...).
+			WithRow(int64(2), "Main St", "Vienna", nil, nil, nil, nil, nil, nil)
+
+		// when
+		require.True(t, rows.Next())
+		err := scanStruct(rows, allColumns, reflectTestReflector, &order)
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, order.Shipping)
+		assert.Nil(t, order.Billing.Geo)
+	})
+
+	t.Run("duplicate prefixed column", func(t *testing.T) {
+		// given
+		type row struct {
+			Street  string            `db:"billing_street"`
+			Billing inlineTestAddress `db:"billing_,inline"`
+		}
+
+		// when
+		_, err := reflectTestReflector.ReflectStructColumns(reflect.TypeFor[row]())
+
+		// then
+		require.ErrorContains(t, err, `duplicate column "billing_street"`)
+	})
+
+	t.Run("inline non struct", func(t *testing.T) {
+		// given
+		type row struct {
+			Name string `db:"name_,inline"`
+		}
+
+		// when
+		_, err := reflectTestReflector.ReflectStructColumns(reflect.TypeFor[row]())
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestInsertRowStruct_InlineNilPointer(t *testing.T) {
+	// given
+	conn, _, builder, fmtr := newTestInterfaces()
+	refl := NewTaggedStructReflector()
+	var gotQuery string
+	var gotArgs [][]any
+	conn.MockExec = func(ctx context.Context, query string, args ...any) error {
+		gotQuery = query
+		gotArgs = append(gotArgs, args)
+		return nil
+	}
+	order := inlineTestOrder{ID: 1, Billing: inlineTestAddress{Street: "Main St"}}
+
+	// when
+	err1 := InsertRowStruct(t.Context(), conn, refl, builder, fmtr, &order)
+	err2 := InsertRowStruct(t.Context(), conn, refl, builder, fmtr, &order)
+
+	// then
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.Equal(t, "INSERT INTO orders(id,billing_street,billing_city,billing_geo_lat,billing_geo_lng,shipping_street,shipping_city,shipping_geo_lat,shipping_geo_lng) VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9)", gotQuery)
+	want := []any{int64(1), "Main St", "", nil, nil, nil, nil, nil, nil}
+	assert.Equal(t, [][]any{want, want}, gotArgs)
+}
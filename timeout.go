@@ -0,0 +1,285 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+type (
+	queryTimeoutCtxKey    struct{}
+	timeoutsAppliedCtxKey struct{}
+)
+
+// ContextWithQueryTimeout returns a new context that limits the duration
+// of every statement executed with it on a connection wrapped
+// with [TimeoutInterceptor] to timeout, overriding [Config.DefaultQueryTimeout].
+// Connections returned by the Connect functions of the driver packages
+// are only wrapped if their Config has a default timeout,
+// see [WithDefaultTimeouts], other connections have to be wrapped
+// explicitly with WrapConnection(conn, TimeoutInterceptor()).
+// A timeout <= 0 disables the default query timeout for the context.
+func ContextWithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutCtxKey{}, max(timeout, 0))
+}
+
+// QueryTimeoutFromContext returns the timeout added to the context
+// with [ContextWithQueryTimeout] and true,
+// or false if the context has no query timeout.
+func QueryTimeoutFromContext(ctx context.Context) (timeout time.Duration, ok bool) {
+	timeout, ok = ctx.Value(queryTimeoutCtxKey{}).(time.Duration)
+	return timeout, ok
+}
+
+// TimeoutSetter is implemented by transaction connections
+// that can enforce timeouts server-side.
+type TimeoutSetter interface {
+	// SetTimeouts sets server-side limits for the rest of the transaction,
+	// queryTimeout for every statement and transactionTimeout for the
+	// whole transaction, where zero means no limit.
+	// Databases may only support some of the limits.
+	//
+	// Databases without transaction scoped settings
+	// have to restore the previous settings with the returned
+	// reset function that must be called before the transaction
+	// is committed or rolled back.
+	SetTimeouts(ctx context.Context, queryTimeout, transactionTimeout time.Duration) (reset func(context.Context) error, err error)
+}
+
+// QueryTimeoutHinter is implemented by connections
+// that enforce statement timeouts server-side with query hints.
+type QueryTimeoutHinter interface {
+	// QueryWithTimeoutHint returns the query with a hint that limits
+	// its execution time to timeout, or the query unchanged
+	// if the hint is not supported for the query.
+	QueryWithTimeoutHint(query string, timeout time.Duration) string
+}
+
+// WithDefaultTimeouts returns conn wrapped with [TimeoutInterceptor]
+// if its Config has a DefaultQueryTimeout or DefaultTransactionTimeout,
+// else conn is returned unchanged.
+// The Connect functions of the driver packages use it.
+func WithDefaultTimeouts(conn Connection) Connection {
+	config := conn.Config()
+	if config == nil || (config.DefaultQueryTimeout <= 0 && config.DefaultTransactionTimeout <= 0) {
+		return conn
+	}
+	return WrapConnection(conn, TimeoutInterceptor())
+}
+
+// TimeoutInterceptor returns an [Interceptor] that enforces
+// the query timeout from [ContextWithQueryTimeout] or
+// [Config.DefaultQueryTimeout] for every statement and
+// [Config.DefaultTransactionTimeout] for every transaction.
+//
+// The timeouts are enforced client-side with context deadlines.
+// A transaction is rolled back when its deadline expires
+// and statements within it are limited to the remaining time.
+// The deadline is released when the transaction is committed,
+// rolled back, or closed.
+// Server-side the timeouts are set for transactions whose connection
+// implements [TimeoutSetter], and queries are passed through
+// [QueryTimeoutHinter] if the connection implements it.
+//
+// Errors caused by the timeouts are joined with an [ErrQueryTimeout]
+// telling if the query or the transaction timeout fired.
+//
+// If connections are wrapped multiple times with the interceptor,
+// only the outermost one applies the timeouts.
+func TimeoutInterceptor() Interceptor {
+	return Interceptor{
+		Exec: func(ctx context.Context, conn Connection, query string, args []any, next ExecFunc) error {
+			ctx, query, cancel := statementTimeout(ctx, conn, query)
+			defer cancel()
+			return withTimeoutCause(ctx, next(ctx, query, args))
+		},
+		ExecRowsAffected: func(ctx context.Context, conn Connection, query string, args []any, next ExecRowsAffectedFunc) (int64, error) {
+			ctx, query, cancel := statementTimeout(ctx, conn, query)
+			defer cancel()
+			n, err := next(ctx, query, args)
+			return n, withTimeoutCause(ctx, err)
+		},
+		Query: func(ctx context.Context, conn Connection, query string, args []any, next QueryFunc) Rows {
+			ctx, query, cancel := statementTimeout(ctx, conn, query)
+			rows := next(ctx, query, args)
+			if err := rows.Err(); err != nil {
+				_ = rows.Close()
+				cancel()
+				return NewErrRows(withTimeoutCause(ctx, err))
+			}
+			return &timeoutRows{Rows: rows, ctx: ctx, cancel: cancel}
+		},
+		Begin: beginWithTimeouts,
+		Commit: func(conn Connection, next func() error) error {
+			return endTxWithTimeouts(conn, next)
+		},
+		Rollback: func(conn Connection, next func() error) error {
+			return endTxWithTimeouts(conn, next)
+		},
+		Close: func(conn Connection, next func() error) error {
+			return endTxWithTimeouts(conn, next)
+		},
+	}
+}
+
+// txTimeouts holds the *txTimeoutState of running transactions by their ID.
+// Transaction IDs are globally unique, so every TimeoutInterceptor
+// wrapping a transaction finds it independent of the instance
+// that began the transaction.
+var txTimeouts sync.Map
+
+type txTimeoutState struct {
+	ctx      context.Context // without cancel for reset
+	timeout  time.Duration
+	deadline time.Time // zero if no transaction timeout
+	cancel   context.CancelFunc
+	reset    func(context.Context) error
+}
+
+func timeoutsApplied(ctx context.Context) bool {
+	return ctx.Value(timeoutsAppliedCtxKey{}) != nil
+}
+
+// statementTimeout returns the context with the deadline for a statement,
+// the query with a timeout hint if supported, and the cancel function
+// to release the context.
+func statementTimeout(ctx context.Context, conn Connection, query string) (context.Context, string, context.CancelFunc) {
+	if timeoutsApplied(ctx) {
+		return ctx, query, func() {}
+	}
+	ctx = context.WithValue(ctx, timeoutsAppliedCtxKey{}, struct{}{})
+
+	timeout, ok := QueryTimeoutFromContext(ctx)
+	if !ok && conn.Config() != nil {
+		timeout = conn.Config().DefaultQueryTimeout
+	}
+	var cause error = ErrQueryTimeout{Limit: QueryTimeoutLimit, Timeout: timeout}
+	if tx := conn.Transaction(); tx.Active() {
+		if v, ok := txTimeouts.Load(tx.ID); ok && !v.(*txTimeoutState).deadline.IsZero() {
+			t := v.(*txTimeoutState)
+			if remaining := time.Until(t.deadline); timeout <= 0 || remaining < timeout {
+				timeout = remaining
+				cause = ErrQueryTimeout{Limit: TransactionTimeoutLimit, Timeout: t.timeout}
+			}
+		}
+	}
+	if timeout == 0 || (timeout < 0 && cause.(ErrQueryTimeout).Limit == QueryTimeoutLimit) {
+		return ctx, query, func() {}
+	}
+
+	if timeout > 0 && !isPreparedStmtExecution(ctx) {
+		if hinter, ok := ConnectionAs[QueryTimeoutHinter](conn); ok {
+			query = hinter.QueryWithTimeoutHint(query, timeout)
+		}
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, cause)
+	return ctx, query, cancel
+}
+
+// withTimeoutCause joins err with the ErrQueryTimeout
+// that caused the deadline of ctx to expire.
+func withTimeoutCause(ctx context.Context, err error) error {
+	if err == nil || errors.As(err, new(ErrQueryTimeout)) {
+		return err
+	}
+	var timeoutErr ErrQueryTimeout
+	if errors.As(context.Cause(ctx), &timeoutErr) {
+		return errors.Join(timeoutErr, err)
+	}
+	return err
+}
+
+func beginWithTimeouts(ctx context.Context, conn Connection, id uint64, opts *sql.TxOptions, next BeginFunc) (Connection, error) {
+	if timeoutsApplied(ctx) {
+		return next(ctx, id, opts)
+	}
+	ctx = context.WithValue(ctx, timeoutsAppliedCtxKey{}, struct{}{})
+
+	var queryTimeout, txTimeout time.Duration
+	if config := conn.Config(); config != nil {
+		queryTimeout = max(config.DefaultQueryTimeout, 0)
+		txTimeout = max(config.DefaultTransactionTimeout, 0)
+	}
+	if t, ok := QueryTimeoutFromContext(ctx); ok {
+		queryTimeout = t
+	}
+	if queryTimeout == 0 && txTimeout == 0 {
+		return next(ctx, id, opts)
+	}
+
+	t := &txTimeoutState{
+		ctx:     context.WithoutCancel(ctx),
+		timeout: txTimeout,
+		cancel:  func() {},
+		reset:   func(context.Context) error { return nil },
+	}
+	txCtx := ctx
+	if txTimeout > 0 {
+		// The context passed to Begin is used until the transaction ends,
+		// database/sql rolls back the transaction when it expires
+		t.deadline = time.Now().Add(txTimeout)
+		txCtx, t.cancel = context.WithDeadlineCause(ctx, t.deadline, ErrQueryTimeout{Limit: TransactionTimeoutLimit, Timeout: txTimeout})
+	}
+	tx, err := next(txCtx, id, opts)
+	if err != nil {
+		t.cancel()
+		return nil, withTimeoutCause(txCtx, err)
+	}
+	if setter, ok := ConnectionAs[TimeoutSetter](tx); ok {
+		reset, err := setter.SetTimeouts(ctx, queryTimeout, txTimeout)
+		if err != nil {
+			t.cancel()
+			return nil, errors.Join(err, tx.Rollback())
+		}
+		if reset != nil {
+			t.reset = reset
+		}
+	}
+	txTimeouts.Store(id, t)
+	return tx, nil
+}
+
+func endTxWithTimeouts(conn Connection, next func() error) error {
+	v, ok := txTimeouts.LoadAndDelete(conn.Transaction().ID)
+	if !ok {
+		return next()
+	}
+	t := v.(*txTimeoutState)
+	defer t.cancel()
+
+	resetErr := t.reset(t.ctx)
+	err := next()
+	if err != nil && !t.deadline.IsZero() && !time.Now().Before(t.deadline) {
+		err = errors.Join(ErrQueryTimeout{Limit: TransactionTimeoutLimit, Timeout: t.timeout}, err)
+	}
+	return errors.Join(resetErr, err)
+}
+
+// timeoutRows releases the statement context
+// when the rows are closed or iterated to the end.
+type timeoutRows struct {
+	Rows
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	// Rows are closed automatically after the last row
+	r.cancel()
+	return false
+}
+
+func (r *timeoutRows) Err() error {
+	return withTimeoutCause(r.ctx, r.Rows.Err())
+}
+
+func (r *timeoutRows) Close() error {
+	err := r.Rows.Close()
+	r.cancel()
+	return withTimeoutCause(r.ctx, err)
+}
@@ -0,0 +1,312 @@
+package sqldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTimeoutTestConn returns a MockConn with the passed default timeouts
+// that logs the setting and resetting of server-side timeouts to its QueryLog.
+func newTimeoutTestConn(queryTimeout, transactionTimeout time.Duration) (*MockConn, *bytes.Buffer) {
+	log := new(bytes.Buffer)
+	conn := NewMockConn(nil).WithQueryLog(log)
+	conn.MockConfig = func() *Config {
+		return &Config{
+			Driver:                    "MockConn",
+			DefaultQueryTimeout:       queryTimeout,
+			DefaultTransactionTimeout: transactionTimeout,
+		}
+	}
+	conn.MockSetTimeouts = func(ctx context.Context, queryTimeout, transactionTimeout time.Duration) (func(context.Context) error, error) {
+		fmt.Fprintf(log, "SET TIMEOUTS %s, %s;\n", queryTimeout, transactionTimeout)
+		return func(context.Context) error {
+			fmt.Fprint(log, "RESET;\n")
+			return nil
+		}, nil
+	}
+	return conn, log
+}
+
+// blockUntilDone is a MockConn.MockExec function that
+// blocks until the context is done.
+func blockUntilDone(ctx context.Context, query string, args ...any) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// timeoutHintConn implements QueryTimeoutHinter for MockConn.
+type timeoutHintConn struct {
+	*MockConn
+}
+
+func (timeoutHintConn) QueryWithTimeoutHint(query string, timeout time.Duration) string {
+	return fmt.Sprintf("/*+ TIMEOUT(%d) */ %s", timeout.Milliseconds(), query)
+}
+
+func TestContextWithQueryTimeout(t *testing.T) {
+	_, ok := QueryTimeoutFromContext(t.Context())
+	assert.False(t, ok)
+
+	timeout, ok := QueryTimeoutFromContext(ContextWithQueryTimeout(t.Context(), time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, timeout)
+
+	timeout, ok = QueryTimeoutFromContext(ContextWithQueryTimeout(t.Context(), -time.Second))
+	assert.True(t, ok, "negative timeout disables default")
+	assert.Equal(t, time.Duration(0), timeout)
+}
+
+func TestErrQueryTimeout(t *testing.T) {
+	err := ErrQueryTimeout{Limit: QueryTimeoutLimit, Timeout: time.Second}
+	assert.Equal(t, "query timeout of 1s exceeded", err.Error())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "transaction timeout exceeded", ErrQueryTimeout{Limit: TransactionTimeoutLimit}.Error())
+}
+
+func TestWithDefaultTimeouts(t *testing.T) {
+	t.Run("no defaults", func(t *testing.T) {
+		conn := NewMockConn(nil)
+		assert.Same(t, conn, WithDefaultTimeouts(conn))
+	})
+
+	t.Run("with defaults", func(t *testing.T) {
+		conn, _ := newTimeoutTestConn(time.Second, 0)
+		assert.NotEqual(t, Connection(conn), WithDefaultTimeouts(conn))
+	})
+}
+
+func TestTimeoutInterceptor(t *testing.T) {
+	t.Run("Exec query timeout", func(t *testing.T) {
+		// given
+		base, _ := newTimeoutTestConn(10*time.Millisecond, 0)
+		base.MockExec = blockUntilDone
+		conn := WithDefaultTimeouts(base)
+
+		// when
+		err := conn.Exec(t.Context(), "SELECT pg_sleep(1)")
+
+		// then
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		var timeoutErr ErrQueryTimeout
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, ErrQueryTimeout{Limit: QueryTimeoutLimit, Timeout: 10 * time.Millisecond}, timeoutErr)
+		assert.Equal(t, "ErrQueryTimeout", ErrorKind(err))
+	})
+
+	t.Run("ExecRowsAffected query timeout", func(t *testing.T) {
+		// given
+		base, _ := newTimeoutTestConn(10*time.Millisecond, 0)
+		base.MockExecRowsAffected = func(ctx context.Context, query string, args ...any) (int64, error) {
+			return 0, blockUntilDone(ctx, query, args...)
+		}
+		conn := WithDefaultTimeouts(base)
+
+		// when
+		_, err := conn.ExecRowsAffected(t.Context(), "DELETE FROM t")
+
+		// then
+		assert.ErrorAs(t, err, new(ErrQueryTimeout))
+	})
+
+	t.Run("Query deadline released after rows", func(t *testing.T) {
+		// given
+		var queryCtx context.Context
+		base, _ := newTimeoutTestConn(time.Minute, 0)
+		base.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			queryCtx = ctx
+			return NewMockRows("v").WithRow(int64(1))
+		}
+		conn := WithDefaultTimeouts(base)
+
+		// when
+		rows := conn.Query(t.Context(), "SELECT v FROM t")
+		require.NoError(t, rows.Err())
+		_, hasDeadline := queryCtx.Deadline()
+		assert.True(t, hasDeadline)
+		assert.NoError(t, queryCtx.Err(), "not canceled while rows are open")
+		for rows.Next() {
+		}
+
+		// then
+		require.NoError(t, rows.Err())
+		require.NoError(t, rows.Close())
+		assert.ErrorIs(t, queryCtx.Err(), context.Canceled)
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		// given
+		base, _ := newTimeoutTestConn(10*time.Millisecond, 0)
+		base.MockQuery = func(ctx context.Context, query string, args ...any) Rows {
+			return NewErrRows(blockUntilDone(ctx, query, args...))
+		}
+		conn := WithDefaultTimeouts(base)
+
+		// when
+		rows := conn.Query(t.Context(), "SELECT pg_sleep(1)")
+
+		// then
+		assert.ErrorAs(t, rows.Err(), new(ErrQueryTimeout))
+	})
+
+	t.Run("context timeout overrides default", func(t *testing.T) {
+		// given
+		var deadline time.Time
+		base, _ := newTimeoutTestConn(time.Hour, 0)
+		base.MockExec = func(ctx context.Context, query string, args ...any) error {
+			deadline, _ = ctx.Deadline()
+			return nil
+		}
+		conn := WithDefaultTimeouts(base)
+
+		// when
+		err := conn.Exec(ContextWithQueryTimeout(t.Context(), time.Minute), "DELETE FROM t")
+
+		// then
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 10*time.Second)
+	})
+
+	t.Run("context timeout without defaults", func(t *testing.T) {
+		// given
+		base := NewMockConn(nil)
+		base.MockExec = blockUntilDone
+		conn := WrapConnection(base, TimeoutInterceptor())
+
+		// when
+		err := conn.Exec(ContextWithQueryTimeout(t.Context(), 10*time.Millisecond), "SELECT pg_sleep(1)")
+
+		// then
+		var timeoutErr ErrQueryTimeout
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, ErrQueryTimeout{Limit: QueryTimeoutLimit, Timeout: 10 * time.Millisecond}, timeoutErr)
+	})
+
+	t.Run("context without timeout disables default", func(t *testing.T) {
+		// given
+		hasDeadline := true
+		base, _ := newTimeoutTestConn(time.Minute, 0)
+		base.MockExec = func(ctx context.Context, query string, args ...any) error {
+			_, hasDeadline = ctx.Deadline()
+			return nil
+		}
+		conn := WithDefaultTimeouts(base)
+
+		// when
+		err := conn.Exec(ContextWithQueryTimeout(t.Context(), 0), "DELETE FROM t")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, hasDeadline)
+	})
+
+	t.Run("query hint", func(t *testing.T) {
+		// given
+		base, log := newTimeoutTestConn(time.Second, 0)
+		conn := WrapConnection(timeoutHintConn{base}, TimeoutInterceptor())
+
+		// when
+		err := conn.Exec(t.Context(), "SELECT 1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "/*+ TIMEOUT(1000) */ SELECT 1;\n", log.String())
+	})
+
+	t.Run("transaction timeouts set and reset", func(t *testing.T) {
+		// given
+		base, log := newTimeoutTestConn(time.Second, time.Minute)
+		conn := WithDefaultTimeouts(base)
+
+		// when
+		err := Transaction(t.Context(), conn, nil, func(tx Connection) error {
+			return tx.Exec(t.Context(), "DELETE FROM t")
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "BEGIN;\nSET TIMEOUTS 1s, 1m0s;\nDELETE FROM t;\nRESET;\nCOMMIT;\n", log.String())
+	})
+
+	t.Run("transaction timeout limits statements", func(t *testing.T) {
+		// given
+		base, _ := newTimeoutTestConn(0, 10*time.Millisecond)
+		base.MockExec = blockUntilDone
+		conn := WithDefaultTimeouts(base)
+
+		// when
+		err := Transaction(t.Context(), conn, nil, func(tx Connection) error {
+			return tx.Exec(t.Context(), "SELECT pg_sleep(1)")
+		})
+
+		// then
+		var timeoutErr ErrQueryTimeout
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, ErrQueryTimeout{Limit: TransactionTimeoutLimit, Timeout: 10 * time.Millisecond}, timeoutErr)
+	})
+
+	t.Run("transaction timeouts released on Close", func(t *testing.T) {
+		// given
+		var txCtx context.Context
+		base, log := newTimeoutTestConn(0, time.Minute)
+		base.MockBegin = func(ctx context.Context, id uint64, opts *sql.TxOptions) (Connection, error) {
+			txCtx = ctx
+			tx := base.Clone()
+			tx.TxID = id
+			return tx, nil
+		}
+		conn := WithDefaultTimeouts(base)
+		id := NextTransactionID()
+		tx, err := conn.Begin(t.Context(), id, nil)
+		require.NoError(t, err)
+
+		// when
+		err = tx.Close()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "BEGIN;\nSET TIMEOUTS 0s, 1m0s;\nRESET;\n", log.String())
+		assert.ErrorIs(t, txCtx.Err(), context.Canceled)
+		_, ok := txTimeouts.Load(id)
+		assert.False(t, ok)
+	})
+
+	t.Run("set timeouts error rolls back", func(t *testing.T) {
+		// given
+		errSet := errors.New("set error")
+		base, log := newTimeoutTestConn(time.Second, 0)
+		base.MockSetTimeouts = func(context.Context, time.Duration, time.Duration) (func(context.Context) error, error) {
+			return nil, errSet
+		}
+		conn := WithDefaultTimeouts(base)
+
+		// when
+		err := Transaction(t.Context(), conn, nil, func(tx Connection) error { return nil })
+
+		// then
+		assert.ErrorIs(t, err, errSet)
+		assert.Equal(t, "BEGIN;\nROLLBACK;\n", log.String())
+	})
+
+	t.Run("applied once when wrapped twice", func(t *testing.T) {
+		// given
+		base, log := newTimeoutTestConn(time.Second, 0)
+		conn := WrapConnection(WithDefaultTimeouts(base), TimeoutInterceptor())
+
+		// when
+		err := Transaction(t.Context(), conn, nil, func(tx Connection) error {
+			return tx.Exec(t.Context(), "DELETE FROM t")
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "BEGIN;\nSET TIMEOUTS 1s, 0s;\nDELETE FROM t;\nRESET;\nCOMMIT;\n", log.String())
+	})
+}
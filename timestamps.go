@@ -0,0 +1,142 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Clock returns the current time for the columns of struct fields
+// with the created or updated tag option (e.g. `db:"updated_at,updated"`).
+//
+// The function time.Now is used if the context has no Clock,
+// see [ContextWithClock]. The signature matches db.CurrentTimestamp
+// to use the CURRENT_TIMESTAMP of the database instead.
+type Clock func(ctx context.Context) time.Time
+
+type clockCtxKey struct{}
+
+// ContextWithClock returns a new context that makes the struct insert,
+// update, and upsert functions use clock for the columns of struct fields
+// with the created or updated tag option instead of time.Now.
+func ContextWithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockCtxKey{}, clock)
+}
+
+// ClockFromContext returns the Clock added to the context
+// with [ContextWithClock] or a Clock returning time.Now().
+func ClockFromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockCtxKey{}).(Clock); ok && clock != nil {
+		return clock
+	}
+	return func(context.Context) time.Time { return time.Now() }
+}
+
+// ignoreCreated is an IgnoreColumnFunc that ignores columns
+// with the created tag option because they are never updated.
+var ignoreCreated = IgnoreColumnFunc(func(column *ColumnInfo) bool {
+	return column.Created
+})
+
+// timestampField is a struct field with the created or updated tag option.
+type timestampField struct {
+	valIndex    int   // index of the value of the field in the query values of a row
+	fieldIndex  []int // index for reflect.Value.FieldByIndex
+	created     bool  // only set if the field has the zero value
+	noWriteBack bool  // value is not written back to the struct field
+}
+
+// timestampFields are the struct fields of a row that are
+// set to the time of the Clock from the context when the row is written.
+type timestampFields []timestampField
+
+// timestampFieldsOf returns the timestamp fields for the columns
+// of structType in the order of the query values of a row.
+// Fields with the created tag option are only included if insert is true.
+func timestampFieldsOf(refl StructReflector, structType reflect.Type, columns []ColumnInfo, insert bool) (timestampFields, error) {
+	var fields timestampFields
+	for i, col := range columns {
+		if !(col.Created && insert) && !col.Updated {
+			continue
+		}
+		if col.PrimaryKey {
+			return nil, fmt.Errorf("timestamp column %s of %s can't be a primary key column", col.Name, structType)
+		}
+		fieldIndex, err := structFieldIndex(refl, structType, col.Name)
+		if err != nil {
+			return nil, err
+		}
+		fieldType := structType.FieldByIndex(fieldIndex).Type
+		if fieldType != typeOfTime && fieldType != typeOfTimePtr && !reflect.PointerTo(fieldType).Implements(typeOfSQLScanner) {
+			return nil, fmt.Errorf("timestamp field for column %s of %s must be time.Time, *time.Time, or implement sql.Scanner, but is %s", col.Name, structType, fieldType)
+		}
+		fields = append(fields, timestampField{valIndex: i, fieldIndex: fieldIndex, created: col.Created})
+	}
+	return fields, nil
+}
+
+// forUpsert returns the fields with the created fields
+// excluded from writeBack, because the created column
+// of an existing row is not updated by an upsert
+// and the written time would differ from the stored one.
+func (f timestampFields) forUpsert() timestampFields {
+	for i := range f {
+		f[i].noWriteBack = f[i].created
+	}
+	return f
+}
+
+// now returns the time of the Clock from the context
+// or the zero time if there are no timestamp fields.
+func (f timestampFields) now(ctx context.Context) time.Time {
+	if len(f) == 0 {
+		return time.Time{}
+	}
+	return ClockFromContext(ctx)(ctx)
+}
+
+// setValues sets the values of the timestamp fields
+// in the query values of the row of structVal to now.
+// Fields with the created tag option keep non-zero values.
+// Fields of nil inline struct pointers stay NULL.
+func (f timestampFields) setValues(structVal reflect.Value, vals []any, now time.Time) {
+	for _, field := range f {
+		v, err := structVal.FieldByIndexErr(field.fieldIndex)
+		if err != nil || field.created && !v.IsZero() {
+			continue
+		}
+		vals[field.valIndex] = now
+	}
+}
+
+// writeBack sets the timestamp fields of structVal to now
+// like setValues if structVal is addressable.
+func (f timestampFields) writeBack(structVal reflect.Value, now time.Time) error {
+	if !structVal.CanAddr() {
+		return nil
+	}
+	for _, field := range f {
+		if field.noWriteBack {
+			continue
+		}
+		v, err := structVal.FieldByIndexErr(field.fieldIndex)
+		if err != nil || field.created && !v.IsZero() {
+			continue
+		}
+		switch v.Type() {
+		case typeOfTime:
+			v.Set(reflect.ValueOf(now))
+		case typeOfTimePtr:
+			t := now
+			v.Set(reflect.ValueOf(&t))
+		default:
+			err := v.Addr().Interface().(sql.Scanner).Scan(now)
+			if err != nil {
+				return fmt.Errorf("can't set timestamp field of %s: %w", structVal.Type(), err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timestampsTestStruct struct {
+	TableName `db:"timestamps_table"`
+
+	ID        int64      `db:"id,primarykey"`
+	Name      string     `db:"name"`
+	CreatedAt time.Time  `db:"created_at,created"`
+	UpdatedAt *time.Time `db:"updated_at,updated"`
+}
+
+var timestampsTestNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// timestampsTestContext returns a context with a Clock
+// that always returns timestampsTestNow.
+func timestampsTestContext(t *testing.T) context.Context {
+	return ContextWithClock(t.Context(), func(context.Context) time.Time { return timestampsTestNow })
+}
+
+func TestInsertRowStruct_Timestamps(t *testing.T) {
+	wantQuery := "INSERT INTO timestamps_table(id,name,created_at,updated_at) VALUES($1,$2,$3,$4)"
+
+	t.Run("sets zero timestamps", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		row := &timestampsTestStruct{ID: 1, Name: "Alice"}
+
+		// when
+		err := InsertRowStruct(timestampsTestContext(t), conn, refl, builder, fmtr, row)
+		// Second insert uses the cached query
+		err2 := InsertRowStruct(timestampsTestContext(t), conn, refl, builder, fmtr, &timestampsTestStruct{ID: 2})
+
+		// then
+		require.NoError(t, err)
+		require.NoError(t, err2)
+		assert.Equal(t, timestampsTestNow, row.CreatedAt)
+		require.NotNil(t, row.UpdatedAt)
+		assert.Equal(t, timestampsTestNow, *row.UpdatedAt)
+		require.Len(t, conn.Recordings.Execs, 2)
+		assert.Equal(t, wantQuery, conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{int64(1), "Alice", timestampsTestNow, timestampsTestNow}, conn.Recordings.Execs[0].Args)
+		assert.Equal(t, []any{int64(2), "", timestampsTestNow, timestampsTestNow}, conn.Recordings.Execs[1].Args)
+	})
+
+	t.Run("keeps non-zero created", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		updated := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		row := &timestampsTestStruct{ID: 1, CreatedAt: created, UpdatedAt: &updated}
+
+		// when
+		err := InsertRowStruct(timestampsTestContext(t), conn, refl, builder, fmtr, row)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, created, row.CreatedAt)
+		assert.Equal(t, timestampsTestNow, *row.UpdatedAt)
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), updated, "previous updated time must not be modified")
+		assert.Equal(t, []any{int64(1), "", created, timestampsTestNow}, conn.Recordings.Execs[0].Args)
+	})
+
+	t.Run("non-pointer", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		row := timestampsTestStruct{ID: 1}
+
+		// when
+		err := InsertRowStruct(timestampsTestContext(t), conn, refl, builder, fmtr, row)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, row.CreatedAt.IsZero())
+		assert.Equal(t, []any{int64(1), "", timestampsTestNow, timestampsTestNow}, conn.Recordings.Execs[0].Args)
+	})
+
+	t.Run("sql.Scanner field", func(t *testing.T) {
+		type scannerTimestamps struct {
+			TableName `db:"timestamps_table"`
+
+			ID        int64        `db:"id,primarykey"`
+			UpdatedAt sql.NullTime `db:"updated_at,updated"`
+		}
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		row := &scannerTimestamps{ID: 1}
+
+		// when
+		err := InsertRowStruct(timestampsTestContext(t), conn, refl, builder, fmtr, row)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, sql.NullTime{Time: timestampsTestNow, Valid: true}, row.UpdatedAt)
+	})
+
+	t.Run("invalid field type", func(t *testing.T) {
+		type stringTimestamps struct {
+			TableName `db:"timestamps_table"`
+
+			ID        int64  `db:"id,primarykey"`
+			UpdatedAt string `db:"updated_at,updated"`
+		}
+		conn, refl, builder, fmtr := newTestInterfaces()
+		err := InsertRowStruct(t.Context(), conn, refl, builder, fmtr, &stringTimestamps{ID: 1})
+		require.Error(t, err)
+		assert.Empty(t, conn.Recordings.Execs)
+	})
+
+	t.Run("InsertRowStructs", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		rows := []timestampsTestStruct{{ID: 1}, {ID: 2}}
+
+		// when
+		err := InsertRowStructs(timestampsTestContext(t), conn, refl, builder, fmtr, rows)
+
+		// then
+		require.NoError(t, err)
+		for _, row := range rows {
+			assert.Equal(t, timestampsTestNow, row.CreatedAt)
+			assert.Equal(t, timestampsTestNow, *row.UpdatedAt)
+		}
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, []any{int64(1), "", timestampsTestNow, timestampsTestNow, int64(2), "", timestampsTestNow, timestampsTestNow}, conn.Recordings.Execs[0].Args)
+	})
+
+	t.Run("InsertRowStructStmt", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		insertFunc, closeStmt, err := InsertRowStructStmt[*timestampsTestStruct](timestampsTestContext(t), conn, refl, builder, fmtr)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, closeStmt()) })
+		row := &timestampsTestStruct{ID: 1}
+
+		// when
+		err = insertFunc(timestampsTestContext(t), row)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, timestampsTestNow, row.CreatedAt)
+		assert.Equal(t, timestampsTestNow, *row.UpdatedAt)
+	})
+}
+
+func TestUpdateRowStruct_Timestamps(t *testing.T) {
+	t.Run("sets updated and keeps created", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(1)
+		created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		row := &timestampsTestStruct{ID: 1, Name: "Alice", CreatedAt: created}
+
+		// when
+		err := UpdateRowStruct(timestampsTestContext(t), conn, refl, builder, fmtr, row)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, created, row.CreatedAt)
+		assert.Equal(t, timestampsTestNow, *row.UpdatedAt)
+		require.Len(t, conn.Recordings.Execs, 1)
+		assert.Equal(t, "UPDATE timestamps_table SET name=$1, updated_at=$2 WHERE id = $3", conn.Recordings.Execs[0].Query)
+		assert.Equal(t, []any{"Alice", timestampsTestNow, int64(1)}, conn.Recordings.Execs[0].Args)
+	})
+
+	t.Run("UpdateRowStructStmt", func(t *testing.T) {
+		// given
+		conn, refl, builder, fmtr := newTestInterfaces()
+		conn.MockExecRowsAffected = rowsAffected(1)
+		updateFunc, closeStmt, err := UpdateRowStructStmt[*timestampsTestStruct](t.Context(), conn, refl, builder, fmtr)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, closeStmt()) })
+		row := &timestampsTestStruct{ID: 1}
+
+		// when
+		err = updateFunc(timestampsTestContext(t), row)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, row.CreatedAt.IsZero())
+		assert.Equal(t, timestampsTestNow, *row.UpdatedAt)
+	})
+}
+
+func TestUpsertRowStruct_Timestamps(t *testing.T) {
+	// given
+	conn, refl, _, fmtr := newTestInterfaces()
+	builder := testUpsertOptionsBuilder{}
+	conn.MockExecRowsAffected = rowsAffected(1)
+	row := &timestampsTestStruct{ID: 1, Name: "Alice"}
+
+	// when
+	err := UpsertRowStruct(timestampsTestContext(t), conn, refl, builder, fmtr, row, UpsertOptions{UpdateColumns: []string{"name"}})
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, row.CreatedAt.IsZero(), "created time of an existing row is unknown")
+	assert.Equal(t, timestampsTestNow, *row.UpdatedAt)
+	require.Len(t, conn.Recordings.Execs, 1)
+	assert.Equal(t, "INSERT INTO timestamps_table(id,name,created_at,updated_at) VALUES($1,$2,$3,$4) ON CONFLICT(id) DO UPDATE SET name=$2, updated_at=$4", conn.Recordings.Execs[0].Query)
+	assert.Equal(t, []any{int64(1), "Alice", timestampsTestNow, timestampsTestNow}, conn.Recordings.Execs[0].Args)
+}
+
+func TestClockFromContext(t *testing.T) {
+	before := time.Now()
+	now := ClockFromContext(t.Context())(t.Context())
+	assert.False(t, now.Before(before))
+	assert.Equal(t, timestampsTestNow, ClockFromContext(timestampsTestContext(t))(t.Context()))
+}
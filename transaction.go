@@ -44,14 +44,25 @@ func Transaction(ctx context.Context, parentConn Connection, opts *sql.TxOptions
 // If parentConn is already a transaction, a brand new transaction will begin on the parent's connection.
 // Errors and panics from txFunc will rollback the transaction.
 // Recovered panics are re-panicked after rollback.
+// Session variables added to ctx with [ContextWithSessionVars]
+// are set for the transaction after it began.
 func IsolatedTransaction(ctx context.Context, parentConn Connection, opts *sql.TxOptions, txFunc func(tx Connection) error) (err error) {
 	id := NextTransactionID()
 	tx, e := parentConn.Begin(ctx, id, opts)
 	if e != nil {
 		return fmt.Errorf("transaction %d BEGIN error: %w", id, e)
 	}
+	resetSessionVars, e := setSessionVarsFromContext(ctx, tx)
+	if e != nil {
+		return errors.Join(fmt.Errorf("transaction %d session variables error: %w", id, e), tx.Rollback())
+	}
 
 	defer func() {
+		if e := resetSessionVars(ctx); e != nil {
+			// Don't commit if the session variables could leak
+			err = errors.Join(err, fmt.Errorf("transaction %d session variables reset error: %w", id, e))
+		}
+
 		if r := recover(); r != nil {
 			// txFunc panicked
 			e := tx.Rollback()
@@ -75,7 +86,7 @@ func IsolatedTransaction(ctx context.Context, parentConn Connection, opts *sql.T
 		e := tx.Commit()
 		if e != nil {
 			// Set Commit error as function return value
-			err = fmt.Errorf("transaction %d COMMIT error: %w", id, e)
+			err = fmt.Errorf("transaction %d COMMIT error: %w", id, commitError{e})
 		}
 	}()
 
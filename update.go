@@ -95,6 +95,21 @@ func UpdateReturningRows(ctx context.Context, conn Querier, builder ReturningQue
 // Struct fields can be filtered with options like [IgnoreColumns] or [OnlyColumns].
 // The struct must have at least one field with a `db` tag value having a ",primarykey" suffix
 // to mark primary key column(s).
+//
+// If the struct has a field with a `db` tag value having a ",version" suffix,
+// then rowStruct must be a pointer and the row is only updated if its
+// version column still has the value of the field (optimistic locking).
+// The version column is incremented by the update and the field
+// is set to the new version. If no row was updated,
+// then an [ErrStaleRow] error is returned.
+//
+// Fields with a `db` tag value having a ",updated" suffix are updated
+// with the time of the [Clock] from ctx (default time.Now), see [ContextWithClock].
+// If rowStruct is a pointer, then the fields are set to that time after the update.
+// Fields with a ",created" suffix are never updated.
+//
+// Columns of [Patch] fields that are not set are not updated,
+// see [PatchField].
 func UpdateRowStruct(ctx context.Context, conn Executor, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rowStruct StructWithTableName, options ...QueryOption) error {
 	if refl == nil {
 		return errors.New("UpdateRowStruct: nil StructReflector")
@@ -103,6 +118,13 @@ func UpdateRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 	if err != nil {
 		return err
 	}
+	_, err = updateRowStruct(ctx, conn, refl, builder, fmtr, structVal, options)
+	return err
+}
+
+// updateRowStruct also returns the updated version
+// of structs with a version field.
+func updateRowStruct(ctx context.Context, conn Executor, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, structVal reflect.Value, options []QueryOption) (rowVersion, error) {
 	structType := structVal.Type()
 
 	var vals []any
@@ -117,39 +139,75 @@ func UpdateRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 		if ok {
 			vals = make([]any, len(cached.structFieldIndices))
 			for i, fieldIndex := range cached.structFieldIndices {
-				vals[i] = structVal.FieldByIndex(fieldIndex).Interface()
+				vals[i] = structFieldValue(refl, structVal, fieldIndex, &cached.columns[i])
 			}
-			err = conn.Exec(ctx, cached.query, vals...)
-			if err != nil {
-				return WrapErrorWithQuery(err, cached.query, vals, fmtr)
+			var version rowVersion
+			if cached.versionIndex >= 0 {
+				var err error
+				version, err = newRowVersion(structVal, cached.structFieldIndices[cached.versionIndex])
+				if err != nil {
+					return rowVersion{}, err
+				}
+				vals[cached.versionIndex] = version.next.Interface()
 			}
-			return nil
+			return version, execUpdateRowStruct(ctx, conn, refl, fmtr, structVal, cached, version, vals)
 		}
 	}
-	var cached queryCache
-	var columns []ColumnInfo
-	columns, cached.structFieldIndices, vals, err = refl.ReflectStructColumnsFieldIndicesAndValues(structVal, append(options, IgnoreReadOnly)...)
+	var (
+		cached  queryCache
+		columns []ColumnInfo
+		version rowVersion
+		err     error
+	)
+	columns, cached.structFieldIndices, vals, err = refl.ReflectStructColumnsFieldIndicesAndValues(structVal, append(options, IgnoreReadOnly, ignoreCreated)...)
 	if err != nil {
-		return err
+		return rowVersion{}, err
+	}
+	if hasPatchColumns(columns) {
+		// The query depends on which Patch fields are set
+		useCache = false
+		columns, cached.structFieldIndices, vals = withoutUnsetPatches(columns, cached.structFieldIndices, vals)
 	}
 	table, err := refl.TableNameForStruct(structType)
 	if err != nil {
-		return err
+		return rowVersion{}, err
 	}
 	hasPK := slices.ContainsFunc(columns, func(col ColumnInfo) bool {
 		return col.PrimaryKey
 	})
 	if !hasPK {
-		return fmt.Errorf("UpdateRowStruct of table %s: %s has no mapped primary key field", table, structType)
+		return rowVersion{}, fmt.Errorf("UpdateRowStruct of table %s: %s has no mapped primary key field", table, structType)
+	}
+	versionIndex, err := versionColumnIndex(table, columns)
+	if err != nil {
+		return rowVersion{}, err
+	}
+	if versionIndex >= 0 {
+		version, err = newRowVersion(structVal, cached.structFieldIndices[versionIndex])
+		if err != nil {
+			return rowVersion{}, err
+		}
+		// The version column is set to the incremented version
+		// if the appended WHERE column still has the current version
+		columns = versionCondition(columns, versionIndex)
+		cached.structFieldIndices = append(cached.structFieldIndices, cached.structFieldIndices[versionIndex])
+		vals = append(vals, vals[versionIndex])
+		vals[versionIndex] = version.next.Interface()
 	}
 	cached.query, err = builder.UpdateColumns(fmtr, table, columns)
 	if err != nil {
-		return err
+		return rowVersion{}, err
 	}
 	// Reorder field indices and values: non-PK first, then PK,
 	// matching the placeholder order in UpdateColumns.
 	cached.structFieldIndices = reorderForUpdate(columns, cached.structFieldIndices)
+	cached.columns = reorderForUpdate(columns, columns)
 	vals = reorderForUpdate(columns, vals)
+	cached.versionIndex = versionIndexForUpdate(columns, versionIndex)
+	cached.timestamps, err = timestampFieldsOf(refl, structType, cached.columns, false)
+	if err != nil {
+		return rowVersion{}, err
+	}
 	if useCache {
 		updateRowStructQueryCacheMtx.Lock()
 		if _, ok := updateRowStructQueryCache[structType]; !ok {
@@ -165,11 +223,29 @@ func UpdateRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 		updateRowStructQueryCacheMtx.Unlock()
 	}
 
-	err = conn.Exec(ctx, cached.query, vals...)
-	if err != nil {
-		return WrapErrorWithQuery(err, cached.query, vals, fmtr)
+	return version, execUpdateRowStruct(ctx, conn, refl, fmtr, structVal, cached, version, vals)
+}
+
+// execUpdateRowStruct executes the cached UPDATE or UPSERT query of a row struct
+// with optimistic locking if the query has a version column
+// and sets the timestamp fields of the struct after the row was written.
+func execUpdateRowStruct(ctx context.Context, conn Executor, refl StructReflector, fmtr QueryFormatter, structVal reflect.Value, cached queryCache, version rowVersion, vals []any) error {
+	now := cached.timestamps.now(ctx)
+	cached.timestamps.setValues(structVal, vals, now)
+	if cached.versionIndex >= 0 {
+		err := execVersioned(refl, fmtr, structVal, version, cached.query, vals, func() (int64, error) {
+			return conn.ExecRowsAffected(ctx, cached.query, vals...)
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		err := conn.Exec(ctx, cached.query, vals...)
+		if err != nil {
+			return WrapErrorWithQuery(err, cached.query, vals, fmtr)
+		}
 	}
-	return nil
+	return cached.timestamps.writeBack(structVal, now)
 }
 
 // UpdateRowStructStmt prepares an UPDATE statement for the struct type S
@@ -179,12 +255,36 @@ func UpdateRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 // Column names are derived from the `db` struct tags of the struct's fields.
 // The struct must have at least one field with a `db` tag value having a ",primarykey" suffix
 // to mark primary key column(s).
+// Structs with a version field are updated with optimistic locking
+// like with [UpdateRowStruct], which requires S to be a pointer type.
+// Fields with the updated tag option are updated with the time
+// of the [Clock] from ctx and fields with the created tag option
+// are not updated like with [UpdateRowStruct].
+// Structs with [Patch] fields are not supported because
+// the updated columns depend on which fields are set.
 // The returned closeStmt function must be called to release the prepared statement.
 func UpdateRowStructStmt[S StructWithTableName](ctx context.Context, conn Preparer, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, options ...QueryOption) (updateFunc func(ctx context.Context, rowStruct S) error, closeStmt func() error, err error) {
 	if refl == nil {
 		return nil, nil, errors.New("UpdateRowStructStmt: nil StructReflector")
 	}
-	structType := reflect.TypeFor[S]()
+	update, closeStmt, err := updateRowStructStmt(ctx, conn, refl, builder, fmtr, reflect.TypeFor[S](), options)
+	if err != nil {
+		return nil, nil, err
+	}
+	updateFunc = func(ctx context.Context, rowStruct S) error {
+		v, err := derefStruct(reflect.ValueOf(rowStruct))
+		if err != nil {
+			return err
+		}
+		_, err = update(ctx, v)
+		return err
+	}
+	return updateFunc, closeStmt, nil
+}
+
+// updateRowStructStmt returns an updateFunc that also returns
+// the updated version of structs with a version field.
+func updateRowStructStmt(ctx context.Context, conn Preparer, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, structType reflect.Type, options []QueryOption) (updateFunc func(ctx context.Context, structVal reflect.Value) (rowVersion, error), closeStmt func() error, err error) {
 	for structType.Kind() == reflect.Pointer {
 		structType = structType.Elem()
 	}
@@ -193,7 +293,7 @@ func UpdateRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 		return nil, nil, err
 	}
 
-	options = append(options, IgnoreReadOnly)
+	options = append(options, IgnoreReadOnly, ignoreCreated)
 	columns, err := refl.ReflectStructColumns(structType, options...)
 	if err != nil {
 		return nil, nil, err
@@ -204,6 +304,25 @@ func UpdateRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 	if !hasPK {
 		return nil, nil, fmt.Errorf("UpdateRowStructStmt of table %s: %s has no mapped primary key field", table, structType)
 	}
+	if hasPatchColumns(columns) {
+		return nil, nil, fmt.Errorf("UpdateRowStructStmt of table %s: %s has Patch fields that can't be updated with a prepared statement", table, structType)
+	}
+	versionIndex, err := versionColumnIndex(table, columns)
+	if err != nil {
+		return nil, nil, err
+	}
+	timestamps, err := timestampFieldsOf(refl, structType, columns, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	var versionFieldIndex []int
+	if versionIndex >= 0 {
+		versionFieldIndex, err = structFieldIndex(refl, structType, columns[versionIndex].Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		columns = versionCondition(columns, versionIndex)
+	}
 
 	query, err := builder.UpdateColumns(fmtr, table, columns)
 	if err != nil {
@@ -215,23 +334,37 @@ func UpdateRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 		return nil, nil, fmt.Errorf("UpdateRowStructStmt of table %s: failed to prepare UPDATE statement: %w", table, err)
 	}
 
-	updateFunc = func(ctx context.Context, rowStruct S) error {
-		v, err := derefStruct(reflect.ValueOf(rowStruct))
+	updateFunc = func(ctx context.Context, v reflect.Value) (rowVersion, error) {
+		vals, err := refl.ReflectStructValues(v, options...)
 		if err != nil {
-			return err
+			return rowVersion{}, err
 		}
-		vals, err := refl.ReflectStructValues(v, options...)
+		now := timestamps.now(ctx)
+		timestamps.setValues(v, vals, now)
+		if versionIndex < 0 {
+			// Reorder values: non-PK first, then PK,
+			// matching the placeholder order in UpdateColumns.
+			vals = reorderForUpdate(columns, vals)
+			err = stmt.Exec(ctx, vals...)
+			if err != nil {
+				return rowVersion{}, WrapErrorWithQuery(err, query, vals, fmtr)
+			}
+			return rowVersion{}, timestamps.writeBack(v, now)
+		}
+		version, err := newRowVersion(v, versionFieldIndex)
 		if err != nil {
-			return err
+			return rowVersion{}, err
 		}
-		// Reorder values: non-PK first, then PK,
-		// matching the placeholder order in UpdateColumns.
+		vals = append(vals, vals[versionIndex])
+		vals[versionIndex] = version.next.Interface()
 		vals = reorderForUpdate(columns, vals)
-		err = stmt.Exec(ctx, vals...)
+		err = execVersioned(refl, fmtr, v, version, query, vals, func() (int64, error) {
+			return stmt.ExecRowsAffected(ctx, vals...)
+		})
 		if err != nil {
-			return WrapErrorWithQuery(err, query, vals, fmtr)
+			return rowVersion{}, err
 		}
-		return nil
+		return version, timestamps.writeBack(v, now)
 	}
 	return updateFunc, stmt.Close, nil
 }
@@ -261,31 +394,72 @@ func reorderForUpdate[T any](columns []ColumnInfo, vals []T) []T {
 // Column names are derived from the `db` struct tags of the struct's fields.
 // The struct must have at least one field with a `db` tag value having a ",primarykey" suffix
 // to mark primary key column(s).
+// Structs with a version field are updated with optimistic locking
+// like with [UpdateRowStruct] and the version fields of the slice
+// elements are set to the new versions.
+// Timestamp fields are handled like with [UpdateRowStruct].
+// Structs with [Patch] fields are updated one by one
+// without a prepared statement.
 func UpdateRowStructs[S StructWithTableName](ctx context.Context, conn Connection, refl StructReflector, builder QueryBuilder, fmtr QueryFormatter, rowStructs []S, options ...QueryOption) error {
 	if refl == nil {
 		return errors.New("UpdateRowStructs: nil StructReflector")
 	}
+	// Pointers to the slice elements so that the version fields
+	// of non-pointer structs can be updated
+	structVals := make([]reflect.Value, len(rowStructs))
+	for i := range rowStructs {
+		structVal, err := derefStruct(reflect.ValueOf(&rowStructs[i]))
+		if err != nil {
+			return err
+		}
+		structVals[i] = structVal
+	}
 	switch len(rowStructs) {
 	case 0:
 		return nil
 	case 1:
-		return UpdateRowStruct(ctx, conn, refl, builder, fmtr, rowStructs[0], options...)
+		_, err := updateRowStruct(ctx, conn, refl, builder, fmtr, structVals[0], options)
+		return err
 	}
-	return Transaction(ctx, conn, nil, func(tx Connection) (err error) {
-		updateFunc, closeStmt, stmtErr := UpdateRowStructStmt[S](ctx, tx, refl, builder, fmtr, options...)
-		if stmtErr != nil {
-			return stmtErr
+	columns, err := refl.ReflectStructColumns(structVals[0].Type(), options...)
+	if err != nil {
+		return err
+	}
+	var updatedVersions []rowVersion
+	err = Transaction(ctx, conn, nil, func(tx Connection) (err error) {
+		updateFunc := func(ctx context.Context, structVal reflect.Value) (rowVersion, error) {
+			return updateRowStruct(ctx, tx, refl, builder, fmtr, structVal, options)
+		}
+		// Structs with Patch fields are updated one by one
+		// because the query depends on which fields are set
+		if !hasPatchColumns(columns) {
+			var closeStmt func() error
+			updateFunc, closeStmt, err = updateRowStructStmt(ctx, tx, refl, builder, fmtr, reflect.TypeFor[S](), options)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				err = errors.Join(err, closeStmt())
+			}()
 		}
-		defer func() {
-			err = errors.Join(err, closeStmt())
-		}()
 
-		for _, rowStruct := range rowStructs {
-			err = updateFunc(ctx, rowStruct)
+		for _, structVal := range structVals {
+			version, err := updateFunc(ctx, structVal)
 			if err != nil {
 				return err
 			}
+			if version.field.IsValid() {
+				updatedVersions = append(updatedVersions, version)
+			}
 		}
 		return nil
 	})
+	if err != nil {
+		// The transaction was rolled back
+		for _, version := range updatedVersions {
+			version.reset()
+		}
+		return err
+	}
+	return nil
 }
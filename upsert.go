@@ -4,10 +4,218 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
 	"slices"
 )
 
+// UpsertOptions configure how [UpsertOptionsQueryBuilder.UpsertWithOptions]
+// handles a conflict with an existing row.
+// The zero value updates all inserted columns except the primary key columns
+// with their proposed values if the primary key columns conflict,
+// like [UpsertQueryBuilder.Upsert].
+//
+// UpsertOptions also implement [QueryOption] so that they can be passed
+// to [UpsertRowStruct], [UpsertRowStructStmt], and [UpsertRowStructs],
+// which requires their builder to implement [UpsertOptionsQueryBuilder].
+//
+// UpdateExpressions and Where are SQL of the database
+// which references the existing and the proposed row differently:
+//   - PostgreSQL/SQLite: existing row as table.column, proposed row as excluded.column
+//   - MySQL: existing row as column, proposed row as VALUES(column)
+//   - MSSQL/Oracle: existing row as target.column, proposed row as source.column
+//
+// SECURITY: UpdateExpressions and Where are concatenated into the
+// generated SQL verbatim and are NOT parameterized or validated.
+// They must be static SQL written by the developer.
+type UpsertOptions struct {
+	// ConflictColumns identify the existing row that conflicts
+	// with the proposed row. Defaults to the primary key columns.
+	// PostgreSQL and SQLite need a unique index or constraint
+	// on exactly these columns, MSSQL and Oracle use them as MERGE ON keys.
+	// MySQL ignores them because ON DUPLICATE KEY UPDATE
+	// detects conflicts on all unique indexes of the table.
+	ConflictColumns []string
+
+	// UpdateColumns are the inserted columns that are updated
+	// with their proposed values on conflict.
+	// If UpdateColumns and UpdateExpressions are empty,
+	// then all inserted columns except the ConflictColumns are updated.
+	UpdateColumns []string
+
+	// UpdateExpressions maps column names to SQL expressions
+	// that are assigned to the columns on conflict
+	// in the order of the column names after the UpdateColumns.
+	// Example for PostgreSQL: {"count": "counter.count + excluded.count"}
+	UpdateExpressions map[string]string
+
+	// Where is a boolean SQL expression that must be true
+	// to update the existing row on conflict.
+	// It must NOT include the WHERE keyword.
+	// Example for PostgreSQL: "excluded.version > doc.version"
+	Where string
+
+	// VersionColumn is the inserted column used for optimistic locking.
+	// If set, it is always updated on conflict and the existing row
+	// is only updated if its version is the proposed version minus one,
+	// see [UpsertOptions.VersionWhere].
+	// [UpsertRowStruct] sets it to the column of a struct field
+	// tagged with the version option.
+	VersionColumn string
+}
+
+// QueryOption implements the [QueryOption] interface.
+func (UpsertOptions) QueryOption() {}
+
+// UpsertAssignment is a column assignment of the update
+// on conflict of an upsert, see [UpsertOptions.Resolve].
+type UpsertAssignment struct {
+	Column string
+	// ColumnIndex is the index of the inserted column
+	// whose proposed value is assigned,
+	// or -1 if Expression is assigned.
+	ColumnIndex int
+	Expression  string
+}
+
+// Resolve returns the conflict columns and the column assignments
+// of the update on conflict of an upsert with the inserted columns.
+// It is used by [UpsertOptionsQueryBuilder] implementations
+// and returns an error if a column is not inserted,
+// or if a conflict column or a column with the created tag option
+// would be updated, or if there is no column to update.
+// Columns with the created tag option are never updated
+// and columns with the updated tag option are always updated.
+func (o UpsertOptions) Resolve(columns []ColumnInfo) (conflictColumns []string, assignments []UpsertAssignment, err error) {
+	columnIndex := func(name string) int {
+		return slices.IndexFunc(columns, func(col ColumnInfo) bool { return col.Name == name })
+	}
+
+	conflictColumns = o.ConflictColumns
+	if len(conflictColumns) == 0 {
+		for i := range columns {
+			if columns[i].PrimaryKey {
+				conflictColumns = append(conflictColumns, columns[i].Name)
+			}
+		}
+		if len(conflictColumns) == 0 {
+			return nil, nil, errors.New("Upsert requires a primary key column or UpsertOptions.ConflictColumns")
+		}
+	}
+	for _, col := range conflictColumns {
+		if columnIndex(col) < 0 {
+			return nil, nil, fmt.Errorf("Upsert conflict column %q is not an inserted column", col)
+		}
+	}
+	if o.VersionColumn != "" {
+		if columnIndex(o.VersionColumn) < 0 {
+			return nil, nil, fmt.Errorf("Upsert version column %q is not an inserted column", o.VersionColumn)
+		}
+		if slices.Contains(conflictColumns, o.VersionColumn) {
+			return nil, nil, fmt.Errorf("Upsert version column %q can't be a conflict column", o.VersionColumn)
+		}
+	}
+
+	if len(o.UpdateColumns) == 0 && len(o.UpdateExpressions) == 0 {
+		for i := range columns {
+			if !slices.Contains(conflictColumns, columns[i].Name) && !columns[i].Created {
+				assignments = append(assignments, UpsertAssignment{Column: columns[i].Name, ColumnIndex: i})
+			}
+		}
+		if len(assignments) == 0 {
+			if len(o.ConflictColumns) == 0 {
+				return nil, nil, errors.New("Upsert requires at least one non-primary-key column")
+			}
+			return nil, nil, errors.New("Upsert requires at least one column that is not a conflict column")
+		}
+		return conflictColumns, assignments, nil
+	}
+
+	for _, col := range o.UpdateColumns {
+		i := columnIndex(col)
+		if i < 0 {
+			return nil, nil, fmt.Errorf("Upsert update column %q is not an inserted column", col)
+		}
+		assignments = append(assignments, UpsertAssignment{Column: col, ColumnIndex: i})
+	}
+	for _, col := range slices.Sorted(maps.Keys(o.UpdateExpressions)) {
+		assignments = append(assignments, UpsertAssignment{Column: col, ColumnIndex: -1, Expression: o.UpdateExpressions[col]})
+	}
+	for i, a := range assignments {
+		if slices.Contains(conflictColumns, a.Column) {
+			return nil, nil, fmt.Errorf("Upsert can't update conflict column %q", a.Column)
+		}
+		if ci := columnIndex(a.Column); ci >= 0 && columns[ci].Created {
+			return nil, nil, fmt.Errorf("Upsert can't update created column %q", a.Column)
+		}
+		if slices.ContainsFunc(assignments[:i], func(b UpsertAssignment) bool { return b.Column == a.Column }) {
+			return nil, nil, fmt.Errorf("Upsert updates column %q more than once", a.Column)
+		}
+	}
+	if o.VersionColumn != "" && !slices.ContainsFunc(assignments, func(a UpsertAssignment) bool { return a.Column == o.VersionColumn }) {
+		assignments = append(assignments, UpsertAssignment{Column: o.VersionColumn, ColumnIndex: columnIndex(o.VersionColumn)})
+	}
+	for i := range columns {
+		if columns[i].Updated && !slices.ContainsFunc(assignments, func(a UpsertAssignment) bool { return a.Column == columns[i].Name }) {
+			assignments = append(assignments, UpsertAssignment{Column: columns[i].Name, ColumnIndex: i})
+		}
+	}
+	return conflictColumns, assignments, nil
+}
+
+// VersionWhere returns the Where condition of the options
+// combined with the optimistic locking condition
+//
+//	existingVersion = proposedVersion - 1
+//
+// if VersionColumn is set, else just the Where condition.
+// existingVersion and proposedVersion are the SQL references
+// to the VersionColumn of the existing and the proposed row
+// and are used by [UpsertOptionsQueryBuilder] implementations.
+func (o UpsertOptions) VersionWhere(existingVersion, proposedVersion string) string {
+	if o.VersionColumn == "" {
+		return o.Where
+	}
+	condition := fmt.Sprintf("%s = %s - 1", existingVersion, proposedVersion)
+	if o.Where == "" {
+		return condition
+	}
+	return fmt.Sprintf("(%s) AND %s", o.Where, condition)
+}
+
+func (o UpsertOptions) isZero() bool {
+	return len(o.ConflictColumns) == 0 &&
+		len(o.UpdateColumns) == 0 &&
+		len(o.UpdateExpressions) == 0 &&
+		o.Where == "" &&
+		o.VersionColumn == ""
+}
+
+// upsertQuery returns the upsert query built with the
+// [UpsertOptionsQueryBuilder] implementation of builder,
+// or with builder.Upsert if builder does not implement it
+// and the options are the zero value.
+func upsertQuery(builder UpsertQueryBuilder, fmtr QueryFormatter, table string, columns []ColumnInfo, options UpsertOptions) (string, error) {
+	if uoqb, ok := builder.(UpsertOptionsQueryBuilder); ok {
+		return uoqb.UpsertWithOptions(fmtr, table, columns, options)
+	}
+	if !options.isZero() {
+		return "", fmt.Errorf("QueryBuilder %T does not implement UpsertOptionsQueryBuilder", builder)
+	}
+	return builder.Upsert(fmtr, table, columns)
+}
+
+// upsertOptionsFrom returns the last UpsertOptions in options
+// or the zero value.
+func upsertOptionsFrom(options []QueryOption) UpsertOptions {
+	for _, opt := range slices.Backward(options) {
+		if o, ok := opt.(UpsertOptions); ok {
+			return o
+		}
+	}
+	return UpsertOptions{}
+}
+
 // UpsertRowStruct inserts a new row or updates an existing one
 // if inserting conflicts on the primary key column(s).
 // The table name is derived from the `db` struct tag of an embedded sqldb.TableName field
@@ -16,6 +224,28 @@ import (
 // Primary key columns are identified by the "primarykey" option
 // in their `db` struct tag (e.g., ID int `db:"id,primarykey"`).
 // The struct must have at least one primary key field.
+// Pass [UpsertOptions] as option to configure the conflict handling
+// if builder implements [UpsertOptionsQueryBuilder].
+//
+// If the struct has a field with a `db` tag value having a ",version" suffix,
+// then rowStruct must be a pointer, builder must implement
+// [UpsertOptionsQueryBuilder], and the row is inserted or updated
+// with the incremented version of the field. An existing row is only
+// updated if its version column still has the value of the field
+// (optimistic locking). The field is set to the new version on success,
+// else an [ErrStaleRow] error is returned.
+//
+// Fields with a `db` tag value having a ",updated" suffix
+// and fields with a ",created" suffix that have the zero value
+// are upserted with the time of the [Clock] from ctx (default time.Now),
+// see [ContextWithClock]. The created column of an existing row
+// is never updated on conflict, see [UpsertOptions.Resolve].
+// If rowStruct is a pointer, then the updated fields are set to that time
+// after the upsert. Created fields are not set because an existing row
+// keeps its created time that is not known after the upsert.
+//
+// Columns of [Patch] fields that are not set are neither inserted
+// nor updated, see [PatchField].
 func UpsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, builder UpsertQueryBuilder, fmtr QueryFormatter, rowStruct StructWithTableName, options ...QueryOption) error {
 	if refl == nil {
 		return errors.New("UpsertRowStruct: nil StructReflector")
@@ -24,6 +254,13 @@ func UpsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 	if err != nil {
 		return err
 	}
+	_, err = upsertRowStruct(ctx, conn, refl, builder, fmtr, structVal, options)
+	return err
+}
+
+// upsertRowStruct also returns the upserted version
+// of structs with a version field.
+func upsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, builder UpsertQueryBuilder, fmtr QueryFormatter, structVal reflect.Value, options []QueryOption) (rowVersion, error) {
 	structType := structVal.Type()
 
 	var vals []any
@@ -38,35 +275,68 @@ func UpsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 		if ok {
 			vals = make([]any, len(cached.structFieldIndices))
 			for i, fieldIndex := range cached.structFieldIndices {
-				vals[i] = structVal.FieldByIndex(fieldIndex).Interface()
+				vals[i] = structFieldValue(refl, structVal, fieldIndex, &cached.columns[i])
 			}
-			err = conn.Exec(ctx, cached.query, vals...)
-			if err != nil {
-				return WrapErrorWithQuery(err, cached.query, vals, fmtr)
+			var version rowVersion
+			if cached.versionIndex >= 0 {
+				var err error
+				version, err = newRowVersion(structVal, cached.structFieldIndices[cached.versionIndex])
+				if err != nil {
+					return rowVersion{}, err
+				}
+				vals[cached.versionIndex] = version.next.Interface()
 			}
-			return nil
+			return version, execUpdateRowStruct(ctx, conn, refl, fmtr, structVal, cached, version, vals)
 		}
 	}
-	var cached queryCache
-	var columns []ColumnInfo
+	var (
+		cached  queryCache
+		columns []ColumnInfo
+		version rowVersion
+		err     error
+	)
 	columns, cached.structFieldIndices, vals, err = refl.ReflectStructColumnsFieldIndicesAndValues(structVal, append(options, IgnoreReadOnly)...)
 	if err != nil {
-		return err
+		return rowVersion{}, err
+	}
+	if hasPatchColumns(columns) {
+		// The query depends on which Patch fields are set
+		useCache = false
+		columns, cached.structFieldIndices, vals = withoutUnsetPatches(columns, cached.structFieldIndices, vals)
 	}
 	table, err := refl.TableNameForStruct(structType)
 	if err != nil {
-		return err
+		return rowVersion{}, err
 	}
+	upsertOptions := upsertOptionsFrom(options)
 	hasPK := slices.ContainsFunc(columns, func(col ColumnInfo) bool {
 		return col.PrimaryKey
 	})
-	if !hasPK {
-		return fmt.Errorf("UpsertRowStruct of table %s: %s has no mapped primary key field", table, structType)
+	if !hasPK && len(upsertOptions.ConflictColumns) == 0 {
+		return rowVersion{}, fmt.Errorf("UpsertRowStruct of table %s: %s has no mapped primary key field", table, structType)
 	}
-	cached.query, err = builder.Upsert(fmtr, table, columns)
+	cached.versionIndex, err = versionColumnIndex(table, columns)
 	if err != nil {
-		return fmt.Errorf("UpsertRowStruct of table %s: failed to create UPSERT query: %w", table, err)
+		return rowVersion{}, err
 	}
+	if cached.versionIndex >= 0 {
+		version, err = newRowVersion(structVal, cached.structFieldIndices[cached.versionIndex])
+		if err != nil {
+			return rowVersion{}, err
+		}
+		vals[cached.versionIndex] = version.next.Interface()
+		upsertOptions.VersionColumn = columns[cached.versionIndex].Name
+	}
+	cached.timestamps, err = timestampFieldsOf(refl, structType, columns, true)
+	if err != nil {
+		return rowVersion{}, err
+	}
+	cached.timestamps = cached.timestamps.forUpsert()
+	cached.query, err = upsertQuery(builder, fmtr, table, columns, upsertOptions)
+	if err != nil {
+		return rowVersion{}, fmt.Errorf("UpsertRowStruct of table %s: failed to create UPSERT query: %w", table, err)
+	}
+	cached.columns = columns
 	if useCache {
 		upsertRowStructQueryCacheMtx.Lock()
 		if _, ok := upsertRowStructQueryCache[structType]; !ok {
@@ -82,11 +352,7 @@ func UpsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 		upsertRowStructQueryCacheMtx.Unlock()
 	}
 
-	err = conn.Exec(ctx, cached.query, vals...)
-	if err != nil {
-		return WrapErrorWithQuery(err, cached.query, vals, fmtr)
-	}
-	return nil
+	return version, execUpdateRowStruct(ctx, conn, refl, fmtr, structVal, cached, version, vals)
 }
 
 // UpsertRowStructStmt prepares a statement for upserting rows of type S.
@@ -96,13 +362,36 @@ func UpsertRowStruct(ctx context.Context, conn Executor, refl StructReflector, b
 // Primary key columns are identified by the "primarykey" option
 // in their `db` struct tag (e.g., ID int `db:"id,primarykey"`).
 // The struct must have at least one primary key field.
+// Pass [UpsertOptions] as option to configure the conflict handling.
+// Structs with a version field are upserted with optimistic locking
+// like with [UpsertRowStruct], which requires S to be a pointer type.
+// Timestamp fields are handled like with [UpsertRowStruct].
+// Structs with [Patch] fields are not supported because
+// the upserted columns depend on which fields are set.
 // Returns an upsert function to upsert individual rows and a closeStmt
 // function that must be called when done to close the prepared statement.
 func UpsertRowStructStmt[S StructWithTableName](ctx context.Context, conn Preparer, refl StructReflector, builder UpsertQueryBuilder, fmtr QueryFormatter, options ...QueryOption) (upsert func(ctx context.Context, rowStruct S) error, closeStmt func() error, err error) {
 	if refl == nil {
 		return nil, nil, errors.New("UpsertRowStructStmt: nil StructReflector")
 	}
-	structType := reflect.TypeFor[S]()
+	upsertVal, closeStmt, err := upsertRowStructStmt(ctx, conn, refl, builder, fmtr, reflect.TypeFor[S](), options)
+	if err != nil {
+		return nil, nil, err
+	}
+	upsert = func(ctx context.Context, rowStruct S) error {
+		v, err := derefStruct(reflect.ValueOf(rowStruct))
+		if err != nil {
+			return err
+		}
+		_, err = upsertVal(ctx, v)
+		return err
+	}
+	return upsert, closeStmt, nil
+}
+
+// upsertRowStructStmt returns an upsert function that also returns
+// the upserted version of structs with a version field.
+func upsertRowStructStmt(ctx context.Context, conn Preparer, refl StructReflector, builder UpsertQueryBuilder, fmtr QueryFormatter, structType reflect.Type, options []QueryOption) (upsert func(ctx context.Context, structVal reflect.Value) (rowVersion, error), closeStmt func() error, err error) {
 	for structType.Kind() == reflect.Pointer {
 		structType = structType.Elem()
 	}
@@ -116,14 +405,35 @@ func UpsertRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 	if err != nil {
 		return nil, nil, err
 	}
+	upsertOptions := upsertOptionsFrom(options)
 	hasPK := slices.ContainsFunc(columns, func(col ColumnInfo) bool {
 		return col.PrimaryKey
 	})
-	if !hasPK {
+	if !hasPK && len(upsertOptions.ConflictColumns) == 0 {
 		return nil, nil, fmt.Errorf("UpsertRowStructStmt of table %s: %s has no mapped primary key field", table, structType)
 	}
+	if hasPatchColumns(columns) {
+		return nil, nil, fmt.Errorf("UpsertRowStructStmt of table %s: %s has Patch fields that can't be upserted with a prepared statement", table, structType)
+	}
+	versionIndex, err := versionColumnIndex(table, columns)
+	if err != nil {
+		return nil, nil, err
+	}
+	var versionFieldIndex []int
+	if versionIndex >= 0 {
+		versionFieldIndex, err = structFieldIndex(refl, structType, columns[versionIndex].Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		upsertOptions.VersionColumn = columns[versionIndex].Name
+	}
+	timestamps, err := timestampFieldsOf(refl, structType, columns, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	timestamps = timestamps.forUpsert()
 
-	query, err := builder.Upsert(fmtr, table, columns)
+	query, err := upsertQuery(builder, fmtr, table, columns, upsertOptions)
 	if err != nil {
 		return nil, nil, fmt.Errorf("UpsertRowStructStmt of table %s: failed to create UPSERT query: %w", table, err)
 	}
@@ -133,20 +443,32 @@ func UpsertRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 		return nil, nil, fmt.Errorf("UpsertRowStructStmt of table %s: failed to prepare UPSERT statement: %w", table, err)
 	}
 
-	upsert = func(ctx context.Context, rowStruct S) error {
-		v, err := derefStruct(reflect.ValueOf(rowStruct))
+	upsert = func(ctx context.Context, v reflect.Value) (rowVersion, error) {
+		vals, err := refl.ReflectStructValues(v, options...)
 		if err != nil {
-			return err
+			return rowVersion{}, err
 		}
-		vals, err := refl.ReflectStructValues(v, options...)
+		now := timestamps.now(ctx)
+		timestamps.setValues(v, vals, now)
+		if versionIndex < 0 {
+			err = stmt.Exec(ctx, vals...)
+			if err != nil {
+				return rowVersion{}, WrapErrorWithQuery(err, query, vals, fmtr)
+			}
+			return rowVersion{}, timestamps.writeBack(v, now)
+		}
+		version, err := newRowVersion(v, versionFieldIndex)
 		if err != nil {
-			return err
+			return rowVersion{}, err
 		}
-		err = stmt.Exec(ctx, vals...)
+		vals[versionIndex] = version.next.Interface()
+		err = execVersioned(refl, fmtr, v, version, query, vals, func() (int64, error) {
+			return stmt.ExecRowsAffected(ctx, vals...)
+		})
 		if err != nil {
-			return WrapErrorWithQuery(err, query, vals, fmtr)
+			return rowVersion{}, err
 		}
-		return nil
+		return version, timestamps.writeBack(v, now)
 	}
 	return upsert, stmt.Close, nil
 }
@@ -158,31 +480,73 @@ func UpsertRowStructStmt[S StructWithTableName](ctx context.Context, conn Prepar
 // Column names are derived from the `db` struct tags of the struct's fields.
 // Primary key columns are identified by the "primarykey" option
 // in their `db` struct tag (e.g., ID int `db:"id,primarykey"`).
+// Pass [UpsertOptions] as option to configure the conflict handling.
+// Structs with a version field are upserted with optimistic locking
+// like with [UpsertRowStruct] and the version fields of the slice
+// elements are set to the new versions.
+// Timestamp fields are handled like with [UpsertRowStruct].
+// Structs with [Patch] fields are upserted one by one
+// without a prepared statement.
 func UpsertRowStructs[S StructWithTableName](ctx context.Context, conn Connection, refl StructReflector, builder UpsertQueryBuilder, fmtr QueryFormatter, rowStructs []S, options ...QueryOption) error {
 	if refl == nil {
 		return errors.New("UpsertRowStructs: nil StructReflector")
 	}
+	// Pointers to the slice elements so that the version fields
+	// of non-pointer structs can be updated
+	structVals := make([]reflect.Value, len(rowStructs))
+	for i := range rowStructs {
+		structVal, err := derefStruct(reflect.ValueOf(&rowStructs[i]))
+		if err != nil {
+			return err
+		}
+		structVals[i] = structVal
+	}
 	switch len(rowStructs) {
 	case 0:
 		return nil
 	case 1:
-		return UpsertRowStruct(ctx, conn, refl, builder, fmtr, rowStructs[0], options...)
+		_, err := upsertRowStruct(ctx, conn, refl, builder, fmtr, structVals[0], options)
+		return err
+	}
+	columns, err := refl.ReflectStructColumns(structVals[0].Type(), options...)
+	if err != nil {
+		return err
 	}
-	return Transaction(ctx, conn, nil, func(tx Connection) (err error) {
-		upsertFunc, closeStmt, stmtErr := UpsertRowStructStmt[S](ctx, tx, refl, builder, fmtr, options...)
-		if stmtErr != nil {
-			return stmtErr
+	var upsertedVersions []rowVersion
+	err = Transaction(ctx, conn, nil, func(tx Connection) (err error) {
+		upsertFunc := func(ctx context.Context, structVal reflect.Value) (rowVersion, error) {
+			return upsertRowStruct(ctx, tx, refl, builder, fmtr, structVal, options)
+		}
+		// Structs with Patch fields are upserted one by one
+		// because the query depends on which fields are set
+		if !hasPatchColumns(columns) {
+			var closeStmt func() error
+			upsertFunc, closeStmt, err = upsertRowStructStmt(ctx, tx, refl, builder, fmtr, reflect.TypeFor[S](), options)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				err = errors.Join(err, closeStmt())
+			}()
 		}
-		defer func() {
-			err = errors.Join(err, closeStmt())
-		}()
 
-		for _, rowStruct := range rowStructs {
-			err = upsertFunc(ctx, rowStruct)
+		for _, structVal := range structVals {
+			version, err := upsertFunc(ctx, structVal)
 			if err != nil {
 				return err
 			}
+			if version.field.IsValid() {
+				upsertedVersions = append(upsertedVersions, version)
+			}
 		}
 		return nil
 	})
+	if err != nil {
+		// The transaction was rolled back
+		for _, version := range upsertedVersions {
+			version.reset()
+		}
+		return err
+	}
+	return nil
 }
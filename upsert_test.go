@@ -3,9 +3,67 @@ package sqldb
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// testUpsertOptionsBuilder extends testUpsertBuilder
+// with [UpsertOptionsQueryBuilder] for tests of [UpsertOptions].
+type testUpsertOptionsBuilder struct {
+	testUpsertBuilder
+}
+
+func (b testUpsertOptionsBuilder) UpsertWithOptions(formatter QueryFormatter, table string, columns []ColumnInfo, options UpsertOptions) (query string, err error) {
+	conflictColumns, assignments, err := options.Resolve(columns)
+	if err != nil {
+		return "", err
+	}
+	var q strings.Builder
+	insert, err := b.Insert(formatter, table, columns)
+	if err != nil {
+		return "", err
+	}
+	q.WriteString(insert)
+	q.WriteString(` ON CONFLICT(`)
+	for i, column := range conflictColumns {
+		if i > 0 {
+			q.WriteByte(',')
+		}
+		columnName, err := formatter.FormatColumnName(column)
+		if err != nil {
+			return "", err
+		}
+		q.WriteString(columnName)
+	}
+	q.WriteString(`) DO UPDATE SET`)
+	for i, assignment := range assignments {
+		if i > 0 {
+			q.WriteByte(',')
+		}
+		columnName, err := formatter.FormatColumnName(assignment.Column)
+		if err != nil {
+			return "", err
+		}
+		value := assignment.Expression
+		if assignment.ColumnIndex >= 0 {
+			value = formatter.FormatPlaceholder(assignment.ColumnIndex)
+		}
+		fmt.Fprintf(&q, ` %s=%s`, columnName, value)
+	}
+	where := options.Where
+	if options.VersionColumn != "" {
+		where = options.VersionWhere(table+"."+options.VersionColumn, "excluded."+options.VersionColumn)
+	}
+	if where != "" {
+		fmt.Fprintf(&q, ` WHERE %s`, where)
+	}
+	return q.String(), nil
+}
+
 func TestUpsertRowStruct(t *testing.T) {
 	wantQuery := "INSERT INTO test_table(id,name,active) VALUES($1,$2,$3) ON CONFLICT(id) DO UPDATE SET name=$2, active=$3"
 
@@ -67,6 +125,66 @@ func TestUpsertRowStruct(t *testing.T) {
 		assertArgs(t, gotArgs, []any{int64(2), "Bob", false})
 	})
 
+	t.Run("with UpsertOptions", func(t *testing.T) {
+		conn, refl, _, fmtr := newTestInterfaces()
+		builder := testUpsertOptionsBuilder{}
+		var gotQuery string
+		conn.MockExec = func(ctx context.Context, query string, args ...any) error {
+			gotQuery = query
+			return nil
+		}
+		row := reflectTestStruct{ID: 1, Name: "Alice", Active: true}
+		options := UpsertOptions{
+			UpdateColumns: []string{"active"},
+			Where:         "test_table.name = excluded.name",
+		}
+		err := UpsertRowStruct(t.Context(), conn, refl, builder, fmtr, row, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "INSERT INTO test_table(id,name,active) VALUES($1,$2,$3) ON CONFLICT(id) DO UPDATE SET active=$3 WHERE test_table.name = excluded.name"
+		if gotQuery != want {
+			t.Errorf("query = %q, want %q", gotQuery, want)
+		}
+	})
+
+	t.Run("UpsertOptions without UpsertOptionsQueryBuilder", func(t *testing.T) {
+		// given a builder that only implements UpsertQueryBuilder
+		conn, refl, builder, fmtr := newTestInterfaces()
+		row := reflectTestStruct{ID: 1, Name: "Alice", Active: true}
+
+		// when upserting with options
+		err := UpsertRowStruct(t.Context(), conn, refl, builder, fmtr, row, UpsertOptions{UpdateColumns: []string{"active"}})
+
+		// then no query is executed
+		require.ErrorContains(t, err, "does not implement UpsertOptionsQueryBuilder")
+		assert.Empty(t, conn.Recordings.Execs)
+	})
+
+	t.Run("no primary key with ConflictColumns", func(t *testing.T) {
+		conn, refl, _, fmtr := newTestInterfaces()
+		builder := testUpsertOptionsBuilder{}
+		var gotQuery string
+		conn.MockExec = func(ctx context.Context, query string, args ...any) error {
+			gotQuery = query
+			return nil
+		}
+		type noPKRow struct {
+			TableName `db:"no_pk_table"`
+			Email     string `db:"email"`
+			Name      string `db:"name"`
+		}
+		options := UpsertOptions{ConflictColumns: []string{"email"}}
+		err := UpsertRowStruct(t.Context(), conn, refl, builder, fmtr, noPKRow{Email: "a@example.com", Name: "test"}, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "INSERT INTO no_pk_table(email,name) VALUES($1,$2) ON CONFLICT(email) DO UPDATE SET name=$2"
+		if gotQuery != want {
+			t.Errorf("query = %q, want %q", gotQuery, want)
+		}
+	})
+
 	t.Run("exec error", func(t *testing.T) {
 		conn, refl, builder, fmtr := newTestInterfaces()
 		var execCount int
@@ -257,3 +375,174 @@ func TestUpsertRowStructs(t *testing.T) {
 		}
 	})
 }
+
+func TestUpsertOptions_Resolve(t *testing.T) {
+	columns := []ColumnInfo{
+		{Name: "id", PrimaryKey: true},
+		{Name: "email"},
+		{Name: "count"},
+		{Name: "updated_at"},
+	}
+
+	for _, scenario := range []struct {
+		name              string
+		options           UpsertOptions
+		columns           []ColumnInfo
+		wantConflict      []string
+		wantAssignments   []UpsertAssignment
+		wantErrorContains string
+	}{
+		{
+			name:         "zero value updates non-primary-key columns",
+			columns:      columns,
+			wantConflict: []string{"id"},
+			wantAssignments: []UpsertAssignment{
+				{Column: "email", ColumnIndex: 1},
+				{Column: "count", ColumnIndex: 2},
+				{Column: "updated_at", ColumnIndex: 3},
+			},
+		},
+		{
+			name:         "conflict columns excluded from default update",
+			options:      UpsertOptions{ConflictColumns: []string{"email"}},
+			columns:      columns,
+			wantConflict: []string{"email"},
+			wantAssignments: []UpsertAssignment{
+				{Column: "id", ColumnIndex: 0},
+				{Column: "count", ColumnIndex: 2},
+				{Column: "updated_at", ColumnIndex: 3},
+			},
+		},
+		{
+			name: "update columns and sorted expressions",
+			options: UpsertOptions{
+				UpdateColumns: []string{"updated_at"},
+				UpdateExpressions: map[string]string{
+					"count": "t.count + excluded.count",
+					"email": "lower(excluded.email)",
+				},
+			},
+			columns:      columns,
+			wantConflict: []string{"id"},
+			wantAssignments: []UpsertAssignment{
+				{Column: "updated_at", ColumnIndex: 3},
+				{Column: "count", ColumnIndex: -1, Expression: "t.count + excluded.count"},
+				{Column: "email", ColumnIndex: -1, Expression: "lower(excluded.email)"},
+			},
+		},
+		{
+			name:              "no primary key",
+			columns:           []ColumnInfo{{Name: "a"}},
+			wantErrorContains: "ConflictColumns",
+		},
+		{
+			name:              "only primary key columns",
+			columns:           []ColumnInfo{{Name: "id", PrimaryKey: true}},
+			wantErrorContains: "non-primary-key column",
+		},
+		{
+			name:              "conflict column not inserted",
+			options:           UpsertOptions{ConflictColumns: []string{"slug"}},
+			columns:           columns,
+			wantErrorContains: `conflict column "slug"`,
+		},
+		{
+			name:              "update column not inserted",
+			options:           UpsertOptions{UpdateColumns: []string{"slug"}},
+			columns:           columns,
+			wantErrorContains: `update column "slug"`,
+		},
+		{
+			name:              "update conflict column",
+			options:           UpsertOptions{UpdateExpressions: map[string]string{"id": "excluded.id"}},
+			columns:           columns,
+			wantErrorContains: `can't update conflict column "id"`,
+		},
+		{
+			name: "column updated twice",
+			options: UpsertOptions{
+				UpdateColumns:     []string{"count"},
+				UpdateExpressions: map[string]string{"count": "t.count + 1"},
+			},
+			columns:           columns,
+			wantErrorContains: `column "count" more than once`,
+		},
+		{
+			name:         "version column appended to update columns",
+			options:      UpsertOptions{UpdateColumns: []string{"email"}, VersionColumn: "count"},
+			columns:      columns,
+			wantConflict: []string{"id"},
+			wantAssignments: []UpsertAssignment{
+				{Column: "email", ColumnIndex: 1},
+				{Column: "count", ColumnIndex: 2},
+			},
+		},
+		{
+			name:              "version column not inserted",
+			options:           UpsertOptions{VersionColumn: "version"},
+			columns:           columns,
+			wantErrorContains: `version column "version"`,
+		},
+		{
+			name:              "version conflict column",
+			options:           UpsertOptions{ConflictColumns: []string{"email"}, VersionColumn: "email"},
+			columns:           columns,
+			wantErrorContains: `version column "email" can't be a conflict column`,
+		},
+		{
+			name:         "created column excluded from default update",
+			columns:      []ColumnInfo{{Name: "id", PrimaryKey: true}, {Name: "created_at", Created: true}, {Name: "email"}},
+			wantConflict: []string{"id"},
+			wantAssignments: []UpsertAssignment{
+				{Column: "email", ColumnIndex: 2},
+			},
+		},
+		{
+			name:              "update created column",
+			options:           UpsertOptions{UpdateColumns: []string{"created_at"}},
+			columns:           []ColumnInfo{{Name: "id", PrimaryKey: true}, {Name: "created_at", Created: true}, {Name: "email"}},
+			wantErrorContains: `can't update created column "created_at"`,
+		},
+		{
+			name:         "updated column appended to update columns",
+			options:      UpsertOptions{UpdateColumns: []string{"email"}},
+			columns:      []ColumnInfo{{Name: "id", PrimaryKey: true}, {Name: "email"}, {Name: "updated_at", Updated: true}},
+			wantConflict: []string{"id"},
+			wantAssignments: []UpsertAssignment{
+				{Column: "email", ColumnIndex: 1},
+				{Column: "updated_at", ColumnIndex: 2},
+			},
+		},
+	} {
+		t.Run(scenario.name, func(t *testing.T) {
+			// when
+			conflict, assignments, err := scenario.options.Resolve(scenario.columns)
+
+			// then
+			if scenario.wantErrorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), scenario.wantErrorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.wantConflict, conflict)
+			assert.Equal(t, scenario.wantAssignments, assignments)
+		})
+	}
+}
+
+func TestUpsertOptions_VersionWhere(t *testing.T) {
+	for _, scenario := range []struct {
+		name    string
+		options UpsertOptions
+		want    string
+	}{
+		{name: "no version column", options: UpsertOptions{Where: "t.a > 0"}, want: "t.a > 0"},
+		{name: "version column", options: UpsertOptions{VersionColumn: "version"}, want: "t.version = excluded.version - 1"},
+		{name: "version column and where", options: UpsertOptions{VersionColumn: "version", Where: "t.a > 0 OR t.b > 0"}, want: "(t.a > 0 OR t.b > 0) AND t.version = excluded.version - 1"},
+	} {
+		t.Run(scenario.name, func(t *testing.T) {
+			assert.Equal(t, scenario.want, scenario.options.VersionWhere("t.version", "excluded.version"))
+		})
+	}
+}